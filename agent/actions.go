@@ -0,0 +1,477 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/console"
+)
+
+// actionHandler выполняет действие решения модели
+type actionHandler func(a *Agent, ctx context.Context, decision *ai.Decision) error
+
+// actionHandlers - обработчики действий реестра ai.Actions. executeAction выполняет
+// только действия из этой таблицы, а CheckActions сверяет ее с реестром.
+var actionHandlers = map[string]actionHandler{
+	"navigate":        (*Agent).navigate,
+	"click":           (*Agent).click,
+	"fill":            (*Agent).fill,
+	"press_key":       (*Agent).pressKey,
+	"switch_tab":      (*Agent).switchTab,
+	"close_tab":       (*Agent).closeTab,
+	"switch_new_tab":  withoutContext((*Agent).switchNewTab),
+	"wait":            (*Agent).wait,
+	"extract":         (*Agent).extract,
+	"extract_text":    withoutContext((*Agent).extractText),
+	"upload":          (*Agent).upload,
+	"download":        (*Agent).download,
+	"play_media":      (*Agent).media,
+	"pause_media":     (*Agent).media,
+	"read_document":   (*Agent).readDocument,
+	"select_text":     (*Agent).selectText,
+	"set_range":       (*Agent).setRange,
+	"scroll_to_load":  withoutContext((*Agent).scrollToLoad),
+	"go_back":         withoutContext((*Agent).goBack),
+	"go_forward":      withoutContext((*Agent).goForward),
+	"reload":          withoutContext((*Agent).reload),
+	"set_checkbox":    (*Agent).setCheckbox,
+	"set_choices":     (*Agent).setChoices,
+	"paginate_scrape": withoutContext((*Agent).paginateScrape),
+	"save_pdf":        withoutContext((*Agent).savePDF),
+	"scroll":          withoutContext((*Agent).scrollTo),
+	"select":          (*Agent).selectOption,
+	"submit":          (*Agent).submit,
+	// Завершение обрабатывает processDecision; сюда complete попадает только при повторе
+	"complete": func(*Agent, context.Context, *ai.Decision) error { return nil },
+}
+
+// withoutContext приводит обработчик без контекста к actionHandler
+func withoutContext(handler func(a *Agent, decision *ai.Decision) error) actionHandler {
+	return func(a *Agent, _ context.Context, decision *ai.Decision) error {
+		return handler(a, decision)
+	}
+}
+
+// CheckActions сверяет обработчики агента с реестром действий ai.Actions. Действие
+// реестра без обработчика модель увидит в промпте, но не сможет выполнить, а
+// обработчик без записи в реестре модель не выберет никогда. Вызывается самопроверкой.
+func CheckActions() error {
+	var problems []string
+	documented := make(map[string]bool)
+	for _, spec := range ai.Actions() {
+		documented[spec.Name] = true
+		if _, ok := actionHandlers[spec.Name]; !ok {
+			problems = append(problems, fmt.Sprintf("действие %s описано в реестре, но не выполняется агентом", spec.Name))
+		}
+	}
+	var undocumented []string
+	for name := range actionHandlers {
+		if !documented[name] {
+			undocumented = append(undocumented, name)
+		}
+	}
+	sort.Strings(undocumented)
+	for _, name := range undocumented {
+		problems = append(problems, fmt.Sprintf("действие %s выполняется агентом, но не описано в реестре ai.Actions", name))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("реестр действий не совпадает с агентом: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// navigate переходит на URL решения; адрес без схемы дополняется https://
+func (a *Agent) navigate(ctx context.Context, decision *ai.Decision) error {
+	if decision.URL == "" {
+		return fmt.Errorf("URL не указан для навигации. Используй поле 'url' с адресом (можно прямой URL или из списка links)")
+	}
+
+	// Нормализуем URL - добавляем https:// если отсутствует
+	url := decision.URL
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		// Если это домен без протокола, добавляем https://
+		if strings.Contains(url, ".") && !strings.Contains(url, " ") {
+			url = "https://" + url
+		}
+	}
+
+	if a.skipSameURLNavigation(url, decision.ForceReload) {
+		return nil
+	}
+	if err := a.waitForHostPoliteness(ctx, url); err != nil {
+		return err
+	}
+	fmt.Printf("🌐 Переход на: %s\n", url)
+	stopStatus := console.StartStatus("загрузка страницы...")
+	defer stopStatus()
+	return a.browser.Navigate(url)
+}
+
+// click кликает по тексту или селектору, во фрейме - по тексту
+func (a *Agent) click(ctx context.Context, decision *ai.Decision) error {
+	// Файл, который начнет скачивать этот клик, и вкладку, которую он откроет,
+	// download и switch_new_tab найдут после меток
+	a.downloadMark = a.browser.MarkDownloads()
+	a.newTabMark = a.browser.MarkNewTabs()
+	if decision.Frame != "" && decision.Text != "" {
+		fmt.Printf("🖱️  Клик по тексту во фрейме %s: %s\n", decision.Frame, decision.Text)
+		return a.browser.ClickByTextInFrame(decision.Frame, decision.Text)
+	}
+	if err := a.verifyTarget(decision); err != nil {
+		return err
+	}
+	if decision.Text != "" {
+		fmt.Printf("🖱️  Клик по тексту: %s\n", decision.Text)
+		return a.browser.ClickByText(decision.Text)
+	} else if decision.Selector != "" {
+		fmt.Printf("🖱️  Клик по селектору: %s\n", decision.Selector)
+		return a.browser.ClickElement(decision.Selector)
+	}
+	return fmt.Errorf("не указан текст или селектор для клика. Используй поле 'text' с текстом кнопки/ссылки из списка buttons/links, или поле 'selector' с CSS селектором")
+}
+
+// fill заполняет поле по селектору или placeholder, во фрейме - по подписи
+func (a *Agent) fill(ctx context.Context, decision *ai.Decision) error {
+	if decision.Value == "" {
+		return fmt.Errorf("не указано значение для заполнения (value пустое)")
+	}
+	if decision.Frame != "" {
+		target := decision.Text
+		if target == "" {
+			target = decision.Selector
+		}
+		fmt.Printf("✍️  Заполнение поля во фрейме %s: %s = %s\n", decision.Frame, target, decision.Value)
+		return a.browser.FillInputInFrame(decision.Frame, target, decision.Value)
+	}
+	if err := a.verifyTarget(decision); err != nil {
+		return err
+	}
+	if decision.Selector != "" {
+		fmt.Printf("✍️  Заполнение поля: %s = %s\n", decision.Selector, decision.Value)
+		return a.browser.FillInput(decision.Selector, decision.Value)
+	} else if decision.Text != "" {
+		fmt.Printf("✍️  Заполнение поля по placeholder: %s = %s\n", decision.Text, decision.Value)
+		return a.browser.FillInputByPlaceholder(decision.Text, decision.Value)
+	}
+	return fmt.Errorf("не указан селектор или placeholder для заполнения. Используй поле 'text' с placeholder/name из списка inputs, или поле 'selector' с CSS селектором")
+}
+
+// pressKey нажимает клавишу решения
+func (a *Agent) pressKey(ctx context.Context, decision *ai.Decision) error {
+	if decision.Key == "" {
+		return fmt.Errorf("не указана клавиша для нажатия (key пустое). Используй поле 'key' с названием клавиши (delete, enter, escape и т.д.)")
+	}
+	fmt.Printf("⌨️  Нажатие клавиши: %s\n", decision.Key)
+	return a.browser.PressKey(decision.Key)
+}
+
+// switchTab переключается на вкладку по номеру из списка вкладок
+func (a *Agent) switchTab(ctx context.Context, decision *ai.Decision) error {
+	if decision.TabIndex <= 0 {
+		return fmt.Errorf("не указан индекс вкладки (tab_index пустое или неверное). Используй поле 'tab_index' с номером вкладки (1, 2, 3...)")
+	}
+	// Получаем список вкладок
+	tabs, err := a.browser.GetAllTabs()
+	if err != nil {
+		return fmt.Errorf("не удалось получить список вкладок: %w", err)
+	}
+	if decision.TabIndex > len(tabs) {
+		return fmt.Errorf("неверный индекс вкладки: %d (всего вкладок: %d)", decision.TabIndex, len(tabs))
+	}
+	targetTab := tabs[decision.TabIndex-1]
+	fmt.Printf("🔄 Переключение на вкладку %d: %s\n", decision.TabIndex, targetTab.Title)
+	return a.browser.SwitchToTab(targetTab.ID)
+}
+
+// closeTab закрывает вкладку по номеру; активную - после переключения на соседнюю
+func (a *Agent) closeTab(ctx context.Context, decision *ai.Decision) error {
+	if decision.TabIndex <= 0 {
+		return fmt.Errorf("не указан индекс вкладки (tab_index пустое или неверное). Используй поле 'tab_index' с номером вкладки (1, 2, 3...)")
+	}
+	// Получаем список вкладок
+	tabs, err := a.browser.GetAllTabs()
+	if err != nil {
+		return fmt.Errorf("не удалось получить список вкладок: %w", err)
+	}
+	if decision.TabIndex > len(tabs) {
+		return fmt.Errorf("неверный индекс вкладки: %d (всего вкладок: %d)", decision.TabIndex, len(tabs))
+	}
+	if len(tabs) == 1 {
+		return fmt.Errorf("нельзя закрыть единственную открытую вкладку")
+	}
+	targetTab := tabs[decision.TabIndex-1]
+	if targetTab.IsActive {
+		// Если закрываем активную вкладку, сначала переключимся на другую
+		newActiveIndex := 0
+		if decision.TabIndex == 1 {
+			newActiveIndex = 1 // переключимся на следующую
+		}
+		if err := a.browser.SwitchToTab(tabs[newActiveIndex].ID); err != nil {
+			return fmt.Errorf("не удалось переключиться перед закрытием: %w", err)
+		}
+	}
+	fmt.Printf("❌ Закрытие вкладки %d: %s\n", decision.TabIndex, targetTab.Title)
+	return a.browser.CloseTab(targetTab.ID)
+}
+
+// newTabTimeout - сколько ждать вкладку, которую открывает клик
+const newTabTimeout = 10 * time.Second
+
+// switchNewTab ждет вкладку, открытую предыдущим кликом (ссылка с target="_blank",
+// "предпросмотр в новой вкладке"), и переключается на нее
+func (a *Agent) switchNewTab(decision *ai.Decision) error {
+	fmt.Printf("🗂️  Ожидание новой вкладки...\n")
+	if _, err := a.browser.SwitchToNewTab(a.newTabMark, newTabTimeout); err != nil {
+		return err
+	}
+	current, _ := a.browser.GetCurrentURL()
+	fmt.Printf("🔄 Переключение на новую вкладку: %s\n", current)
+	a.history = append(a.history, fmt.Sprintf("Открылась новая вкладка (%s) - дальнейшие действия выполняются в ней", current))
+	return nil
+}
+
+// upload загружает файлы (пути через запятую) в поле <input type="file">;
+// принимаются только файлы задачи и UPLOAD_DIRS (см. uploadPath)
+func (a *Agent) upload(ctx context.Context, decision *ai.Decision) error {
+	var paths []string
+	for _, path := range strings.Split(decision.Value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		resolved, err := a.uploadPath(path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, resolved)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("не указан путь к файлу для загрузки. Используй поле 'value' с путем к файлу (несколько файлов - через запятую)")
+	}
+	fmt.Printf("📎 Загрузка файлов %v в поле: %s\n", paths, decision.Selector)
+	return a.browser.UploadFile(decision.Selector, paths)
+}
+
+// selectText выделяет текст и сохраняет выделение в переменную selected_text и историю
+func (a *Agent) selectText(ctx context.Context, decision *ai.Decision) error {
+	var err error
+	if decision.Selector != "" {
+		fmt.Printf("🖍️  Выделение текста: %s\n", decision.Selector)
+		err = a.browser.SelectText(decision.Selector)
+	} else if decision.Text != "" {
+		fmt.Printf("🖍️  Выделение текста: %s\n", decision.Text)
+		err = a.browser.SelectTextByContent(decision.Text)
+	}
+	if err != nil {
+		return err
+	}
+	// Без селектора и текста читаем текущее выделение (например, сделанное пользователем)
+	selected, err := a.browser.GetSelectedText()
+	if err != nil {
+		return err
+	}
+	selected = strings.TrimSpace(selected)
+	if selected == "" {
+		return fmt.Errorf("на странице ничего не выделено")
+	}
+	a.vars["selected_text"] = selected
+	preview := []rune(selected)
+	if len(preview) > 1000 {
+		preview = append(preview[:1000], []rune("...")...)
+	}
+	a.history = append(a.history, fmt.Sprintf("Выделенный текст: «%s»", string(preview)))
+	return nil
+}
+
+// setRange ставит ползунок на значение решения
+func (a *Agent) setRange(ctx context.Context, decision *ai.Decision) error {
+	target := decision.Selector
+	if target == "" {
+		target = decision.Text
+	}
+	if target == "" {
+		return fmt.Errorf("не указан ползунок. Используй 'selector' из списка \"Ползунки\" или 'text' (подпись ползунка)")
+	}
+	value, err := parseRangeValue(decision.Value)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🎚️  Ползунок %s -> %s\n", target, strconv.FormatFloat(value, 'f', -1, 64))
+	set, err := a.browser.SetRange(target, value)
+	if err != nil {
+		return err
+	}
+	if set != value {
+		// Модель должна знать, что фильтр встал не на запрошенное значение
+		a.history = append(a.history, fmt.Sprintf("Ползунок %s установлен на %s вместо %s (граница или шаг ползунка)",
+			target, strconv.FormatFloat(set, 'f', -1, 64), strconv.FormatFloat(value, 'f', -1, 64)))
+	}
+	return nil
+}
+
+// setCheckbox ставит флажок или снимает его при value "false"
+func (a *Agent) setCheckbox(ctx context.Context, decision *ai.Decision) error {
+	target := decision.Selector
+	if target == "" {
+		target = decision.Text
+	}
+	if target == "" {
+		return fmt.Errorf("не указан флажок. Используй 'text' (подпись из списка полей ввода) или 'selector'")
+	}
+	checked := checkboxState(decision.Value)
+	fmt.Printf("☑️  Флажок %s -> %t\n", target, checked)
+	return a.browser.SetCheckbox(target, checked)
+}
+
+// setChoices выбирает несколько вариантов группы
+func (a *Agent) setChoices(ctx context.Context, decision *ai.Decision) error {
+	values := choiceValues(decision)
+	if len(values) == 0 {
+		return fmt.Errorf("не указаны варианты. Заполни 'values' (список вариантов) и 'text' (подпись группы)")
+	}
+	fmt.Printf("☑️  Группа '%s': %s\n", decision.Text, strings.Join(values, ", "))
+	return a.browser.SetChoices(decision.Text, values)
+}
+
+// checkboxState разбирает value действия set_checkbox: пусто или "true" - поставить флажок,
+// "false", "off", "нет" и т.п. - снять
+func checkboxState(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "false", "off", "no", "0", "снять", "нет":
+		return false
+	}
+	return true
+}
+
+// choiceValues возвращает варианты set_choices: список values или value через запятую
+func choiceValues(decision *ai.Decision) []string {
+	if len(decision.Values) > 0 {
+		return decision.Values
+	}
+	var values []string
+	for _, v := range strings.Split(decision.Value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// selectOption выбирает вариант выпадающего списка
+func (a *Agent) selectOption(ctx context.Context, decision *ai.Decision) error {
+	if decision.Text == "" {
+		return fmt.Errorf("не указан вариант для выбора. Заполни 'text' (вариант из списка) и 'selector' (селектор или подпись списка)")
+	}
+	if decision.Frame != "" {
+		fmt.Printf("🔽 Выбор во фрейме %s: %s = %s\n", decision.Frame, decision.Selector, decision.Text)
+		return a.browser.SelectOptionInFrame(decision.Frame, decision.Selector, decision.Text)
+	}
+	fmt.Printf("🔽 Выбор в списке %s: %s\n", decision.Selector, decision.Text)
+	return a.browser.SelectOption(decision.Selector, decision.Text)
+}
+
+// submit отправляет форму поля в фокусе или поля selector ее кнопкой отправки. Если
+// такой кнопки в форме нет, браузер предлагает первую видимую кнопку формы - агент
+// нажимает ее, только если она проходит проверки клика (autoClickBlocked).
+func (a *Agent) submit(ctx context.Context, decision *ai.Decision) error {
+	target := decision.Selector
+	if target == "" {
+		target = "поле в фокусе"
+	}
+	fallback := false
+	approve := func(control string) error {
+		if reason := a.autoClickBlocked(control); reason != "" {
+			return fmt.Errorf("в форме нет кнопки отправки, а первая кнопка формы '%s' не нажимается автоматически: %s - кликни нужную кнопку по тексту (click)", control, reason)
+		}
+		fallback = true
+		return nil
+	}
+	var control string
+	var err error
+	if decision.Frame != "" {
+		fmt.Printf("📨 Отправка формы во фрейме %s: %s\n", decision.Frame, target)
+		control, err = a.browser.SubmitEnclosingFormInFrame(decision.Frame, decision.Selector, approve)
+	} else {
+		fmt.Printf("📨 Отправка формы: %s\n", target)
+		control, err = a.browser.SubmitEnclosingForm(decision.Selector, approve)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case fallback:
+		a.history = append(a.history, fmt.Sprintf("Форма отправлена кнопкой '%s' (кнопки отправки в форме нет, нажата первая кнопка формы)", control))
+	case control != "":
+		a.history = append(a.history, fmt.Sprintf("Форма отправлена кнопкой '%s'", control))
+	default:
+		a.history = append(a.history, "Форма отправлена (кнопки отправки в форме нет)")
+	}
+	return nil
+}
+
+// wait ждет элемент wait_for или две секунды
+func (a *Agent) wait(ctx context.Context, decision *ai.Decision) error {
+	if decision.WaitFor != "" {
+		fmt.Printf("⏳ Ожидание элемента: %s\n", decision.WaitFor)
+		return a.browser.WaitForElement(decision.WaitFor, 10*time.Second)
+	}
+	fmt.Printf("⏳ Ожидание 2 секунды...\n")
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// extract запрашивает полный анализ страницы с текстом на следующем шаге
+func (a *Agent) extract(ctx context.Context, decision *ai.Decision) error {
+	fmt.Printf("📄 Извлечение информации со страницы...\n")
+	// Компактный промпт показывает текст страницы только по запросу
+	a.aiClient.RequestPageText()
+	a.forceFullExtraction = true
+	return nil
+}
+
+// extractText читает текст одного элемента по селектору и записывает его в историю и
+// в decision.Metadata["extracted"]
+func (a *Agent) extractText(decision *ai.Decision) error {
+	if decision.Selector == "" {
+		return fmt.Errorf("не указан элемент. Заполни 'selector' - CSS селектор элемента, текст которого нужно прочитать")
+	}
+	fmt.Printf("📄 Текст элемента: %s\n", decision.Selector)
+	text, err := a.browser.GetText(decision.Selector)
+	if err != nil {
+		return err
+	}
+	if decision.Metadata == nil {
+		decision.Metadata = make(map[string]string)
+	}
+	decision.Metadata["extracted"] = text
+	a.vars["extracted_text"] = text
+	if text == "" {
+		a.history = append(a.history, fmt.Sprintf("Текст элемента %s: элемент пуст", decision.Selector))
+		return nil
+	}
+	preview := []rune(text)
+	if len(preview) > 1000 {
+		preview = append(preview[:1000], []rune("...")...)
+	}
+	a.history = append(a.history, fmt.Sprintf("Текст элемента %s: «%s»", decision.Selector, string(preview)))
+	return nil
+}
+
+// media запускает или ставит на паузу видео/аудио по селектору или первый медиа-элемент
+func (a *Agent) media(ctx context.Context, decision *ai.Decision) error {
+	target := decision.Selector
+	if target == "" {
+		target = "первый медиа-элемент"
+	}
+	if decision.Action == "play_media" {
+		fmt.Printf("▶️  Воспроизведение: %s\n", target)
+		return a.browser.PlayMedia(decision.Selector)
+	}
+	fmt.Printf("⏸️  Пауза: %s\n", target)
+	return a.browser.PauseMedia(decision.Selector)
+}
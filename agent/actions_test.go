@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// Каждое действие реестра выполняется агентом, и каждый обработчик описан в реестре
+func TestCheckActions(t *testing.T) {
+	if err := CheckActions(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckActionsReportsUndocumentedHandler(t *testing.T) {
+	actionHandlers["teleport"] = withoutContext(func(*Agent, *ai.Decision) error { return nil })
+	defer delete(actionHandlers, "teleport")
+
+	err := CheckActions()
+	if err == nil || !strings.Contains(err.Error(), "teleport") {
+		t.Errorf("CheckActions() = %v, want an error about teleport", err)
+	}
+}
+
+func TestCheckboxState(t *testing.T) {
+	for value, want := range map[string]bool{
+		"": true, "true": true, "on": true, "поставить": true,
+		"false": false, " OFF ": false, "no": false, "0": false, "снять": false, "Нет": false,
+	} {
+		if got := checkboxState(value); got != want {
+			t.Errorf("checkboxState(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestChoiceValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision ai.Decision
+		want     []string
+	}{
+		{"values list", ai.Decision{Values: []string{"Красный", "Синий"}, Value: "игнорируется"}, []string{"Красный", "Синий"}},
+		{"comma separated value", ai.Decision{Value: " S, M ,,L "}, []string{"S", "M", "L"}},
+		{"empty", ai.Decision{Value: " , "}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := choiceValues(&tt.decision); strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("choiceValues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
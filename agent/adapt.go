@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// timeoutRetryMultiplier - во сколько раз увеличиваются таймауты при повторе после таймаута
+const timeoutRetryMultiplier = 2.0
+
+// adaptation описывает реакцию агента на ошибку действия
+type adaptation struct {
+	Kind        string // timeout, scroll_into_view, full_extraction, frame_gone, delay
+	Description string // стратегия для истории
+	Recovered   bool   // локальный повтор прошел успешно
+}
+
+// adaptToError меняет условия следующей попытки в зависимости от ошибки:
+// таймаут - повтор с увеличенными таймаутами, элемент не видим или перекрыт -
+// прокрутка к нему и повтор без обращения к модели, элемент не найден - полный
+// анализ страницы на следующем шаге и список похожих элементов для модели.
+// Клик и нажатие клавиши не идемпотентны: таймаут после клика может значить, что клик
+// прошел и страница еще грузится, поэтому они повторяются, только если браузер сообщил,
+// что до цели действие не дошло (элемент не найден, не видим или перекрыт).
+// После смены страницы (errPageChanged) действие не повторяется никогда.
+func (a *Agent) adaptToError(ctx context.Context, err error, decision *ai.Decision) adaptation {
+	errStr := strings.ToLower(err.Error())
+	retriable := decision.Action == "click" || decision.Action == "fill" || decision.Action == "wait" || decision.Action == "press_key" || decision.Action == "upload"
+	if errors.Is(err, errPageChanged) || ((decision.Action == "click" || decision.Action == "press_key") && !targetNotReached(errStr)) {
+		retriable = false
+	}
+
+	switch {
+	case errors.Is(err, browser.ErrFrameGone):
+		a.forceFullExtraction = true
+		return adaptation{Kind: "frame_gone", Description: fmt.Sprintf("фрейм %s пересоздан страницей - на следующем шаге будет новый анализ, используй новый идентификатор фрейма", decision.Frame)}
+
+	case retriable && (strings.Contains(errStr, "timeout") || strings.Contains(errStr, "таймаут") || strings.Contains(errStr, "deadline exceeded")):
+		fmt.Printf("🔁 Повтор с увеличенным в %.0f раза временем ожидания...\n", timeoutRetryMultiplier)
+		a.browser.SetTimeoutMultiplier(timeoutRetryMultiplier)
+		retryErr := a.executeAction(ctx, decision)
+		a.browser.SetTimeoutMultiplier(1)
+		if retryErr == nil {
+			return adaptation{Kind: "timeout", Description: fmt.Sprintf("таймаут - повтор с увеличенным в %.0f раза временем ожидания прошел успешно", timeoutRetryMultiplier), Recovered: true}
+		}
+		return adaptation{Kind: "timeout", Description: fmt.Sprintf("таймаут - повтор с увеличенным временем ожидания не помог (%v), выбери другой элемент или подожди загрузки", retryErr)}
+
+	case retriable && (strings.Contains(errStr, "not visible") || strings.Contains(errStr, "видим") ||
+		strings.Contains(errStr, "covered") || strings.Contains(errStr, "перекрыт") || strings.Contains(errStr, "viewport")):
+		fmt.Printf("🔁 Прокрутка к элементу и повтор...\n")
+		if scrollErr := a.browser.ScrollIntoView(decision.Selector, decision.Text); scrollErr != nil {
+			return adaptation{Kind: "scroll_into_view", Description: fmt.Sprintf("элемент не видим, прокрутить к нему не удалось (%v) - подожди загрузки или закрой перекрывающий элемент", scrollErr)}
+		}
+		retryErr := a.executeAction(ctx, decision)
+		if retryErr == nil {
+			return adaptation{Kind: "scroll_into_view", Description: "элемент не был видим - после прокрутки к нему действие выполнено", Recovered: true}
+		}
+		return adaptation{Kind: "scroll_into_view", Description: fmt.Sprintf("элемент не видим даже после прокрутки (%v) - закрой перекрывающий элемент (escape, крестик) или выбери другой", retryErr)}
+
+	case strings.Contains(errStr, "not found") || strings.Contains(errStr, "не найден"):
+		a.forceFullExtraction = true
+		description := "элемент не найден - на следующем шаге будет полный анализ страницы"
+		if decision.Text != "" {
+			if similar, simErr := a.browser.SimilarElements(decision.Text, 5); simErr == nil && len(similar) > 0 {
+				description += fmt.Sprintf(". Похожие элементы на странице: '%s'", strings.Join(similar, "', '"))
+			}
+		}
+		return adaptation{Kind: "full_extraction", Description: description}
+	}
+
+	return adaptation{Kind: "delay", Description: "повторю попытку с задержкой"}
+}
+
+// targetNotReached сообщает, что по тексту ошибки действие до цели не дошло: браузер
+// не нашел элемент или не смог по нему кликнуть, потому что он не видим или перекрыт
+func targetNotReached(errStr string) bool {
+	for _, marker := range []string{"not found", "не найден", "not visible", "is covered", "перекрыт"} {
+		if strings.Contains(errStr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// quickPageInfo возвращает быструю информацию о странице, если не запрошен полный анализ
+// (после ошибки "не найден" или прокрутки ленты scroll_to_load)
+func (a *Agent) quickPageInfo() (*browser.QuickPageInfo, error) {
+	if a.forceFullExtraction {
+		a.forceFullExtraction = false
+		fmt.Printf("🔍 Полный анализ страницы\n")
+		return nil, fmt.Errorf("full extraction requested")
+	}
+	return a.pageSource().GetQuickPageInfo()
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// Ошибки, после которых клик мог пройти, не повторяются: агент без браузера упал бы
+// на повторе, поэтому тест заодно проверяет, что до браузера дело не доходит
+func TestAdaptToErrorDoesNotRepeatClicksThatMayHaveLanded(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		err    error
+	}{
+		{"click timeout", "click", errors.New("context deadline exceeded")},
+		{"press_key timeout", "press_key", errors.New("таймаут ожидания навигации")},
+		{"click after page change", "click", fmt.Errorf("%w: новый адрес", errPageChanged)},
+		{"fill after page change", "fill", fmt.Errorf("%w: timeout", errPageChanged)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{}
+			got := a.adaptToError(context.Background(), tt.err, &ai.Decision{Action: tt.action, Text: "Оформить"})
+			if got.Kind != "delay" || got.Recovered {
+				t.Errorf("adaptToError() = %+v, want delay without retry", got)
+			}
+		})
+	}
+}
+
+func TestTargetNotReached(t *testing.T) {
+	tests := map[string]bool{
+		"element #buy not found - на странице нет такого элемента даже среди скрытых: context deadline exceeded": true,
+		"element with text 'ok' is covered by another element: div.overlay":                                      true,
+		"element .menu is not visible after scroll: timeout":                                                     true,
+		"context deadline exceeded":              false,
+		"failed to wait for navigation: timeout": false,
+	}
+	for errStr, want := range tests {
+		if got := targetNotReached(errStr); got != want {
+			t.Errorf("targetNotReached(%q) = %v, want %v", errStr, got, want)
+		}
+	}
+}
@@ -1,75 +1,282 @@
 package agent
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	neturl "net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Angabebr/Golang-AI-agent/ai"
 	"github.com/Angabebr/Golang-AI-agent/browser"
+	"github.com/Angabebr/Golang-AI-agent/bundle"
+	"github.com/Angabebr/Golang-AI-agent/console"
 )
 
+// defaultHostDelay - минимальный интервал между переходами на один домен по умолчанию
+const defaultHostDelay = 1 * time.Second
+
+// defaultMaxAutoScrolls - сколько раз scroll_to_load прокручивает ленту по умолчанию
+const defaultMaxAutoScrolls = 30
+
 type Agent struct {
-	browser       *browser.Browser
-	aiClient      *ai.Client
-	task          string
-	maxIterations int
-	errorCount    int
-	maxErrors     int
-	retryStrategy string
+	browser                 *browser.Browser
+	aiClient                *ai.Client
+	task                    string
+	maxIterations           int
+	errorCount              int
+	maxErrors               int
+	retryStrategy           string
+	history                 []string
+	safeMode                bool
+	completed               bool
+	summary                 string
+	extractedData           json.RawMessage
+	resultSchema            map[string]interface{}
+	schemaRetries           int
+	missingFields           []string
+	hostDelay               time.Duration
+	lastHostVisit           map[string]time.Time
+	iteration               int
+	vars                    map[string]string
+	checkpointPath          string
+	watchStatePath          string
+	confirmationPolicy      ConfirmationPolicy
+	disableDestructiveCheck bool
+	confirmations           []string
+	interactive             bool
+	allowHandoff            bool
+	elements                *elementSnapshot // ссылки и кнопки предыдущего анализа страницы
+	otpAttempts             map[string]int
+	preferredTargets        []string
+	transcriptDir           string
+	transcript              *os.File
+	runMetadata             *RunMetadata
+	chromeVersion           string
+	running                 bool
+	forceFullExtraction     bool
+	documents               []DocumentAnswer
+	changeNote              string     // изменилась ли страница после клика или ввода (для истории)
+	savedFiles              []string   // файлы, сохраненные действиями задачи (save_pdf, download)
+	downloadMark            int        // очередь загрузок перед последним кликом (Browser.MarkDownloads)
+	newTabMark              int        // очередь новых вкладок перед последним кликом (Browser.MarkNewTabs)
+	pages                   PageSource // источник страниц для анализа (SetPageSource); nil - браузер
+	pdfDir                  string
+	uploadDirs              []string // каталоги, из которых можно загружать файлы на сайты (UPLOAD_DIRS)
+	bundleDir               string
+	bundleConfig            map[string]string
+	bundle                  *bundle.Recorder // пакет для воспроизведения текущей задачи
+	crashReloads            map[string]int
+	idleWarningMode         IdleWarningMode
+	idleWarningNoted        string // текст предупреждения о бездействии, уже сообщенного модели
+	subAgentType            SubAgentType
+	pendingSubAgent         *SubAgent // под-агент, которому задача передается после первого шага
+	maxAutoScrolls          int
+	locale                  string
+	reportDir               string
+	reportPath              string
+	printTaskReport         bool
+	transcriptPath          string
+	steps                   []transcriptEntry // действия задачи для отчета
+	usageStart              ai.TokenUsage
+	loginIndicator          string
+	loginChecked            map[string]bool
+	loginCache              map[string]SiteLogin // команда logins: профиль|сайт -> состояние входа
+	batchApprovals          map[string]*batchApproval
+	patternConfirmations    map[string]int
+	verbose                 bool
+	slowDecision            time.Duration
+	timings                 phaseTimings
+	phaseStart              time.Time
+	stopStatus              func() // стирает строку статуса ожидания модели
+	sameURLNavigations      int    // переходов подряд на уже открытую страницу
+	repeatLimit             int
+	lastDecisionKey         string
+	decisionRepeats         int             // одинаковых решений подряд
+	executed                *ai.Decision    // успешно выполненное решение, ждет отпечатка страницы после него
+	executedKey             string          // отпечаток последнего успешного решения и страницы после него
+	paginatedData           json.RawMessage // строки последнего paginate_scrape
+	captureFinalPage        bool
+	finalPage               *FinalPage
+	phaseSamples            map[string][]time.Duration
+	settleMax               time.Duration
+	settleTime              time.Duration // ожидание готовности страницы за задачу
 }
 
 func NewAgent(browser *browser.Browser, aiClient *ai.Client) *Agent {
 	return &Agent{
-		browser:       browser,
-		aiClient:      aiClient,
-		maxIterations: 50,
-		maxErrors:     5, // Увеличено для лучшей адаптации
-		retryStrategy:  "adaptive",
+		browser:              browser,
+		aiClient:             aiClient,
+		maxIterations:        50,
+		maxErrors:            5, // Увеличено для лучшей адаптации
+		retryStrategy:        "adaptive",
+		hostDelay:            defaultHostDelay,
+		lastHostVisit:        make(map[string]time.Time),
+		vars:                 make(map[string]string),
+		confirmationPolicy:   DefaultConfirmationPolicy(),
+		interactive:          true,
+		allowHandoff:         true,
+		otpAttempts:          make(map[string]int),
+		crashReloads:         make(map[string]int),
+		idleWarningMode:      IdleWarningDismiss,
+		loginChecked:         make(map[string]bool),
+		batchApprovals:       make(map[string]*batchApproval),
+		patternConfirmations: make(map[string]int),
+		maxAutoScrolls:       defaultMaxAutoScrolls,
+		slowDecision:         defaultSlowDecision,
+		locale:               LocaleRU,
+		settleMax:            defaultSettleMax,
+		repeatLimit:          defaultRepeatLimit,
 	}
 }
 
 func (a *Agent) Execute(ctx context.Context, task string) error {
+	a.resetTaskState(task)
+	// Новая задача заменяет checkpoint предыдущей
+	a.discardCheckpoint()
+
+	fmt.Printf("\n🤖 Начинаю выполнение задачи: %s\n\n", task)
+
+	return a.run(ctx, task)
+}
+
+// resetTaskState сбрасывает состояние, которое относится к одной задаче. Вызывается
+// и при новой задаче, и при продолжении из checkpoint: новое поле задачи добавляется сюда.
+func (a *Agent) resetTaskState(task string) {
 	a.task = task
 	a.errorCount = 0
+	a.history = nil
+	a.completed = false
+	a.summary = ""
+	a.extractedData = nil
+	a.schemaRetries = 0
+	a.missingFields = nil
+	a.iteration = 0
+	a.vars = make(map[string]string)
+	a.confirmations = nil
+	a.batchApprovals = make(map[string]*batchApproval)
+	a.patternConfirmations = make(map[string]int)
+	a.otpAttempts = make(map[string]int)
+	a.documents = nil
+	a.savedFiles = nil
+	a.crashReloads = make(map[string]int)
+	a.idleWarningNoted = ""
+	a.reportPath = ""
+	a.loginChecked = make(map[string]bool)
+	a.sameURLNavigations = 0
+	a.lastDecisionKey, a.decisionRepeats = "", 0
+	a.executed, a.executedKey = nil, ""
+	a.elements = nil
+	a.paginatedData = nil
+	a.finalPage = nil
+}
+
+// run выбирает под-агента для задачи и запускает цикл выполнения
+func (a *Agent) run(ctx context.Context, task string) error {
+	// Директивы (!prefer=...) действуют только на время задачи
+	task, startURL := taskStartURL(task)
+	task, directives := parseTaskDirectives(task)
+	task, err := expandTemplates(task, time.Now(), a.locale, a.vars)
+	if err != nil {
+		return err
+	}
+	if profiles := directives["profile"]; len(profiles) > 0 {
+		if err := a.SwitchProfile(profiles[len(profiles)-1]); err != nil {
+			return err
+		}
+	}
+	if indicators := directives["login_indicator"]; len(indicators) > 0 {
+		defer a.SetLoginIndicator(a.loginIndicator)
+		a.SetLoginIndicator(indicators[len(indicators)-1])
+	}
+	a.running = true
+	defer func() { a.running = false }()
+	a.aiClient.SetPreferredTargets(append(append([]string(nil), a.preferredTargets...), directives["prefer"]...))
+	defer a.aiClient.SetPreferredTargets(a.preferredTargets)
+	if len(directives["prefer"]) > 0 {
+		fmt.Printf("⭐ Предпочтительные элементы: %s\n", strings.Join(directives["prefer"], ", "))
+	}
 
-	fmt.Printf("\n🤖 Начинаю выполнение задачи: %s\n\n", task)
-	
 	// Определяем тип под-агента и используем его, если нужно
 	// Отладочный вывод для диагностики
-	taskPreview := task
-	if len(task) > 50 {
-		taskPreview = task[:50] + "..."
-	}
+	// Обрезка по рунам: байтовый срез разрезал бы кириллицу, арабский или CJK посреди символа
+	taskPreview := truncateRunes(task, 50)
 	fmt.Printf("🔍 Отладка: длина задачи = %d, первые символы = %q\n", len(task), taskPreview)
 	subAgentType := DetectSubAgentType(task)
 	fmt.Printf("🔍 Отладка: определен тип агента = %s\n", subAgentType)
+	a.subAgentType = subAgentType
+	a.startTranscript(task)
+	a.startBundle(task)
+	// Продолжение из checkpoint уже открыло сохраненную страницу
+	if a.iteration == 0 {
+		if startURL != "" {
+			a.openStartURL(ctx, startURL)
+		} else {
+			a.selectTaskTab(ctx, task)
+		}
+	}
+
+	// Специализированный агент принимает задачу после первого шага основного: к этому
+	// моменту есть наблюдение открытой страницы и история, которые он продолжает
+	a.pendingSubAgent = nil
 	if subAgentType != SubAgentGeneric {
-		subAgent := NewSubAgent(subAgentType, a.browser, a.aiClient)
-		fmt.Printf("🎯 Использую специализированного агента: %s\n\n", subAgentType)
-		return subAgent.Execute(ctx, task, a)
+		a.pendingSubAgent = NewSubAgent(subAgentType, a.browser, a.aiClient)
 	}
+	err = a.executeTask(ctx, task)
 
-	return a.executeTask(ctx, task)
+	a.finishCheckpoint(err)
+	a.finishTranscript(err)
+	a.finishBundle()
+	a.writeTaskReport(a.buildResult(task, err, time.Since(a.runMetadata.StartedAt)))
+	return err
 }
 
 // executeTask выполняет задачу (внутренний метод для использования sub-agents)
 func (a *Agent) executeTask(ctx context.Context, task string) error {
-	iteration := 0
-	var history []string
+	for a.iteration < a.maxIterations {
+		// Ctrl+C или таймаут задачи: следующий шаг не начинается
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("задача прервана: %w", err)
+		}
+		if a.pendingSubAgent != nil && a.iteration > 0 {
+			subAgent := a.pendingSubAgent
+			a.pendingSubAgent = nil
+			fmt.Printf("🎯 Использую специализированного агента: %s\n\n", subAgent.agentType)
+			return subAgent.Execute(ctx, task, a)
+		}
+		a.iteration++
+		a.startIteration()
+		a.saveCheckpoint()
+
+		// Страница сбоя Chrome - не содержимое сайта: перезагружаем до анализа
+		a.recoverCrashedPage()
+
+		// Предупреждение "вы еще здесь?" закрываем, пока сайт не завершил сессию
+		a.handleIdleWarning()
 
-	for iteration < a.maxIterations {
-		iteration++
+		// Код подтверждения вводит пользователь, а не модель
+		a.handleOTP()
+
+		// Состояние входа на новом сайте - чтобы не проходить вход повторно
+		a.noteLoginState()
 
 		// Сначала пытаемся получить быструю информацию
-		quickInfo, quickErr := a.browser.GetQuickPageInfo()
+		quickInfo, quickErr := a.quickPageInfo()
 		if quickErr != nil {
 			// Если быстрый метод не работает, пробуем полный
-			pageContent, err := a.browser.GetPageContent()
+			stopStatus := console.StartStatus("анализ страницы...")
+			pageContent, err := a.pageSource().GetPageContent()
+			stopStatus()
 			if err != nil {
+				// Упавшая вкладка - не ошибка анализа: восстанавливаем без расхода бюджета ошибок
+				if a.browser.RendererCrashed() {
+					a.recoverRenderer("")
+					continue
+				}
 				// Если контекст браузера отменен, это критическая ошибка
 				if strings.Contains(err.Error(), "browser context was canceled") {
 					return fmt.Errorf("браузер недоступен после предыдущей задачи: %w. Возможно, браузер был закрыт или контекст отменен", err)
@@ -87,10 +294,19 @@ func (a *Agent) executeTask(ctx context.Context, task string) error {
 				
 				return fmt.Errorf("failed to get page content: %w", err)
 			}
+			a.markNewElements(pageContent.URL, true, pageContent.Links, pageContent.Buttons)
 			
 			// Используем полный контент
-			decision, err := a.aiClient.MakeDecision(ctx, task, pageContent, history, 500)
+			a.startDecision()
+			mark := a.markBundle()
+			decision, err := a.aiClient.MakeDecision(ctx, task, pageContent, a.history, 500)
+			a.finishDecision()
+			a.recordPromptDowngrade(err)
+			a.recordBundleIteration(mark, "full", pageContent.URL, pageContent, decision, err)
 			if err != nil {
+				if refusal := (*ai.RefusalError)(nil); errors.As(err, &refusal) {
+					return a.modelDeclined(refusal)
+				}
 				a.errorCount++
 				if a.errorCount >= a.maxErrors {
 					return fmt.Errorf("too many errors: %w", err)
@@ -101,20 +317,31 @@ func (a *Agent) executeTask(ctx context.Context, task string) error {
 			}
 			
 			// Обработка решения с полным контентом
-			if err := a.processDecision(ctx, decision, history); err != nil {
+			if err := a.processDecision(ctx, decision); err != nil {
 				return err
 			}
+			if a.completed {
+				return nil
+			}
 			
-			a.errorCount = 0
-			actionDesc := fmt.Sprintf("%s: %s", decision.Action, decision.Reasoning)
-			history = append(history, actionDesc)
-			time.Sleep(1 * time.Second)
+			a.history = append(a.history, a.actionEntry(decision))
+			a.settleAfter(decision)
+			a.rememberExecuted()
 			continue
 		}
 		
 		// Используем быструю информацию для простых действий
-		decision, err := a.aiClient.MakeDecision(ctx, task, quickInfo, history, 500)
+		a.markNewElements(quickInfo.URL, false, quickInfo.Links, quickInfo.Buttons)
+		a.startDecision()
+		mark := a.markBundle()
+		decision, err := a.aiClient.MakeDecision(ctx, task, quickInfo, a.history, 500)
+		a.finishDecision()
+		a.recordPromptDowngrade(err)
+		a.recordBundleIteration(mark, "quick", quickInfo.URL, quickInfo, decision, err)
 		if err != nil {
+			if refusal := (*ai.RefusalError)(nil); errors.As(err, &refusal) {
+				return a.modelDeclined(refusal)
+			}
 			a.errorCount++
 			if a.errorCount >= a.maxErrors {
 				return fmt.Errorf("too many errors: %w", err)
@@ -125,33 +352,39 @@ func (a *Agent) executeTask(ctx context.Context, task string) error {
 		}
 
 		// Обработка решения
-		if err := a.processDecision(ctx, decision, history); err != nil {
+		if err := a.processDecision(ctx, decision); err != nil {
 			return err
 		}
+		if a.completed {
+			return nil
+		}
 		
-		// Сбрасываем счетчик ошибок при успешном выполнении
-		a.errorCount = 0
-		actionDesc := fmt.Sprintf("%s: %s", decision.Action, decision.Reasoning)
-		history = append(history, actionDesc)
-		time.Sleep(1 * time.Second)
+		// Счетчик ошибок сбрасывается в processDecision после успешного действия
+		a.history = append(a.history, a.actionEntry(decision))
+		a.settleAfter(decision)
+		a.rememberExecuted()
 	}
 
 	return fmt.Errorf("достигнут максимум итераций (%d)", a.maxIterations)
 }
 
 // processDecision обрабатывает решение AI
-func (a *Agent) processDecision(ctx context.Context, decision *ai.Decision, history []string) error {
+func (a *Agent) processDecision(ctx context.Context, decision *ai.Decision) error {
+	defer a.finishIteration()
 	fmt.Printf("💭 Решение: %s\n", decision.Action)
 	if decision.Reasoning != "" {
 		fmt.Printf("   Обоснование: %s\n", decision.Reasoning)
 	}
+	for key, value := range decision.Metadata {
+		a.vars[key] = value
+	}
 
 	if decision.IsComplete {
 		// Проверяем, действительно ли задача выполнена
 		// Если в истории много завершений подряд - это зацикливание
 		recentCompletes := 0
-		for i := len(history) - 1; i >= 0 && i >= len(history)-5; i-- {
-			if strings.Contains(history[i], "complete") || strings.Contains(history[i], "Задача выполнена") {
+		for i := len(a.history) - 1; i >= 0 && i >= len(a.history)-5; i-- {
+			if strings.Contains(a.history[i], "complete") || strings.Contains(a.history[i], "Задача выполнена") {
 				recentCompletes++
 			}
 		}
@@ -161,19 +394,39 @@ func (a *Agent) processDecision(ctx context.Context, decision *ai.Decision, hist
 			// Не завершаем, продолжаем работу - сбрасываем IsComplete
 			decision.IsComplete = false
 			// Добавляем в историю, что было зацикливание
-			history = append(history, "ОБНАРУЖЕНО зацикливание завершения - продолжаю работу")
+			a.history = append(a.history, "ОБНАРУЖЕНО зацикливание завершения - продолжаю работу")
+		} else if !a.acceptResult(decision) {
+			// Результат не соответствует схеме - модель должна дозаполнить поля
+			return nil
 		} else {
 			fmt.Printf("\n✅ Задача выполнена!\n")
 			if decision.Summary != "" {
 				fmt.Printf("📋 Резюме: %s\n", decision.Summary)
 			}
+			a.completed = true
+			a.summary = a.summaryWithFiles(a.summaryWithConfirmations(decision.Summary))
+			a.extractedData = a.documentsData(a.withPaginatedData(decision.ExtractedData))
+			a.recordAction(decision, "complete", nil)
+			a.captureFinal()
+			a.writeReport(ctx)
 			return nil
 		}
 	}
 
 	if decision.NeedsInput {
-		fmt.Printf("\n❓ Требуется ввод от пользователя: %s\n", decision.InputPrompt)
-		return fmt.Errorf("needs user input")
+		err := a.handleNeedsInput(decision)
+		a.recordAction(decision, "needs_input", err)
+		if err != nil && !a.interactive {
+			return err
+		}
+		return nil
+	}
+
+	// Шаг, который модель передает пользователю, - вне фазы действия: время ожидания не учитывается
+	if decision.Action == "handoff" {
+		err := a.handOffToUser(ctx, decision)
+		a.recordAction(decision, "handoff", err)
+		return nil
 	}
 	
 	// Если действие "complete" но IsComplete=false (после сброса зацикливания), пропускаем
@@ -182,231 +435,466 @@ func (a *Agent) processDecision(ctx context.Context, decision *ai.Decision, hist
 		return fmt.Errorf("complete action skipped due to loop detection")
 	}
 
-	// Проверка на деструктивные действия
-	if a.isDestructiveAction(decision) {
-		quickInfo, _ := a.browser.GetQuickPageInfo()
-		contextStr := ""
-		if quickInfo != nil {
-			contextStr = fmt.Sprintf("URL: %s, Title: %s", quickInfo.URL, quickInfo.Title)
-		}
-		
-		confirmed, err := a.checkDestructiveAction(ctx, decision, contextStr)
-		if err != nil {
-			fmt.Printf("⚠️  Ошибка при проверке деструктивного действия: %v\n", err)
-			confirmed = false
+	// Одно и то же решение много раз подряд - действие не работает
+	if a.blockRepeatedDecision(decision) {
+		return nil
+	}
+	// То же решение сразу после успешного на неизменившейся странице - уже выполнено
+	if a.skipExecutedDecision(decision) {
+		return nil
+	}
+
+	// Safe-mode: блокируем любые действия, способные изменить состояние
+	if a.safeMode {
+		if reason := a.safeModeViolation(decision); reason != "" {
+			fmt.Printf("🛡️  Safe-mode: действие '%s' заблокировано (%s)\n", decision.Action, reason)
+			a.history = append(a.history, fmt.Sprintf("заблокировано safe-mode: %s (%s) - выбери действие только для чтения", decision.Action, reason))
+			a.recordAction(decision, "blocked", fmt.Errorf("safe-mode: %s", reason))
+			return nil
 		}
-		
-		if !confirmed {
-			fmt.Printf("🚫 Деструктивное действие отменено пользователем\n")
-			history = append(history, fmt.Sprintf("ОТМЕНЕНО деструктивное действие: %s", decision.Action))
-			time.Sleep(1 * time.Second)
-			return fmt.Errorf("destructive action canceled")
+	}
+
+	// Проверка на деструктивные действия; в доверенном сценарии подтверждения не запрашиваются
+	if !a.disableDestructiveCheck {
+		if severity := a.destructiveSeverity(decision); severity != "" {
+			quickInfo, _ := a.browser.GetQuickPageInfo()
+			contextStr := ""
+			if quickInfo != nil {
+				contextStr = fmt.Sprintf("URL: %s, Title: %s", quickInfo.URL, quickInfo.Title)
+			}
+
+			confirmed, err := a.checkDestructiveAction(ctx, decision, severity, contextStr)
+			if err != nil {
+				fmt.Printf("⚠️  Ошибка при проверке деструктивного действия: %v\n", err)
+				confirmed = false
+			}
+
+			if !confirmed {
+				fmt.Printf("🚫 Деструктивное действие отменено пользователем\n")
+				a.history = append(a.history, fmt.Sprintf("ОТМЕНЕНО деструктивное действие: %s", decision.Action))
+				a.recordAction(decision, "canceled", nil)
+				return ErrActionCanceled
+			}
 		}
 	}
 
+	// Фаза действия - без ожидания подтверждения выше и паузы перед повтором ниже
+	actStart := time.Now()
 	if err := a.executeAction(ctx, decision); err != nil {
-		a.errorCount++
+		if errors.Is(err, errPageChanged) {
+			a.timings.ActMs = time.Since(actStart).Milliseconds()
+			a.notePageChanged(decision, err)
+			// Повтор не нужен, но страница, которая меняется на каждом шаге, не должна
+			// крутить цикл без конца - пропуск расходует бюджет ошибок
+			a.errorCount++
+			if a.errorCount >= a.maxErrors {
+				return fmt.Errorf("too many consecutive errors: %w", err)
+			}
+			return nil
+		}
 		fmt.Printf("❌ Ошибка при выполнении действия: %v\n", err)
+		if a.browser.RendererCrashed() {
+			a.timings.ActMs = time.Since(actStart).Milliseconds()
+			a.recordAction(decision, "error", err)
+			a.recoverRenderer(decision.Action)
+			return nil
+		}
+
+		// Адаптивная обработка ошибок: меняем условия и повторяем действие
+		adapt := a.adaptToError(ctx, err, decision)
+		a.timings.ActMs = time.Since(actStart).Milliseconds()
+		if adapt.Recovered {
+			a.errorCount = 0
+			a.history = append(a.history, fmt.Sprintf("ОШИБКА при '%s': %v. Стратегия: %s", decision.Action, err, adapt.Description))
+			a.recordAdaptedAction(decision, "ok", nil, adapt)
+			return nil
+		}
+		a.recordAdaptedAction(decision, "error", err, adapt)
+		a.errorCount++
 
-		// Адаптивная обработка ошибок
 		retryDelay := a.calculateRetryDelay(a.errorCount)
-		errorDesc := fmt.Sprintf("ОШИБКА при '%s': %v. Стратегия: %s", decision.Action, err, a.adaptToError(err, decision))
-		history = append(history, errorDesc)
+		errorDesc := fmt.Sprintf("ОШИБКА при '%s': %v. Стратегия: %s", decision.Action, err, adapt.Description)
+		a.history = append(a.history, errorDesc)
 
 		if a.errorCount >= a.maxErrors {
 			return fmt.Errorf("too many consecutive errors: %w", err)
 		}
 
+		// Ошибка действия не завершает задачу: модель получит стратегию в истории
+		// и выберет следующий шаг с учетом адаптации
 		fmt.Printf("⏳ Ожидание перед повтором (%v)...\n", retryDelay)
 		time.Sleep(retryDelay)
-		return err
+		return nil
 	}
 
+	a.timings.ActMs = time.Since(actStart).Milliseconds()
+	if decision.Action == "click" || decision.Action == "submit" {
+		a.noteRouteChange()
+	}
+	a.errorCount = 0
+	a.executed = decision
+	a.recordAction(decision, "ok", nil)
 	return nil
 }
 
 func (a *Agent) executeAction(ctx context.Context, decision *ai.Decision) error {
-	switch decision.Action {
-	case "navigate":
-		if decision.URL == "" {
-			return fmt.Errorf("URL не указан для навигации. Используй поле 'url' с адресом (можно прямой URL или из списка links)")
-		}
-		
-		// Нормализуем URL - добавляем https:// если отсутствует
-		url := decision.URL
-		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-			// Если это домен без протокола, добавляем https://
-			if strings.Contains(url, ".") && !strings.Contains(url, " ") {
-				url = "https://" + url
-			}
-		}
-		
-		fmt.Printf("🌐 Переход на: %s\n", url)
-		return a.browser.Navigate(url)
-
-	case "click":
-		if decision.Text != "" {
-			fmt.Printf("🖱️  Клик по тексту: %s\n", decision.Text)
-			return a.browser.ClickByText(decision.Text)
-		} else if decision.Selector != "" {
-			fmt.Printf("🖱️  Клик по селектору: %s\n", decision.Selector)
-			return a.browser.ClickElement(decision.Selector)
-		}
-		return fmt.Errorf("не указан текст или селектор для клика. Используй поле 'text' с текстом кнопки/ссылки из списка buttons/links, или поле 'selector' с CSS селектором")
+	// {{tomorrow}}, {{now+2h}} и переменные задачи подставляются до выполнения.
+	// Значения переменных и секретных полей в пакет для воспроизведения не попадают.
+	if secretFill(decision) {
+		a.redactSecret(decision.Value)
+	}
+	fromVars := usesVars(decision.Value)
+	if err := a.expandDecisionTemplates(decision); err != nil {
+		return err
+	}
+	if fromVars || secretFill(decision) {
+		a.redactSecret(decision.Value)
+	}
+	if decision.Action != "navigate" {
+		a.sameURLNavigations = 0
+	}
 
-	case "fill":
-		if decision.Value == "" {
-			return fmt.Errorf("не указано значение для заполнения (value пустое)")
-		}
-		if decision.Selector != "" {
-			fmt.Printf("✍️  Заполнение поля: %s = %s\n", decision.Selector, decision.Value)
-			return a.browser.FillInput(decision.Selector, decision.Value)
-		} else if decision.Text != "" {
-			fmt.Printf("✍️  Заполнение поля по placeholder: %s = %s\n", decision.Text, decision.Value)
-			return a.browser.FillInputByPlaceholder(decision.Text, decision.Value)
-		}
-		return fmt.Errorf("не указан селектор или placeholder для заполнения. Используй поле 'text' с placeholder/name из списка inputs, или поле 'selector' с CSS селектором")
+	a.changeNote = ""
 
-	case "press_key":
-		if decision.Key == "" {
-			return fmt.Errorf("не указана клавиша для нажатия (key пустое). Используй поле 'key' с названием клавиши (delete, enter, escape и т.д.)")
-		}
-		fmt.Printf("⌨️  Нажатие клавиши: %s\n", decision.Key)
-		return a.browser.PressKey(decision.Key)
+	handler, ok := actionHandlers[decision.Action]
+	if !ok {
+		return fmt.Errorf("неизвестное действие: %s. Доступные действия: %s", decision.Action, strings.Join(ai.ActionNames(), ", "))
+	}
+	if changeCheckedActions[decision.Action] {
+		return a.executeWithChangeCheck(ctx, decision, handler)
+	}
+	return handler(a, ctx, decision)
+}
 
-	case "switch_tab":
-		if decision.TabIndex <= 0 {
-			return fmt.Errorf("не указан индекс вкладки (tab_index пустое или неверное). Используй поле 'tab_index' с номером вкладки (1, 2, 3...)")
-		}
-		// Получаем список вкладок
-		tabs, err := a.browser.GetAllTabs()
-		if err != nil {
-			return fmt.Errorf("не удалось получить список вкладок: %w", err)
+// parseRangeValue разбирает значение для ползунка: "150 000 ₽", "2,5" -> число
+func parseRangeValue(raw string) (float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r == ',':
+			return '.'
 		}
-		if decision.TabIndex > len(tabs) {
-			return fmt.Errorf("неверный индекс вкладки: %d (всего вкладок: %d)", decision.TabIndex, len(tabs))
-		}
-		targetTab := tabs[decision.TabIndex-1]
-		fmt.Printf("🔄 Переключение на вкладку %d: %s\n", decision.TabIndex, targetTab.Title)
-		return a.browser.SwitchToTab(targetTab.ID)
+		return -1
+	}, raw)
+	value, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("значение ползунка должно быть числом, получено: %q", raw)
+	}
+	return value, nil
+}
 
-	case "close_tab":
-		if decision.TabIndex <= 0 {
-			return fmt.Errorf("не указан индекс вкладки (tab_index пустое или неверное). Используй поле 'tab_index' с номером вкладки (1, 2, 3...)")
-		}
-		// Получаем список вкладок
-		tabs, err := a.browser.GetAllTabs()
-		if err != nil {
-			return fmt.Errorf("не удалось получить список вкладок: %w", err)
-		}
-		if decision.TabIndex > len(tabs) {
-			return fmt.Errorf("неверный индекс вкладки: %d (всего вкладок: %d)", decision.TabIndex, len(tabs))
-		}
-		if len(tabs) == 1 {
-			return fmt.Errorf("нельзя закрыть единственную открытую вкладку")
-		}
-		targetTab := tabs[decision.TabIndex-1]
-		if targetTab.IsActive {
-			// Если закрываем активную вкладку, сначала переключимся на другую
-			newActiveIndex := 0
-			if decision.TabIndex == 1 {
-				newActiveIndex = 1 // переключимся на следующую
-			}
-			if err := a.browser.SwitchToTab(tabs[newActiveIndex].ID); err != nil {
-				return fmt.Errorf("не удалось переключиться перед закрытием: %w", err)
-			}
-		}
-		fmt.Printf("❌ Закрытие вкладки %d: %s\n", decision.TabIndex, targetTab.Title)
-		return a.browser.CloseTab(targetTab.ID)
+func (a *Agent) GetBrowser() *browser.Browser {
+	return a.browser
+}
 
-	case "wait":
-		if decision.WaitFor != "" {
-			fmt.Printf("⏳ Ожидание элемента: %s\n", decision.WaitFor)
-			return a.browser.WaitForElement(decision.WaitFor, 10*time.Second)
-		}
-		fmt.Printf("⏳ Ожидание 2 секунды...\n")
-		time.Sleep(2 * time.Second)
-		return nil
+// SetSafeMode включает режим, в котором агент не может изменять состояние на сайтах
+func (a *Agent) SetSafeMode(enabled bool) {
+	a.safeMode = enabled
+	a.aiClient.SetSafeMode(enabled)
+}
 
-	case "extract":
-		fmt.Printf("📄 Извлечение информации со страницы...\n")
-		return nil
+// SetNavigateHostDelay задает минимальный интервал между переходами на один и тот же домен
+func (a *Agent) SetNavigateHostDelay(delay time.Duration) {
+	a.hostDelay = delay
+}
 
-	case "complete":
-		// Действие "complete" должно обрабатываться в processDecision, но на случай если попало сюда
-		return nil
+// SetMaxIterations задает предел итераций одной задачи (по умолчанию 50)
+func (a *Agent) SetMaxIterations(n int) {
+	if n > 0 {
+		a.maxIterations = n
+	}
+}
+
+// SetMaxAutoScrolls задает предел прокруток действия scroll_to_load
+func (a *Agent) SetMaxAutoScrolls(n int) {
+	if n > 0 {
+		a.maxAutoScrolls = n
+	}
+}
+
+// scrollTo прокручивает страницу к элементу по селектору или видимому тексту перед
+// действием с ним: элементы ленивых лент появляются в DOM и видимыми только у края окна
+func (a *Agent) scrollTo(decision *ai.Decision) error {
+	switch {
+	case decision.Direction != "":
+		return a.scrollPage(decision.Direction, decision.Amount)
+	case decision.Selector != "":
+		fmt.Printf("📜 Прокрутка к элементу: %s\n", decision.Selector)
+		return a.browser.ScrollToElement(decision.Selector)
+	case decision.Text != "":
+		fmt.Printf("📜 Прокрутка к элементу: %s\n", decision.Text)
+		return a.browser.ScrollIntoView("", decision.Text)
+	}
+	return fmt.Errorf("не указано, куда прокручивать. Заполни 'direction' (down, up, top, bottom) или 'selector'/'text' элемента")
+}
 
+// scrollPage прокручивает страницу в направлении и записывает в историю, какая часть
+// страницы теперь видна: модель решает, читать ли дальше
+func (a *Agent) scrollPage(direction string, amount int) error {
+	fmt.Printf("📜 Прокрутка: %s\n", direction)
+	position, err := a.browser.Scroll(direction, amount)
+	if err != nil {
+		return err
+	}
+	where := "страница"
+	if position.Container != "" {
+		where = "контейнер " + position.Container
+	}
+	switch {
+	case !position.Moved && position.AtBottom():
+		a.history = append(a.history, fmt.Sprintf("Прокрутка %s: %s уже в конце, дальше прокручивать некуда", direction, where))
+	case !position.Moved:
+		a.history = append(a.history, fmt.Sprintf("Прокрутка %s: %s не сдвинулась (начало или нет прокрутки)", direction, where))
+	case position.AtBottom():
+		a.history = append(a.history, fmt.Sprintf("Прокрутка %s: %s прокручена до конца (%d px)", direction, where, position.Height))
 	default:
-		return fmt.Errorf("неизвестное действие: %s", decision.Action)
+		a.history = append(a.history, fmt.Sprintf("Прокрутка %s: видно %d-%d px из %d px (%s)", direction, position.Y, position.Y+position.Viewport, position.Height, where))
 	}
+	return nil
 }
 
-func (a *Agent) GetBrowser() *browser.Browser {
-	return a.browser
+// scrollToLoad прокручивает бесконечную ленту до конца (не больше maxAutoScrolls раз),
+// чтобы следующий шаг полным анализом страницы увидел все подгруженные элементы
+func (a *Agent) scrollToLoad(decision *ai.Decision) error {
+	limit := a.maxAutoScrolls
+	if n, err := strconv.Atoi(strings.TrimSpace(decision.Value)); err == nil && n > 0 && n < limit {
+		limit = n
+	}
+	fmt.Printf("📜 Прокрутка до загрузки всех элементов (не больше %d прокруток)...\n", limit)
+	result, err := a.browser.ScrollUntilStable(decision.Selector, limit, time.Duration(limit)*3*time.Second)
+	if err != nil {
+		return err
+	}
+
+	status := "лента загружена полностью"
+	if !result.Stable {
+		status = fmt.Sprintf("достигнут предел прокруток (%d), элементы могли загрузиться не все", limit)
+	}
+	fmt.Printf("📜 Прокруток: %d, высота страницы %d -> %d: %s\n", result.Scrolls, result.StartHeight, result.Height, status)
+	where := "страница"
+	if result.Container != "" {
+		where = "контейнер " + result.Container
+	}
+	a.history = append(a.history, fmt.Sprintf("Прокрутка до конца ленты (%s): %d прокруток, %s. Положение прокрутки прежнее, список элементов - в полном анализе страницы", where, result.Scrolls, status))
+	a.forceFullExtraction = true
+	return nil
 }
 
-// isDestructiveAction проверяет, является ли действие деструктивным
-func (a *Agent) isDestructiveAction(decision *ai.Decision) bool {
-	action := strings.ToLower(decision.Action)
-	text := strings.ToLower(decision.Text)
-	reasoning := strings.ToLower(decision.Reasoning)
-	
-	destructiveKeywords := []string{
-		"удалить", "delete", "remove", "удаление",
-		"оплатить", "pay", "payment", "купить", "buy", "purchase",
-		"подтвердить", "confirm", "submit", "отправить",
-		"отменить", "cancel", "отмена",
-		"изменить", "change", "modify", "редактировать",
-		"сохранить", "save", "сохранение",
+// modelDeclined останавливает задачу после отказа модели (в том числе повторного,
+// после пояснения) и показывает пользователю текст отказа вместо ошибки разбора ответа
+func (a *Agent) modelDeclined(refusal *ai.RefusalError) error {
+	fmt.Printf("🙅 Модель отказалась выполнять задачу:\n%s\n", refusal.Text)
+	fmt.Println("💡 Переформулируйте задачу или укажите резервную модель в OPENAI_FALLBACK_MODEL")
+	return fmt.Errorf("the model declined: %w", refusal)
+}
+
+// goBack возвращается на несколько страниц назад одним действием. Начало истории
+// не считается ошибкой: модель получает подсказку перейти по URL.
+func (a *Agent) goBack(decision *ai.Decision) error {
+	n := 1
+	if v, err := strconv.Atoi(strings.TrimSpace(decision.Value)); err == nil && v > 0 {
+		n = v
 	}
-	
-	for _, keyword := range destructiveKeywords {
-		if strings.Contains(action, keyword) || 
-		   strings.Contains(text, keyword) || 
-		   strings.Contains(reasoning, keyword) {
-			return true
+	fmt.Printf("⏪ Назад на %d стр.\n", n)
+	steps, err := a.browser.GoBackN(n)
+	if errors.Is(err, browser.ErrHistoryStart) {
+		fmt.Printf("⏪ %v\n", err)
+		a.history = append(a.history, "Назад вернуться нельзя: это первая страница вкладки. Для перехода используй navigate с URL")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if steps < n {
+		a.history = append(a.history, fmt.Sprintf("Назад на %d стр. (дальше начало истории вкладки)", steps))
+	}
+	return nil
+}
+
+// goForward переходит вперед по истории вкладки после go_back. Конец истории, как и
+// начало для go_back, не ошибка: модель получает подсказку.
+func (a *Agent) goForward(decision *ai.Decision) error {
+	fmt.Printf("⏩ Вперед\n")
+	err := a.browser.GoForward()
+	if errors.Is(err, browser.ErrHistoryEnd) {
+		fmt.Printf("⏩ %v\n", err)
+		a.history = append(a.history, "Вперед перейти нельзя: это последняя страница вкладки. Для перехода используй click или navigate с URL")
+		return nil
+	}
+	return err
+}
+
+// reload перезагружает текущую страницу: зависшая загрузка, устаревшие данные
+func (a *Agent) reload(decision *ai.Decision) error {
+	fmt.Printf("🔄 Перезагрузка страницы\n")
+	stopStatus := console.StartStatus("загрузка страницы...")
+	defer stopStatus()
+	return a.browser.Reload()
+}
+
+// waitForHostPoliteness выдерживает паузу перед повторным переходом на тот же домен,
+// чтобы не упираться в rate limit сайта. Переходы на другие домены не задерживаются.
+// Отмена ctx (Ctrl+C, таймаут задачи) прерывает паузу, и переход не выполняется.
+func (a *Agent) waitForHostPoliteness(ctx context.Context, rawURL string) error {
+	parsed, err := neturl.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return nil
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+
+	if last, ok := a.lastHostVisit[host]; ok && a.hostDelay > 0 {
+		if wait := a.hostDelay - time.Since(last); wait > 0 {
+			fmt.Printf("🐢 Пауза %v перед повторным запросом к %s\n", wait.Round(time.Millisecond), host)
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
 		}
 	}
-	
-	// Проверка на действия с оплатой или удалением
-	if strings.Contains(text, "корзина") && (strings.Contains(text, "оформить") || strings.Contains(text, "заказать")) {
-		return true
+	a.lastHostVisit[host] = time.Now()
+	return nil
+}
+
+// SetPreferredTargets задает тексты кнопок и ссылок, которые модель должна выбирать
+// в первую очередь среди похожих. Действует для всех задач; для одной задачи
+// используйте директиву !prefer=текст в тексте задачи.
+func (a *Agent) SetPreferredTargets(targets []string) {
+	a.preferredTargets = targets
+	a.aiClient.SetPreferredTargets(targets)
+}
+
+// SwitchProfile переключает браузер на именованный профиль (отдельные cookies и сессии).
+// Смена профиля перезапускает браузер, поэтому во время выполнения задачи запрещена.
+func (a *Agent) SwitchProfile(name string) error {
+	if a.running {
+		return fmt.Errorf("нельзя сменить профиль браузера во время выполнения задачи (активный профиль: %s) - дождитесь завершения задачи", a.browser.Profile())
 	}
-	
-	if strings.Contains(text, "удалить") || strings.Contains(text, "delete") {
-		return true
+	if err := browser.ValidateProfileName(name); err != nil {
+		return err
 	}
-	
-	return false
+	if a.browser.Profile() == name {
+		return nil
+	}
+
+	fmt.Printf("👤 Переключение профиля браузера: %s -> %s (перезапуск браузера)...\n", a.browser.Profile(), name)
+	if err := a.browser.SwitchProfile(name); err != nil {
+		return err
+	}
+	a.chromeVersion = ""
+	fmt.Printf("✅ Активный профиль: %s\n", a.browser.Profile())
+	return nil
 }
 
-// checkDestructiveAction запрашивает подтверждение у пользователя
-func (a *Agent) checkDestructiveAction(ctx context.Context, decision *ai.Decision, contextStr string) (bool, error) {
-	isDestructive, description, err := a.aiClient.CheckDestructiveAction(ctx, decision.Action, contextStr)
+// SwitchChromeProfile переключает браузер на профиль Chrome внутри user-data-dir по имени
+// из интерфейса Chrome ("Work") или каталогу ("Profile 2"). Как и SwitchProfile,
+// перезапускает браузер и во время выполнения задачи запрещена.
+func (a *Agent) SwitchChromeProfile(query string) (browser.ChromeProfile, error) {
+	if a.running {
+		return browser.ChromeProfile{}, fmt.Errorf("нельзя сменить профиль Chrome во время выполнения задачи - дождитесь завершения задачи")
+	}
+	profile, err := a.browser.FindChromeProfile(query)
 	if err != nil {
-		return false, err
+		return browser.ChromeProfile{}, err
 	}
-	
-	if !isDestructive {
-		return true, nil
+	if profile.Active {
+		return profile, nil
 	}
-	
-	fmt.Printf("\n⚠️  ВНИМАНИЕ: Деструктивное действие обнаружено!\n")
-	fmt.Printf("   Действие: %s\n", decision.Action)
-	fmt.Printf("   Описание: %s\n", description)
-	if decision.Text != "" {
-		fmt.Printf("   Элемент: %s\n", decision.Text)
+
+	fmt.Printf("👤 Переключение профиля Chrome: %s -> %s (перезапуск браузера)...\n", a.browser.ProfileDirectory(), profile.Directory)
+	if err := a.browser.SwitchProfileDirectory(profile.Directory); err != nil {
+		return browser.ChromeProfile{}, err
 	}
-	fmt.Printf("\n❓ Подтвердите действие (yes/no): ")
-	
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return false, err
+	a.chromeVersion = ""
+	profile.Active = true
+	return profile, nil
+}
+
+// IsSafeMode сообщает, включен ли safe-mode
+func (a *Agent) IsSafeMode() bool {
+	return a.safeMode
+}
+
+// safeModeViolation возвращает причину блокировки действия в safe-mode (пустая строка - действие разрешено)
+func (a *Agent) safeModeViolation(decision *ai.Decision) string {
+	spec, ok := ai.LookupAction(decision.Action)
+	if !ok {
+		return ""
 	}
-	
-	response = strings.TrimSpace(strings.ToLower(response))
-	return response == "yes" || response == "y" || response == "да" || response == "д", nil
+	if spec.Unsafe != "" {
+		return spec.Unsafe
+	}
+	if decision.Action == "press_key" {
+		key := strings.ToLower(decision.Key)
+		if key == "enter" || key == "return" {
+			return "нажатие Enter может отправить форму"
+		}
+		if key == "delete" || key == "del" {
+			return "нажатие Delete может удалить данные"
+		}
+	}
+	// Правила клика действуют для всех действий реестра, которые кликают по элементу (click, download).
+	// Сравниваются целые слова текста и селектора элемента, а не обоснование модели:
+	// "Sort order" или клик, который модель объясняет словом "оплатить", не блокируются.
+	if spec.Clicks {
+		words := splitWords(decision.Text + " " + decision.Selector)
+		for _, pattern := range safeModeClickPatterns {
+			if hasPhrase(words, splitWords(pattern)) {
+				return fmt.Sprintf("элемент похож на '%s'", pattern)
+			}
+		}
+	}
+	return ""
+}
+
+// safeModeClickPatterns - признаки кнопок отправки, покупки и удаления (целые слова)
+var safeModeClickPatterns = []string{
+	"submit", "отправить", "send", "подтвердить", "confirm",
+	"купить", "buy", "purchase", "оплатить", "pay", "checkout",
+	"оформить", "заказать", "place order", "order now", "в корзину", "add to cart", "добавить в",
+	"удалить", "delete", "remove", "в спам", "mark as spam",
+	"откликнуться", "apply now", "apply for", "сохранить", "save", "опубликовать", "publish",
+}
+
+// hasPhrase сообщает, что слова phrase идут подряд среди words
+func hasPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// autoClickBlocked проверяет кнопку, которую агент нажимает сам, без решения модели
+// (продление сессии, следующая страница списка), как клик модели: возвращает причину
+// отказа safe-mode или уровень риска, при котором нужен вопрос пользователю ("" - можно нажать)
+func (a *Agent) autoClickBlocked(text string) string {
+	click := &ai.Decision{Action: "click", Text: text}
+	if a.safeMode {
+		if reason := a.safeModeViolation(click); reason != "" {
+			return "safe-mode: " + reason
+		}
+	}
+	if !a.disableDestructiveCheck {
+		if severity := a.destructiveSeverity(click); severity != "" {
+			return fmt.Sprintf("похоже на деструктивное действие (%s)", severity)
+		}
+	}
+	return ""
 }
 
 // calculateRetryDelay вычисляет задержку перед повтором с экспоненциальным backoff
@@ -422,21 +910,3 @@ func (a *Agent) calculateRetryDelay(errorCount int) time.Duration {
 	return delay
 }
 
-// adaptToError определяет стратегию адаптации к ошибке
-func (a *Agent) adaptToError(err error, decision *ai.Decision) string {
-	errStr := strings.ToLower(err.Error())
-	
-	if strings.Contains(errStr, "not found") || strings.Contains(errStr, "не найден") {
-		return "элемент не найден - попробую найти альтернативный способ"
-	}
-	
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "таймаут") {
-		return "таймаут - увеличу время ожидания"
-	}
-	
-	if strings.Contains(errStr, "visible") || strings.Contains(errStr, "видимый") {
-		return "элемент не видим - подожду загрузки страницы"
-	}
-	
-	return "повторю попытку с задержкой"
-}
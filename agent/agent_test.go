@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+func TestAutoClickBlocked(t *testing.T) {
+	tests := []struct {
+		name        string
+		button      string
+		safeMode    bool
+		noCheck     bool
+		wantBlocked bool
+	}{
+		{"continue", "Продолжить сессию", false, false, false},
+		{"stay", "Stay signed in", true, false, false},
+		{"confirm asks user", "Подтвердить", false, false, true},
+		{"confirm in safe-mode", "Confirm", true, true, true},
+		{"confirm without destructive check", "Confirm", false, true, false},
+		{"cancel", "Отменить выход", false, false, true},
+		{"next page", "Следующая", true, false, false},
+		{"next hint that submits", "Отправить и далее", true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{safeMode: tt.safeMode, disableDestructiveCheck: tt.noCheck}
+			if got := a.autoClickBlocked(tt.button); (got != "") != tt.wantBlocked {
+				t.Errorf("autoClickBlocked(%q) = %q, want blocked %v", tt.button, got, tt.wantBlocked)
+			}
+		})
+	}
+}
+
+func TestSafeModeClickRulesCoverDownload(t *testing.T) {
+	a := &Agent{}
+	for _, action := range []string{"click", "download"} {
+		if reason := a.safeModeViolation(&ai.Decision{Action: action, Text: "Оплатить и скачать чек"}); reason == "" {
+			t.Errorf("%s of a pay button must be blocked in safe-mode", action)
+		}
+		if reason := a.safeModeViolation(&ai.Decision{Action: action, Text: "Скачать выписку"}); reason != "" {
+			t.Errorf("%s of a download link blocked: %s", action, reason)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// batchTaskTimeout - таймаут одной задачи пакета, как у задачи из консоли
+const batchTaskTimeout = 15 * time.Minute
+
+// batchRefRegex находит ссылки на результаты предыдущих задач: ${task1.url}, ${task2.1.name}
+var batchRefRegex = regexp.MustCompile(`\$\{\s*([^{}\s]+)\s*\}`)
+
+// ReadBatchFile читает файл пакета задач: одна задача на строку, пустые строки
+// и строки, начинающиеся с #, пропускаются
+func ReadBatchFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл задач: %w", err)
+	}
+	defer f.Close()
+
+	var tasks []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tasks = append(tasks, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл задач: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("в файле %s нет задач", path)
+	}
+	return tasks, nil
+}
+
+// RunBatch выполняет задачи по очереди. Задача может ссылаться на результат
+// предыдущей через ${taskN.поле}: поля extracted_data (для массива - поля первого
+// элемента и ${taskN.I.поле} для I-го), url, final_url, summary, data, а также
+// переменные, сохраненные моделью во время задачи. Ссылки без префикса (${поле})
+// берут последнее значение переменной из любой предыдущей задачи. Ссылки
+// подставляются перед выполнением; задача со ссылкой на невыполненную задачу
+// или неизвестное поле пропускается с ошибкой, остальные выполняются.
+func (a *Agent) RunBatch(ctx context.Context, tasks []string) []*TaskResult {
+	vars := make(map[string]string)
+	results := make([]*TaskResult, 0, len(tasks))
+	for i, task := range tasks {
+		n := i + 1
+		fmt.Printf("\n📦 Задача %d/%d: %s\n", n, len(tasks), task)
+
+		resolved, err := resolveBatchRefs(task, vars)
+		if err != nil {
+			fmt.Printf("⏭️  Задача %d пропущена: %v\n", n, err)
+			results = append(results, &TaskResult{Task: task, Error: err.Error()})
+			continue
+		}
+		if resolved != task {
+			fmt.Printf("🧩 Подставлены результаты: %s\n", resolved)
+		}
+
+		taskCtx, cancel := WithTaskTimeout(ctx, batchTaskTimeout)
+		result, err := a.ExecuteWithResult(taskCtx, resolved, nil)
+		cancel()
+		results = append(results, result)
+		if err != nil || !result.Success {
+			if result.Error == "" {
+				result.Error = "задача не завершена"
+			}
+			fmt.Printf("❌ Задача %d не выполнена: %s\n", n, result.Error)
+		} else {
+			a.storeBatchVars(vars, n, result)
+			fmt.Printf("✅ Задача %d выполнена\n", n)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return results
+}
+
+// resolveBatchRefs подставляет ${...} из переменных пакета
+func resolveBatchRefs(task string, vars map[string]string) (string, error) {
+	var missing []string
+	resolved := batchRefRegex.ReplaceAllStringFunc(task, func(match string) string {
+		name := batchRefRegex.FindStringSubmatch(match)[1]
+		if value, ok := vars[strings.ToLower(name)]; ok {
+			return value
+		}
+		missing = append(missing, match)
+		return match
+	})
+	if len(missing) > 0 {
+		return task, fmt.Errorf("нет значения для %s (задача не выполнена или поле не извлечено)", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// storeBatchVars сохраняет результат задачи n в переменные пакета
+func (a *Agent) storeBatchVars(vars map[string]string, n int, result *TaskResult) {
+	prefix := "task" + strconv.Itoa(n) + "."
+	set := func(key, value string) {
+		vars[strings.ToLower(prefix+key)] = value
+	}
+
+	// Переменные модели (metadata) доступны и без префикса - последнее значение
+	for key, value := range a.vars {
+		set(key, value)
+		vars[strings.ToLower(key)] = value
+	}
+	set("summary", result.Summary)
+	if url, err := a.browser.GetCurrentURL(); err == nil {
+		set("final_url", url)
+		set("url", url)
+	}
+	if len(result.ExtractedData) == 0 {
+		return
+	}
+	set("data", string(result.ExtractedData))
+
+	var data interface{}
+	if err := json.Unmarshal(result.ExtractedData, &data); err != nil {
+		return
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		storeBatchFields(set, "", v)
+	case []interface{}:
+		for i, item := range v {
+			if fields, ok := item.(map[string]interface{}); ok {
+				if i == 0 {
+					storeBatchFields(set, "", fields)
+				}
+				storeBatchFields(set, strconv.Itoa(i+1)+".", fields)
+			}
+		}
+	}
+}
+
+// storeBatchFields сохраняет поля объекта extracted_data: строки как есть, остальное - JSON
+func storeBatchFields(set func(key, value string), prefix string, fields map[string]interface{}) {
+	for key, value := range fields {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			set(prefix+key, v)
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			set(prefix+key, string(encoded))
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/buildinfo"
+	"github.com/Angabebr/Golang-AI-agent/bundle"
+)
+
+// SetBundleDir включает запись каждой задачи в пакет для воспроизведения в каталоге dir
+// (пустая строка - выключено)
+func (a *Agent) SetBundleDir(dir string) {
+	a.bundleDir = dir
+}
+
+// SetBundleConfig задает настройки запуска (без секретов), которые попадают в пакет
+func (a *Agent) SetBundleConfig(config map[string]string) {
+	a.bundleConfig = config
+}
+
+// startBundle создает пакет задачи и включает запись обмена с моделью
+func (a *Agent) startBundle(task string) {
+	a.bundle = nil
+	if a.bundleDir == "" {
+		return
+	}
+	manifest := bundle.Manifest{
+		Agent:         buildinfo.Get(),
+		Task:          task,
+		Model:         a.aiClient.Model(),
+		ContextTokens: a.aiClient.ContextTokens(),
+		SafeMode:      a.safeMode,
+		Handoff:       a.handoffAvailable(),
+		ResultSchema:  a.aiClient.ResultSchema(),
+		Preferred:     a.aiClient.PreferredTargets(),
+		CreatedAt:     time.Now(),
+	}
+	if err := os.MkdirAll(a.bundleDir, 0755); err != nil {
+		fmt.Printf("⚠️  Не удалось создать пакет для воспроизведения: %v\n", err)
+		return
+	}
+	dir, err := createRunDir(a.bundleDir, a.runMetadata.StartedAt)
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось создать пакет для воспроизведения: %v\n", err)
+		return
+	}
+	rec, err := bundle.Start(dir, manifest, a.bundleConfig)
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось создать пакет для воспроизведения: %v\n", err)
+		return
+	}
+	a.bundle = rec
+	a.aiClient.SetTransport(rec.Transport())
+}
+
+// bundleMark - состояние перед решением модели: что она увидит и с какого обмена начнет
+type bundleMark struct {
+	exchanges         int
+	history           []string
+	pageTextRequested bool
+	systemPrompt      string
+}
+
+func (a *Agent) markBundle() bundleMark {
+	if a.bundle == nil {
+		return bundleMark{}
+	}
+	return bundleMark{
+		exchanges:         a.bundle.Exchanges(),
+		history:           append([]string{}, a.history...),
+		pageTextRequested: a.aiClient.PageTextRequested(),
+		systemPrompt:      a.aiClient.GetSystemPrompt(),
+	}
+}
+
+// recordBundleIteration записывает в пакет страницу, которую видела модель, и ее решение
+func (a *Agent) recordBundleIteration(mark bundleMark, kind, url string, page interface{}, decision *ai.Decision, decisionErr error) {
+	if a.bundle == nil {
+		return
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	it := bundle.Iteration{
+		Iteration:         a.iteration,
+		Kind:              kind,
+		URL:               url,
+		Page:              data,
+		History:           mark.history,
+		PageTextRequested: mark.pageTextRequested,
+		SystemPrompt:      mark.systemPrompt,
+		Exchanges:         [2]int{mark.exchanges, a.bundle.Exchanges()},
+		Decision:          decision,
+	}
+	if decisionErr != nil {
+		it.Error = decisionErr.Error()
+	}
+	html, _ := a.browser.GetPageHTML()
+	a.bundle.RecordIteration(it, html)
+}
+
+// secretFieldRegex - поля, значения которых не должны попасть в пакет: пароли, коды
+// подтверждения, номера и коды карт
+var secretFieldRegex = regexp.MustCompile(`(?i)pass|пароль|otp|one.?time|код|\bcode\b|cvc|cvv|csc|card|карт|\bpin\b|secret|token`)
+
+// secretFill сообщает, что решение заполняет поле секретом
+func secretFill(decision *ai.Decision) bool {
+	return decision.Action == "fill" && secretFieldRegex.MatchString(decision.Text+" "+decision.Selector)
+}
+
+// usesVars сообщает, что в строке есть шаблоны переменных задачи ({{login}}), а не только даты
+func usesVars(s string) bool {
+	for _, match := range templateRegex.FindAllStringSubmatch(s, -1) {
+		parts := templateExprRegex.FindStringSubmatch(strings.TrimSpace(match[1]))
+		if parts == nil || !isTimeTemplate(strings.ToLower(parts[1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecret не дает значению попасть в пакет для воспроизведения
+func (a *Agent) redactSecret(value string) {
+	if a.bundle != nil {
+		a.bundle.Redact(value)
+	}
+}
+
+// finishBundle упаковывает пакет задачи в zip и возвращает обычный транспорт модели
+func (a *Agent) finishBundle() {
+	if a.bundle == nil {
+		return
+	}
+	rec := a.bundle
+	a.bundle = nil
+	a.aiClient.SetTransport(nil)
+	path, err := rec.Finish(a.transcriptPath)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return
+	}
+	fmt.Printf("📦 Пакет для воспроизведения: %s\n", path)
+}
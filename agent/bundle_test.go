@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+func TestSecretFill(t *testing.T) {
+	tests := []struct {
+		decision ai.Decision
+		want     bool
+	}{
+		{ai.Decision{Action: "fill", Text: "Пароль"}, true},
+		{ai.Decision{Action: "fill", Selector: "input[name=password]"}, true},
+		{ai.Decision{Action: "fill", Text: "Код из SMS"}, true},
+		{ai.Decision{Action: "fill", Text: "Номер карты"}, true},
+		{ai.Decision{Action: "fill", Selector: "#cvc"}, true},
+		{ai.Decision{Action: "fill", Text: "Город"}, false},
+		{ai.Decision{Action: "click", Text: "Забыли пароль?"}, false},
+	}
+	for _, tt := range tests {
+		if got := secretFill(&tt.decision); got != tt.want {
+			t.Errorf("secretFill(%+v) = %v, want %v", tt.decision, got, tt.want)
+		}
+	}
+}
+
+func TestUsesVars(t *testing.T) {
+	tests := map[string]bool{
+		"{{password}}":               true,
+		"Москва":                     false,
+		"{{today}}":                  false,
+		"с {{tomorrow+1d}} по {{x}}": true,
+		"{{now + 2h}}":               false,
+	}
+	for in, want := range tests {
+		if got := usesVars(in); got != want {
+			t.Errorf("usesVars(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// checkpointInterval - как часто (в итерациях) сохраняется состояние задачи
+const checkpointInterval = 5
+
+// Checkpoint - сохраненное состояние задачи для продолжения после сбоя
+type Checkpoint struct {
+	Task      string            `json:"task"`
+	History   []string          `json:"history"`
+	Vars      map[string]string `json:"vars,omitempty"`
+	URL       string            `json:"url"`
+	Iteration int               `json:"iteration"`
+	SavedAt   time.Time         `json:"saved_at"`
+}
+
+// SetCheckpointPath включает периодическое сохранение состояния задачи в файл (пустой путь - выключено)
+func (a *Agent) SetCheckpointPath(path string) {
+	a.checkpointPath = path
+}
+
+// saveCheckpoint сохраняет состояние каждые checkpointInterval итераций
+func (a *Agent) saveCheckpoint() {
+	if a.checkpointPath == "" || a.iteration%checkpointInterval != 0 {
+		return
+	}
+
+	url, err := a.browser.GetCurrentURL()
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить checkpoint: %v\n", err)
+		return
+	}
+
+	cp := Checkpoint{
+		Task:      a.task,
+		History:   a.history,
+		Vars:      a.vars,
+		URL:       url,
+		Iteration: a.iteration,
+		SavedAt:   time.Now(),
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить checkpoint: %v\n", err)
+		return
+	}
+
+	if dir := filepath.Dir(a.checkpointPath); dir != "" {
+		os.MkdirAll(dir, 0755)
+	}
+	// Пишем через временный файл, чтобы не повредить checkpoint при прерывании
+	tmpPath := a.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить checkpoint: %v\n", err)
+		return
+	}
+	if err := os.Rename(tmpPath, a.checkpointPath); err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить checkpoint: %v\n", err)
+	}
+}
+
+// finishCheckpoint удаляет checkpoint после успешного завершения задачи
+func (a *Agent) finishCheckpoint(err error) {
+	if err != nil || !a.completed {
+		return
+	}
+	a.discardCheckpoint()
+}
+
+func (a *Agent) discardCheckpoint() {
+	if a.checkpointPath != "" {
+		os.Remove(a.checkpointPath)
+	}
+}
+
+// LoadCheckpoint читает сохраненное состояние задачи
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if cp.Task == "" {
+		return nil, fmt.Errorf("checkpoint %s не содержит задачи", path)
+	}
+	return &cp, nil
+}
+
+// ResumeFromCheckpoint восстанавливает задачу из checkpoint и продолжает цикл выполнения
+// с сохраненной итерации. Браузер заново открывает сохраненный URL, а модель получает
+// подсказку, что страница могла измениться с момента сохранения.
+func (a *Agent) ResumeFromCheckpoint(ctx context.Context, path string) error {
+	cp, err := LoadCheckpoint(path)
+	if err != nil {
+		return err
+	}
+
+	a.resetTaskState(cp.Task)
+	a.history = cp.History
+	a.iteration = cp.Iteration
+	if cp.Vars != nil {
+		a.vars = cp.Vars
+	}
+
+	fmt.Printf("\n♻️  Продолжаю задачу из checkpoint (итерация %d, сохранен %s): %s\n\n",
+		cp.Iteration, cp.SavedAt.Format("02.01.2006 15:04:05"), cp.Task)
+
+	note := fmt.Sprintf("ВОССТАНОВЛЕНО из checkpoint (итерация %d). Страница могла измениться с момента сохранения - сначала изучи текущее состояние", cp.Iteration)
+	if cp.URL != "" && cp.URL != "about:blank" {
+		fmt.Printf("🌐 Возврат на сохраненную страницу: %s\n", cp.URL)
+		if err := a.browser.Navigate(cp.URL); err != nil {
+			note += fmt.Sprintf(". Не удалось открыть сохраненный URL %s: %v", cp.URL, err)
+		}
+	}
+	if len(a.vars) > 0 {
+		keys := make([]string, 0, len(a.vars))
+		for key := range a.vars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var saved []string
+		for _, key := range keys {
+			saved = append(saved, fmt.Sprintf("%s=%s", key, a.vars[key]))
+		}
+		note += ". Сохраненные данные: " + strings.Join(saved, ", ")
+	}
+	a.history = append(a.history, note)
+
+	return a.run(ctx, a.task)
+}
@@ -0,0 +1,430 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// ErrActionCanceled - деструктивное действие не подтверждено, задача остановлена
+var ErrActionCanceled = errors.New("destructive action canceled")
+
+// Severity - уровень риска деструктивного действия
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+var severityRank = map[Severity]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3}
+
+// parseSeverity разбирает уровень из ответа модели; неизвестное значение считается medium
+func parseSeverity(s string) Severity {
+	switch Severity(strings.ToLower(strings.TrimSpace(s))) {
+	case SeverityLow:
+		return SeverityLow
+	case SeverityHigh:
+		return SeverityHigh
+	}
+	return SeverityMedium
+}
+
+func maxSeverity(a, b Severity) Severity {
+	if severityRank[b] > severityRank[a] {
+		return b
+	}
+	return a
+}
+
+// ConfirmationMode определяет, как пользователь подтверждает действие
+type ConfirmationMode int
+
+const (
+	ConfirmAuto   ConfirmationMode = iota // подтверждается автоматически, только запись в лог
+	ConfirmYesNo                          // ответ yes/no
+	ConfirmPhrase                         // ввод фразы подтверждения с суммой или количеством
+)
+
+// ConfirmationPolicy сопоставляет уровни риска и способы подтверждения
+type ConfirmationPolicy struct {
+	Low    ConfirmationMode
+	Medium ConfirmationMode
+	High   ConfirmationMode
+
+	// Batch разрешает подтверждать серию одинаковых действий (удалить 15 писем) одним ответом
+	Batch bool
+	// BatchHigh разрешает серии и для высокого риска (оплата); по умолчанию каждое такое действие подтверждается отдельно
+	BatchHigh bool
+	// MaxBatch - жесткий предел действий в одной одобренной серии
+	MaxBatch int
+}
+
+// DefaultConfirmationPolicy: low - автоматически, medium - yes/no, high - фраза подтверждения;
+// серии до 20 действий разрешены для всех уровней, кроме high
+func DefaultConfirmationPolicy() ConfirmationPolicy {
+	return ConfirmationPolicy{
+		Low:      ConfirmAuto,
+		Medium:   ConfirmYesNo,
+		High:     ConfirmPhrase,
+		Batch:    true,
+		MaxBatch: defaultMaxBatch,
+	}
+}
+
+func (p ConfirmationPolicy) modeFor(severity Severity) ConfirmationMode {
+	switch severity {
+	case SeverityLow:
+		return p.Low
+	case SeverityHigh:
+		return p.High
+	}
+	return p.Medium
+}
+
+// SetConfirmationPolicy задает способы подтверждения для уровней риска
+func (a *Agent) SetConfirmationPolicy(policy ConfirmationPolicy) {
+	a.confirmationPolicy = policy
+}
+
+// SetDisableDestructiveCheck отключает проверку деструктивных действий и подтверждения
+// для доверенных сценариев (тестовый стенд, исследование только для чтения).
+// Safe-mode продолжает блокировать действия, меняющие состояние.
+func (a *Agent) SetDisableDestructiveCheck(disabled bool) {
+	a.disableDestructiveCheck = disabled
+}
+
+var (
+	highRiskKeywords = []string{
+		"оплатить", "оплата", "pay", "payment", "купить", "buy", "purchase", "заказать", "оформить заказ", "checkout",
+		"удалить аккаунт", "delete account", "удалить все", "delete all",
+	}
+	mediumRiskKeywords = []string{
+		"подтвердить", "confirm", "submit", "отправить",
+		"отменить", "cancel", "отмена",
+		"изменить", "change", "modify", "редактировать",
+		"сохранить", "save", "сохранение",
+	}
+	lowRiskKeywords = []string{
+		"удалить", "delete", "remove", "удаление",
+	}
+)
+
+// destructiveSeverity оценивает риск действия по ключевым словам.
+// Пустая строка означает, что действие не выглядит деструктивным.
+func (a *Agent) destructiveSeverity(decision *ai.Decision) Severity {
+	action := strings.ToLower(decision.Action)
+	text := strings.ToLower(decision.Text)
+	reasoning := strings.ToLower(decision.Reasoning)
+
+	matches := func(keywords []string) bool {
+		for _, keyword := range keywords {
+			if strings.Contains(action, keyword) ||
+				strings.Contains(text, keyword) ||
+				strings.Contains(reasoning, keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Оформление заказа из корзины всегда связано с деньгами
+	if strings.Contains(text, "корзина") && (strings.Contains(text, "оформить") || strings.Contains(text, "заказать")) {
+		return SeverityHigh
+	}
+
+	var severity Severity
+	switch {
+	case matches(highRiskKeywords):
+		severity = SeverityHigh
+	case matches(mediumRiskKeywords):
+		severity = SeverityMedium
+	case matches(lowRiskKeywords):
+		severity = SeverityLow
+	}
+	// Загрузка отдает сайту локальный файл, и вернуть его нельзя: всегда спрашиваем пользователя
+	if action == "upload" {
+		severity = maxSeverity(severity, SeverityMedium)
+	}
+	return severity
+}
+
+// amountRegex находит сумму с валютой, например "5 000 ₽" или "$12.50"
+var amountRegex = regexp.MustCompile(`(?i)(\d[\d\s\x{00A0}]*(?:[.,]\d+)?\s*(?:₽|руб\.?|р\.|rub|€|\$))|([\$€]\s*\d[\d\s,]*(?:\.\d+)?)`)
+
+// confirmationPhrase формирует фразу, которую пользователь должен ввести для действия высокого риска
+func confirmationPhrase(check *ai.DestructiveCheck, decision *ai.Decision) string {
+	amount := strings.TrimSpace(check.Amount)
+	if amount == "" {
+		amount = strings.TrimSpace(amountRegex.FindString(decision.Text))
+	}
+	switch {
+	case amount != "":
+		return "подтверждаю " + amount
+	case check.ItemCount > 0:
+		return fmt.Sprintf("подтверждаю %d шт", check.ItemCount)
+	}
+	return "подтверждаю"
+}
+
+// normalizePhrase убирает регистр и пробелы, чтобы "5 000" и "5000" совпадали
+func normalizePhrase(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\u00A0' || r == '\u202F'
+	}), "")
+}
+
+// askUser выводит вопрос и читает ответ пользователя из stdin
+func (a *Agent) askUser(prompt string) (string, error) {
+	fmt.Print(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// checkDestructiveAction уточняет риск через LLM и запрашивает подтверждение по политике
+func (a *Agent) checkDestructiveAction(ctx context.Context, decision *ai.Decision, severity Severity, contextStr string) (bool, error) {
+	actionDesc := decision.Action
+	if decision.Text != "" {
+		actionDesc += fmt.Sprintf(" '%s'", decision.Text)
+	}
+	if decision.Action == "upload" {
+		actionDesc += fmt.Sprintf(" файлы: %s", decision.Value)
+	}
+	if decision.Reasoning != "" {
+		actionDesc += fmt.Sprintf(" (%s)", decision.Reasoning)
+	}
+
+	check, err := a.aiClient.CheckDestructiveAction(ctx, actionDesc, contextStr)
+	if err != nil {
+		return false, err
+	}
+
+	if !check.IsDestructive {
+		if decision.Action != "upload" {
+			return true, nil
+		}
+		// Отправку файла модель может счесть безобидной, но решает пользователь
+		check.IsDestructive = true
+		check.Description = "загрузка локальных файлов на сайт: " + decision.Value
+	}
+
+	// Берем более строгую из двух оценок
+	if check.Severity != "" {
+		severity = maxSeverity(severity, parseSeverity(check.Severity))
+	}
+	mode := a.confirmationPolicy.modeFor(severity)
+
+	// Серия, уже одобренная пользователем, подтверждается без вопроса до одобренного количества
+	pattern := a.confirmationPattern(decision)
+	if approval := a.batchApprovals[pattern]; approval != nil && mode != ConfirmAuto {
+		if approval.used < approval.count {
+			approval.used++
+			fmt.Printf("✅ Действие из одобренной серии (%d из %d): %s\n", approval.used, approval.count, actionDesc)
+			a.recordConfirmation(severity, actionDesc, fmt.Sprintf("серия %d/%d", approval.used, approval.count), true)
+			a.recordBatch(pattern, "used", approval)
+			return true, nil
+		}
+		fmt.Printf("\n⚠️  Одобренная серия исчерпана (%d действий) - нужно новое подтверждение\n", approval.count)
+		a.recordBatch(pattern, "exceeded", approval)
+		delete(a.batchApprovals, pattern)
+	}
+
+	fmt.Printf("\n⚠️  ВНИМАНИЕ: Деструктивное действие обнаружено! (риск: %s)\n", severity)
+	fmt.Printf("   Действие: %s\n", decision.Action)
+	fmt.Printf("   Описание: %s\n", check.Description)
+	if decision.Text != "" {
+		fmt.Printf("   Элемент: %s\n", decision.Text)
+	}
+	if check.Amount != "" {
+		fmt.Printf("   Сумма: %s\n", check.Amount)
+	}
+
+	// Без пользователя спросить некому: ответ из stdin был бы следующей строкой ввода, а не согласием
+	if !a.interactive && mode != ConfirmAuto {
+		fmt.Printf("🚫 Интерактивный ввод недоступен - действие отклонено без вопроса\n")
+		a.recordConfirmation(severity, actionDesc, "нет пользователя", false)
+		return false, nil
+	}
+
+	// Серию предлагаем, если затронуто несколько объектов или действие подтверждается не первый раз
+	if a.batchAllowed(severity, mode) && (check.ItemCount > 1 || (mode == ConfirmYesNo && a.patternConfirmations[pattern] >= batchOfferAfter)) {
+		return a.confirmBatch(decision, check, severity, mode, pattern, actionDesc)
+	}
+
+	var response string
+	var confirmed bool
+	switch mode {
+	case ConfirmAuto:
+		fmt.Printf("ℹ️  Низкий риск - действие подтверждено автоматически\n")
+		response = "авто"
+		confirmed = true
+
+	case ConfirmPhrase:
+		phrase := confirmationPhrase(check, decision)
+		response, err = a.askUser(fmt.Sprintf("\n❓ Для подтверждения введите фразу \"%s\" (любой другой ответ - отмена): ", phrase))
+		if err != nil {
+			return false, err
+		}
+		confirmed = normalizePhrase(response) == normalizePhrase(phrase)
+
+	default:
+		response, err = a.askUser("\n❓ Подтвердите действие (yes/no): ")
+		if err != nil {
+			return false, err
+		}
+		answer := strings.ToLower(response)
+		confirmed = answer == "yes" || answer == "y" || answer == "да" || answer == "д"
+	}
+
+	a.recordConfirmation(severity, actionDesc, response, confirmed)
+	if confirmed {
+		a.patternConfirmations[pattern]++
+	}
+	return confirmed, nil
+}
+
+// recordConfirmation сохраняет уровень риска и ответ пользователя в истории и для итогового резюме
+func (a *Agent) recordConfirmation(severity Severity, actionDesc, response string, confirmed bool) {
+	outcome := "разрешено"
+	if !confirmed {
+		outcome = "отклонено"
+	}
+	entry := fmt.Sprintf("[%s] %s - ответ: %q, %s", severity, actionDesc, response, outcome)
+	a.confirmations = append(a.confirmations, entry)
+	a.history = append(a.history, "ПОДТВЕРЖДЕНИЕ "+entry)
+}
+
+// summaryWithConfirmations дополняет резюме журналом подтверждений
+func (a *Agent) summaryWithConfirmations(summary string) string {
+	if len(a.confirmations) == 0 {
+		return summary
+	}
+	log := "Подтверждения деструктивных действий:\n- " + strings.Join(a.confirmations, "\n- ")
+	if summary == "" {
+		return log
+	}
+	return summary + "\n" + log
+}
+
+const (
+	defaultMaxBatch = 20
+	batchOfferAfter = 2 // после стольких отдельных подтверждений одного действия предлагается серия
+)
+
+// batchApproval - одобренная пользователем серия одинаковых действий
+type batchApproval struct {
+	count int // одобрено действий
+	used  int // выполнено, включая первое
+}
+
+// digitsRegex убирает числа из шаблона действия: "Удалить (3)" и "Удалить (4)" - одно действие
+var digitsRegex = regexp.MustCompile(`\d+`)
+
+// confirmationPattern - ключ серии: действие, текст элемента и тип страницы (домен и путь без чисел)
+func (a *Agent) confirmationPattern(decision *ai.Decision) string {
+	page := ""
+	if currentURL, err := a.browser.GetCurrentURL(); err == nil {
+		if parsed, err := neturl.Parse(currentURL); err == nil {
+			page = parsed.Host + digitsRegex.ReplaceAllString(parsed.Path, "#")
+		}
+	}
+	text := strings.ToLower(strings.TrimSpace(digitsRegex.ReplaceAllString(decision.Text, "#")))
+	return fmt.Sprintf("%s|%s|%s", decision.Action, text, page)
+}
+
+// batchAllowed - можно ли подтверждать действие этого уровня сериями
+func (a *Agent) batchAllowed(severity Severity, mode ConfirmationMode) bool {
+	policy := a.confirmationPolicy
+	if !policy.Batch || mode == ConfirmAuto {
+		return false
+	}
+	return severity != SeverityHigh || policy.BatchHigh
+}
+
+// confirmBatch спрашивает пользователя один раз о серии действий с количеством
+// ("удалить все 15 отмеченных писем?") и запоминает одобрение в рамках задачи
+func (a *Agent) confirmBatch(decision *ai.Decision, check *ai.DestructiveCheck, severity Severity, mode ConfirmationMode, pattern, actionDesc string) (bool, error) {
+	maxBatch := a.confirmationPolicy.MaxBatch
+	if maxBatch <= 0 {
+		maxBatch = defaultMaxBatch
+	}
+	count := min(check.ItemCount, maxBatch)
+
+	if a.patternConfirmations[pattern] >= batchOfferAfter {
+		fmt.Printf("⚠️  Это действие подтверждается уже %d-й раз подряд\n", a.patternConfirmations[pattern]+1)
+	}
+	if check.ItemCount > maxBatch {
+		fmt.Printf("⚠️  Затронуто %d объектов - одной серией можно одобрить не больше %d\n", check.ItemCount, maxBatch)
+	}
+
+	var response string
+	var err error
+	confirmed := false
+	switch {
+	case mode == ConfirmPhrase && count > 1:
+		phrase := fmt.Sprintf("подтверждаю %d шт", count)
+		response, err = a.askUser(fmt.Sprintf("\n❓ Серия действий «%s»: %d шт. Для подтверждения введите фразу \"%s\" (любой другой ответ - отмена): ", decision.Text, count, phrase))
+		confirmed = err == nil && normalizePhrase(response) == normalizePhrase(phrase)
+	case count > 1:
+		response, err = a.askUser(fmt.Sprintf("\n❓ Выполнить «%s» для всех %d элементов? (yes - все, число - столько, no - отмена): ", decision.Text, count))
+		confirmed, count = parseBatchAnswer(response, count, maxBatch)
+	default:
+		response, err = a.askUser(fmt.Sprintf("\n❓ Подтвердите действие (yes/no) или введите число - сколько таких действий выполнить без вопросов (не больше %d): ", maxBatch))
+		confirmed, count = parseBatchAnswer(response, 1, maxBatch)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	a.recordConfirmation(severity, actionDesc, response, confirmed)
+	if !confirmed {
+		return false, nil
+	}
+	a.patternConfirmations[pattern]++
+	if count > 1 {
+		approval := &batchApproval{count: count, used: 1}
+		a.batchApprovals[pattern] = approval
+		fmt.Printf("✅ Серия одобрена: %d действий «%s»\n", count, decision.Text)
+		a.recordBatch(pattern, "approved", approval)
+	}
+	return true, nil
+}
+
+// parseBatchAnswer разбирает ответ на вопрос о серии: yes - все count, число - столько (не больше maxBatch)
+func parseBatchAnswer(response string, count, maxBatch int) (bool, int) {
+	answer := strings.ToLower(strings.TrimSpace(response))
+	switch answer {
+	case "yes", "y", "да", "д":
+		return true, count
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n > 0 {
+		return true, min(n, maxBatch)
+	}
+	return false, 0
+}
+
+// recordBatch записывает область и количество одобренной серии в журнал задачи
+func (a *Agent) recordBatch(pattern, status string, approval *batchApproval) {
+	a.writeTranscript(transcriptEntry{
+		Type:      "event",
+		Iteration: a.iteration,
+		Action:    "confirmation_batch",
+		Status:    status,
+		Scope:     pattern,
+		Count:     approval.count,
+		Used:      approval.used,
+	})
+}
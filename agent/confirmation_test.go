@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/ai/aitest"
+)
+
+// savedArticlesPage - справочный сайт, где "сохранить" есть в тексте обычных ссылок
+const savedArticlesPage = `<p id="status">список статей</p>
+<a href="#" onclick="document.getElementById('status').textContent = 'статья открыта'; return false">Как сохранить документ в PDF</a>
+<button onclick="document.getElementById('status').textContent = 'настройки сохранены'">Сохранить</button>`
+
+func TestDisableDestructiveCheck(t *testing.T) {
+	b := newFixtureBrowser(t, savedArticlesPage)
+	// Сценарий без ответов: любой вопрос модели о риске - ошибка сценария
+	model := aitest.NewScriptedProvider()
+	a := NewAgent(b, ai.NewClientWithProvider(model, "gpt-4o"))
+	a.SetInteractive(false)
+	a.SetDisableDestructiveCheck(true)
+	a.settleMax = 0
+	if _, err := b.GetPageContent(); err != nil {
+		t.Fatal(err)
+	}
+	status := func() string {
+		text, err := b.GetText("#status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(text)
+	}
+
+	article := ai.Decision{Action: "click", Text: "Как сохранить документ в PDF"}
+	if a.destructiveSeverity(&article) == "" {
+		t.Fatal("the fixture link must look destructive to the keyword check")
+	}
+	if err := a.processDecision(context.Background(), &article); err != nil {
+		t.Fatalf("click with the destructive check disabled: %v", err)
+	}
+	if got := status(); got != "статья открыта" {
+		t.Errorf("status = %q, want the article opened", got)
+	}
+	if len(model.Prompts()) != 0 || len(a.confirmations) != 0 {
+		t.Errorf("destructive check ran: prompts %q, confirmations %q", model.Prompts(), a.confirmations)
+	}
+
+	// Safe-mode проверку не отключает: кнопка, меняющая состояние, блокируется
+	a.SetSafeMode(true)
+	save := ai.Decision{Action: "click", Text: "Сохранить"}
+	if err := a.processDecision(context.Background(), &save); err != nil {
+		t.Fatalf("blocked click: %v", err)
+	}
+	if got := status(); got != "статья открыта" {
+		t.Errorf("status = %q, safe-mode must block the save button", got)
+	}
+	if last := a.history[len(a.history)-1]; !strings.Contains(last, "заблокировано safe-mode") {
+		t.Errorf("last history entry = %q, want a safe-mode block", last)
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"fmt"
+)
+
+// maxCrashReloads - сколько раз агент перезагружает одну и ту же упавшую страницу
+const maxCrashReloads = 2
+
+// recoverCrashedPage перезагружает вкладку, если упал процесс ее отрисовки или вместо
+// страницы показана страница ошибки chrome-error://. Без этого модель читает страницу
+// ошибки как содержимое сайта и пытается кликать по несуществующим элементам.
+func (a *Agent) recoverCrashedPage() {
+	// Упавший процесс вкладки не отвечает - проверка страницы только ждала бы таймаута
+	if a.browser.RendererCrashed() {
+		a.recoverRenderer("")
+		return
+	}
+
+	info, err := a.browser.DetectCrashPage()
+	if err != nil || !info.Crashed {
+		return
+	}
+
+	attempts := a.crashReloads[info.URL]
+	if attempts >= maxCrashReloads {
+		if attempts == maxCrashReloads {
+			a.crashReloads[info.URL]++
+			a.history = append(a.history, fmt.Sprintf("страница %s снова показала сбой Chrome (%s) после %d перезагрузок - перейди на другую страницу", info.URL, info.Reason, maxCrashReloads))
+		}
+		return
+	}
+	a.crashReloads[info.URL]++
+
+	fmt.Printf("⚠️  Сбой страницы Chrome (%s): %s - перезагрузка (%d/%d)\n", info.Reason, info.URL, attempts+1, maxCrashReloads)
+	entry := transcriptEntry{Type: "event", Iteration: a.iteration, Action: "crash_reload", URL: info.URL, Status: "ok", Error: info.Reason}
+	if err := a.browser.Reload(); err != nil {
+		fmt.Printf("❌ Не удалось перезагрузить страницу: %v\n", err)
+		entry.Status = "error"
+		entry.Error = fmt.Sprintf("%s: %v", info.Reason, err)
+		a.writeTranscript(entry)
+		return
+	}
+	a.writeTranscript(entry)
+	a.history = append(a.history, fmt.Sprintf("страница %s показала сбой Chrome (%s) и была автоматически перезагружена", info.URL, info.Reason))
+}
+
+// recoverRenderer перезагружает вкладку, процесс отрисовки которой упал. Пока вкладка не
+// перезагружена, каждое действие и анализ страницы завершаются таймаутом - без
+// восстановления они тратили бы бюджет ошибок, а модель видела бы череду непонятных
+// ошибок вместо одной записи о сбое. failedAction - действие, во время которого упала
+// вкладка ("" - сбой найден до действия).
+func (a *Agent) recoverRenderer(failedAction string) {
+	fmt.Println("⚠️  Процесс вкладки Chrome упал - перезагрузка вкладки")
+	url, err := a.browser.RecoverRenderer()
+	entry := transcriptEntry{Type: "event", Iteration: a.iteration, Action: "renderer_crash", URL: url, Status: "ok"}
+	if err != nil {
+		fmt.Printf("❌ Не удалось восстановить вкладку: %v\n", err)
+		entry.Status = "error"
+		entry.Error = err.Error()
+		a.writeTranscript(entry)
+		a.history = append(a.history, fmt.Sprintf("вкладка упала и не восстановилась после перезагрузки (%v) - открой нужную страницу заново", err))
+		return
+	}
+	a.writeTranscript(entry)
+	note := fmt.Sprintf("вкладка перезагружена после сбоя (%s)", url)
+	if failedAction != "" {
+		note += fmt.Sprintf(" - действие %s не выполнено, повтори его, если оно еще нужно", failedAction)
+	}
+	a.history = append(a.history, note)
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// taskContextKey - ключ, по которому taskContext находится и через производные контексты
+type taskContextKey struct{}
+
+// taskContext - контекст задачи с таймаутом, который можно приостановить: пока
+// пользователь вручную выполняет шаг (handoff), время задачи не идет. Истечение
+// таймаута дает context.DeadlineExceeded, как у context.WithTimeout.
+type taskContext struct {
+	parent context.Context
+	done   chan struct{}
+	stop   func() bool // отписка от отмены родительского контекста
+
+	mu       sync.Mutex
+	err      error
+	timer    *time.Timer
+	deadline time.Time
+	paused   bool
+}
+
+// WithTaskTimeout работает как context.WithTimeout, но агент может заморозить
+// таймаут на время ожидания пользователя (действие handoff)
+func WithTaskTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	c := &taskContext{
+		parent:   parent,
+		done:     make(chan struct{}),
+		deadline: time.Now().Add(timeout),
+	}
+	c.timer = time.AfterFunc(timeout, func() { c.cancel(context.DeadlineExceeded) })
+	c.stop = context.AfterFunc(parent, func() { c.cancel(parent.Err()) })
+	return c, func() { c.cancel(context.Canceled) }
+}
+
+func (c *taskContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, true
+}
+
+func (c *taskContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *taskContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *taskContext) Value(key interface{}) interface{} {
+	if key == (taskContextKey{}) {
+		return c
+	}
+	return c.parent.Value(key)
+}
+
+func (c *taskContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	c.timer.Stop()
+	c.stop()
+	close(c.done)
+}
+
+// pauseTaskDeadline останавливает таймаут задачи и возвращает функцию, которая
+// запускает его снова с оставшимся временем. Для контекстов не из WithTaskTimeout
+// ничего не делает.
+func pauseTaskDeadline(ctx context.Context) (resume func()) {
+	c, ok := ctx.Value(taskContextKey{}).(*taskContext)
+	if !ok {
+		return func() {}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || c.paused || !c.timer.Stop() {
+		return func() {}
+	}
+	c.paused = true
+	remaining := time.Until(c.deadline)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.paused = false
+		if c.err != nil {
+			return
+		}
+		c.deadline = time.Now().Add(remaining)
+		c.timer.Reset(remaining)
+	}
+}
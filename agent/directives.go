@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// directiveRegex находит директивы задачи вида !name=value или !name="value с пробелами".
+// Значение без кавычек продолжается до следующей директивы или конца строки.
+var directiveRegex = regexp.MustCompile(`(?m)(?:^|\s)!([a-zA-Z_]+)=("[^"]*"|[^!\n]*)`)
+
+// parseTaskDirectives отделяет директивы от текста задачи.
+// Несколько значений одной директивы разделяются символом "|".
+func parseTaskDirectives(task string) (string, map[string][]string) {
+	directives := make(map[string][]string)
+	clean := directiveRegex.ReplaceAllStringFunc(task, func(match string) string {
+		parts := directiveRegex.FindStringSubmatch(match)
+		name := strings.ToLower(parts[1])
+		value := strings.Trim(strings.TrimSpace(parts[2]), `"`)
+		for _, v := range strings.Split(value, "|") {
+			if v = strings.TrimSpace(v); v != "" {
+				directives[name] = append(directives[name], v)
+			}
+		}
+		return ""
+	})
+	return strings.TrimSpace(clean), directives
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/document"
+)
+
+// DocumentAnswer - ответ на вопрос по документу, прочитанному действием read_document
+type DocumentAnswer struct {
+	Source   string `json:"source"` // имя файла документа
+	URL      string `json:"url,omitempty"`
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// readDocument скачивает документ по ссылке (или читает уже скачанный файл),
+// извлекает текст и отвечает на вопрос отдельным запросом к модели.
+// Ответ попадает в историю и в результат задачи.
+func (a *Agent) readDocument(ctx context.Context, decision *ai.Decision) error {
+	var name, url, contentType string
+	var data []byte
+
+	switch {
+	case decision.Value != "":
+		path, err := a.documentPath(strings.TrimSpace(decision.Value))
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("файл документа недоступен: %s (%v)", path, err)
+		}
+		name, data = filepath.Base(path), content
+	case decision.URL != "" || decision.Text != "":
+		url = decision.URL
+		if url == "" {
+			link, err := a.browser.LinkURL(decision.Text)
+			if err != nil {
+				return err
+			}
+			url = link
+		}
+		fmt.Printf("📥 Скачивание документа: %s\n", url)
+		file, err := a.browser.Fetch(url)
+		if err != nil {
+			return err
+		}
+		name, url, contentType, data = file.Name, file.URL, file.ContentType, file.Data
+	default:
+		return fmt.Errorf("не указан документ. Используй 'text' (текст ссылки), 'url' или 'value' (путь к скачанному файлу)")
+	}
+
+	doc, err := document.Extract(name, contentType, data)
+	if err != nil {
+		return err
+	}
+
+	question := strings.TrimSpace(decision.Question)
+	if question == "" {
+		question = a.task
+	}
+	fmt.Printf("📄 Чтение документа %s (%s, %d символов)...\n", doc.Name, doc.Format, len([]rune(doc.Text)))
+	answer, err := a.aiClient.AnswerFromDocument(ctx, question, doc.Name, doc.Text)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Ответ по документу %s: %s\n", doc.Name, answer)
+	a.documents = append(a.documents, DocumentAnswer{
+		Source:   doc.Name,
+		URL:      url,
+		Question: question,
+		Answer:   answer,
+	})
+	a.history = append(a.history, fmt.Sprintf("Документ %s прочитан. Вопрос: %s. Ответ: %s", doc.Name, question, answer))
+	return nil
+}
+
+// documentPath проверяет путь к документу на диске. Читать можно только файлы, которые
+// сохранила сама задача (download, save_pdf), и файлы в каталогах загрузок и PDF: путь
+// предлагает модель, а ее может подтолкнуть текст страницы, - иначе .env, ключи SSH
+// или профиль браузера ушли бы в запрос к модели.
+func (a *Agent) documentPath(value string) (string, error) {
+	path, err := resolveLocalPath(value)
+	if err != nil {
+		return "", fmt.Errorf("файл документа недоступен: %s (%v)", value, err)
+	}
+	if a.isTaskFile(path) {
+		return path, nil
+	}
+	return "", fmt.Errorf("файл %s нельзя прочитать: read_document читает только файлы, скачанные или сохраненные этой задачей (download, save_pdf), или документы по ссылке ('text', 'url')", value)
+}
+
+// resolveLocalPath возвращает абсолютный путь без символических ссылок
+func resolveLocalPath(value string) (string, error) {
+	path, err := filepath.Abs(value)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// isTaskFile сообщает, относится ли путь (см. resolveLocalPath) к файлам задачи: сохранен
+// действием задачи или лежит в каталоге загрузок, PDF или одном из каталогов extraDirs
+func (a *Agent) isTaskFile(path string, extraDirs ...string) bool {
+	for _, saved := range a.savedFiles {
+		if resolved, err := resolveLocalPath(saved); err == nil && resolved == path {
+			return true
+		}
+	}
+	for _, dir := range append([]string{a.browser.DownloadDir(), a.pdfDir}, extraDirs...) {
+		if dir == "" {
+			continue
+		}
+		root, err := resolveLocalPath(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentsData возвращает прочитанные документы как extracted_data, если модель его не заполнила
+func (a *Agent) documentsData(extracted json.RawMessage) json.RawMessage {
+	if len(extracted) > 0 && string(extracted) != "null" || len(a.documents) == 0 {
+		return extracted
+	}
+	data, err := json.Marshal(map[string]interface{}{"documents": a.documents})
+	if err != nil {
+		return extracted
+	}
+	return data
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+func TestDocumentPath(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel string) string {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("text"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	downloaded := write("downloads/statement.pdf")
+	printed := write("pdf/receipt.pdf")
+	saved := write("elsewhere/saved.txt")
+	secret := write(".env")
+	if err := os.Symlink(secret, filepath.Join(root, "downloads", "link.txt")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	b := &browser.Browser{}
+	browser.WithDownloadDir(filepath.Join(root, "downloads"))(b)
+	a := &Agent{browser: b, pdfDir: filepath.Join(root, "pdf"), savedFiles: []string{saved}}
+
+	for _, path := range []string{downloaded, printed, saved} {
+		if _, err := a.documentPath(path); err != nil {
+			t.Errorf("documentPath(%s) = %v, want allowed", path, err)
+		}
+	}
+	for _, path := range []string{
+		secret,
+		filepath.Join(root, "downloads", "..", ".env"),
+		filepath.Join(root, "downloads", "link.txt"),
+		filepath.Join(root, "downloads"),
+	} {
+		if _, err := a.documentPath(path); err == nil || !strings.Contains(err.Error(), "нельзя прочитать") {
+			t.Errorf("documentPath(%s) = %v, want refused", path, err)
+		}
+	}
+	if _, err := a.documentPath(filepath.Join(root, "missing.pdf")); err == nil {
+		t.Error("missing file must be an error")
+	}
+
+	// Без каталогов доступны только сохраненные задачей файлы, а не текущий каталог
+	a = &Agent{browser: &browser.Browser{}}
+	if _, err := a.documentPath(downloaded); err == nil {
+		t.Error("file outside the task's files must be refused")
+	}
+}
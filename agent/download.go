@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// downloadTimeout - сколько ждать, пока скачается файл после клика
+const downloadTimeout = 60 * time.Second
+
+// download кликает по ссылке или кнопке "Скачать" и ждет, пока файл сохранится в
+// каталоге загрузок (DOWNLOADS_DIR). Клик проходит правила клика safe-mode (действие
+// помечено в реестре как кликающее). Без цели клика ждет загрузку, которую начал
+// предыдущий клик; загрузки, начатые раньше, не выдаются. Путь к файлу попадает в
+// историю, результат задачи и резюме.
+func (a *Agent) download(ctx context.Context, decision *ai.Decision) error {
+	if a.browser.DownloadDir() == "" {
+		return fmt.Errorf("каталог загрузок не задан (DOWNLOADS_DIR) - скачать файл с известным путем нельзя")
+	}
+	if decision.Text != "" || decision.Selector != "" {
+		if err := a.click(ctx, decision); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("⬇️  Ожидание загрузки файла...\n")
+	path, err := a.browser.WaitForDownload(a.downloadMark, downloadTimeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("💾 Файл скачан: %s\n", path)
+	a.savedFiles = append(a.savedFiles, path)
+	a.history = append(a.history, fmt.Sprintf("Файл скачан: %s. НЕ скачивай его повторно", path))
+	return nil
+}
+
+// summaryWithFiles дополняет резюме путями к файлам, сохраненным задачей
+// (download, save_pdf): пользователь сразу видит, где их искать
+func (a *Agent) summaryWithFiles(summary string) string {
+	if len(a.savedFiles) == 0 {
+		return summary
+	}
+	files := "Сохраненные файлы:\n- " + strings.Join(a.savedFiles, "\n- ")
+	if summary == "" {
+		return files
+	}
+	return summary + "\n" + files
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// finalPageTextRunes - сколько текста итоговой страницы сохраняется в результате
+const finalPageTextRunes = 4000
+
+// FinalPage - содержимое страницы в момент завершения задачи
+type FinalPage struct {
+	URL      string              `json:"url"`
+	Title    string              `json:"title"`
+	Route    string              `json:"route,omitempty"`
+	Text     string              `json:"text,omitempty"`
+	Headings []browser.Heading   `json:"headings,omitempty"`
+	Lists    [][]string          `json:"lists,omitempty"`
+	Tables   [][][]string        `json:"tables,omitempty"`
+	Dates    []browser.PageDate  `json:"dates,omitempty"`
+	Prices   []browser.PagePrice `json:"prices,omitempty"`
+}
+
+// SetCaptureFinalPage включает сохранение содержимого итоговой страницы в
+// TaskResult.FinalPage: вызывающему коду не нужен отдельный запрос к браузеру после
+// Execute, а данные соответствуют именно моменту завершения задачи
+func (a *Agent) SetCaptureFinalPage(enabled bool) {
+	a.captureFinalPage = enabled
+}
+
+// captureFinal извлекает итоговую страницу при завершении задачи; ошибка извлечения
+// не мешает завершению
+func (a *Agent) captureFinal() {
+	if !a.captureFinalPage {
+		return
+	}
+	content, err := a.browser.GetPageContent()
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить итоговую страницу: %v\n", err)
+		return
+	}
+	a.finalPage = &FinalPage{
+		URL:      content.URL,
+		Title:    content.Title,
+		Route:    content.Route,
+		Text:     truncateRunes(content.Text, finalPageTextRunes),
+		Headings: content.Headings,
+		Lists:    content.Lists,
+		Tables:   content.Tables,
+		Dates:    content.Dates,
+		Prices:   content.Prices,
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// handOff - контракт передачи задачи под-агенту. Под-агент работает на состоянии
+// родительского агента: история, переменные, счетчики итераций и ошибок, checkpoint
+// и журнал общие, поэтому история непрерывна, а итог задачи и журнал единые.
+// Дополнительно передается последнее наблюдение - открытая страница, чтобы под-агент
+// продолжал с нее, а не начинал с перехода на стартовую страницу. Передача происходит
+// после первого шага основного агента (см. executeTask): до него передавать нечего.
+type handOff struct {
+	to                  SubAgentType
+	url                 string
+	title               string
+	historyLen          int // записей истории на момент передачи
+	remainingIterations int
+	remainingErrors     int
+}
+
+// prepareHandOff собирает состояние родительского агента для передачи под-агенту
+func (a *Agent) prepareHandOff(to SubAgentType) handOff {
+	h := handOff{
+		to:                  to,
+		historyLen:          len(a.history),
+		remainingIterations: a.maxIterations - a.iteration,
+		remainingErrors:     a.maxErrors - a.errorCount,
+	}
+	if info, err := a.browser.GetQuickPageInfo(); err == nil {
+		h.url, h.title = info.URL, info.Title
+	} else if url, err := a.browser.GetCurrentURL(); err == nil {
+		h.url = url
+	}
+	return h
+}
+
+// budgetError возвращает ошибку, если родительский агент исчерпал бюджет итераций или
+// ошибок: под-агент работает на тех же счетчиках, и передача с пустым бюджетом только
+// скрыла бы, что задача уже остановлена
+func (h handOff) budgetError() error {
+	switch {
+	case h.remainingIterations <= 0:
+		return fmt.Errorf("задача не передана агенту %s: бюджет итераций исчерпан", h.to)
+	case h.remainingErrors <= 0:
+		return fmt.Errorf("задача не передана агенту %s: бюджет ошибок исчерпан", h.to)
+	}
+	return nil
+}
+
+// acceptHandOff проверяет бюджеты передачи и фиксирует ее в истории и журнале задачи.
+// Передача с исчерпанным бюджетом отклоняется ошибкой, которой завершается задача.
+func (a *Agent) acceptHandOff(h handOff) error {
+	if err := h.budgetError(); err != nil {
+		a.writeTranscript(transcriptEntry{Type: "event", Iteration: a.iteration, Action: "handoff", URL: h.url, Status: "error", Error: err.Error()})
+		return err
+	}
+	fmt.Printf("🎯 Агент %s продолжает задачу (итераций осталось: %d, история: %d записей)\n", h.to, h.remainingIterations, h.historyLen)
+	a.writeTranscript(transcriptEntry{Type: "event", Iteration: a.iteration, Action: "handoff", URL: h.url, Status: string(h.to)})
+
+	if h.url == "" || strings.HasPrefix(h.url, "about:") || strings.HasPrefix(h.url, "chrome://") {
+		return nil
+	}
+	page := h.url
+	if h.title != "" {
+		page += fmt.Sprintf(" (%s)", h.title)
+	}
+	a.history = append(a.history, fmt.Sprintf("задачу принял агент %s. Уже открыта страница %s - продолжай с нее; переходи на другой сайт, только если открытая страница не подходит для задачи", h.to, page))
+	return nil
+}
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/ai/aitest"
+)
+
+func TestAcceptHandOffBudgets(t *testing.T) {
+	tests := []struct {
+		name    string
+		h       handOff
+		wantErr string
+	}{
+		{"budget left", handOff{to: SubAgentShopping, remainingIterations: 10, remainingErrors: 5}, ""},
+		{"iterations spent", handOff{to: SubAgentShopping, remainingIterations: 0, remainingErrors: 5}, "бюджет итераций"},
+		{"errors spent", handOff{to: SubAgentShopping, remainingIterations: 10, remainingErrors: 0}, "бюджет ошибок"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{}
+			err := a.acceptHandOff(tt.h)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("acceptHandOff() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("acceptHandOff() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAcceptHandOffNotesOpenPage(t *testing.T) {
+	a := &Agent{}
+	h := handOff{to: SubAgentEmail, url: "https://e.mail.ru/inbox", title: "Входящие", remainingIterations: 3, remainingErrors: 1}
+	if err := a.acceptHandOff(h); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.history) != 1 || !strings.Contains(a.history[0], "https://e.mail.ru/inbox (Входящие)") {
+		t.Errorf("history = %q, want the open page", a.history)
+	}
+
+	a = &Agent{}
+	h.url = "about:blank"
+	if err := a.acceptHandOff(h); err != nil || len(a.history) != 0 {
+		t.Errorf("blank page handoff: %v, history %q", err, a.history)
+	}
+}
+
+// inboxPage - открытый почтовый ящик: под-агенту почты не нужно никуда переходить
+const inboxPage = `<title>Входящие</title>
+<p id="status">входящие</p>
+<button onclick="document.getElementById('status').textContent = 'письмо открыто'">Письмо от банка</button>`
+
+func TestHandOffContinuesOpenPage(t *testing.T) {
+	b := newFixtureBrowser(t, inboxPage)
+	start, err := b.GetCurrentURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := aitest.NewScriptedProvider(
+		ai.Decision{Action: "click", Text: "Письмо от банка", Reasoning: "открываю письмо банка"},
+		ai.Decision{Action: "complete", IsComplete: true, Summary: "письмо прочитано"},
+	)
+	a := NewAgent(b, ai.NewClientWithProvider(model, "gpt-4o"))
+	a.SetInteractive(false)
+	a.SetNavigateHostDelay(0)
+	a.SetTranscriptDir(t.TempDir())
+	a.settleMax = 0
+
+	if err := a.Execute(context.Background(), "прочитай письмо от банка в почте"); err != nil {
+		t.Fatalf("Execute: %v (prompts: %q)", err, model.Prompts())
+	}
+	if err := model.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if a.subAgentType != SubAgentEmail {
+		t.Fatalf("sub-agent = %s, want %s", a.subAgentType, SubAgentEmail)
+	}
+
+	// Под-агент продолжил на открытой странице, без перехода
+	if url, _ := b.GetCurrentURL(); url != start {
+		t.Errorf("URL after the task = %s, want the open page %s", url, start)
+	}
+	if status, _ := b.GetText("#status"); strings.TrimSpace(status) != "письмо открыто" {
+		t.Errorf("status = %q, want the click of the first step", status)
+	}
+
+	// Решение под-агента видит историю основного агента и передачу
+	prompts := model.Prompts()
+	if len(prompts) != 2 {
+		t.Fatalf("model called %d times, want 2", len(prompts))
+	}
+	if strings.Contains(prompts[0], "задачу принял агент") {
+		t.Error("the first step must be made before the hand-off")
+	}
+	for _, want := range []string{"click: открываю письмо банка", "задачу принял агент email"} {
+		if !strings.Contains(prompts[1], want) {
+			t.Errorf("sub-agent prompt lacks %q", want)
+		}
+	}
+
+	// Журнал задачи единый: шаг основного агента, передача, шаг под-агента
+	entries := readTranscript(t, a.transcriptPath)
+	var actions []string
+	for _, entry := range entries {
+		if entry.Action == "navigate" {
+			t.Errorf("transcript has a navigation: %+v", entry)
+		}
+		if entry.Type == "action" || entry.Action == "handoff" {
+			actions = append(actions, fmt.Sprintf("%d:%s", entry.Iteration, entry.Action))
+		}
+	}
+	if got, want := strings.Join(actions, " "), "1:click 1:handoff 2:complete"; got != want {
+		t.Errorf("transcript actions = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"fmt"
+)
+
+// IdleWarningMode - что делать с предупреждением о завершении сессии из-за бездействия
+type IdleWarningMode string
+
+const (
+	IdleWarningDismiss IdleWarningMode = "dismiss" // нажать "Продолжить сессию" автоматически
+	IdleWarningReport  IdleWarningMode = "report"  // только сообщить модели
+	IdleWarningOff     IdleWarningMode = "off"     // не проверять
+)
+
+// ParseIdleWarningMode разбирает значение IDLE_WARNING
+func ParseIdleWarningMode(value string) (IdleWarningMode, error) {
+	switch mode := IdleWarningMode(value); mode {
+	case IdleWarningDismiss, IdleWarningReport, IdleWarningOff:
+		return mode, nil
+	case "":
+		return IdleWarningDismiss, nil
+	}
+	return "", fmt.Errorf("ожидается dismiss, report или off")
+}
+
+// SetIdleWarningMode задает обработку предупреждений о бездействии (по умолчанию - dismiss)
+func (a *Agent) SetIdleWarningMode(mode IdleWarningMode) {
+	a.idleWarningMode = mode
+}
+
+// handleIdleWarning проверяет перед анализом страницы, не предупреждает ли сайт о выходе
+// из-за бездействия: пока модель думает, пользователь для сайта бездействует, и на долгих
+// задачах банк или панель управления успевают завершить сессию посреди задачи.
+// Кнопка продления сессии нажимается без модели, но по тем же правилам, что и клик
+// модели: кнопку, которую safe-mode или проверка деструктивных действий не пропустили бы
+// без вопроса, агент сам не нажимает. Если закрыть окно не удалось или автоматическое
+// закрытие выключено, модель получает предупреждение в истории.
+func (a *Agent) handleIdleWarning() {
+	if a.idleWarningMode == IdleWarningOff {
+		return
+	}
+	warning, err := a.browser.DetectIdleWarning()
+	if err != nil || !warning.Found {
+		return
+	}
+	if a.idleWarningMode == IdleWarningDismiss && warning.Button != "" {
+		if reason := a.autoClickBlocked(warning.Button); reason != "" {
+			fmt.Printf("⏰ Кнопка «%s» в предупреждении о бездействии не нажата автоматически: %s\n", warning.Button, reason)
+		} else {
+			entry := transcriptEntry{Type: "event", Iteration: a.iteration, Action: "idle_dismiss", Text: warning.Button, Status: "ok"}
+			err := a.browser.DismissIdleWarning(warning)
+			if err == nil {
+				fmt.Printf("⏰ Предупреждение о бездействии закрыто: «%s»\n", warning.Button)
+				a.writeTranscript(entry)
+				a.history = append(a.history, fmt.Sprintf("сайт предупредил о завершении сессии из-за бездействия («%s») - нажата кнопка «%s», сессия продлена", truncateRunes(warning.Text, 100), warning.Button))
+				a.idleWarningNoted = ""
+				return
+			}
+			fmt.Printf("⚠️  Не удалось закрыть предупреждение о бездействии: %v\n", err)
+			entry.Status = "error"
+			entry.Error = err.Error()
+			a.writeTranscript(entry)
+			if warning, err = a.browser.DetectIdleWarning(); err != nil || !warning.Found {
+				return
+			}
+		}
+	}
+
+	// Модель узнает о предупреждении один раз, а не на каждом шаге
+	if warning.Text == a.idleWarningNoted {
+		return
+	}
+	a.idleWarningNoted = warning.Text
+	note := fmt.Sprintf("на странице предупреждение о завершении сессии из-за бездействия: «%s»", truncateRunes(warning.Text, 150))
+	if warning.Button != "" {
+		note += fmt.Sprintf(" - нажми «%s», чтобы остаться в системе", warning.Button)
+	}
+	a.history = append(a.history, note)
+}
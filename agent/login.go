@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"fmt"
+	neturl "net/url"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// SetLoginIndicator задает CSS селектор признака авторизованного пользователя
+// (аватар, меню аккаунта). Без него состояние входа определяется эвристически.
+func (a *Agent) SetLoginIndicator(selector string) {
+	a.loginIndicator = selector
+}
+
+// LoginState возвращает состояние входа на текущем сайте: сначала по признаку
+// из SetLoginIndicator (или директивы !login_indicator=), затем эвристически
+func (a *Agent) LoginState() (*browser.LoginState, error) {
+	if a.loginIndicator != "" {
+		found, err := a.browser.IsLoggedIn(a.loginIndicator)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return &browser.LoginState{State: browser.LoginStateLoggedIn, Evidence: "признак " + a.loginIndicator}, nil
+		}
+	}
+	return a.browser.DetectLoginState()
+}
+
+// noteLoginState один раз для каждого сайта задачи сообщает модели, выполнен ли вход,
+// чтобы она не проходила вход повторно на уже авторизованном сайте
+func (a *Agent) noteLoginState() {
+	currentURL, err := a.browser.GetCurrentURL()
+	if err != nil {
+		return
+	}
+	parsed, err := neturl.Parse(currentURL)
+	if err != nil || parsed.Host == "" || a.loginChecked[parsed.Host] {
+		return
+	}
+
+	state, err := a.LoginState()
+	if err != nil || state.State == browser.LoginStateUnknown {
+		return
+	}
+	a.loginChecked[parsed.Host] = true
+
+	if state.LoggedIn() {
+		fmt.Printf("🔓 Вход на %s уже выполнен (%s)\n", parsed.Host, state.Evidence)
+		a.history = append(a.history, fmt.Sprintf("На сайте %s вход уже выполнен (%s) - НЕ выполняй вход повторно", parsed.Host, state.Evidence))
+		return
+	}
+	fmt.Printf("🔒 Вход на %s не выполнен (%s)\n", parsed.Host, state.Evidence)
+	a.history = append(a.history, fmt.Sprintf("На сайте %s вход не выполнен (%s)", parsed.Host, state.Evidence))
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// SiteLogin - состояние входа на сайт, проверенное в фоновой вкладке
+type SiteLogin struct {
+	Domain    string
+	State     browser.LoginState
+	CheckedAt time.Time
+	Err       error
+	Cached    bool // результат взят из проверки ранее в этой сессии
+}
+
+// ParseLoginDomains разбирает список сайтов для проверки входа: "mail.yandex.ru, hh.ru"
+func ParseLoginDomains(value string) []string {
+	var domains []string
+	for _, domain := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+		domains = append(domains, domain)
+	}
+	return domains
+}
+
+// CheckLogins проверяет, выполнен ли вход в текущем профиле на каждом из сайтов.
+// Сайт открывается в фоновой вкладке, поэтому страница, за которой следит
+// пользователь, не меняется. Результаты кешируются на сессию (отдельно для каждого
+// профиля); refresh проверяет сайты заново.
+func (a *Agent) CheckLogins(domains []string, refresh bool) []SiteLogin {
+	if a.loginCache == nil {
+		a.loginCache = make(map[string]SiteLogin)
+	}
+	results := make([]SiteLogin, 0, len(domains))
+	for _, domain := range domains {
+		key := a.browser.Profile() + "/" + a.browser.ProfileDirectory() + "|" + domain
+		if cached, ok := a.loginCache[key]; ok && !refresh {
+			cached.Cached = true
+			results = append(results, cached)
+			continue
+		}
+
+		url := domain
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			url = "https://" + url
+		}
+		result := SiteLogin{Domain: domain, CheckedAt: time.Now()}
+		state, err := a.browser.CheckLoginInBackground(url, a.loginIndicator)
+		if err != nil {
+			result.Err = err
+			result.State = browser.LoginState{State: browser.LoginStateUnknown}
+		} else {
+			result.State = *state
+			a.loginCache[key] = result
+		}
+		results = append(results, result)
+	}
+	return results
+}
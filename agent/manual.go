@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// SetAllowHandoff разрешает модели передавать шаг пользователю действием handoff
+// (ALLOW_HANDOFF). Для запусков без пользователя (сервер, cron) его нужно выключить:
+// агент ждал бы нажатия Enter, которого не будет.
+func (a *Agent) SetAllowHandoff(enabled bool) {
+	a.allowHandoff = enabled
+	a.aiClient.SetHandoffEnabled(a.handoffAvailable())
+}
+
+// handoffAvailable - передача шага пользователю разрешена и есть кому его выполнить
+func (a *Agent) handoffAvailable() bool {
+	return a.allowHandoff && a.interactive
+}
+
+// handOffToUser передает шаг пользователю: печатает инструкцию модели и ждет Enter
+// (или done). Таймаут задачи на это время заморожен. После возврата страница
+// анализируется заново, а в историю попадает выполненный вручную шаг.
+func (a *Agent) handOffToUser(ctx context.Context, decision *ai.Decision) error {
+	instruction := strings.TrimSpace(decision.Instruction)
+	if instruction == "" {
+		instruction = strings.TrimSpace(decision.Value)
+	}
+	if instruction == "" {
+		instruction = decision.Reasoning
+	}
+	if !a.handoffAvailable() {
+		a.history = append(a.history, fmt.Sprintf("handoff НЕДОСТУПЕН (запуск без пользователя): шаг '%s' никто не выполнит - сделай его доступными действиями или заверши задачу с объяснением", instruction))
+		return fmt.Errorf("передача управления пользователю отключена")
+	}
+
+	resume := pauseTaskDeadline(ctx)
+	defer resume()
+
+	fmt.Printf("\n%s\n", strings.Repeat("=", 60))
+	fmt.Printf("🙋 ПЕРЕДАЮ УПРАВЛЕНИЕ ПОЛЬЗОВАТЕЛЮ НА ЭТОМ ШАГЕ\n\n   %s\n\n", instruction)
+	fmt.Println("   Выполните шаг в окне браузера. Время задачи на паузе.")
+	fmt.Printf("%s\n", strings.Repeat("=", 60))
+
+	start := time.Now()
+	answer, err := a.askUser("   Нажмите Enter (или введите done), когда закончите; комментарий для агента - любой текст: ")
+	if err != nil {
+		a.history = append(a.history, fmt.Sprintf("ПОЛЬЗОВАТЕЛЬ НЕ ОТВЕТИЛ на передачу шага '%s' - продолжи без него или заверши задачу с объяснением", instruction))
+		return fmt.Errorf("не удалось дождаться пользователя: %w", err)
+	}
+	fmt.Printf("▶️  Продолжаю после ручного шага (%s)\n", time.Since(start).Round(time.Second))
+
+	entry := fmt.Sprintf("пользователь выполнил шаг вручную: %s", instruction)
+	switch strings.ToLower(answer) {
+	case "", "done", "готово":
+	default:
+		entry += fmt.Sprintf(" (комментарий пользователя: %s)", answer)
+	}
+	a.history = append(a.history, entry+". Страница могла измениться - продолжай по ее текущему содержимому")
+	a.forceFullExtraction = true
+	return nil
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// sameURLLoopThreshold - сколько переходов подряд на текущую страницу считается зацикливанием
+const sameURLLoopThreshold = 2
+
+// skipSameURLNavigation сообщает, что navigate ведет на уже открытую страницу и
+// перезагрузку нужно пропустить: она заново ждет загрузку и извлечение, но ничего
+// не меняет. Модель узнает об этом из истории, а повтор подряд отмечается как зацикливание.
+func (a *Agent) skipSameURLNavigation(target string, forceReload bool) bool {
+	current, err := a.browser.GetCurrentURL()
+	if err != nil || !sameURL(current, target) {
+		a.sameURLNavigations = 0
+		return false
+	}
+	if forceReload {
+		a.sameURLNavigations = 0
+		fmt.Printf("🔄 Перезагрузка текущей страницы (force_reload): %s\n", target)
+		return false
+	}
+
+	a.sameURLNavigations++
+	fmt.Printf("📍 Уже на этой странице, переход пропущен: %s\n", current)
+	a.history = append(a.history, fmt.Sprintf("уже на этой странице (%s) - переход пропущен, страница не изменилась. Для перезагрузки укажи \"force_reload\": true", current))
+	if a.sameURLNavigations >= sameURLLoopThreshold {
+		fmt.Printf("⚠️  Обнаружено зацикливание: переход на текущую страницу %d раз подряд\n", a.sameURLNavigations)
+		a.history = append(a.history, fmt.Sprintf("ОБНАРУЖЕНО зацикливание: navigate на текущую страницу %d раз подряд. Нужные данные уже на странице - выбери другое действие (click, scroll_to_load, extract) или заверши задачу", a.sameURLNavigations))
+	}
+	return true
+}
+
+// sameURL сравнивает адреса без учета схемы http/https, www., регистра хоста и
+// завершающего "/"; query и fragment (маршрут SPA вида #/inbox) должны совпадать
+func sameURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil || ua.Host == "" || ub.Host == "" {
+		return false
+	}
+	host := func(u *url.URL) string {
+		return strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	}
+	path := func(u *url.URL) string {
+		return strings.TrimSuffix(u.EscapedPath(), "/")
+	}
+	return host(ua) == host(ub) && path(ua) == path(ub) && ua.RawQuery == ub.RawQuery && ua.Fragment == ub.Fragment
+}
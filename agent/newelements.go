@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// elementSnapshot - ссылки и кнопки предыдущего анализа страницы
+type elementSnapshot struct {
+	url  string
+	full bool // полный анализ (GetPageContent) показывает больше элементов, чем быстрый
+	keys map[string]bool
+}
+
+func linkKey(link browser.Link) string {
+	return "a\x00" + link.Text + "\x00" + link.Href
+}
+
+func buttonKey(btn browser.Button) string {
+	return "b\x00" + btn.Text + "\x00" + btn.AriaLabel + "\x00" + btn.Title + "\x00" + btn.ID
+}
+
+// markNewElements отмечает ссылки и кнопки, которых не было при предыдущем анализе той же
+// страницы: открывшееся меню, появившееся окно. Так модель видит последствие своего
+// клика и выбирает среди новых элементов, а не просматривает страницу заново.
+// Новые элементы переносятся в начало списков: быстрый анализ показывает модели
+// только первые ссылки. После перехода на другую страницу ничего не отмечается.
+func (a *Agent) markNewElements(url string, full bool, links []browser.Link, buttons []browser.Button) {
+	url, _, _ = strings.Cut(url, "#")
+	keys := make(map[string]bool, len(links)+len(buttons))
+	for _, link := range links {
+		keys[linkKey(link)] = true
+	}
+	for _, btn := range buttons {
+		keys[buttonKey(btn)] = true
+	}
+
+	prev := a.elements
+	a.elements = &elementSnapshot{url: url, full: full, keys: keys}
+	// Быстрый анализ после полного не отмечает лишнего, а полный после быстрого -
+	// отметил бы все элементы, которые быстрый просто не показывал
+	if prev == nil || prev.url != url || (full && !prev.full) {
+		return
+	}
+
+	newLinks := 0
+	for i := range links {
+		links[i].New = !prev.keys[linkKey(links[i])]
+		if links[i].New {
+			newLinks++
+		}
+	}
+	newButtons := 0
+	for i := range buttons {
+		buttons[i].New = !prev.keys[buttonKey(buttons[i])]
+		if buttons[i].New {
+			newButtons++
+		}
+	}
+	if newLinks > 0 {
+		moveNewFirst(links, func(i int) bool { return links[i].New })
+	}
+	if newButtons > 0 {
+		moveNewFirst(buttons, func(i int) bool { return buttons[i].New })
+	}
+}
+
+// moveNewFirst переставляет новые элементы в начало, сохраняя порядок внутри групп
+func moveNewFirst[T any](items []T, isNew func(i int) bool) {
+	var fresh, old []T
+	for i := range items {
+		if isNew(i) {
+			fresh = append(fresh, items[i])
+		} else {
+			old = append(old, items[i])
+		}
+	}
+	copy(items, append(fresh, old...))
+}
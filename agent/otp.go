@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// maxOTPAttempts - сколько раз подряд агент спрашивает код на одной странице
+const maxOTPAttempts = 3
+
+// otpInputPrompt - вопрос пользователю, когда страница ждет код из SMS или приложения
+const otpInputPrompt = "Введите код, пришедший на ваш телефон"
+
+// SetInteractive сообщает агенту, можно ли задавать вопросы пользователю в консоли
+func (a *Agent) SetInteractive(enabled bool) {
+	a.interactive = enabled
+	a.aiClient.SetHandoffEnabled(a.handoffAvailable())
+}
+
+// handleOTP проверяет, не запрашивает ли страница одноразовый код (OTP/2FA), и
+// запрашивает его у пользователя через needs_input, не дожидаясь, пока модель
+// попробует угадать код. Модель код не видит: в историю попадает только факт ввода.
+func (a *Agent) handleOTP() {
+	field, err := a.browser.DetectOTP()
+	if err != nil || !field.Found || field.Filled {
+		return
+	}
+
+	url, _ := a.browser.GetCurrentURL()
+	if a.otpAttempts[url] >= maxOTPAttempts {
+		return
+	}
+	a.otpAttempts[url]++
+
+	if !a.interactive {
+		if a.otpAttempts[url] == 1 {
+			a.history = append(a.history, "НА СТРАНИЦЕ ЗАПРОШЕН код подтверждения (OTP/2FA), но интерактивный ввод недоступен - НЕ вводи код сам, запроси ввод у пользователя (needs_input)")
+		}
+		return
+	}
+
+	prompt := otpInputPrompt
+	if field.Hint != "" {
+		prompt += fmt.Sprintf(" (%s)", field.Hint)
+	}
+	decision := &ai.Decision{NeedsInput: true, InputPrompt: prompt}
+	if err := a.handleNeedsInput(decision); err != nil {
+		a.otpAttempts[url] = maxOTPAttempts
+	}
+}
+
+// handleNeedsInput спрашивает пользователя в ответ на needs_input и продолжает задачу.
+// Если на странице есть поле кода подтверждения, ответ вводится прямо в него.
+func (a *Agent) handleNeedsInput(decision *ai.Decision) error {
+	fmt.Printf("\n❓ Требуется ввод от пользователя: %s\n", decision.InputPrompt)
+	if !a.interactive {
+		return fmt.Errorf("needs user input")
+	}
+
+	var field *browser.OTPField
+	if detected, err := a.browser.DetectOTP(); err == nil && detected.Found && !detected.Filled {
+		field = detected
+	}
+
+	prompt := "   Ответ (пустая строка - пропустить): "
+	if field != nil {
+		fmt.Printf("🔐 Найдено поле кода подтверждения")
+		if field.Boxes > 1 {
+			fmt.Printf(" (%d ячеек)", field.Boxes)
+		}
+		fmt.Println()
+		length := field.Length
+		if field.Boxes > 1 {
+			length = field.Boxes
+		}
+		if length > 0 {
+			prompt = fmt.Sprintf("   Код из %d символов (пустая строка - пропустить): ", length)
+		}
+	}
+
+	answer, err := a.askUser(prompt)
+	if err != nil || answer == "" {
+		fmt.Println("ℹ️  Ввод пропущен")
+		a.history = append(a.history, fmt.Sprintf("ПОЛЬЗОВАТЕЛЬ НЕ ОТВЕТИЛ на запрос '%s' - продолжи без этих данных или заверши задачу с объяснением", decision.InputPrompt))
+		return fmt.Errorf("user input skipped")
+	}
+
+	if field != nil || secretFieldRegex.MatchString(decision.InputPrompt) {
+		a.redactSecret(answer)
+	}
+	if field == nil {
+		a.history = append(a.history, fmt.Sprintf("ОТВЕТ ПОЛЬЗОВАТЕЛЯ на '%s': %s", decision.InputPrompt, answer))
+		return nil
+	}
+
+	if err := a.browser.FillOTP(field, answer); err != nil {
+		fmt.Printf("❌ Не удалось ввести код: %v\n", err)
+		a.history = append(a.history, fmt.Sprintf("ОШИБКА ввода кода подтверждения: %v", err))
+		return nil
+	}
+
+	fmt.Printf("✅ Код введен (%d символов)\n", utf8.RuneCountInString(answer))
+	a.history = append(a.history, fmt.Sprintf("КОД ПОДТВЕРЖДЕНИЯ (OTP/2FA) введен пользователем в %d пол(е/я) - нажми кнопку подтверждения, если страница не отправила код автоматически", len(field.Selectors)))
+	return nil
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// pageChangeTimeout - сколько ждать, пока клик или ввод изменит страницу. Изменение
+// обычно видно за доли секунды, ожидание до конца - только у действий впустую.
+const pageChangeTimeout = 1500 * time.Millisecond
+
+// changeCheckedActions - действия, после которых модель узнает, изменилась ли страница
+var changeCheckedActions = map[string]bool{
+	"click": true,
+	"fill":  true,
+}
+
+// executeWithChangeCheck выполняет клик или ввод и проверяет, изменил ли он страницу:
+// сменился адрес или DOM. Ответ попадает в запись действия в истории, чтобы модель
+// видела, что ее последнее действие ничего не сделало, и не повторяла его.
+func (a *Agent) executeWithChangeCheck(ctx context.Context, decision *ai.Decision, handler actionHandler) error {
+	a.changeNote = ""
+	marked := a.browser.MarkPageState() == nil
+	if err := handler(a, ctx, decision); err != nil {
+		return err
+	}
+	if !marked {
+		return nil
+	}
+	err := a.browser.WaitForNavigationOrMutation(pageChangeTimeout)
+	switch {
+	case err == nil:
+		a.changeNote = "страница изменилась: да"
+	case errors.Is(err, browser.ErrPageUnchanged):
+		fmt.Printf("   ⚠️  Страница после действия не изменилась\n")
+		a.changeNote = "страница изменилась: нет - действие, похоже, ничего не сделало"
+	}
+	return nil
+}
+
+// actionEntry - запись выполненного действия в истории: действие, обоснование и,
+// после клика или ввода, изменилась ли страница
+func (a *Agent) actionEntry(decision *ai.Decision) string {
+	entry := fmt.Sprintf("%s: %s", decision.Action, decision.Reasoning)
+	if a.changeNote != "" {
+		entry += " (" + a.changeNote + ")"
+		a.changeNote = ""
+	}
+	return entry
+}
@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// PageSource - откуда агент читает страницу для решения модели: живой браузер
+// (*browser.Browser) или записанные страницы пакета (*bundle.Snapshots). Источник
+// отвечает только за анализ страницы перед решением - по одному чтению на шаг, как
+// у Snapshots; действия, проверки и итоговую страницу по-прежнему дает браузер агента.
+type PageSource interface {
+	GetQuickPageInfo() (*browser.QuickPageInfo, error)
+	GetPageContent() (*browser.PageContent, error)
+	GetCurrentURL() (string, error)
+}
+
+// SetPageSource задает источник страниц для анализа (nil - браузер агента): с
+// bundle.Snapshots агент получает на каждом шаге страницу, которую видела модель
+// записанного запуска
+func (a *Agent) SetPageSource(source PageSource) {
+	a.pages = source
+}
+
+// pageSource возвращает заданный источник страниц или браузер агента
+func (a *Agent) pageSource() PageSource {
+	if a.pages != nil {
+		return a.pages
+	}
+	return a.browser
+}
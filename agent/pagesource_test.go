@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+	"github.com/Angabebr/Golang-AI-agent/bundle"
+)
+
+var (
+	_ PageSource = (*browser.Browser)(nil)
+	_ PageSource = (*bundle.Snapshots)(nil)
+)
+
+func TestSnapshotsAsPageSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"manifest.json": `{"task": "найти чайник", "model": "gpt-4o"}`,
+		"iterations.jsonl": `{"iteration": 1, "kind": "quick", "url": "https://shop.example/", "page": {"url": "https://shop.example/", "title": "Магазин"}}
+{"iteration": 2, "kind": "full", "url": "https://shop.example/search", "page": {"url": "https://shop.example/search", "title": "Поиск"}}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := bundle.OpenSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Agent{}
+	a.SetPageSource(snapshots)
+	quick, err := a.quickPageInfo()
+	if err != nil || quick.Title != "Магазин" {
+		t.Fatalf("step 1 quickPageInfo() = %+v, %v", quick, err)
+	}
+	// Шаг записан с полным анализом: быстрый путь отказывает, как у браузера, и шаг не расходуется
+	if _, err := a.quickPageInfo(); err == nil {
+		t.Fatal("step 2 quickPageInfo() succeeded on a full step")
+	}
+	full, err := a.pageSource().GetPageContent()
+	if err != nil || full.Title != "Поиск" {
+		t.Fatalf("step 2 GetPageContent() = %+v, %v", full, err)
+	}
+	if url, _ := a.pageSource().GetCurrentURL(); url != "https://shop.example/search" {
+		t.Errorf("GetCurrentURL() = %q", url)
+	}
+}
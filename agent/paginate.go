@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// Пределы paginate_scrape по умолчанию
+const (
+	defaultPaginatePages = 10
+	maxPaginatePages     = 50
+	maxPaginateItems     = 500
+	paginatePreviewRows  = 5
+)
+
+// paginateScrape собирает список со всех страниц результатов одним действием, без
+// запросов к модели между страницами. Строки с номером страницы попадают в историю
+// (начало списка) и в extracted_data задачи, если модель не передаст свои данные.
+func (a *Agent) paginateScrape(decision *ai.Decision) error {
+	fields, err := parsePaginateFields(decision.Values)
+	if err != nil {
+		return err
+	}
+	pages := defaultPaginatePages
+	if n, err := strconv.Atoi(strings.TrimSpace(decision.Value)); err == nil && n > 0 {
+		pages = min(n, maxPaginatePages)
+	}
+	// Подсказку кнопки агент нажимает на каждой странице сам - она проходит проверки клика
+	if hint := strings.TrimSpace(decision.Text); hint != "" {
+		if reason := a.autoClickBlocked(hint); reason != "" {
+			return fmt.Errorf("кнопка следующей страницы %q не нажимается автоматически: %s", hint, reason)
+		}
+	}
+	spec := browser.PaginateSpec{
+		ItemSelector: decision.Selector,
+		Fields:       fields,
+		NextHint:     decision.Text,
+		MaxPages:     pages,
+		MaxItems:     maxPaginateItems,
+	}
+
+	fmt.Printf("📚 Сбор списка %s по страницам (не больше %d стр., %d строк)...\n", spec.ItemSelector, spec.MaxPages, spec.MaxItems)
+	result, err := a.browser.PaginateScrape(spec)
+	if err != nil && (result == nil || len(result.Rows) == 0) {
+		return err
+	}
+	if err != nil {
+		// Собранное до сбоя не теряем
+		result.StopReason = fmt.Sprintf("сбор прерван: %v", err)
+	}
+
+	strategy := "по кнопке следующей страницы"
+	if result.Strategy == browser.PaginateScroll {
+		strategy = "прокруткой ленты"
+	}
+	fmt.Printf("📚 Собрано строк: %d со страниц: %d (%s), остановка: %s\n", len(result.Rows), result.Pages, strategy, result.StopReason)
+
+	data := paginatedRows(result.Rows, fields)
+	a.paginatedData = data
+	a.history = append(a.history, fmt.Sprintf("paginate_scrape: собрано %d строк с %d страниц (%s), остановка: %s. Первые строки: %s. Все строки попадут в extracted_data, если при завершении не указать свои данные",
+		len(result.Rows), result.Pages, strategy, result.StopReason, paginatePreview(data)))
+	return nil
+}
+
+// parsePaginateFields разбирает поля строки: "название=.title", "ссылка=a@href",
+// "цена=.price". Атрибут указывается после "@", пустой селектор - сам элемент списка.
+func parsePaginateFields(values []string) ([]browser.PaginateField, error) {
+	var fields []browser.PaginateField
+	for _, value := range values {
+		name, selector, ok := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("поле %q должно быть в формате имя=селектор (например \"ссылка=a@href\")", value)
+		}
+		field := browser.PaginateField{Name: name, Selector: strings.TrimSpace(selector)}
+		if i := strings.LastIndex(field.Selector, "@"); i >= 0 {
+			field.Selector, field.Attr = strings.TrimSpace(field.Selector[:i]), strings.TrimSpace(field.Selector[i+1:])
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// paginatedRows переводит строки в JSON-массив объектов: страница, затем поля в порядке запроса
+func paginatedRows(rows []browser.ScrapedRow, fields []browser.PaginateField) json.RawMessage {
+	names := []string{"text", "href"}
+	if len(fields) > 0 {
+		names = names[:0]
+		for _, f := range fields {
+			names = append(names, f.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, `{"page":%d`, row.Page)
+		for _, name := range names {
+			value, ok := row.Fields[name]
+			if !ok {
+				continue
+			}
+			key, _ := json.Marshal(name)
+			encoded, _ := json.Marshal(value)
+			buf.WriteString("," + string(key) + ":" + string(encoded))
+		}
+		buf.WriteString("}")
+	}
+	buf.WriteString("]")
+	return buf.Bytes()
+}
+
+// paginatePreview - первые строки результата для истории
+func paginatePreview(data json.RawMessage) string {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil || len(rows) == 0 {
+		return "нет"
+	}
+	preview := make([]string, 0, paginatePreviewRows)
+	for _, row := range rows[:min(len(rows), paginatePreviewRows)] {
+		preview = append(preview, string(row))
+	}
+	return truncateRunes(strings.Join(preview, "; "), 1500)
+}
+
+// withPaginatedData подставляет строки paginate_scrape в итог задачи, если модель
+// завершила задачу без собственного extracted_data
+func (a *Agent) withPaginatedData(extracted json.RawMessage) json.RawMessage {
+	if len(extracted) > 0 && string(extracted) != "null" || a.paginatedData == nil {
+		return extracted
+	}
+	return a.paginatedData
+}
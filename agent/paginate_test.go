@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+func TestParsePaginateFields(t *testing.T) {
+	fields, err := parsePaginateFields([]string{"название=.title", "ссылка = a@href", "текст="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []browser.PaginateField{
+		{Name: "название", Selector: ".title"},
+		{Name: "ссылка", Selector: "a", Attr: "href"},
+		{Name: "текст"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("parsePaginateFields() = %+v, want %+v", fields, want)
+	}
+	if _, err := parsePaginateFields([]string{".title"}); err == nil {
+		t.Error("field without a name must fail")
+	}
+}
+
+func TestPaginatedRows(t *testing.T) {
+	rows := []browser.ScrapedRow{
+		{Page: 1, Fields: map[string]string{"цена": "100", "название": "Чайник"}},
+		{Page: 2, Fields: map[string]string{"название": "Кофе \"Арабика\""}},
+	}
+	fields := []browser.PaginateField{{Name: "название"}, {Name: "цена"}}
+	got := string(paginatedRows(rows, fields))
+	want := `[{"page":1,"название":"Чайник","цена":"100"},{"page":2,"название":"Кофе \"Арабика\""}]`
+	if got != want {
+		t.Errorf("paginatedRows() = %s, want %s", got, want)
+	}
+
+	a := &Agent{paginatedData: []byte(want)}
+	if got := a.withPaginatedData(nil); string(got) != want {
+		t.Errorf("withPaginatedData(nil) = %s", got)
+	}
+	if got := a.withPaginatedData([]byte(`{"own":1}`)); string(got) != `{"own":1}` {
+		t.Errorf("model data must win, got %s", got)
+	}
+}
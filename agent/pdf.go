@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// SetPDFDir задает каталог для PDF, сохраненных действием save_pdf (PDF_DIR).
+// Отдельно от REPORT_DIR: сохраненные квитанции не должны удаляться вместе со
+// старыми отчетами. Пустое значение - текущий каталог.
+func (a *Agent) SetPDFDir(dir string) {
+	a.pdfDir = dir
+}
+
+// savePDF сохраняет текущую страницу в PDF. Имя файла - из "value" модели или по
+// адресу страницы; файл попадает в результат задачи и в историю.
+func (a *Agent) savePDF(decision *ai.Decision) error {
+	name := pdfFileName(strings.TrimSpace(decision.Value))
+	if name == "" {
+		if current, err := a.browser.GetCurrentURL(); err == nil {
+			if parsed, err := url.Parse(current); err == nil {
+				name = pdfFileName(parsed.Hostname())
+			}
+		}
+	}
+	if name == "" {
+		name = "page"
+	}
+
+	dir := a.pdfDir
+	if dir == "" {
+		dir = "."
+	}
+	path := filepath.Join(dir, time.Now().Format("20060102-150405")+"-"+name+".pdf")
+	if _, err := os.Stat(path); err == nil {
+		path = filepath.Join(dir, time.Now().Format("20060102-150405.000")+"-"+name+".pdf")
+	}
+
+	fmt.Printf("🖨️  Сохранение страницы в PDF: %s\n", path)
+	if err := a.browser.PrintToPDF(path); err != nil {
+		return err
+	}
+	a.savedFiles = append(a.savedFiles, path)
+	a.history = append(a.history, fmt.Sprintf("Страница сохранена в PDF: %s. НЕ сохраняй ее повторно", path))
+	return nil
+}
+
+// pdfFileName оставляет от имени, предложенного моделью, безопасную для файловой
+// системы часть: без каталогов, расширения и служебных символов
+func pdfFileName(value string) string {
+	base := filepath.Base(strings.ReplaceAll(value, "\\", "/"))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_', r == '.':
+			return r
+		case unicode.IsSpace(r):
+			return '-'
+		}
+		return -1
+	}, base)
+	name = strings.Trim(name, ".-")
+	if runes := []rune(name); len(runes) > 80 {
+		name = string(runes[:80])
+	}
+	return name
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForHostPoliteness(t *testing.T) {
+	a := &Agent{hostDelay: 200 * time.Millisecond, lastHostVisit: make(map[string]time.Time)}
+	ctx := context.Background()
+
+	start := time.Now()
+	for _, url := range []string{"https://www.shop.example/a", "https://other.example/", "https://shop.example/b"} {
+		if err := a.waitForHostPoliteness(ctx, url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("repeated host waited %v, want the politeness delay", elapsed)
+	}
+
+	start = time.Now()
+	if err := a.waitForHostPoliteness(ctx, "https://third.example/"); err != nil || time.Since(start) > 50*time.Millisecond {
+		t.Errorf("new host: err %v after %v, want no delay", err, time.Since(start))
+	}
+}
+
+func TestWaitForHostPolitenessCanceled(t *testing.T) {
+	a := &Agent{hostDelay: time.Minute, lastHostVisit: map[string]time.Time{"shop.example": time.Now()}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := a.waitForHostPoliteness(ctx, "https://shop.example/next")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 5*time.Second {
+		t.Errorf("waitForHostPoliteness() = %v after %v, want the canceled context to interrupt the pause", err, time.Since(start))
+	}
+	if last := a.lastHostVisit["shop.example"]; time.Since(last) < 40*time.Millisecond {
+		t.Error("canceled navigation recorded as a visit")
+	}
+}
+
+func TestExecuteTaskStopsOnCanceledContext(t *testing.T) {
+	// Без браузера и модели: прерванная задача не должна начинать следующий шаг
+	a := &Agent{maxIterations: 5, iteration: 2}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := a.executeTask(ctx, "найти чайник")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("executeTask() = %v, want context.Canceled", err)
+	}
+	if a.iteration != 2 {
+		t.Errorf("iteration = %d, want 2 (no step started)", a.iteration)
+	}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// defaultRepeatLimit - с какого одинакового решения подряд действие не выполняется:
+// два раза подряд - обычное дело (кнопка "+" или "Далее"), третий раз - зацикливание
+const defaultRepeatLimit = 3
+
+// repeatFreeActions - действия, которые модель законно повторяет много раз подряд
+var repeatFreeActions = map[string]bool{
+	"wait":           true,
+	"scroll_to_load": true,
+	"extract":        true,
+	"complete":       true,
+}
+
+// SetRepeatLimit задает, на каком одинаковом решении подряд агент перестает его
+// выполнять (минимум 2)
+func (a *Agent) SetRepeatLimit(n int) {
+	if n >= 2 {
+		a.repeatLimit = n
+	}
+}
+
+// decisionKey - решение без обоснования: действие, цель, значение и состояние страницы.
+// Кнопка "Показать еще" меняет страницу, поэтому ее повторные клики не совпадают.
+func (a *Agent) decisionKey(decision *ai.Decision) string {
+	page, _ := a.browser.PageSignature()
+	return strings.Join([]string{decision.Action, decision.Selector, decision.Text, decision.Value,
+		decision.URL, decision.Key, decision.Frame, strings.Join(decision.Values, ","), page}, "\x00")
+}
+
+// rememberExecuted запоминает отпечаток успешно выполненного решения вместе со страницей,
+// какой она стала после него (после ожидания готовности): с этой страницей модель
+// принимает следующее решение
+func (a *Agent) rememberExecuted() {
+	if a.executed == nil {
+		return
+	}
+	a.executedKey = a.decisionKey(a.executed)
+	a.executed = nil
+}
+
+// skipExecutedDecision пропускает решение, которое совпадает с только что успешно
+// выполненным, если страница с тех пор не изменилась: модель иногда повторяет шаг,
+// не заметив, что он сработал, - повторное заполнение тратит время, а повторная
+// отправка может отправить форму дважды. "repeat": true - намеренный повтор
+// (кнопка "+" несколько раз). Повтор после пропуска пропускается снова, пока
+// blockRepeatedDecision не потребует сменить стратегию.
+func (a *Agent) skipExecutedDecision(decision *ai.Decision) bool {
+	previous := a.executedKey
+	a.executedKey = ""
+	if previous == "" || decision.Repeat || repeatFreeActions[decision.Action] {
+		return false
+	}
+	if a.decisionKey(decision) != previous {
+		return false
+	}
+	a.executedKey = previous
+
+	target := decision.Text
+	if target == "" {
+		target = decision.Selector
+	}
+	fmt.Printf("⏭️  '%s' %s уже выполнено успешно, страница не изменилась - пропускаю\n", decision.Action, target)
+	a.history = append(a.history, fmt.Sprintf("%s %s: это действие уже было выполнено успешно, страница после него не изменилась - повтор пропущен. "+
+		"Переходи к следующему шагу; если повтор действительно нужен (кнопка \"+\" несколько раз), добавь \"repeat\": true", decision.Action, target))
+	a.recordAction(decision, "skipped", nil)
+	return true
+}
+
+// blockRepeatedDecision не дает выполнить одно и то же решение repeatLimit раз подряд
+// на неизменившейся странице: действие явно не работает (мертвая кнопка, перекрытый элемент),
+// а модель возвращается к нему. Вместо выполнения модель получает требование сменить
+// стратегию, а следующее решение принимает более сильная модель, если она задана.
+func (a *Agent) blockRepeatedDecision(decision *ai.Decision) bool {
+	if repeatFreeActions[decision.Action] {
+		a.lastDecisionKey, a.decisionRepeats = "", 0
+		return false
+	}
+	key := a.decisionKey(decision)
+	if key == a.lastDecisionKey {
+		a.decisionRepeats++
+	} else {
+		a.lastDecisionKey, a.decisionRepeats = key, 1
+	}
+	if a.decisionRepeats < a.repeatLimit {
+		return false
+	}
+
+	target := decision.Text
+	if target == "" {
+		target = decision.Selector
+	}
+	fmt.Printf("🔁 Решение '%s' %s выбрано в %d-й раз подряд - не выполняю, нужна другая стратегия\n", decision.Action, target, a.decisionRepeats)
+	a.history = append(a.history, fmt.Sprintf("ЗАСТРЯЛ: '%s' %s выбрано в %d-й раз подряд и НЕ РАБОТАЕТ - страница не меняется. "+
+		"Это действие больше не выполняется. Выбери СОВЕРШЕННО ДРУГОЕ действие: другой элемент, прокрутку, "+
+		"закрытие перекрывающего окна, другую страницу или needs_input", decision.Action, target, a.decisionRepeats))
+	a.recordAction(decision, "repeated", fmt.Errorf("решение выбрано в %d-й раз подряд", a.decisionRepeats))
+	if a.aiClient.EscalateNextDecision() {
+		fmt.Printf("🧠 Следующее решение примет модель %s\n", a.aiClient.EscalationModel())
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+const (
+	maxReportChunks    = 10   // частей отчета, пересказываемых отдельными запросами
+	reportPreviewRunes = 1500 // сколько отчета печатается в консоль
+)
+
+// SetReportDir задает каталог Markdown-отчетов по большим результатам задач
+// (пустая строка - отчеты не строятся)
+func (a *Agent) SetReportDir(dir string) {
+	a.reportDir = dir
+}
+
+// writeReport строит отчет по большому extracted_data частями (map-reduce), чтобы
+// итог не обрезался лимитом ответа модели, и сохраняет его в Markdown-файл.
+// Запросы к модели засчитываются в бюджет итераций задачи.
+func (a *Agent) writeReport(ctx context.Context) {
+	if a.reportDir == "" || len(a.extractedData) < ai.ReportThresholdChars {
+		return
+	}
+
+	// Каждая часть - отдельный запрос; оставляем одну итерацию на объединение
+	budget := min(maxReportChunks, a.maxIterations-a.iteration-1)
+	chunks := ai.ReportChunks(a.extractedData, budget)
+	fmt.Printf("📑 Большой результат (%d символов): отчет по %d частям...\n", len(a.extractedData), len(chunks))
+
+	report, calls, err := a.aiClient.Report(ctx, a.task, chunks)
+	a.iteration += calls
+	entry := transcriptEntry{Type: "event", Iteration: a.iteration, Action: "report", Status: "ok"}
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось построить отчет: %v\n", err)
+		entry.Status, entry.Error = "error", err.Error()
+		a.writeTranscript(entry)
+		return
+	}
+
+	path, err := a.saveReport(report)
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить отчет: %v\n", err)
+		entry.Status, entry.Error = "error", err.Error()
+		a.writeTranscript(entry)
+		return
+	}
+	entry.URL = path
+	a.writeTranscript(entry)
+	a.reportPath = path
+
+	preview := []rune(report)
+	if len(preview) > reportPreviewRunes {
+		preview = append(preview[:reportPreviewRunes], []rune("...")...)
+	}
+	fmt.Printf("\n%s\n\n📑 Полный отчет: %s\n", string(preview), path)
+}
+
+// saveReport записывает отчет и исходные данные в Markdown-файл
+func (a *Agent) saveReport(report string) (string, error) {
+	if err := os.MkdirAll(a.reportDir, 0755); err != nil {
+		return "", err
+	}
+
+	var data bytes.Buffer
+	if err := json.Indent(&data, a.extractedData, "", "  "); err != nil {
+		data.Reset()
+		data.Write(a.extractedData)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Отчет: %s\n\n", a.task))
+	sb.WriteString(fmt.Sprintf("_%s_\n\n", time.Now().Format("02.01.2006 15:04")))
+	sb.WriteString(report)
+	sb.WriteString("\n\n## Собранные данные\n\n```json\n")
+	sb.WriteString(data.String())
+	sb.WriteString("\n```\n")
+
+	return writeRunFile(a.reportDir, time.Now(), ".md", []byte(sb.String()))
+}
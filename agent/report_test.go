@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/ai/aitest"
+)
+
+func TestWriteReportWithinBudget(t *testing.T) {
+	items := make([]string, 8)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"name":"товар %d","description":%q}`, i, strings.Repeat("описание ", 600))
+	}
+	model := aitest.NewScriptedProvider().
+		WhenReply(`Часть \d+ из`, "- товары части").
+		WhenReply(`Отчет собран по частям`, "Найдено 8 товаров.")
+	a := &Agent{
+		aiClient:      ai.NewClientWithProvider(model, "gpt-4o"),
+		reportDir:     t.TempDir(),
+		task:          "собрать товары",
+		maxIterations: 10,
+		iteration:     6,
+		extractedData: json.RawMessage("[" + strings.Join(items, ",") + "]"),
+	}
+
+	a.writeReport(context.Background())
+	// Осталось 4 итерации: не больше 3 частей и объединение
+	parts := len(model.Prompts()) - 1
+	if parts < 2 || parts > 3 || a.iteration != 6+parts+1 {
+		t.Errorf("%d part requests, iteration %d, want at most 3 parts counted in the iteration budget", parts, a.iteration)
+	}
+	data, err := os.ReadFile(a.reportPath)
+	if err != nil {
+		t.Fatalf("report file: %v", err)
+	}
+	report := string(data)
+	if !strings.HasPrefix(report, "# Отчет: собрать товары") || !strings.Contains(report, "Найдено 8 товаров.") || !strings.Contains(report, `"товар 7"`) {
+		t.Errorf("report lacks the merged summary or the raw data:\n%.300s", report)
+	}
+}
+
+func TestWriteReportSkipsSmallResults(t *testing.T) {
+	model := aitest.NewScriptedProvider()
+	a := &Agent{
+		aiClient:      ai.NewClientWithProvider(model, "gpt-4o"),
+		reportDir:     t.TempDir(),
+		maxIterations: 10,
+		extractedData: json.RawMessage(`{"price": 1490}`),
+	}
+	a.writeReport(context.Background())
+	if len(model.Prompts()) != 0 || a.reportPath != "" {
+		t.Errorf("small result: %d requests, report %q, want none", len(model.Prompts()), a.reportPath)
+	}
+}
@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// maxSchemaRetries - сколько раз модель может дозаполнять результат перед завершением
+const maxSchemaRetries = 2
+
+// TaskResult содержит итог выполнения задачи
+type TaskResult struct {
+	Task          string           `json:"task"`
+	Success       bool             `json:"success"`
+	Summary       string           `json:"summary,omitempty"`
+	ExtractedData json.RawMessage  `json:"extracted_data,omitempty"`
+	MissingFields []string         `json:"missing_fields,omitempty"` // поля схемы, которые не удалось заполнить
+	Error         string           `json:"error,omitempty"`
+	Duration      time.Duration    `json:"duration"`
+	Iterations    int              `json:"iterations"`
+	Usage         ai.TokenUsage    `json:"usage"`                 // токены модели за задачу
+	SettleTime    time.Duration    `json:"settle_time"`           // ожидание готовности страницы после действий
+	LowPower      bool             `json:"low_power,omitempty"`   // браузер работал в режиме низкой мощности
+	Metadata      *RunMetadata     `json:"metadata,omitempty"`    // версии и настройки запуска
+	Documents     []DocumentAnswer `json:"documents,omitempty"`   // ответы по прочитанным документам
+	Files         []string         `json:"files,omitempty"`       // сохраненные файлы (PDF страниц)
+	ReportPath    string           `json:"report_path,omitempty"` // Markdown-отчет по большому результату
+	FinalPage     *FinalPage       `json:"final_page,omitempty"`  // страница в момент завершения (SetCaptureFinalPage)
+}
+
+// ExecuteWithResult выполняет задачу и возвращает структурированный результат.
+// Если передана схема (JSON Schema), итоговый extracted_data проверяется по ней
+// до завершения задачи, а модель получает подсказку о недостающих полях.
+func (a *Agent) ExecuteWithResult(ctx context.Context, task string, schema json.RawMessage) (*TaskResult, error) {
+	a.resultSchema = nil
+	if len(schema) > 0 {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(schema, &parsed); err != nil {
+			return nil, fmt.Errorf("invalid result schema: %w", err)
+		}
+		a.resultSchema = parsed
+		a.aiClient.SetResultSchema(string(schema))
+	}
+	defer func() {
+		a.resultSchema = nil
+		a.aiClient.SetResultSchema("")
+	}()
+
+	startTime := time.Now()
+	err := a.Execute(ctx, task)
+	return a.buildResult(task, err, time.Since(startTime)), err
+}
+
+// buildResult собирает итог последней задачи
+func (a *Agent) buildResult(task string, err error, duration time.Duration) *TaskResult {
+	result := &TaskResult{
+		Task:          task,
+		Success:       err == nil && a.completed,
+		Summary:       a.summary,
+		ExtractedData: a.extractedData,
+		MissingFields: a.missingFields,
+		Duration:      duration,
+		Iterations:    a.iteration,
+		Usage:         a.aiClient.Usage().Sub(a.usageStart),
+		SettleTime:    a.settleTime,
+		LowPower:      a.browser.LowPower(),
+		Metadata:      a.runMetadata,
+		Documents:     a.documents,
+		Files:         a.savedFiles,
+		ReportPath:    a.reportPath,
+		FinalPage:     a.finalPage,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if len(result.MissingFields) > 0 {
+		explanation := fmt.Sprintf("не удалось заполнить поля: %s", strings.Join(result.MissingFields, ", "))
+		if result.Summary != "" {
+			result.Summary += "\n" + explanation
+		} else {
+			result.Summary = explanation
+		}
+	}
+	return result
+}
+
+// acceptResult проверяет extracted_data по схеме. Возвращает false, если модель
+// должна дозаполнить результат перед завершением.
+func (a *Agent) acceptResult(decision *ai.Decision) bool {
+	if a.resultSchema == nil {
+		return true
+	}
+
+	var data interface{}
+	problems := []string{"extracted_data отсутствует"}
+	if len(decision.ExtractedData) > 0 {
+		if err := json.Unmarshal(decision.ExtractedData, &data); err != nil {
+			problems = []string{fmt.Sprintf("extracted_data не является валидным JSON: %v", err)}
+		} else {
+			problems = validateSchema(data, a.resultSchema, "extracted_data")
+		}
+	}
+
+	if len(problems) == 0 {
+		a.missingFields = nil
+		return true
+	}
+
+	a.schemaRetries++
+	if a.schemaRetries > maxSchemaRetries {
+		fmt.Printf("⚠️  Результат не соответствует схеме после %d попыток: %s\n", maxSchemaRetries, strings.Join(problems, "; "))
+		a.missingFields = problems
+		return true
+	}
+
+	fmt.Printf("⚠️  Результат не соответствует схеме (%s), прошу модель дозаполнить...\n", strings.Join(problems, "; "))
+	a.history = append(a.history, fmt.Sprintf("РЕЗУЛЬТАТ НЕ ПРИНЯТ - не соответствует схеме: %s. Найди недостающие данные и заверши снова с полным extracted_data", strings.Join(problems, "; ")))
+	decision.IsComplete = false
+	return false
+}
+
+// validateSchema проверяет значение по подмножеству JSON Schema
+// (type, required, properties, items, minItems) и возвращает список проблем
+func validateSchema(value interface{}, schema map[string]interface{}, path string) []string {
+	var problems []string
+
+	if expected, ok := schema["type"].(string); ok && !matchesSchemaType(value, expected) {
+		return []string{fmt.Sprintf("поле %s должно иметь тип %s", path, expected)}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if field, exists := v[name]; !exists || field == nil || field == "" {
+					problems = append(problems, fmt.Sprintf("поле %s.%s не заполнено", path, name))
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			names := make([]string, 0, len(properties))
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				propSchema, ok := properties[name].(map[string]interface{})
+				field, exists := v[name]
+				if !ok || !exists || field == nil {
+					continue
+				}
+				problems = append(problems, validateSchema(field, propSchema, path+"."+name)...)
+			}
+		}
+
+	case []interface{}:
+		if minItems, ok := schema["minItems"].(float64); ok && len(v) < int(minItems) {
+			problems = append(problems, fmt.Sprintf("в %s %d элементов, ожидается минимум %d", path, len(v), int(minItems)))
+		}
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				problems = append(problems, validateSchema(item, itemSchema, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return problems
+}
+
+func matchesSchemaType(value interface{}, expected string) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+package agent
+
+import "fmt"
+
+// noteRouteChange сообщает модели, что клик сменил маршрут SPA: адрес изменился
+// без перезагрузки, и это признак того, что клик сработал
+func (a *Agent) noteRouteChange() {
+	change := a.browser.LastRouteChange()
+	if change == nil {
+		return
+	}
+	fmt.Printf("🧭 Маршрут SPA сменился: %s\n", change.URL)
+	a.history = append(a.history, fmt.Sprintf("Клик сработал: страница сменила маршрут на %s без перезагрузки", change.URL))
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runStampLayout - время запуска в именах журналов, отчетов и пакетов
+const runStampLayout = "20060102-150405"
+
+// maxRunNameAttempts - сколько номеров перебирается для файлов одной секунды
+const maxRunNameAttempts = 1000
+
+// runFileName возвращает имя файла запуска: 20240131-120000.jsonl для первого
+// файла секунды, 20240131-120000-2.jsonl, -3 ... для следующих
+func runFileName(stamp time.Time, attempt int, suffix string) string {
+	name := stamp.Format(runStampLayout)
+	if attempt > 1 {
+		name += fmt.Sprintf("-%d", attempt)
+	}
+	return name + suffix
+}
+
+// createRunFile создает в dir новый файл запуска с суффиксом suffix (".jsonl",
+// "-task.md"). Файл открывается с O_EXCL: задачи, начатые в одну секунду, в том числе
+// в разных процессах, не перезаписывают файлы друг друга, а получают следующий номер.
+func createRunFile(dir string, stamp time.Time, suffix string) (*os.File, error) {
+	for attempt := 1; attempt <= maxRunNameAttempts; attempt++ {
+		path := filepath.Join(dir, runFileName(stamp, attempt, suffix))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, fmt.Errorf("не удалось подобрать свободное имя файла в %s", dir)
+}
+
+// writeRunFile записывает data в новый файл запуска (см. createRunFile) и возвращает путь
+func writeRunFile(dir string, stamp time.Time, suffix string, data []byte) (string, error) {
+	f, err := createRunFile(dir, stamp, suffix)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// createRunDir создает в dir новый каталог запуска для пакета воспроизведения. Имя
+// занято, если есть каталог или уже упакованный архив <имя>.zip.
+func createRunDir(dir string, stamp time.Time) (string, error) {
+	for attempt := 1; attempt <= maxRunNameAttempts; attempt++ {
+		path := filepath.Join(dir, runFileName(stamp, attempt, ""))
+		if _, err := os.Stat(path + ".zip"); err == nil {
+			continue
+		}
+		err := os.Mkdir(path, 0755)
+		if os.IsExist(err) {
+			continue
+		}
+		return path, err
+	}
+	return "", fmt.Errorf("не удалось подобрать свободное имя каталога в %s", dir)
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateRunFileSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	stamp := time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local)
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		path, err := writeRunFile(dir, stamp, "-task.md", []byte{byte('a' + i)})
+		if err != nil {
+			t.Fatalf("writeRunFile() #%d error: %v", i+1, err)
+		}
+		names = append(names, filepath.Base(path))
+	}
+	want := []string{"20240131-120000-task.md", "20240131-120000-2-task.md", "20240131-120000-3-task.md"}
+	for i, name := range names {
+		if name != want[i] {
+			t.Errorf("file #%d = %q, want %q", i+1, name, want[i])
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != string(rune('a'+i)) {
+			t.Errorf("%s = %q, %v, want the content of write #%d kept", name, data, err, i+1)
+		}
+	}
+
+	f, err := createRunFile(dir, stamp, ".jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got := filepath.Base(f.Name()); got != "20240131-120000.jsonl" {
+		t.Errorf("transcript = %q, want its own counter per suffix", got)
+	}
+}
+
+func TestCreateRunDirSkipsPackedBundle(t *testing.T) {
+	dir := t.TempDir()
+	stamp := time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local)
+	if err := os.WriteFile(filepath.Join(dir, "20240131-120000.zip"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := createRunDir(dir, stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := createRunDir(dir, stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(first) != "20240131-120000-2" || filepath.Base(second) != "20240131-120000-3" {
+		t.Errorf("createRunDir() = %q, %q, want -2 and -3 next to the packed bundle", first, second)
+	}
+}
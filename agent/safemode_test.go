@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/ai/aitest"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+func TestSafeModeViolation(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision ai.Decision
+		blocked  bool
+	}{
+		{"navigate", ai.Decision{Action: "navigate", URL: "https://shop.example/search?q=чайник"}, false},
+		{"fill", ai.Decision{Action: "fill", Text: "Поиск", Value: "чайник"}, false},
+		{"extract", ai.Decision{Action: "extract"}, false},
+		{"open product", ai.Decision{Action: "click", Text: "Чайник Scarlett"}, false},
+		{"buy button", ai.Decision{Action: "click", Text: "Купить"}, true},
+		{"checkout selector", ai.Decision{Action: "click", Selector: "#checkout-btn"}, true},
+		{"add to cart", ai.Decision{Action: "click", Text: "Добавить в корзину"}, true},
+		{"save", ai.Decision{Action: "click", Text: "Save"}, true},
+		{"delete mail", ai.Decision{Action: "click", Text: "Удалить письмо"}, true},
+		{"cart only in reasoning", ai.Decision{Action: "click", Text: "→", Reasoning: "добавить товар в корзину"}, false},
+		{"sort order", ai.Decision{Action: "click", Text: "Sort order"}, false},
+		{"payments history", ai.Decision{Action: "click", Text: "Payments history"}, false},
+		{"saved searches", ai.Decision{Action: "click", Text: "Saved searches"}, false},
+		{"add filter", ai.Decision{Action: "click", Text: "Добавить фильтр"}, false},
+		{"apply filters", ai.Decision{Action: "click", Text: "Apply filters"}, false},
+		{"submit", ai.Decision{Action: "submit"}, true},
+		{"enter", ai.Decision{Action: "press_key", Key: "Enter"}, true},
+		{"delete key", ai.Decision{Action: "press_key", Key: "delete"}, true},
+		{"escape", ai.Decision{Action: "press_key", Key: "escape"}, false},
+		{"upload", ai.Decision{Action: "upload", Value: "cv.pdf"}, true},
+	}
+	a := &Agent{safeMode: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := tt.decision
+			if reason := a.safeModeViolation(&decision); (reason != "") != tt.blocked {
+				t.Errorf("safeModeViolation(%+v) = %q, want blocked %v", tt.decision, reason, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestSafeModeBlocksUnsafeRegistryActions(t *testing.T) {
+	a := &Agent{safeMode: true}
+	var unsafe int
+	for _, name := range ai.ActionNames() {
+		spec, _ := ai.LookupAction(name)
+		if spec.Unsafe == "" {
+			continue
+		}
+		unsafe++
+		if reason := a.safeModeViolation(&ai.Decision{Action: name}); reason != spec.Unsafe {
+			t.Errorf("safeModeViolation(%s) = %q, want %q", name, reason, spec.Unsafe)
+		}
+	}
+	if unsafe == 0 {
+		t.Error("registry has no actions forbidden in safe-mode")
+	}
+}
+
+// purchaseFlowPage - карточка товара: выбор товара и количества разрешены, заказ - нет
+const purchaseFlowPage = `<p id="status">каталог</p>
+<button onclick="document.getElementById('status').textContent = 'товар выбран'">Чайник Scarlett</button>
+<form onsubmit="document.getElementById('status').textContent = 'заказ отправлен'; return false">
+	<input name="qty" placeholder="Количество">
+	<button type="submit">Оформить заказ</button>
+</form>`
+
+func TestSafeModeStopsPurchaseFlow(t *testing.T) {
+	b := newFixtureBrowser(t, purchaseFlowPage)
+	a := NewAgent(b, ai.NewClientWithProvider(aitest.NewScriptedProvider(), "gpt-4o"))
+	a.SetSafeMode(true)
+	a.disableDestructiveCheck = true // без вопросов в stdin: блокировать должен safe-mode
+	a.settleMax = 0
+	// Анализ страницы, как перед первым решением модели
+	if _, err := b.GetPageContent(); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		decision ai.Decision
+		status   string
+	}{
+		{ai.Decision{Action: "click", Text: "Чайник Scarlett"}, "товар выбран"},
+		{ai.Decision{Action: "fill", Text: "Количество", Value: "2"}, "товар выбран"},
+		{ai.Decision{Action: "click", Text: "Оформить заказ"}, "товар выбран"},
+		{ai.Decision{Action: "press_key", Key: "enter"}, "товар выбран"},
+		{ai.Decision{Action: "submit"}, "товар выбран"},
+	}
+	for i, step := range steps {
+		decision := step.decision
+		if err := a.processDecision(context.Background(), &decision); err != nil {
+			t.Fatalf("step %d (%s): %v", i+1, decision.Action, err)
+		}
+		status, err := b.GetText("#status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(status) != step.status {
+			t.Fatalf("after step %d (%s) status = %q, want %q", i+1, decision.Action, status, step.status)
+		}
+	}
+
+	var blocked int
+	for _, note := range a.history {
+		if strings.Contains(note, "заблокировано safe-mode") {
+			blocked++
+		}
+	}
+	if blocked != 3 {
+		t.Errorf("history has %d safe-mode blocks, want 3 (order button, Enter, submit): %q", blocked, a.history)
+	}
+}
+
+// newFixtureBrowser открывает html в браузере без окна; без Chrome тест пропускается
+func newFixtureBrowser(t *testing.T, html string) *browser.Browser {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("browser tests skipped in -short mode")
+	}
+	b, err := browser.NewBrowser(t.TempDir(), true)
+	if err != nil {
+		t.Skipf("Chrome unavailable: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(html))
+	}))
+	t.Cleanup(server.Close)
+	if err := b.Navigate(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// defaultSettleMax - сколько ждать, пока страница устоится после действия
+const defaultSettleMax = time.Second
+
+// pageStaticActions - действия, которые не меняют страницу: после них ждать нечего
+var pageStaticActions = map[string]bool{
+	"wait":          true,
+	"extract":       true,
+	"extract_text":  true,
+	"read_document": true,
+	"save_pdf":      true,
+	"select_text":   true,
+	"complete":      true,
+}
+
+// SetSettleMax задает предел ожидания готовности страницы после действия
+// (PAGE_SETTLE_MAX). 0 - не ждать вовсе.
+func (a *Agent) SetSettleMax(d time.Duration) {
+	if d >= 0 {
+		a.settleMax = d
+	}
+}
+
+// readyWaiter - ожидание готовности страницы (*browser.Browser; в тестах - фейковый браузер)
+type readyWaiter interface {
+	WaitForReady(maxWait time.Duration) (time.Duration, error)
+}
+
+// settleAfter ждет после действия, пока страница загрузится и DOM перестанет меняться,
+// но не дольше settleMax. Вместо фиксированной паузы: страница, которая устоялась
+// сразу, не задерживает следующую итерацию. Время ожидания копится в settleTime.
+func (a *Agent) settleAfter(decision *ai.Decision) {
+	a.settleWith(a.browser, decision)
+}
+
+func (a *Agent) settleWith(page readyWaiter, decision *ai.Decision) {
+	if pageStaticActions[decision.Action] {
+		return
+	}
+	waited, err := page.WaitForReady(a.settleMax)
+	a.settleTime += waited
+	if err != nil && a.verbose {
+		fmt.Printf("   ⚠️  Ожидание готовности страницы прервано: %v\n", err)
+	}
+}
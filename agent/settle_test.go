@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// fakeReadyBrowser запоминает вызовы WaitForReady и "ждет" заданное время
+type fakeReadyBrowser struct {
+	calls  []time.Duration
+	waited time.Duration
+	err    error
+}
+
+func (b *fakeReadyBrowser) WaitForReady(maxWait time.Duration) (time.Duration, error) {
+	b.calls = append(b.calls, maxWait)
+	return min(b.waited, maxWait), b.err
+}
+
+func TestSettleAfterSkipsStaticActions(t *testing.T) {
+	tests := []struct {
+		action string
+		settle bool
+	}{
+		{"click", true},
+		{"navigate", true},
+		{"type", true},
+		{"press_enter", true},
+		{"scroll", true},
+		{"wait", false},
+		{"extract", false},
+		{"extract_text", false},
+		{"read_document", false},
+		{"save_pdf", false},
+		{"select_text", false},
+		{"complete", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			page := &fakeReadyBrowser{waited: 300 * time.Millisecond}
+			a := &Agent{settleMax: defaultSettleMax}
+			a.settleWith(page, &ai.Decision{Action: tt.action})
+			if got := len(page.calls) > 0; got != tt.settle {
+				t.Fatalf("%s: stabilization called %v, want %v", tt.action, got, tt.settle)
+			}
+			if tt.settle && (page.calls[0] != defaultSettleMax || a.settleTime != 300*time.Millisecond) {
+				t.Errorf("%s: WaitForReady(%v), settleTime %v, want the cap passed and 300ms counted", tt.action, page.calls[0], a.settleTime)
+			}
+		})
+	}
+}
+
+func TestSettleTimeAccumulates(t *testing.T) {
+	page := &fakeReadyBrowser{waited: 2 * time.Second}
+	a := &Agent{}
+	a.SetSettleMax(500 * time.Millisecond)
+	a.SetSettleMax(-time.Second) // отрицательный предел игнорируется
+
+	a.settleWith(page, &ai.Decision{Action: "click"})
+	page.err = errors.New("navigation interrupted")
+	a.settleWith(page, &ai.Decision{Action: "click"})
+	if a.settleTime != time.Second {
+		t.Errorf("settleTime = %v, want two waits capped at 500ms even after an error", a.settleTime)
+	}
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// errPageChanged - цель действия пропала со страницы после анализа: действие не
+// выполнялось, нужен повторный анализ, а не повтор после ошибки
+var errPageChanged = errors.New("страница изменилась после анализа")
+
+// verifyTarget проверяет перед кликом или заполнением, что выбранный моделью элемент
+// все еще на месте. Сместившийся элемент (баннер или реклама над ним) действие не
+// останавливает: клик и заполнение заново находят элемент по тексту или селектору.
+// Пропавший элемент - errPageChanged. Ошибка самой проверки действию не мешает.
+func (a *Agent) verifyTarget(decision *ai.Decision) error {
+	check, err := a.browser.VerifyTarget(decision.Selector, decision.Text)
+	if err != nil {
+		return nil
+	}
+	target := decision.Text
+	if target == "" {
+		target = decision.Selector
+	}
+	switch check.Status {
+	case browser.TargetMoved:
+		fmt.Printf("🔄 Элемент \"%s\" сместился на %dpx после анализа страницы - действие по новому положению\n", target, check.Shift)
+	case browser.TargetMissing:
+		return fmt.Errorf("%w: элемента \"%s\" больше нет", errPageChanged, target)
+	}
+	return nil
+}
+
+// notePageChanged записывает в историю, что действие не выполнялось из-за изменения
+// страницы. Действие не повторяется и паузы перед следующим шагом нет: модель получит
+// новый снимок страницы на следующей итерации. Счетчик ошибок растет в processDecision.
+func (a *Agent) notePageChanged(decision *ai.Decision, err error) {
+	fmt.Printf("🔄 %v - повторный анализ страницы\n", err)
+	a.history = append(a.history, fmt.Sprintf("'%s' не выполнено: %v. Выбери действие по новому содержимому страницы", decision.Action, err))
+	a.recordAction(decision, "stale", err)
+}
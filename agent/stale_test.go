@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/ai/aitest"
+)
+
+// staleTargetsPage - кнопки «Подробнее» и «Отзывы» пропадают после клика по «Обновить»
+const staleTargetsPage = `<div id="actions">
+	<button>Подробнее</button>
+	<button>Отзывы</button>
+</div>
+<button onclick="document.getElementById('actions').remove()">Обновить</button>`
+
+func TestPageChangedCountsAgainstErrorBudget(t *testing.T) {
+	b := newFixtureBrowser(t, staleTargetsPage)
+	// Анализ страницы запоминает кнопки, потом страница их убирает
+	if _, err := b.GetPageContent(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ClickByText("Обновить"); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAgent(b, ai.NewClientWithProvider(aitest.NewScriptedProvider(), "gpt-4o"))
+	a.maxErrors = 2
+	ctx := context.Background()
+	if err := a.processDecision(ctx, &ai.Decision{Action: "click", Text: "Подробнее"}); err != nil {
+		t.Fatalf("first stale click: %v", err)
+	}
+	if a.errorCount != 1 || len(a.history) == 0 || !strings.Contains(a.history[len(a.history)-1], "не выполнено") {
+		t.Fatalf("after first stale click: errors %d, history %q", a.errorCount, a.history)
+	}
+	err := a.processDecision(ctx, &ai.Decision{Action: "click", Text: "Отзывы"})
+	if !errors.Is(err, errPageChanged) || !strings.Contains(err.Error(), "too many consecutive errors") {
+		t.Errorf("second stale click = %v, want the error budget exhausted by page changes", err)
+	}
+}
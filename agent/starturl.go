@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/console"
+)
+
+// startDirectiveRegex - директива !start=URL. В отличие от других директив значение
+// заканчивается на пробеле: "!start=https://ozon.ru проверь мои заказы".
+var startDirectiveRegex = regexp.MustCompile(`(?i)(?:^|\s)!start=("[^"]*"|\S*)`)
+
+// leadingURLRegex - задача начинается с адреса: "https://ozon.ru проверь мои заказы"
+var leadingURLRegex = regexp.MustCompile(`^https?://\S+`)
+
+// taskStartURL отделяет от задачи директиву !start=URL и возвращает стартовую страницу
+// задачи: адрес из директивы или адрес, с которого начинается задача. Адрес в начале
+// задачи остается в ее тексте - модель видит, о каком сайте речь.
+func taskStartURL(task string) (string, string) {
+	start := ""
+	task = startDirectiveRegex.ReplaceAllStringFunc(task, func(match string) string {
+		start = strings.Trim(startDirectiveRegex.FindStringSubmatch(match)[1], `"`)
+		return ""
+	})
+	task = strings.TrimSpace(task)
+	if start == "" {
+		start = leadingURLRegex.FindString(task)
+	}
+	return task, normalizeStartURL(start)
+}
+
+// normalizeStartURL добавляет https:// к домену без схемы и отбрасывает знаки
+// препинания после адреса ("https://ozon.ru, проверь")
+func normalizeStartURL(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), ",.;:!?)»\"'")
+	if raw == "" {
+		return ""
+	}
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = "https://" + raw
+	}
+	if u, err := url.Parse(raw); err != nil || u.Host == "" || !strings.Contains(u.Host, ".") && u.Hostname() != "localhost" {
+		return ""
+	}
+	return raw
+}
+
+// openStartURL открывает стартовую страницу задачи до первого анализа страницы:
+// без нее модель начинает со START_URL и тратит шаг на переход. Переход записывается
+// в историю как указанный пользователем. Если страница не открылась, задача
+// продолжается с текущей страницы, и модель узнает о сбое из истории. Отмена ctx
+// во время паузы перед переходом на тот же домен пропускает переход.
+func (a *Agent) openStartURL(ctx context.Context, target string) {
+	if current, err := a.browser.GetCurrentURL(); err == nil && sameURL(current, target) {
+		a.history = append(a.history, fmt.Sprintf("стартовая страница задачи, указанная пользователем, уже открыта: %s", current))
+		return
+	}
+	if a.waitForHostPoliteness(ctx, target) != nil {
+		return
+	}
+	fmt.Printf("🌐 Стартовая страница задачи: %s\n", target)
+	stopStatus := console.StartStatus("загрузка страницы...")
+	err := a.browser.Navigate(target)
+	stopStatus()
+	if err != nil {
+		current, _ := a.browser.GetCurrentURL()
+		fmt.Printf("⚠️  Не удалось открыть стартовую страницу: %v\n", err)
+		a.history = append(a.history, fmt.Sprintf("пользователь указал стартовую страницу %s, но она не открылась (%v) - задача продолжается с текущей страницы %s", target, err, current))
+		return
+	}
+	a.history = append(a.history, fmt.Sprintf("navigate: %s (стартовая страница задачи, указана пользователем)", target))
+}
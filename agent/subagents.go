@@ -153,28 +153,7 @@ func (sa *SubAgent) Execute(ctx context.Context, task string, mainAgent *Agent)
 	actionsPrompt := `
 
 Доступные действия:
-1. navigate - перейти на URL
-   - Можешь использовать URL из списка links.href ИЛИ указать прямой URL (например, "https://mail.ru", "https://e.mail.ru", "https://hh.ru")
-   - Заполни: "url" (полный URL, можно прямой или из списка links)
-   
-2. click - кликнуть на элемент
-   - ОБЯЗАТЕЛЬНО заполни: "text" (видимый текст из списка buttons или links)
-   - Или если text не работает: "selector" (CSS селектор)
-   
-3. fill - заполнить поле ввода
-   - ОБЯЗАТЕЛЬНО заполни: "text" (placeholder, name, aria-label из списка inputs)
-   - ОБЯЗАТЕЛЬНО заполни: "value" (значение для ввода)
-   - Для полей поиска можно использовать общие термины: "искать", "search", "поиск"
-   - Для полей сопроводительного письма используй: "Сопроводительное письмо" или "письмо" в поле "text", а сам текст письма - в "value"
-   - ВАЖНО: НЕ используй весь текст письма как placeholder! Используй короткий placeholder типа "Сопроводительное письмо", а сам текст - в "value"
-   - Или если text не работает: "selector" (CSS селектор) + "value"
-   
-4. wait - подождать
-   - Опционально: "wait_for" (селектор элемента)
-   
-5. extract - извлечь информацию (уже сделано автоматически)
-6. complete - задача выполнена ТОЛЬКО когда задача действительно выполнена
-
+` + ai.ActionsShortPrompt() + `
 КРИТИЧЕСКИ ВАЖНО - ПРАВИЛА ЗАПОЛНЕНИЯ ПОЛЕЙ:
 - Для действия "navigate": Можешь использовать URL из списка links ИЛИ указать прямой URL (например, "https://mail.ru", "https://e.mail.ru", "https://hh.ru")
 - Для действия "click": ВСЕГДА заполняй "text" из списка buttons/links
@@ -183,20 +162,27 @@ func (sa *SubAgent) Execute(ctx context.Context, task string, mainAgent *Agent)
 - Для полей сопроводительного письма: используй "Сопроводительное письмо" или "письмо" в "text", а сам текст письма - в "value"
 - КРИТИЧЕСКИ ВАЖНО: НЕ используй весь длинный текст письма как placeholder! Это приведет к поиску в неправильном месте
 - НЕ завершай задачу (complete) если просто не можешь найти ссылку - используй navigate с прямым URL
+- Если нужный сайт уже открыт (см. текущую страницу и историю) - продолжай с него, НЕ переходи на стартовую страницу или поисковик
+- Даты "сегодня", "завтра", "через 2 часа" считай от текущих даты и времени из запроса; в "value" можно писать шаблоны {{today}}, {{tomorrow}}, {{now+2h}}, {{weekday}}
 - НЕ используй заготовленные селекторы - анализируй ТОЛЬКО данные текущей страницы
 - НЕ отказывайся от работы с веб-сайтами - это твоя основная функция
+- Если нужны данные, которых нет на странице и в задаче (код из SMS, одноразовый пароль, выбор пользователя) - НЕ придумывай их: верни "needs_input": true и вопрос в "input_prompt", код подтверждения пользователь введет сам
 - Если задача требует выполнить несколько действий (например, откликнуться на 3 вакансии) - выполни ВСЕ действия, не останавливайся на первом
 - Отвечай ТОЛЬКО в формате JSON, без дополнительного текста до или после JSON
 
 Формат ответа (строго валидный JSON):
 {
-  "action": "click|fill|navigate|wait|complete",
+  "action": "` + strings.Join(ai.ActionNames(), "|") + `",
   "reasoning": "объяснение",
   "text": "текст элемента (для click/fill)",
   "selector": "CSS селектор (опционально)",
   "value": "значение (для fill)",
   "url": "URL (для navigate - можно прямой URL или из списка)",
+  "key": "клавиша (для press_key)",
+  "tab_index": номер вкладки (для switch_tab/close_tab),
   "wait_for": "селектор (для wait)",
+  "question": "вопрос к документу (для read_document)",
+  "frame": "фрейм из списка \"Фреймы\" (для click/fill внутри iframe)",
   "is_complete": true/false,
   "summary": "резюме (при завершении)"
 }`
@@ -207,6 +193,11 @@ func (sa *SubAgent) Execute(ctx context.Context, task string, mainAgent *Agent)
 	// Восстанавливаем оригинальный промпт после выполнения
 	defer sa.aiClient.SetSystemPrompt(originalPrompt)
 	
+	// Под-агент продолжает с состояния основного агента (история, страница, бюджеты)
+	if err := mainAgent.acceptHandOff(mainAgent.prepareHandOff(sa.agentType)); err != nil {
+		return err
+	}
+
 	// Выполняем задачу через основной агент (который теперь использует специализированный промпт)
 	return mainAgent.executeTask(ctx, task)
 }
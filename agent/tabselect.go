@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// tabReferenceRegex - задача явно говорит о вкладке: "в той вкладке, где открыт Ozon"
+var tabReferenceRegex = regexp.MustCompile(`(?i)вкладк|\btabs?\b`)
+
+// tabStopStems - слова, которые описывают саму вкладку, а не сайт в ней
+var tabStopStems = []string{"вкладк", "открыт", "tab", "open", "где", "той", "тот", "там", "which", "where", "the"}
+
+// translit - латиница для кириллических названий сайтов: "озон" -> "ozon"
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch",
+	'ы': "y", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// minTabWordRunes - слова короче не сравниваются с вкладками: "для", "все", "что"
+// случайно находятся в доменах и заголовках
+const minTabWordRunes = 4
+
+// splitWords делит текст на слова в нижнем регистре
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tabWords возвращает основы слов задачи (первые 5 букв), по которым ищется вкладка
+func tabWords(task string) []string {
+	var stems []string
+	for _, word := range splitWords(task) {
+		if utf8.RuneCountInString(word) < minTabWordRunes {
+			continue
+		}
+		stem := word
+		if runes := []rune(word); len(runes) > 5 {
+			stem = string(runes[:5])
+		}
+		stop := false
+		for _, s := range tabStopStems {
+			if strings.HasPrefix(stem, s) || strings.HasPrefix(s, stem) {
+				stop = true
+				break
+			}
+		}
+		if !stop {
+			stems = append(stems, stem)
+		}
+	}
+	return stems
+}
+
+// scoreTab оценивает, насколько вкладка подходит к словам задачи: совпадение с доменом
+// важнее совпадения с заголовком. Основа сравнивается с началом части домена ("yandex"
+// в "market.yandex.ru") и с началом слова заголовка по первым 4 буквам ("почтой" -
+// "Почта"), кириллица с доменом - в транслитерации ("яндекс" -> "yande"). Совпадение
+// внутри слова не считается: "проверь" ("prov") не находит "improve.com".
+func scoreTab(stems []string, tab browser.TabInfo) int {
+	var labels []string
+	if u, err := neturl.Parse(tab.URL); err == nil {
+		labels = strings.FieldsFunc(strings.ToLower(u.Hostname()), func(r rune) bool { return r == '.' || r == '-' })
+	}
+	titleWords := splitWords(tab.Title)
+	score := 0
+	for _, stem := range stems {
+		short := stem
+		if runes := []rune(stem); len(runes) > 4 {
+			short = string(runes[:4])
+		}
+		var lat strings.Builder
+		for _, r := range short {
+			if t, ok := translit[r]; ok {
+				lat.WriteString(t)
+			} else {
+				lat.WriteRune(r)
+			}
+		}
+		switch {
+		case hasWordPrefix(labels, stem) || hasWordPrefix(labels, lat.String()):
+			score += 3
+		case hasWordPrefix(titleWords, short):
+			score += 2
+		}
+	}
+	return score
+}
+
+// hasWordPrefix сообщает, что одно из слов начинается с prefix
+func hasWordPrefix(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTaskTab переключается перед первым анализом страницы на открытую вкладку, о
+// которой говорит задача: с KEEP_BROWSER_OPEN пользователь держит открытыми несколько
+// сайтов и пишет "в той вкладке, где открыт Ozon, проверь статус заказа". Вкладка
+// выбирается по домену и заголовку, при равенстве - моделью. Если задача говорит о
+// вкладке, а подходящей нет, модель узнает об этом из истории и откроет сайт сама.
+// Без упоминания вкладки переключение происходит только при совпадении с доменом.
+func (a *Agent) selectTaskTab(ctx context.Context, task string) {
+	explicit := tabReferenceRegex.MatchString(task)
+	tabs, err := a.browser.GetAllTabs()
+	if err != nil || len(tabs) == 0 || (len(tabs) == 1 && !explicit) {
+		return
+	}
+
+	stems := tabWords(task)
+	best, bestScore := -1, 0
+	var tied []int
+	for i, tab := range tabs {
+		score := scoreTab(stems, tab)
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = i, score, []int{i}
+		case score == bestScore && score > 0:
+			tied = append(tied, i)
+		}
+	}
+	// Без упоминания вкладки совпадения одного заголовка мало: "заказ" есть на многих сайтах
+	if bestScore == 0 || (!explicit && bestScore < 3) {
+		if explicit {
+			var open []string
+			for _, tab := range tabs {
+				open = append(open, truncateRunes(tab.Title, 40))
+			}
+			fmt.Println("🗂  Подходящая вкладка не найдена - начинаю с текущей")
+			a.history = append(a.history, fmt.Sprintf("в задаче упомянута вкладка, но среди открытых (%s) подходящей нет - открой нужный сайт сам", strings.Join(open, "; ")))
+		}
+		return
+	}
+
+	if len(tied) > 1 {
+		candidates := make([]browser.TabInfo, len(tied))
+		for i, index := range tied {
+			candidates[i] = tabs[index]
+		}
+		choice, err := a.aiClient.ChooseTab(ctx, task, candidates)
+		switch {
+		case err != nil:
+			fmt.Printf("⚠️  Не удалось выбрать вкладку: %v\n", err)
+		case choice == 0:
+			return
+		default:
+			best = tied[choice-1]
+		}
+	}
+
+	tab := tabs[best]
+	if tab.IsActive && !explicit {
+		return
+	}
+	entry := transcriptEntry{Type: "event", Iteration: a.iteration, Action: "select_tab", Text: tab.Title, URL: tab.URL, Status: "ok"}
+	if !tab.IsActive {
+		fmt.Printf("🗂  Задача выполняется во вкладке %d: %s\n", best+1, tab.Title)
+		if err := a.browser.SwitchToTab(tab.ID); err != nil {
+			fmt.Printf("⚠️  Не удалось переключиться на вкладку: %v\n", err)
+			entry.Status = "error"
+			entry.Error = err.Error()
+			a.writeTranscript(entry)
+			return
+		}
+	}
+	a.writeTranscript(entry)
+	a.history = append(a.history, fmt.Sprintf("задача начата во вкладке %d: %s (%s)", best+1, tab.Title, tab.URL))
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+func TestTabWords(t *testing.T) {
+	got := tabWords("В той вкладке, где открыт Ozon, проверь статус заказа для меня")
+	want := []string{"ozon", "прове", "стату", "заказ", "меня"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tabWords() = %q, want %q", got, want)
+	}
+}
+
+func TestScoreTab(t *testing.T) {
+	tests := []struct {
+		name string
+		task string
+		tab  browser.TabInfo
+		want int
+	}{
+		{"domain", "где открыт Ozon", browser.TabInfo{URL: "https://www.ozon.ru/my/orders", Title: "Мои заказы"}, 3},
+		{"transliterated domain", "в яндекс маркете", browser.TabInfo{URL: "https://market.yandex.ru/", Title: "Маркет"}, 6},
+		{"title word", "во вкладке с почтой", browser.TabInfo{URL: "https://e.mail.ru/inbox", Title: "Почта Mail.ru"}, 2},
+		{"short words ignored", "что для всех", browser.TabInfo{URL: "https://chto.ru/", Title: "Все для дома"}, 0},
+		{"no match inside a domain label", "проверь заказ", browser.TabInfo{URL: "https://improve.com/", Title: "Improve"}, 0},
+		{"no match inside a title word", "проверь статус", browser.TabInfo{URL: "https://news.example/", Title: "Перепроверьте данные"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreTab(tabWords(tt.task), tt.tab); got != tt.want {
+				t.Errorf("scoreTab(%q) = %d, want %d", tt.task, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,470 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxReportDepth - глубина вложенности extracted_data, до которой данные
+// раскладываются по таблицам; глубже значения выводятся как JSON в ячейке
+const maxReportDepth = 2
+
+// SetPrintTaskReport включает печать краткого итога задачи в консоль (флаг --report).
+// Полный Markdown-отчет по задаче пишется в каталог отчетов независимо от флага.
+func (a *Agent) SetPrintTaskReport(enabled bool) {
+	a.printTaskReport = enabled
+}
+
+// writeTaskReport сохраняет Markdown-отчет по задаче рядом с отчетами по большим
+// результатам и при включенном --report печатает его краткую версию
+func (a *Agent) writeTaskReport(result *TaskResult) {
+	var path string
+	if a.reportDir != "" {
+		stamp := time.Now()
+		if result.Metadata != nil {
+			stamp = result.Metadata.StartedAt
+		}
+		files := []reportArtifact{
+			{Label: "Журнал задачи", Path: a.transcriptPath},
+			{Label: "Отчет по большому результату", Path: result.ReportPath},
+		}
+		for _, file := range result.Files {
+			files = append(files, reportArtifact{Label: filepath.Base(file), Path: file})
+		}
+		artifacts := relativeArtifacts(a.reportDir, files)
+		report := renderTaskReport(result, a.steps, artifacts)
+		if err := os.MkdirAll(a.reportDir, 0755); err != nil {
+			fmt.Printf("⚠️  Не удалось сохранить отчет по задаче: %v\n", err)
+		} else if path, err = writeRunFile(a.reportDir, stamp, "-task.md", []byte(report)); err != nil {
+			fmt.Printf("⚠️  Не удалось сохранить отчет по задаче: %v\n", err)
+			path = ""
+		}
+	}
+
+	if a.printTaskReport {
+		fmt.Print(condensedTaskReport(result, len(a.steps), path))
+	}
+}
+
+// reportArtifact - файл запуска, на который ссылается отчет
+type reportArtifact struct {
+	Label string
+	Path  string // относительно каталога отчета, через "/"
+}
+
+// relativeArtifacts переводит пути артефактов в относительные от каталога отчета,
+// чтобы ссылки работали после копирования каталогов запуска целиком
+// (артефакты, которых в этом запуске нет, пропускаются)
+func relativeArtifacts(reportDir string, artifacts []reportArtifact) []reportArtifact {
+	var relative []reportArtifact
+	for _, artifact := range artifacts {
+		if artifact.Path == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(reportDir, artifact.Path); err == nil {
+			artifact.Path = rel
+		}
+		artifact.Path = filepath.ToSlash(artifact.Path)
+		relative = append(relative, artifact)
+	}
+	return relative
+}
+
+// renderTaskReport строит Markdown-отчет: задача, итог, длительность и расход
+// токенов, свернутый список шагов, собранные данные таблицами и ссылки на артефакты
+func renderTaskReport(result *TaskResult, steps []transcriptEntry, artifacts []reportArtifact) string {
+	var sb strings.Builder
+	sb.WriteString("# Задача\n\n")
+	sb.WriteString(quoteMarkdown(result.Task))
+	sb.WriteString("\n\n## Итог\n\n")
+	sb.WriteString(fmt.Sprintf("- Результат: %s\n", formatOutcome(result)))
+	sb.WriteString(fmt.Sprintf("- Длительность: %s\n", result.Duration.Round(time.Second)))
+	if result.SettleTime > 0 {
+		sb.WriteString(fmt.Sprintf("- Ожидание готовности страницы: %.1fs\n", result.SettleTime.Seconds()))
+	}
+	if result.LowPower {
+		sb.WriteString("- Браузер в режиме низкой мощности: интервалы опроса удлинены\n")
+	}
+	if total := result.Usage.Total(); total > 0 {
+		sb.WriteString(fmt.Sprintf("- Токены: %d (запрос %d, ответ %d)\n", total, result.Usage.Prompt, result.Usage.Completion))
+	}
+	if result.Metadata != nil {
+		sb.WriteString(fmt.Sprintf("- Модель: %s\n", result.Metadata.Model))
+		sb.WriteString(fmt.Sprintf("- Начало: %s\n", result.Metadata.StartedAt.Format("02.01.2006 15:04:05")))
+	}
+	if result.Summary != "" {
+		sb.WriteString("\n")
+		sb.WriteString(quoteMarkdown(result.Summary))
+		sb.WriteString("\n")
+	}
+
+	if len(steps) > 0 {
+		lines := collapseSteps(steps)
+		sb.WriteString("\n## Шаги\n\n")
+		sb.WriteString(fmt.Sprintf("<details>\n<summary>Действий: %d</summary>\n\n", len(steps)))
+		for i, line := range lines {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, line))
+		}
+		sb.WriteString("\n</details>\n")
+	}
+
+	if len(result.ExtractedData) > 0 {
+		sb.WriteString("\n## Собранные данные\n\n")
+		sb.WriteString(renderExtractedData(result.ExtractedData))
+	}
+
+	if page := result.FinalPage; page != nil {
+		sb.WriteString("\n## Итоговая страница\n\n")
+		sb.WriteString(fmt.Sprintf("- [%s](%s)\n", escapeInline(page.Title), page.URL))
+		for _, h := range page.Headings[:min(len(page.Headings), 5)] {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", h.Level, escapeInline(h.Text)))
+		}
+	}
+
+	if len(result.Documents) > 0 {
+		sb.WriteString("\n## Документы\n\n")
+		for _, doc := range result.Documents {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", escapeInline(doc.Source), escapeInline(doc.Question)))
+		}
+	}
+
+	if len(artifacts) > 0 {
+		sb.WriteString("\n## Артефакты\n\n")
+		for _, artifact := range artifacts {
+			sb.WriteString(fmt.Sprintf("- [%s](%s)\n", artifact.Label, strings.ReplaceAll(artifact.Path, " ", "%20")))
+		}
+	}
+	return sb.String()
+}
+
+// condensedTaskReport - краткий итог задачи для консоли
+func condensedTaskReport(result *TaskResult, steps int, path string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n📋 Итог: %s за %s, действий: %d", formatOutcome(result), result.Duration.Round(time.Second), steps))
+	if total := result.Usage.Total(); total > 0 {
+		sb.WriteString(fmt.Sprintf(", токенов: %d", total))
+	}
+	if result.SettleTime > 0 {
+		sb.WriteString(fmt.Sprintf(", ожидание страницы: %.1fs", result.SettleTime.Seconds()))
+	}
+	sb.WriteString("\n")
+	if result.Summary != "" {
+		summary, _, _ := strings.Cut(result.Summary, "\n")
+		sb.WriteString(fmt.Sprintf("   %s\n", truncateRunes(summary, 200)))
+	}
+	if path != "" {
+		sb.WriteString(fmt.Sprintf("   📑 Отчет: %s\n", path))
+	}
+	return sb.String()
+}
+
+func formatOutcome(result *TaskResult) string {
+	switch {
+	case result.Success:
+		return "✅ выполнена"
+	case result.Error != "":
+		return "❌ не выполнена: " + escapeInline(result.Error)
+	}
+	return "⚠️ не завершена"
+}
+
+// collapseSteps сворачивает подряд идущие одинаковые действия (прокрутка,
+// ожидание, повтор клика) в одну строку со счетчиком
+func collapseSteps(steps []transcriptEntry) []string {
+	var lines []string
+	var last string
+	count := 0
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		if count > 1 {
+			last += fmt.Sprintf(" ×%d", count)
+		}
+		lines = append(lines, last)
+	}
+	for _, step := range steps {
+		line := "`" + step.Action + "`"
+		if target := stepTarget(step); target != "" {
+			line += " " + escapeInline(truncateRunes(target, 80))
+		}
+		if step.Status != "ok" && step.Status != "" {
+			line += " - " + step.Status
+		}
+		if step.Error != "" {
+			line += ": " + escapeInline(truncateRunes(step.Error, 120))
+		}
+		if line == last && count > 0 {
+			count++
+			continue
+		}
+		flush()
+		last, count = line, 1
+	}
+	flush()
+	return lines
+}
+
+func stepTarget(step transcriptEntry) string {
+	switch {
+	case step.URL != "":
+		return step.URL
+	case step.Text != "":
+		return "«" + step.Text + "»"
+	}
+	return step.Selector
+}
+
+// renderExtractedData выводит extracted_data таблицами: массив объектов - одна
+// таблица, объект - таблица "поле | значение" и вложенные разделы, массив
+// значений - список. Порядок полей сохраняется как в ответе модели.
+func renderExtractedData(data json.RawMessage) string {
+	value, err := decodeOrdered(json.NewDecoder(bytes.NewReader(data)))
+	if err != nil {
+		return "```json\n" + string(data) + "\n```\n"
+	}
+	var sb strings.Builder
+	renderValue(&sb, value, 0)
+	return sb.String()
+}
+
+func renderValue(sb *strings.Builder, value interface{}, depth int) {
+	switch v := value.(type) {
+	case []interface{}:
+		if columns, ok := tableColumns(v); ok {
+			rows := make([][]string, 0, len(v))
+			for _, item := range v {
+				obj := item.(*orderedObject)
+				row := make([]string, len(columns))
+				for i, column := range columns {
+					if field, exists := obj.values[column]; exists {
+						row[i] = formatCell(field)
+					}
+				}
+				rows = append(rows, row)
+			}
+			sb.WriteString(markdownTable(columns, rows))
+			return
+		}
+		for _, item := range v {
+			sb.WriteString("- " + escapeInline(formatCell(item)) + "\n")
+		}
+	case *orderedObject:
+		var rows [][]string
+		var nested []string
+		for _, key := range v.keys {
+			if isComposite(v.values[key]) && depth < maxReportDepth {
+				nested = append(nested, key)
+				continue
+			}
+			rows = append(rows, []string{key, formatCell(v.values[key])})
+		}
+		if len(rows) > 0 {
+			sb.WriteString(markdownTable([]string{"Поле", "Значение"}, rows))
+		}
+		for _, key := range nested {
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", depth+3), escapeInline(key)))
+			renderValue(sb, v.values[key], depth+1)
+		}
+	default:
+		sb.WriteString(escapeInline(formatCell(v)) + "\n")
+	}
+}
+
+// tableColumns возвращает объединение полей, если все элементы массива - объекты
+func tableColumns(items []interface{}) ([]string, bool) {
+	if len(items) == 0 {
+		return nil, false
+	}
+	var columns []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		obj, ok := item.(*orderedObject)
+		if !ok {
+			return nil, false
+		}
+		for _, key := range obj.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns, len(columns) > 0
+}
+
+// markdownTable строит таблицу с выравниванием столбцов по числу символов (не байт),
+// чтобы кириллица не сбивала колонки в исходном тексте отчета
+func markdownTable(header []string, rows [][]string) string {
+	cells := make([][]string, 0, len(rows)+1)
+	cells = append(cells, header)
+	cells = append(cells, rows...)
+	widths := make([]int, len(header))
+	for r, row := range cells {
+		for i, cell := range row {
+			cell = escapeCell(cell)
+			cells[r][i] = cell
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell), 3)
+		}
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		sb.WriteString("|")
+		for i, cell := range row {
+			sb.WriteString(" " + cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)) + " |")
+		}
+		sb.WriteString("\n")
+	}
+	writeRow(cells[0])
+	sb.WriteString("|")
+	for _, width := range widths {
+		sb.WriteString(" " + strings.Repeat("-", width) + " |")
+	}
+	sb.WriteString("\n")
+	for _, row := range cells[1:] {
+		writeRow(row)
+	}
+	return sb.String()
+}
+
+// escapeCell экранирует "|" и переводы строк, которые ломают строку таблицы
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+// escapeInline убирает переводы строк из значения, выводимого в строке списка
+func escapeInline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// quoteMarkdown оформляет многострочный текст цитатой
+func quoteMarkdown(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isComposite(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, *orderedObject:
+		return true
+	}
+	return false
+}
+
+// formatCell - значение ячейки: строки как есть, числа без экспоненты,
+// вложенные объекты и массивы - компактным JSON
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "да"
+		}
+		return "нет"
+	}
+	data, err := json.Marshal(toPlain(value))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// orderedObject - JSON-объект с порядком полей из исходного текста
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// decodeOrdered разбирает JSON-значение, сохраняя порядок полей объектов
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	dec.UseNumber()
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := &orderedObject{values: make(map[string]interface{})}
+			for dec.More() {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := keyToken.(string)
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, exists := obj.values[key]; !exists {
+					obj.keys = append(obj.keys, key)
+				}
+				obj.values[key] = value
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			items := []interface{}{}
+			for dec.More() {
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, value)
+			}
+			_, err := dec.Token()
+			return items, err
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", t)
+	}
+	return token, nil
+}
+
+// toPlain переводит разобранное значение обратно в типы encoding/json для вывода
+func toPlain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *orderedObject:
+		plain := make(map[string]interface{}, len(v.values))
+		for key, field := range v.values {
+			plain[key] = toPlain(field)
+		}
+		return plain
+	case []interface{}:
+		plain := make([]interface{}, len(v))
+		for i, item := range v {
+			plain[i] = toPlain(item)
+		}
+		return plain
+	}
+	return value
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "..."
+}
@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+var updateGolden = flag.Bool("update", false, "перезаписать golden-файлы в testdata")
+
+// checkGolden сравнивает got с testdata/<name>; с -update перезаписывает файл
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden file: %v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch (run go test -update to accept):\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestRenderTaskReportGolden(t *testing.T) {
+	started := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		result    *TaskResult
+		steps     []transcriptEntry
+		artifacts []reportArtifact
+	}{
+		{
+			name: "task_report_success.golden",
+			result: &TaskResult{
+				Task:          "Найди три чайника дешевле 3000 ₽",
+				Success:       true,
+				Summary:       "Нашел три чайника.\nСамый дешевый - Scarlett.",
+				ExtractedData: json.RawMessage(`{"items":[{"name":"Scarlett | SC-EK","price":1490},{"name":"Polaris","price":2190,"extra":{"color":"white"}}],"source":{"site":"market","pages":2}}`),
+				Duration:      83 * time.Second,
+				Usage:         ai.TokenUsage{Prompt: 1200, Completion: 300},
+				SettleTime:    2500 * time.Millisecond,
+				Metadata:      &RunMetadata{Model: "gpt-4o", StartedAt: started},
+				FinalPage: &FinalPage{
+					URL:      "https://market.example/search?q=чайник",
+					Title:    "Чайники [каталог]",
+					Headings: []browser.Heading{{Level: "h1", Text: "Чайники"}},
+				},
+			},
+			steps: []transcriptEntry{
+				{Action: "navigate", URL: "https://market.example", Status: "ok"},
+				{Action: "type", Text: "чайник", Selector: "#search", Status: "ok"},
+				{Action: "scroll", Status: "ok"},
+				{Action: "scroll", Status: "ok"},
+				{Action: "scroll", Status: "ok"},
+				{Action: "click", Text: "Далее", Status: "error", Error: "element not found"},
+			},
+			artifacts: relativeArtifacts("/runs/reports", []reportArtifact{
+				{Label: "Журнал задачи", Path: "/runs/transcripts/20240131-120000.jsonl"},
+				{Label: "Отчет по большому результату", Path: ""},
+				{Label: "check.pdf", Path: "/runs/reports/pdf/my check.pdf"},
+			}),
+		},
+		{
+			name: "task_report_failure.golden",
+			result: &TaskResult{
+				Task:     "Оформи заказ",
+				Error:    "too many consecutive errors: page changed",
+				Duration: 12 * time.Second,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkGolden(t, tt.name, renderTaskReport(tt.result, tt.steps, tt.artifacts))
+		})
+	}
+}
+
+func TestCondensedTaskReportGolden(t *testing.T) {
+	result := &TaskResult{
+		Success:    true,
+		Summary:    "Готово: найдено 3 товара\nподробности в отчете",
+		Duration:   61 * time.Second,
+		Usage:      ai.TokenUsage{Prompt: 100, Completion: 20},
+		SettleTime: 1500 * time.Millisecond,
+	}
+	checkGolden(t, "task_report_condensed.golden", condensedTaskReport(result, 7, "reports/20240131-120000-task.md"))
+}
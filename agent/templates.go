@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// templateRegex находит шаблоны вида {{today}}, {{now+2h}}, {{weekday + 1d}}
+var templateRegex = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// templateExprRegex разбирает выражение шаблона: имя и необязательное смещение со знаком
+// и единицей (m - минуты, h - часы, d - дни, w - недели, M - месяцы; без единицы - дни)
+var templateExprRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(?:([+-])\s*(\d+)\s*([mhdwMHDW]?))?$`)
+
+// templateNameRegex - имя в начале выражения, которое не разобралось целиком
+var templateNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// errUnknownTemplate - в шаблоне не дата и не переменная задачи: такой текст
+// ({{item.name}} со страницы, {{x}} из задачи) остается как есть
+var errUnknownTemplate = errors.New("неизвестный шаблон")
+
+// Локали форматирования дат в шаблонах
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+)
+
+// templateLayouts - форматы даты, даты со временем и времени для локали
+var templateLayouts = map[string][3]string{
+	LocaleRU: {"02.01.2006", "02.01.2006 15:04", "15:04"},
+	LocaleEN: {"01/02/2006", "01/02/2006 3:04 PM", "3:04 PM"},
+}
+
+// expandTemplates подставляет в строку значения шаблонов {{...}}: даты и время
+// относительно now (today, tomorrow, yesterday, date, now, time, weekday со смещением)
+// и переменные задачи из vars. Неверное смещение или смещение у переменной - ошибка;
+// неизвестное имя остается в строке как есть с предупреждением.
+func expandTemplates(s string, now time.Time, locale string, vars map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	if _, ok := templateLayouts[locale]; !ok {
+		locale = LocaleRU
+	}
+
+	var firstErr error
+	result := templateRegex.ReplaceAllStringFunc(s, func(match string) string {
+		value, err := evalTemplate(strings.TrimSpace(match[2:len(match)-2]), now, locale, vars)
+		if errors.Is(err, errUnknownTemplate) {
+			fmt.Printf("⚠️  Шаблон %s не распознан и оставлен как есть\n", match)
+			return match
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("ошибка в шаблоне %s: %w", match, err)
+			}
+			return match
+		}
+		return value
+	})
+	if firstErr != nil {
+		return s, firstErr
+	}
+	return result, nil
+}
+
+func evalTemplate(expr string, now time.Time, locale string, vars map[string]string) (string, error) {
+	parts := templateExprRegex.FindStringSubmatch(expr)
+	if parts == nil {
+		name := templateNameRegex.FindString(expr)
+		if _, isVar := vars[name]; !isTimeTemplate(strings.ToLower(name)) && !isVar {
+			return "", errUnknownTemplate
+		}
+		return "", fmt.Errorf("неверное выражение %q (ожидается, например, today, tomorrow, now+2h, today+1M, weekday)", expr)
+	}
+	// M - месяцы, m - минуты; остальные единицы без учета регистра
+	name, sign, amount, unit := strings.ToLower(parts[1]), parts[2], parts[3], parts[4]
+	if unit != "M" {
+		unit = strings.ToLower(unit)
+	}
+
+	if !isTimeTemplate(name) {
+		value, ok := vars[parts[1]]
+		if !ok {
+			value, ok = vars[name]
+		}
+		if !ok {
+			return "", errUnknownTemplate
+		}
+		if sign != "" {
+			return "", fmt.Errorf("смещение допустимо только для дат и времени, а не для переменной %q", name)
+		}
+		return value, nil
+	}
+
+	t := now
+	switch name {
+	case "tomorrow":
+		t = t.AddDate(0, 0, 1)
+	case "yesterday":
+		t = t.AddDate(0, 0, -1)
+	}
+	if sign != "" {
+		n, err := strconv.Atoi(amount)
+		if err != nil {
+			return "", fmt.Errorf("неверное смещение %q", amount)
+		}
+		if sign == "-" {
+			n = -n
+		}
+		switch unit {
+		case "m":
+			t = t.Add(time.Duration(n) * time.Minute)
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "M":
+			t = addMonths(t, n)
+		default:
+			t = t.AddDate(0, 0, n)
+		}
+	}
+
+	layouts := templateLayouts[locale]
+	switch name {
+	case "now":
+		return t.Format(layouts[1]), nil
+	case "time":
+		return t.Format(layouts[2]), nil
+	case "weekday":
+		return ai.WeekdayName(t.Weekday(), locale), nil
+	default:
+		return t.Format(layouts[0]), nil
+	}
+}
+
+// addMonths сдвигает дату на n месяцев, не перескакивая в следующий месяц:
+// 31 января + 1 месяц - 28 (29) февраля, а не 3 марта, как у AddDate
+func addMonths(t time.Time, n int) time.Time {
+	shifted := t.AddDate(0, n, 0)
+	if shifted.Day() != t.Day() {
+		// День вышел за конец месяца - последний день нужного месяца
+		shifted = shifted.AddDate(0, 0, -shifted.Day())
+	}
+	return shifted
+}
+
+func isTimeTemplate(name string) bool {
+	switch name {
+	case "now", "today", "tomorrow", "yesterday", "date", "time", "weekday":
+		return true
+	}
+	return false
+}
+
+// SetLocale задает локаль форматирования дат в шаблонах ({{today}} -> 17.10.2026 или 10/17/2026)
+func (a *Agent) SetLocale(locale string) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if _, ok := templateLayouts[locale]; ok {
+		a.locale = locale
+	}
+}
+
+// expandDecisionTemplates подставляет шаблоны в значение и URL решения модели
+func (a *Agent) expandDecisionTemplates(decision *ai.Decision) error {
+	now := time.Now()
+	value, err := expandTemplates(decision.Value, now, a.locale, a.vars)
+	if err != nil {
+		return err
+	}
+	url, err := expandTemplates(decision.URL, now, a.locale, a.vars)
+	if err != nil {
+		return err
+	}
+	if value != decision.Value {
+		fmt.Printf("🧩 Шаблон: %s -> %s\n", decision.Value, value)
+	}
+	decision.Value, decision.URL = value, url
+	return nil
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	// Суббота, 31 января 2026, 23:30
+	now := time.Date(2026, time.January, 31, 23, 30, 0, 0, time.UTC)
+	vars := map[string]string{"selected_text": "Чайник"}
+	tests := []struct {
+		name    string
+		in      string
+		locale  string
+		want    string
+		wantErr string
+	}{
+		{"no templates", "купить чайник", LocaleRU, "купить чайник", ""},
+		{"today", "{{today}}", LocaleRU, "31.01.2026", ""},
+		{"today en", "{{today}}", LocaleEN, "01/31/2026", ""},
+		{"tomorrow crosses month", "{{tomorrow}}", LocaleRU, "01.02.2026", ""},
+		{"yesterday", "{{ yesterday }}", LocaleRU, "30.01.2026", ""},
+		{"minutes", "{{now+45m}}", LocaleRU, "01.02.2026 00:15", ""},
+		{"hours", "{{time-2h}}", LocaleRU, "21:30", ""},
+		{"hours en", "{{time+1h}}", LocaleEN, "12:30 AM", ""},
+		{"days without unit", "{{today + 3}}", LocaleRU, "03.02.2026", ""},
+		{"weeks", "{{today-1w}}", LocaleRU, "24.01.2026", ""},
+		{"months", "{{today+1M}}", LocaleRU, "28.02.2026", ""},
+		{"months back", "{{date-2M}}", LocaleRU, "30.11.2025", ""},
+		{"upper-case units", "{{now+1H}} {{today+1D}}", LocaleRU, "01.02.2026 00:30 01.02.2026", ""},
+		{"weekday", "{{weekday}}", LocaleRU, "суббота", ""},
+		{"weekday en offset", "{{weekday+1d}}", LocaleEN, "Sunday", ""},
+		{"unknown locale", "{{today}}", "de", "31.01.2026", ""},
+		{"variable", "найти {{selected_text}}", LocaleRU, "найти Чайник", ""},
+		{"unknown name kept", "{{x}} и {{today}}", LocaleRU, "{{x}} и 31.01.2026", ""},
+		{"page template kept", "{{ item.name }}", LocaleRU, "{{ item.name }}", ""},
+		{"bad unit", "{{now+2x}}", LocaleRU, "", "неверное выражение"},
+		{"missing amount", "{{today+}}", LocaleRU, "", "неверное выражение"},
+		{"offset on variable", "{{selected_text+1d}}", LocaleRU, "", "смещение допустимо"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandTemplates(tt.in, now, tt.locale, vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expandTemplates(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expandTemplates(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
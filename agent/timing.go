@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/console"
+)
+
+// defaultSlowDecision - ответ модели дольше этого порога отмечается в консоли
+const defaultSlowDecision = 20 * time.Second
+
+// Фазы итерации: анализ страницы, решение модели, выполнение действия
+const (
+	phaseObserve = "obs"
+	phaseDecide  = "llm"
+	phaseAct     = "act"
+)
+
+var phaseOrder = []string{phaseObserve, phaseDecide, phaseAct}
+
+// phaseTimings - длительность фаз одной итерации в миллисекундах. Ожидание ответа
+// пользователя и паузы перед повтором после ошибки в фазы не входят.
+type phaseTimings struct {
+	ObserveMs int64 `json:"observe_ms"`
+	DecideMs  int64 `json:"decide_ms"`
+	ActMs     int64 `json:"act_ms"`
+}
+
+func (t phaseTimings) String() string {
+	return fmt.Sprintf("[obs %.1fs | llm %.1fs | act %.1fs]",
+		float64(t.ObserveMs)/1000, float64(t.DecideMs)/1000, float64(t.ActMs)/1000)
+}
+
+// SetVerbose включает вывод длительности фаз после каждого действия
+func (a *Agent) SetVerbose(enabled bool) {
+	a.verbose = enabled
+}
+
+// SetSlowDecisionThreshold задает порог, после которого ответ модели отмечается как медленный
+func (a *Agent) SetSlowDecisionThreshold(d time.Duration) {
+	if d > 0 {
+		a.slowDecision = d
+	}
+}
+
+// startIteration отмечает начало итерации: до запроса к модели идет анализ страницы
+func (a *Agent) startIteration() {
+	a.timings = phaseTimings{}
+	a.phaseStart = time.Now()
+}
+
+// startDecision завершает фазу анализа страницы и начинает ожидание модели
+// (в терминале на это время показывается строка статуса)
+func (a *Agent) startDecision() {
+	a.timings.ObserveMs = time.Since(a.phaseStart).Milliseconds()
+	a.phaseStart = time.Now()
+	a.stopStatus = console.StartStatus("думаю...")
+}
+
+// finishDecision завершает ожидание модели и отмечает медленный ответ
+func (a *Agent) finishDecision() {
+	if a.stopStatus != nil {
+		a.stopStatus()
+		a.stopStatus = nil
+	}
+	elapsed := time.Since(a.phaseStart)
+	a.timings.DecideMs = elapsed.Milliseconds()
+	if elapsed > a.slowDecision {
+		fmt.Printf("🐌 Медленный ответ модели: %.1fs (порог %v)\n", elapsed.Seconds(), a.slowDecision)
+	}
+}
+
+// finishIteration сохраняет длительности фаз в статистику сессии и в verbose-режиме
+// выводит их в консоль
+func (a *Agent) finishIteration() {
+	if a.timings == (phaseTimings{}) {
+		return
+	}
+	if a.phaseSamples == nil {
+		a.phaseSamples = make(map[string][]time.Duration)
+	}
+	a.phaseSamples[phaseObserve] = append(a.phaseSamples[phaseObserve], time.Duration(a.timings.ObserveMs)*time.Millisecond)
+	a.phaseSamples[phaseDecide] = append(a.phaseSamples[phaseDecide], time.Duration(a.timings.DecideMs)*time.Millisecond)
+	a.phaseSamples[phaseAct] = append(a.phaseSamples[phaseAct], time.Duration(a.timings.ActMs)*time.Millisecond)
+	if a.verbose {
+		fmt.Printf("   ⏱️  %s\n", a.timings)
+	}
+	a.timings = phaseTimings{}
+}
+
+// PhaseStats возвращает P50/P95 длительности фаз итераций за сессию
+// (по всем задачам с момента запуска) и нагрузку на браузер: на слабом
+// компьютере по ней видно, что замедление вызвано Chrome
+func (a *Agent) PhaseStats() string {
+	if len(a.phaseSamples[phaseDecide]) == 0 {
+		return "Итераций в этой сессии еще не было\n" + a.browser.LoadStats().String()
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Итераций: %d\n", len(a.phaseSamples[phaseDecide])))
+	for _, phase := range phaseOrder {
+		samples := append([]time.Duration(nil), a.phaseSamples[phase]...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		sb.WriteString(fmt.Sprintf("  %-3s  P50 %6.1fs  P95 %6.1fs  max %6.1fs\n", phase,
+			percentile(samples, 50).Seconds(), percentile(samples, 95).Seconds(), samples[len(samples)-1].Seconds()))
+	}
+	sb.WriteString(a.browser.LoadStats().String())
+	return sb.String()
+}
+
+// percentile возвращает перцентиль p отсортированной выборки (метод ближайшего ранга)
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/buildinfo"
+)
+
+// RunMetadata описывает сборку и настройки, с которыми выполнялась задача
+type RunMetadata struct {
+	Agent         buildinfo.Info `json:"agent"`
+	ChromeVersion string         `json:"chrome_version,omitempty"`
+	Model         string         `json:"model"`
+	Provider      string         `json:"provider"`
+	Profile       string         `json:"profile"`
+	ProfileDir    string         `json:"profile_directory"`
+	Headless      bool           `json:"headless"`
+	LowPower      bool           `json:"low_power,omitempty"` // режим низкой мощности браузера на начало задачи
+	SafeMode      bool           `json:"safe_mode"`
+	MaxIterations int            `json:"max_iterations"`
+	MaxErrors     int            `json:"max_errors"`
+	SubAgent      string         `json:"sub_agent,omitempty"` // специализированный агент, принявший задачу
+	StartedAt     time.Time      `json:"started_at"`
+}
+
+// Metadata собирает метаданные текущего запуска
+func (a *Agent) Metadata() RunMetadata {
+	if a.chromeVersion == "" {
+		if version, err := a.browser.Version(); err == nil {
+			a.chromeVersion = version
+		}
+	}
+	return RunMetadata{
+		Agent:         buildinfo.Get(),
+		ChromeVersion: a.chromeVersion,
+		Model:         a.aiClient.Model(),
+		Provider:      a.aiClient.Provider(),
+		Profile:       a.browser.Profile(),
+		ProfileDir:    a.browser.ProfileDirectory(),
+		Headless:      a.browser.Headless(),
+		LowPower:      a.browser.LowPower(),
+		SafeMode:      a.safeMode,
+		MaxIterations: a.maxIterations,
+		MaxErrors:     a.maxErrors,
+		SubAgent:      string(a.subAgentType),
+		StartedAt:     time.Now(),
+	}
+}
+
+// transcriptEntry - строка журнала задачи (JSONL)
+type transcriptEntry struct {
+	Type       string        `json:"type"` // metadata, action, event (сбой страницы и т.п.) или result
+	Time       time.Time     `json:"time"`
+	Task       string        `json:"task,omitempty"`
+	Metadata   *RunMetadata  `json:"metadata,omitempty"`
+	Iteration  int           `json:"iteration,omitempty"`
+	Action     string        `json:"action,omitempty"`
+	Text       string        `json:"text,omitempty"`
+	Selector   string        `json:"selector,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	Reasoning  string        `json:"reasoning,omitempty"`
+	Status     string        `json:"status,omitempty"`
+	Adaptation string        `json:"adaptation,omitempty"` // примененная адаптация после ошибки: timeout, scroll_into_view, full_extraction, frame_gone, delay
+	Recovered  bool          `json:"recovered,omitempty"`  // повтор после адаптации прошел успешно
+	Success    bool          `json:"success,omitempty"`
+	Summary    string        `json:"summary,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Scope      string        `json:"scope,omitempty"`   // серия подтверждений: действие|элемент|страница
+	Count      int           `json:"count,omitempty"`   // одобрено действий в серии
+	Used       int           `json:"used,omitempty"`    // выполнено действий серии
+	Timings    *phaseTimings `json:"timings,omitempty"` // длительность фаз итерации: анализ, модель, действие
+}
+
+// SetTranscriptDir включает запись журнала каждой задачи в JSONL-файл в каталоге dir
+// (пустая строка - выключено). Первая строка журнала содержит метаданные запуска.
+func (a *Agent) SetTranscriptDir(dir string) {
+	a.transcriptDir = dir
+}
+
+// startTranscript собирает метаданные задачи и открывает журнал
+func (a *Agent) startTranscript(task string) {
+	meta := a.Metadata()
+	a.runMetadata = &meta
+	a.steps = nil
+	a.transcriptPath = ""
+	a.usageStart = a.aiClient.Usage()
+	a.settleTime = 0
+
+	if a.transcriptDir == "" {
+		return
+	}
+	if err := os.MkdirAll(a.transcriptDir, 0755); err != nil {
+		fmt.Printf("⚠️  Не удалось создать каталог журналов: %v\n", err)
+		return
+	}
+	f, err := createRunFile(a.transcriptDir, meta.StartedAt, ".jsonl")
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось создать журнал задачи: %v\n", err)
+		return
+	}
+	a.transcript = f
+	a.writeTranscript(transcriptEntry{Type: "metadata", Task: task, Metadata: &meta})
+}
+
+// recordAction записывает действие и его итог (ok, error, blocked, canceled ...) в журнал
+func (a *Agent) recordAction(decision *ai.Decision, status string, actionErr error) {
+	a.recordAdaptedAction(decision, status, actionErr, adaptation{})
+}
+
+// recordAdaptedAction записывает действие вместе с адаптацией, примененной после ошибки,
+// чтобы по журналам можно было оценить, какие адаптации помогают
+func (a *Agent) recordAdaptedAction(decision *ai.Decision, status string, actionErr error, adapt adaptation) {
+	entry := transcriptEntry{
+		Type:       "action",
+		Iteration:  a.iteration,
+		Action:     decision.Action,
+		Text:       decision.Text,
+		Selector:   decision.Selector,
+		URL:        decision.URL,
+		Reasoning:  decision.Reasoning,
+		Status:     status,
+		Adaptation: adapt.Kind,
+		Recovered:  adapt.Recovered,
+	}
+	if a.timings != (phaseTimings{}) {
+		timings := a.timings
+		entry.Timings = &timings
+	}
+	if actionErr != nil {
+		entry.Error = actionErr.Error()
+	}
+	a.steps = append(a.steps, entry)
+	a.writeTranscript(entry)
+}
+
+// recordPromptDowngrade отмечает в журнале решение, для которого промпт пришлось
+// сократить из-за переполнения контекста модели
+func (a *Agent) recordPromptDowngrade(decisionErr error) {
+	profile := a.aiClient.PromptDowngrade()
+	if profile == "" {
+		return
+	}
+	entry := transcriptEntry{Type: "event", Iteration: a.iteration, Action: "prompt_downgrade", Text: profile, Status: "ok"}
+	if decisionErr != nil {
+		entry.Status = "error"
+		entry.Error = decisionErr.Error()
+	}
+	a.writeTranscript(entry)
+}
+
+// finishTranscript записывает итог задачи и закрывает журнал
+func (a *Agent) finishTranscript(taskErr error) {
+	if a.transcript == nil {
+		return
+	}
+	entry := transcriptEntry{Type: "result", Success: taskErr == nil && a.completed, Summary: a.summary}
+	if taskErr != nil {
+		entry.Error = taskErr.Error()
+	}
+	a.writeTranscript(entry)
+
+	a.transcriptPath = a.transcript.Name()
+	fmt.Printf("📄 Журнал задачи: %s\n", a.transcriptPath)
+	a.transcript.Close()
+	a.transcript = nil
+}
+
+func (a *Agent) writeTranscript(entry transcriptEntry) {
+	if a.transcript == nil {
+		return
+	}
+	entry.Time = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	a.transcript.Write(append(data, '\n'))
+}
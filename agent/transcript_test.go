@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/ai/aitest"
+)
+
+func TestTranscriptMetadataHeader(t *testing.T) {
+	b := newFixtureBrowser(t, `<button>Найти</button>`)
+	a := NewAgent(b, ai.NewClientWithProvider(aitest.NewScriptedProvider(), "gpt-4o"))
+	a.SetSafeMode(true)
+	a.SetTranscriptDir(t.TempDir())
+
+	a.startTranscript("найти чайник")
+	a.recordAction(&ai.Decision{Action: "click", Text: "Найти"}, "ok", nil)
+	a.finishTranscript(errors.New("прервано"))
+
+	entries := readTranscript(t, a.transcriptPath)
+	if len(entries) != 3 || entries[0].Type != "metadata" || entries[1].Type != "action" || entries[2].Type != "result" {
+		t.Fatalf("entries = %+v, want metadata, action, result", entries)
+	}
+
+	meta := entries[0].Metadata
+	if meta == nil || entries[0].Task != "найти чайник" {
+		t.Fatalf("metadata entry = %+v", entries[0])
+	}
+	missing := map[string]bool{
+		"agent version":  meta.Agent.Version == "",
+		"go version":     meta.Agent.GoVersion == "",
+		"chrome version": meta.ChromeVersion == "",
+		"model":          meta.Model != "gpt-4o",
+		"provider":       meta.Provider == "",
+		"headless":       !meta.Headless,
+		"safe mode":      !meta.SafeMode,
+		"max iterations": meta.MaxIterations <= 0,
+		"max errors":     meta.MaxErrors <= 0,
+		"started at":     meta.StartedAt.IsZero(),
+	}
+	for field, bad := range missing {
+		if bad {
+			t.Errorf("metadata %s missing or wrong: %+v", field, meta)
+		}
+	}
+	if entries[2].Success || entries[2].Error != "прервано" {
+		t.Errorf("result entry = %+v", entries[2])
+	}
+}
+
+// readTranscript читает записи журнала задачи
+func readTranscript(t *testing.T, path string) []transcriptEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []transcriptEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry transcriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
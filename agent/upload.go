@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetUploadDirs задает каталоги, файлы из которых можно загружать на сайты (UPLOAD_DIRS),
+// в дополнение к файлам задачи и каталогам загрузок и PDF
+func (a *Agent) SetUploadDirs(dirs []string) {
+	a.uploadDirs = dirs
+}
+
+// ParseUploadDirs разбирает список каталогов из UPLOAD_DIRS (через запятую или разделитель путей ОС)
+func ParseUploadDirs(value string) []string {
+	var dirs []string
+	for _, dir := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == os.PathListSeparator
+	}) {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// uploadPath проверяет путь к файлу для загрузки на сайт. Путь предлагает модель, и
+// текст страницы может подтолкнуть ее отправить в форму ~/.ssh/id_rsa или .env, поэтому
+// загружать можно только файлы задачи (как в read_document) и файлы из UPLOAD_DIRS.
+// Путь должен быть абсолютным и без "..".
+func (a *Agent) uploadPath(value string) (string, error) {
+	if !filepath.IsAbs(value) {
+		return "", fmt.Errorf("путь к файлу для загрузки должен быть абсолютным: %s", value)
+	}
+	for _, part := range strings.Split(filepath.ToSlash(value), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("путь к файлу для загрузки не должен содержать '..': %s", value)
+		}
+	}
+	path, err := resolveLocalPath(filepath.Clean(value))
+	if err != nil {
+		return "", fmt.Errorf("файл для загрузки недоступен: %s (%v)", value, err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("файл для загрузки недоступен: %s (не обычный файл)", value)
+	}
+	if !a.isTaskFile(path, a.uploadDirs...) {
+		return "", fmt.Errorf("файл %s нельзя загрузить: upload принимает только файлы, скачанные или сохраненные этой задачей (download, save_pdf), и файлы из каталогов UPLOAD_DIRS", value)
+	}
+	return path, nil
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+func TestUploadPath(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel string) string {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	downloaded := write("downloads/invoice.pdf")
+	resume := write("uploads/cv.pdf")
+	key := write("home/.ssh/id_rsa")
+	env := write(".env")
+	if err := os.Symlink(key, filepath.Join(root, "uploads", "key.pdf")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	b := &browser.Browser{}
+	browser.WithDownloadDir(filepath.Join(root, "downloads"))(b)
+	a := &Agent{browser: b}
+	a.SetUploadDirs([]string{filepath.Join(root, "uploads")})
+
+	for _, path := range []string{downloaded, resume} {
+		if _, err := a.uploadPath(path); err != nil {
+			t.Errorf("uploadPath(%s) = %v, want allowed", path, err)
+		}
+	}
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{key, "нельзя загрузить"},
+		{env, "нельзя загрузить"},
+		{filepath.Join(root, "uploads", "key.pdf"), "нельзя загрузить"},
+		{filepath.Join(root, "uploads") + "/../.env", "'..'"},
+		{filepath.Join(root, "uploads"), "не обычный файл"},
+		{filepath.Join(root, "uploads", "missing.pdf"), "недоступен"},
+		{"uploads/cv.pdf", "абсолютным"},
+		{".env", "абсолютным"},
+	} {
+		if _, err := a.uploadPath(tt.path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("uploadPath(%s) = %v, want error with %q", tt.path, err, tt.want)
+		}
+	}
+
+	// Без UPLOAD_DIRS доступны только файлы задачи
+	a.SetUploadDirs(nil)
+	if _, err := a.uploadPath(resume); err == nil {
+		t.Error("file outside the task's files must be refused without UPLOAD_DIRS")
+	}
+}
+
+func TestUploadNeedsConfirmation(t *testing.T) {
+	a := &Agent{}
+	for _, decision := range []ai.Decision{
+		{Action: "upload", Value: "/tmp/cv.pdf"},
+		{Action: "upload", Value: "/tmp/cv.pdf", Reasoning: "remove the old file first"},
+	} {
+		if got := a.destructiveSeverity(&decision); got != SeverityMedium {
+			t.Errorf("destructiveSeverity(%+v) = %q, want %q", decision, got, SeverityMedium)
+		}
+	}
+	pay := ai.Decision{Action: "upload", Value: "/tmp/receipt.pdf", Reasoning: "оплатить счет"}
+	if got := a.destructiveSeverity(&pay); got != SeverityHigh {
+		t.Errorf("destructiveSeverity(%+v) = %q, want %q", pay, got, SeverityHigh)
+	}
+}
+
+func TestParseUploadDirs(t *testing.T) {
+	got := ParseUploadDirs(" /srv/files , /home/user/docs" + string(os.PathListSeparator) + "/tmp/out,")
+	want := []string{"/srv/files", "/home/user/docs", "/tmp/out"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseUploadDirs = %q, want %q", got, want)
+	}
+	if got := ParseUploadDirs(""); got != nil {
+		t.Errorf("ParseUploadDirs(\"\") = %q, want nil", got)
+	}
+}
@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// Значения наблюдения по умолчанию
+const (
+	defaultWatchInterval = 10 * time.Minute
+	defaultWatchCooldown = time.Hour
+	defaultWatchBudget   = 1000 // токенов на одну проверку условия
+	minWatchInterval     = 30 * time.Second
+)
+
+// WatchSpec - наблюдение за страницей: агент раз в Interval открывает URL, дешево
+// проверяет Condition и, когда условие становится выполненным, уведомляет
+// пользователя и выполняет Task (если задана). Повторное уведомление - не раньше Cooldown.
+type WatchSpec struct {
+	URL         string
+	Condition   string
+	Task        string
+	Interval    time.Duration
+	Cooldown    time.Duration
+	TokenBudget int
+}
+
+// ParseWatchCommand разбирает аргументы команды watch:
+//
+//	interval=10m url=https://... cooldown=1h budget=800 <условие> => <задача>
+//
+// Параметры идут перед условием в любом порядке, задача после "=>" необязательна.
+func ParseWatchCommand(args string) (WatchSpec, error) {
+	spec := WatchSpec{Interval: defaultWatchInterval, Cooldown: defaultWatchCooldown, TokenBudget: defaultWatchBudget}
+	fields := strings.Fields(args)
+	i := 0
+	for ; i < len(fields); i++ {
+		key, value, ok := strings.Cut(fields[i], "=")
+		if !ok || value == "" {
+			break
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "interval":
+			spec.Interval, err = time.ParseDuration(value)
+		case "cooldown":
+			spec.Cooldown, err = time.ParseDuration(value)
+		case "url":
+			spec.URL = value
+		case "budget":
+			spec.TokenBudget, err = strconv.Atoi(value)
+		default:
+			return spec, fmt.Errorf("неизвестный параметр %q (доступны interval, url, cooldown, budget)", key)
+		}
+		if err != nil {
+			return spec, fmt.Errorf("некорректное значение %s: %v", fields[i], err)
+		}
+	}
+
+	rest := strings.Join(fields[i:], " ")
+	condition, task, _ := strings.Cut(rest, "=>")
+	spec.Condition = strings.TrimSpace(condition)
+	spec.Task = strings.TrimSpace(task)
+	switch {
+	case spec.Condition == "":
+		return spec, fmt.Errorf("не указано условие наблюдения")
+	case spec.Interval < minWatchInterval:
+		return spec, fmt.Errorf("интервал %v слишком короткий (минимум %v)", spec.Interval, minWatchInterval)
+	case spec.Cooldown < 0 || spec.TokenBudget <= 0:
+		return spec, fmt.Errorf("cooldown и budget должны быть положительными")
+	}
+	return spec, nil
+}
+
+// WatchState - состояние наблюдения между проверками и перезапусками агента
+type WatchState struct {
+	URL          string    `json:"url"`
+	Condition    string    `json:"condition"`
+	LastCheck    time.Time `json:"last_check"`
+	LastValue    *bool     `json:"last_value,omitempty"` // nil - условие еще не проверялось
+	LastEvidence string    `json:"last_evidence,omitempty"`
+	LastAlert    time.Time `json:"last_alert"`
+	Checks       int       `json:"checks"`
+}
+
+// key - наблюдение определяется страницей и условием
+func (s WatchSpec) key() string {
+	sum := sha256.Sum256([]byte(s.URL + "\n" + s.Condition))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SetWatchStatePath задает файл состояния наблюдений (пустой путь - состояние не сохраняется)
+func (a *Agent) SetWatchStatePath(path string) {
+	a.watchStatePath = path
+}
+
+// Watch наблюдает за страницей до отмены ctx. Каждая проверка - переход на URL,
+// краткий текст страницы и одна короткая проверка условия моделью в пределах
+// TokenBudget. Состояние сохраняется после каждой проверки, поэтому после
+// перезапуска наблюдение продолжается по расписанию, а уже отправленное
+// уведомление не повторяется до конца cooldown.
+func (a *Agent) Watch(ctx context.Context, spec WatchSpec) error {
+	if spec.URL == "" {
+		url, err := a.browser.GetCurrentURL()
+		if err != nil {
+			return fmt.Errorf("не указан url наблюдения и не удалось получить текущий: %w", err)
+		}
+		spec.URL = url
+	}
+
+	states := a.loadWatchStates()
+	key := spec.key()
+	state := states[key]
+	state.URL, state.Condition = spec.URL, spec.Condition
+
+	fmt.Printf("👀 Наблюдение: %s\n", spec.Condition)
+	fmt.Printf("   Страница: %s, интервал %v, cooldown %v, бюджет проверки %d токенов\n", spec.URL, spec.Interval, spec.Cooldown, spec.TokenBudget)
+	if spec.Task != "" {
+		fmt.Printf("   Задача при срабатывании: %s\n", spec.Task)
+	}
+	fmt.Println("   Остановка - Ctrl+C, состояние сохраняется между запусками")
+
+	// После перезапуска первая проверка - по расписанию прошлой сессии
+	wait := time.Duration(0)
+	if !state.LastCheck.IsZero() {
+		wait = max(spec.Interval-time.Since(state.LastCheck), 0)
+		fmt.Printf("   Последняя проверка: %s (условие: %s)\n", state.LastCheck.Format("02.01 15:04"), formatWatchValue(state.LastValue))
+	}
+
+	for {
+		if wait > 0 {
+			fmt.Printf("⏳ Следующая проверка в %s\n", time.Now().Add(wait).Format("15:04:05"))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		wait = spec.Interval
+
+		check, err := a.checkWatchCondition(ctx, spec)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Сбой одной проверки (сеть, таймаут модели) наблюдение не останавливает
+			fmt.Printf("⚠️  Проверка не удалась: %v\n", err)
+			continue
+		}
+
+		previous := state.LastValue
+		state.LastCheck = time.Now()
+		state.LastValue = &check.Met
+		state.LastEvidence = check.Evidence
+		state.Checks++
+		fmt.Printf("🔎 [%s] условие: %s - %s (%d токенов)\n", state.LastCheck.Format("15:04:05"), formatWatchValue(&check.Met), check.Evidence, check.Tokens)
+		if check.Tokens > spec.TokenBudget {
+			fmt.Printf("⚠️  Проверка превысила бюджет: %d > %d токенов\n", check.Tokens, spec.TokenBudget)
+		}
+
+		flipped := check.Met && (previous == nil || !*previous)
+		if flipped && time.Since(state.LastAlert) < spec.Cooldown {
+			fmt.Printf("🔕 Условие снова выполнено, но уведомление уже было в %s (cooldown %v)\n", state.LastAlert.Format("15:04"), spec.Cooldown)
+			flipped = false
+		}
+		if flipped {
+			state.LastAlert = state.LastCheck
+		}
+		states[key] = state
+		a.saveWatchStates(states)
+
+		if !flipped {
+			continue
+		}
+		fmt.Printf("\a🔔 Условие выполнено: %s\n   %s\n   %s\n", spec.Condition, check.Evidence, spec.URL)
+		if spec.Task != "" {
+			taskCtx, cancel := WithTaskTimeout(ctx, 15*time.Minute)
+			if err := a.Execute(taskCtx, spec.Task); err != nil {
+				fmt.Printf("❌ Задача наблюдения не выполнена: %v\n", err)
+			}
+			cancel()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// checkWatchCondition открывает страницу и проверяет условие по ее краткому тексту
+func (a *Agent) checkWatchCondition(ctx context.Context, spec WatchSpec) (*ai.ConditionCheck, error) {
+	if err := a.browser.Navigate(spec.URL); err != nil {
+		return nil, err
+	}
+	summary, err := a.browser.GetPageSummary()
+	if err != nil {
+		return nil, err
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	return a.aiClient.CheckCondition(checkCtx, spec.Condition, summary, spec.TokenBudget)
+}
+
+func formatWatchValue(value *bool) string {
+	switch {
+	case value == nil:
+		return "неизвестно"
+	case *value:
+		return "выполнено"
+	}
+	return "не выполнено"
+}
+
+// loadWatchStates читает состояние всех наблюдений; поврежденный файл не мешает наблюдению
+func (a *Agent) loadWatchStates() map[string]WatchState {
+	states := make(map[string]WatchState)
+	if a.watchStatePath == "" {
+		return states
+	}
+	data, err := os.ReadFile(a.watchStatePath)
+	if err != nil {
+		return states
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		fmt.Printf("⚠️  Состояние наблюдений %s повреждено, начинаем заново: %v\n", a.watchStatePath, err)
+		return make(map[string]WatchState)
+	}
+	return states
+}
+
+func (a *Agent) saveWatchStates(states map[string]WatchState) {
+	if a.watchStatePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить состояние наблюдения: %v\n", err)
+		return
+	}
+	if dir := filepath.Dir(a.watchStatePath); dir != "" {
+		os.MkdirAll(dir, 0755)
+	}
+	// Пишем через временный файл, чтобы не повредить состояние при прерывании
+	tmpPath := a.watchStatePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить состояние наблюдения: %v\n", err)
+		return
+	}
+	if err := os.Rename(tmpPath, a.watchStatePath); err != nil {
+		fmt.Printf("⚠️  Не удалось сохранить состояние наблюдения: %v\n", err)
+	}
+}
@@ -0,0 +1,466 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ActionSpec - действие агента в реестре. Из реестра строятся список действий в
+// системных промптах, JSON-схема решения и схемы инструментов, а агент выполняет
+// только действия реестра, поэтому описание для модели и выполнение не расходятся.
+type ActionSpec struct {
+	Name     string
+	Summary  string   // что делает действие
+	Required []string // обязательные поля решения; "text|selector" - любое из них
+	Optional []string
+	Details  []string // подробности для полного промпта; строка с "* " - пример к предыдущей
+	Brief    string   // строка компактного промпта; пусто - действие там не предлагается
+	Clicks   bool     // действие кликает по элементу страницы: к нему применяются правила клика safe-mode
+	Unsafe   string   // действие меняет состояние сайта и всегда запрещено в safe-mode: причина для модели
+}
+
+// actionRegistry - все действия агента в порядке описания в системном промпте
+var actionRegistry = []ActionSpec{
+	{
+		Name: "navigate", Summary: "перейти на URL", Required: []string{"url"}, Optional: []string{"force_reload"},
+		Details: []string{
+			`Можешь использовать URL из списка links.href ИЛИ указать прямой URL (например, "https://mail.ru")`,
+			`Заполни: "url" (полный URL, например "https://mail.ru" или из списка links)`,
+			`Переход на уже открытую страницу пропускается; если перезагрузка действительно нужна, добавь "force_reload": true`,
+		},
+		Brief: `"url" (переход на открытую страницу пропускается, "force_reload": true - перезагрузить)`,
+	},
+	{
+		Name: "click", Summary: "кликнуть на элемент", Required: []string{"text|selector"}, Optional: []string{"frame", "repeat"}, Clicks: true,
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "text" (видимый текст из списка buttons или links)`,
+			`Доступна дополнительная информация о кнопках: aria-label, title, action, контекст, id, class`,
+			`Используй эту информацию, чтобы лучше понять назначение кнопки`,
+			`Или если text не работает: "selector" (CSS селектор)`,
+			`Кнопка внутри фрейма: добавь "frame" (из списка "Фреймы")`,
+			`Тот же клик сразу после успешного, пока страница не изменилась, пропускается как уже выполненный. Если повтор действительно нужен (кнопка "+" три раза), добавь "repeat": true`,
+		},
+		Brief: `"text" (текст кнопки/ссылки из списка) или "selector"`,
+	},
+	{
+		Name: "fill", Summary: "заполнить поле ввода", Required: []string{"text|selector", "value"}, Optional: []string{"frame"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "text" (placeholder, name, aria-label из списка inputs)`,
+			`ОБЯЗАТЕЛЬНО заполни: "value" (значение для ввода)`,
+			`Для полей поиска можно использовать общие термины: "искать", "search", "поиск"`,
+			`Или если text не работает: "selector" (CSS селектор) + "value"`,
+			`Поле или кнопка внутри фрейма (номер карты в платежной форме): добавь "frame" (frame-1, frame-2... из списка "Фреймы"), работает для click и fill`,
+		},
+		Brief: `"text" (placeholder/name поля) и "value" (соблюдай ограничения поля в {})`,
+	},
+	{
+		Name: "press_key", Summary: "нажать клавишу на клавиатуре", Required: []string{"key"}, Optional: []string{"repeat"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "key" (название клавиши)`,
+			`Доступные клавиши: "delete", "enter", "escape", "backspace", "tab", "space", "up", "down", "left", "right", "pageup", "pagedown", "home", "end"`,
+			`Примеры использования:`,
+			`* Удалить письмо: сначала кликни на письмо, затем нажми "delete"`,
+			`* Отправить форму: нажми "enter"`,
+			`* Закрыть диалог: нажми "escape"`,
+			`Повторное нажатие той же клавиши на неизменившейся странице - с "repeat": true`,
+		},
+		Brief: `"key" (enter, escape, delete)`,
+	},
+	{
+		Name: "switch_tab", Summary: "переключиться на другую вкладку", Required: []string{"tab_index"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "tab_index" (номер вкладки из списка "Открытые вкладки браузера", например 1, 2, 3)`,
+			`Используй когда нужно переключиться между открытыми вкладками`,
+			`Пример: {"action": "switch_tab", "tab_index": 2}`,
+		},
+		Brief: `"tab_index" (номер из списка вкладок)`,
+	},
+	{
+		Name: "switch_new_tab", Summary: "дождаться вкладки, которую открыл предыдущий клик, и переключиться на нее",
+		Details: []string{
+			`Используй сразу после клика по ссылке, которая открывается в новой вкладке (target="_blank", "предпросмотр", внешний сайт)`,
+			`Номер вкладки не нужен - агент сам дождется открытой кликом вкладки`,
+		},
+		Brief: "перейти во вкладку, открытую предыдущим кликом",
+	},
+	{
+		Name: "close_tab", Summary: "закрыть вкладку", Required: []string{"tab_index"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "tab_index" (номер вкладки из списка "Открытые вкладки браузера")`,
+			`Используй для закрытия ненужных вкладок`,
+			`НЕ закрывай активную вкладку, если это последняя вкладка`,
+		},
+		Brief: `"tab_index"`,
+	},
+	{
+		Name: "wait", Summary: "подождать", Optional: []string{"wait_for"},
+		Details: []string{`Опционально: "wait_for" (селектор элемента)`},
+		Brief:   `опционально "wait_for"`,
+	},
+	{
+		Name: "extract", Summary: "извлечь информацию (уже сделано автоматически)",
+		Brief: "показать текст страницы на следующем шаге",
+	},
+	{
+		Name: "extract_text", Summary: "прочитать текст одного элемента (цена, номер заказа, итоговая сумма)", Required: []string{"selector"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "selector" (CSS селектор, который находит ровно один элемент)`,
+			`Текст элемента появится в истории - не нужно показывать всю страницу через extract`,
+			`Если селектор находит несколько элементов, уточни его (id, :nth-of-type, родитель)`,
+		},
+		Brief: `текст одного элемента - "selector"`,
+	},
+	{
+		Name: "complete", Summary: "задача выполнена ТОЛЬКО когда задача действительно выполнена",
+		Optional: []string{"is_complete", "summary", "extracted_data"},
+		Brief:    `задача выполнена, "is_complete": true и "summary"`,
+	},
+	{
+		Name: "upload", Summary: `загрузить файл в поле <input type="file">`, Required: []string{"value"}, Optional: []string{"selector"},
+		Unsafe: "загрузка файлов на сайт запрещена",
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "value" (абсолютный путь к файлу на диске, несколько файлов - через запятую)`,
+			`Загружать можно только файлы, скачанные или сохраненные этой задачей, и файлы из каталогов, разрешенных пользователем`,
+			`Опционально: "selector" (CSS селектор поля; если клик открыл диалог выбора файла, используй селектор из сообщения об ошибке)`,
+			`НЕ кликай по кнопкам "Прикрепить файл" повторно - системный диалог выбора файла недоступен`,
+		},
+	},
+	{
+		Name: "download", Summary: "скачать файл по ссылке или кнопке \"Скачать\" (выписка, счет, архив)", Optional: []string{"text", "selector"}, Clicks: true,
+		Details: []string{
+			`Опционально: "text" или "selector" ссылки/кнопки загрузки - агент кликнет и дождется файла`,
+			`Без "text" и "selector" - дождаться загрузки, которую начал предыдущий клик`,
+			`Путь к скачанному файлу появится в истории; НЕ используй click для ссылок на файлы`,
+		},
+		Brief: `скачать файл - "text" или "selector" ссылки, путь появится в истории`,
+	},
+	{
+		Name: "play_media", Summary: "запустить видео/аудио", Optional: []string{"selector"},
+		Details: []string{
+			`Опционально: "selector" (селектор из списка "Медиа на странице"; без него - первый медиа-элемент)`,
+			`Состояние воспроизведения видно в списке "Медиа на странице"`,
+		},
+	},
+	{
+		Name: "pause_media", Summary: "поставить видео/аудио на паузу", Optional: []string{"selector"},
+		Details: []string{`Опционально: "selector" (селектор из списка "Медиа на странице"; без него - первый медиа-элемент)`},
+	},
+	{
+		Name: "read_document", Summary: "прочитать документ (PDF, TXT, CSV, HTML) и ответить на вопрос по нему",
+		Required: []string{"text|url|value", "question"},
+		Details: []string{
+			`Источник: "text" (текст ссылки на документ со страницы), "url" (адрес документа) ИЛИ "value" (путь к файлу, который эта задача скачала через download или сохранила через save_pdf)`,
+			`ОБЯЗАТЕЛЬНО заполни: "question" (что нужно узнать из документа, например "какая сумма в квитанции?")`,
+			`Ответ появится в истории действий - НЕ открывай документ повторно`,
+		},
+	},
+	{
+		Name: "select_text", Summary: `выделить текст на странице и прочитать выделение (для "скопируй и вставь")`,
+		Optional: []string{"selector", "text"},
+		Details: []string{
+			`"selector" (CSS селектор элемента) ИЛИ "text" (фрагмент текста абзаца, который нужно выделить)`,
+			`Без selector и text - прочитать текущее выделение пользователя`,
+			`Выделенный текст появится в истории; чтобы "вставить" его в форму, используй fill с этим текстом в "value"`,
+		},
+	},
+	{
+		Name: "set_range", Summary: "установить значение ползунка (цена, площадь, громкость)", Required: []string{"selector|text", "value"},
+		Details: []string{
+			`"selector" (из списка "Ползунки") ИЛИ "text" (подпись ползунка)`,
+			`ОБЯЗАТЕЛЬНО заполни: "value" (число в пределах min..max из списка "Ползунки")`,
+		},
+	},
+	{
+		Name: "scroll_to_load", Summary: "прокрутить бесконечную ленту до конца, пока подгружаются новые элементы", Optional: []string{"selector", "value"},
+		Details: []string{
+			`Используй ПЕРЕД extract/complete в задачах "перечисли все", "собери все" на страницах с подгрузкой при прокрутке`,
+			`Опционально: "value" (максимум прокруток); после прокрутки следующий шаг получит полный список элементов`,
+			`Лента внутри блока со своей прокруткой (чат, список писем) - укажи "selector" элемента ленты: прокрутится ближайший прокручиваемый блок`,
+		},
+	},
+	{
+		Name: "go_back", Summary: "вернуться назад по истории вкладки", Optional: []string{"value"},
+		Details: []string{
+			`Опционально: "value" (на сколько страниц назад, по умолчанию 1)`,
+			`Используй, чтобы вернуться к результатам поиска после просмотра нескольких карточек одним действием, а не несколькими`,
+			`Попал не на ту страницу - вернись через go_back, а не угадывай URL для navigate`,
+		},
+		Brief: `опционально "value" (сколько страниц назад)`,
+	},
+	{
+		Name: "go_forward", Summary: "перейти вперед по истории вкладки (после go_back)",
+		Brief: "вперед по истории вкладки",
+	},
+	{
+		Name: "reload", Summary: "перезагрузить текущую страницу (зависла загрузка, устаревшие данные)",
+		Brief: "перезагрузить страницу",
+	},
+	{
+		Name: "set_checkbox", Summary: "поставить или снять флажок, выбрать переключатель (radio)", Required: []string{"text|selector"}, Optional: []string{"value"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "text" (подпись флажка из списка полей ввода, [x] - отмечен, [ ] - нет) ИЛИ "selector"`,
+			`Опционально: "value": "false", чтобы снять флажок (по умолчанию флажок ставится)`,
+		},
+		Brief: `"text" (подпись флажка), "value": "false" - снять`,
+	},
+	{
+		Name: "set_choices", Summary: "выбрать несколько вариантов в группе флажков или списке с множественным выбором за одно действие",
+		Required: []string{"text", "values"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "text" (подпись группы: "Размер", "Цвет") и "values" (список вариантов, например ["M", "L"])`,
+			`Используй вместо нескольких click для фильтров вроде "выбери размеры M и L"`,
+		},
+		Brief: `"text" (подпись группы) и "values" (["M", "L"])`,
+	},
+	{
+		Name: "paginate_scrape", Summary: "собрать список со ВСЕХ страниц результатов одним действием (агент сам листает страницы или прокручивает ленту)",
+		Required: []string{"selector"}, Optional: []string{"values", "text", "value"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "selector" (CSS-селектор одного элемента списка: карточки, строки таблицы)`,
+			`Опционально: "values" - поля строки в формате "имя=селектор внутри элемента", атрибут после "@": ["название=.title", "зарплата=.salary", "ссылка=a@href"] (без values - текст элемента и ссылка)`,
+			`Опционально: "text" (текст или селектор кнопки следующей страницы внутри блока пагинации, по умолчанию "Следующая", "Next", rel=next) и "value" (максимум страниц, по умолчанию 10)`,
+			`Используй для задач "собери все ..." по многостраничной выдаче вместо ручного перелистывания; собранные строки попадут в extracted_data`,
+		},
+		Brief: `собрать список со всех страниц - "selector" (элемент списка), опционально "values" (["название=.title", "ссылка=a@href"]), "text" (кнопка следующей страницы), "value" (максимум страниц)`,
+	},
+	{
+		Name: "save_pdf", Summary: "сохранить текущую страницу в PDF-файл (квитанция, чек, подтверждение заказа, статья)", Optional: []string{"value"},
+		Details: []string{
+			`Опционально: "value" (имя файла без каталога, например "receipt-12345")`,
+			`Сначала открой нужную страницу (подтверждение, полный текст статьи), потом save_pdf; путь к файлу появится в истории`,
+		},
+		Brief: `сохранить текущую страницу в PDF, опционально "value" (имя файла)`,
+	},
+	{
+		Name: "scroll", Summary: "прокрутить страницу в направлении или к элементу", Required: []string{"direction|selector|text"}, Optional: []string{"amount"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "direction" ("down", "up", "top", "bottom") ИЛИ "selector"/"text" элемента`,
+			`"amount" - на сколько пикселей прокрутить down/up; без него - на экран`,
+			`Лента подгружает элементы при прокрутке: прокрути "down" и прочитай страницу заново на следующем шаге, пока не найдешь нужное или не дойдешь до конца`,
+			`С "selector" (CSS селектор) или "text" (текст кнопки/ссылки из списка) элемент прокручивается в центр окна - когда он ниже видимой области или его содержимое не загрузилось, а затем действуй с ним`,
+		},
+		Brief: `прокрутить - "direction" (down, up, top, bottom) и "amount" (px) или к элементу - "selector"/"text"`,
+	},
+	{
+		Name: "select", Summary: `выбрать вариант в выпадающем списке (поле типа select-one, варианты показаны после "варианты:")`,
+		Required: []string{"text"}, Optional: []string{"selector", "frame"},
+		Details: []string{
+			`ОБЯЗАТЕЛЬНО заполни: "text" (вариант из списка, например "Москва")`,
+			`"selector" - CSS селектор списка ИЛИ его подпись/name из списка полей ввода; без него выбирается список, в котором есть такой вариант`,
+			`Для поля внутри iframe добавь "frame"`,
+			`НЕ кликай по тексту варианта - варианты нативного списка не кликабельны`,
+		},
+		Brief: `вариант выпадающего списка - "text" (вариант) и "selector" (подпись или name списка)`,
+	},
+	{
+		Name: "submit", Summary: "отправить форму поля, которое ты только что заполнил, ее кнопкой отправки", Optional: []string{"selector", "frame"},
+		Unsafe: "отправка форм запрещена",
+		Details: []string{
+			`Используй после fill, если не знаешь текст кнопки отправки (вход, поиск): агент сам найдет кнопку формы`,
+			`"selector" - CSS селектор поля или формы; без него отправляется форма поля в фокусе`,
+			`Для формы внутри iframe добавь "frame"`,
+		},
+		Brief: `отправить форму заполненного поля без текста кнопки`,
+	},
+}
+
+// decisionFields - поля решения в JSON-схеме: тип и описание
+var decisionFields = map[string]struct{ Type, Description string }{
+	"reasoning":      {"string", "почему выбрано это действие"},
+	"url":            {"string", "полный URL: прямой или из списка links"},
+	"text":           {"string", "видимый текст элемента, placeholder или подпись из списка страницы"},
+	"selector":       {"string", "CSS селектор"},
+	"value":          {"string", "значение: текст для ввода, число, путь к файлу"},
+	"values":         {"array", "список вариантов или полей"},
+	"key":            {"string", "клавиша: enter, escape, delete, tab..."},
+	"tab_index":      {"integer", "номер вкладки из списка открытых вкладок, с 1"},
+	"wait_for":       {"string", "селектор элемента, появления которого ждать"},
+	"question":       {"string", "вопрос к документу"},
+	"frame":          {"string", "фрейм из списка фреймов: frame-1, frame-2..."},
+	"force_reload":   {"boolean", "перезагрузить уже открытую страницу"},
+	"repeat":         {"boolean", "повторить только что успешно выполненное действие"},
+	"direction":      {"string", "направление прокрутки: down, up, top, bottom"},
+	"amount":         {"integer", "прокрутка в пикселях"},
+	"is_complete":    {"boolean", "задача выполнена"},
+	"summary":        {"string", "что было выполнено"},
+	"extracted_data": {"object", "структурированный результат задачи"},
+	"needs_input":    {"boolean", "нужны данные пользователя (код из SMS, выбор)"},
+	"input_prompt":   {"string", "вопрос пользователю, если needs_input"},
+	"instruction":    {"string", "что сделать пользователю вручную"},
+	"metadata":       {"object", "дополнительные сведения о шаге"},
+}
+
+// Actions возвращает реестр действий агента
+func Actions() []ActionSpec {
+	return append([]ActionSpec(nil), actionRegistry...)
+}
+
+// LookupAction находит действие реестра по имени
+func LookupAction(name string) (ActionSpec, bool) {
+	for _, spec := range actionRegistry {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return ActionSpec{}, false
+}
+
+// ActionNames возвращает имена действий реестра по порядку
+func ActionNames() []string {
+	names := make([]string, len(actionRegistry))
+	for i, spec := range actionRegistry {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+// ActionsPrompt - нумерованный список действий для полного системного промпта
+func ActionsPrompt() string {
+	var sb strings.Builder
+	for i, spec := range actionRegistry {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, spec.Name, spec.Summary))
+		for _, line := range spec.Details {
+			if strings.HasPrefix(line, "* ") {
+				sb.WriteString("     " + line + "\n")
+			} else {
+				sb.WriteString("   - " + line + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// ActionsShortPrompt - нумерованный список действий по строке на действие: назначение
+// и поля. Для промптов специализированных агентов, где подробности заняли бы лишнее место.
+func ActionsShortPrompt() string {
+	var sb strings.Builder
+	for i, spec := range actionRegistry {
+		line := fmt.Sprintf("%d. %s - %s", i+1, spec.Name, spec.Summary)
+		var fields []string
+		for _, field := range spec.Required {
+			fields = append(fields, `"`+strings.Join(strings.Split(field, "|"), `" ИЛИ "`)+`"`)
+		}
+		if len(spec.Optional) > 0 {
+			fields = append(fields, `опционально "`+strings.Join(spec.Optional, `", "`)+`"`)
+		}
+		if len(fields) > 0 {
+			line += ": " + strings.Join(fields, ", ")
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// compactActionsPrompt - строки "- действие: поля" для компактного системного промпта
+func compactActionsPrompt() string {
+	var sb strings.Builder
+	for _, spec := range actionRegistry {
+		if spec.Brief != "" {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", spec.Name, spec.Brief))
+		}
+	}
+	return sb.String()
+}
+
+// ActionSchema возвращает компактную JSON-схему решения модели: перечень действий
+// и обязательные поля каждого из них
+func ActionSchema() json.RawMessage {
+	properties := map[string]any{
+		"action": map[string]any{"type": "string", "enum": ActionNames()},
+	}
+	for name := range decisionFields {
+		properties[name] = fieldSchema(name)
+	}
+	var rules []any
+	for _, spec := range actionRegistry {
+		if len(spec.Required) == 0 {
+			continue
+		}
+		rules = append(rules, map[string]any{
+			"if":   map[string]any{"properties": map[string]any{"action": map[string]any{"const": spec.Name}}},
+			"then": requiredSchema(spec.Required),
+		})
+	}
+	return mustSchema(map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"action"},
+		"allOf":      rules,
+	})
+}
+
+// ActionTools возвращает действия реестра как инструменты для моделей с вызовом
+// функций: по функции на действие с его полями
+func ActionTools() []openai.Tool {
+	tools := make([]openai.Tool, 0, len(actionRegistry))
+	for _, spec := range actionRegistry {
+		properties := map[string]any{"reasoning": fieldSchema("reasoning")}
+		for _, field := range append(append([]string(nil), spec.Required...), spec.Optional...) {
+			for _, name := range strings.Split(field, "|") {
+				properties[name] = fieldSchema(name)
+			}
+		}
+		parameters := requiredSchema(spec.Required)
+		parameters["type"] = "object"
+		parameters["properties"] = properties
+		tools = append(tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        spec.Name,
+				Description: spec.Summary,
+				Parameters:  mustSchema(parameters),
+			},
+		})
+	}
+	return tools
+}
+
+// fieldSchema - JSON-схема поля решения
+func fieldSchema(name string) map[string]any {
+	field := decisionFields[name]
+	schema := map[string]any{"type": field.Type, "description": field.Description}
+	if field.Type == "array" {
+		schema["items"] = map[string]any{"type": "string"}
+	}
+	return schema
+}
+
+// requiredSchema - обязательные поля действия в JSON-схеме: "text|selector" - anyOf
+func requiredSchema(required []string) map[string]any {
+	schema := map[string]any{}
+	var plain []string
+	var alternatives []any
+	for _, field := range required {
+		names := strings.Split(field, "|")
+		if len(names) == 1 {
+			plain = append(plain, field)
+			continue
+		}
+		var anyOf []any
+		for _, name := range names {
+			anyOf = append(anyOf, map[string]any{"required": []string{name}})
+		}
+		alternatives = append(alternatives, map[string]any{"anyOf": anyOf})
+	}
+	if len(plain) > 0 {
+		schema["required"] = plain
+	}
+	if len(alternatives) > 0 {
+		schema["allOf"] = alternatives
+	}
+	return schema
+}
+
+func mustSchema(schema map[string]any) json.RawMessage {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic(fmt.Sprintf("ai: action schema is not serializable: %v", err))
+	}
+	return data
+}
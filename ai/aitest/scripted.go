@@ -0,0 +1,164 @@
+// Package aitest - сценарная модель для детерминированных проверок агента без сети
+// и ключа API: настоящий браузер и тестовые сайты, а вместо модели - заранее
+// заданные решения.
+//
+//	model := aitest.NewScriptedProvider(
+//		ai.Decision{Action: "fill", Text: "Поиск", Value: "чайник"},
+//		ai.Decision{Action: "click", Text: "Найти"},
+//	).When(`Найдено товаров: \d+`, ai.Decision{Action: "complete", IsComplete: true})
+//	client := ai.NewClientWithProvider(model, "gpt-4o")
+//	...
+//	if err := model.Err(); err != nil {
+//		t.Fatal(err)
+//	}
+package aitest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// rule - ответ на промпт, который совпал с шаблоном
+type rule struct {
+	pattern *regexp.Regexp
+	reply   string
+}
+
+// ScriptedProvider - ai.Provider, который отвечает по сценарию. Подключается к клиенту
+// через ai.NewClientWithProvider вместо модели, поэтому весь путь клиента - сборка
+// промпта, разбор JSON решения, повторы и учет токенов - работает как с моделью.
+//
+// На каждый запрос сначала проверяются правила When/WhenReply (по порядку, по
+// последнему сообщению пользователя); если ни одно не совпало, отдается следующее
+// решение из последовательности. Когда последовательность кончилась, запрос
+// завершается ошибкой, а Err возвращает ее и после задачи: агент, которому нужно
+// больше решений, чем задано, - ошибка сценария, а не повод отвечать наугад.
+type ScriptedProvider struct {
+	mu        sync.Mutex
+	decisions []string
+	next      int
+	rules     []rule
+	prompts   []string
+	err       error
+}
+
+// NewScriptedProvider создает сценарную модель с последовательностью решений
+func NewScriptedProvider(decisions ...ai.Decision) *ScriptedProvider {
+	p := &ScriptedProvider{}
+	for _, decision := range decisions {
+		p.decisions = append(p.decisions, mustJSON(decision))
+	}
+	return p
+}
+
+// When отвечает решением decision на каждый промпт, в котором есть совпадение с
+// регулярным выражением pattern. Правило не расходует последовательность.
+func (p *ScriptedProvider) When(pattern string, decision ai.Decision) *ScriptedProvider {
+	return p.WhenReply(pattern, mustJSON(decision))
+}
+
+// WhenReply - как When, но с произвольным текстом ответа: для запросов, которые
+// ждут не решение (проверка деструктивного действия, выбор вкладки)
+func (p *ScriptedProvider) WhenReply(pattern, reply string) *ScriptedProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = append(p.rules, rule{pattern: regexp.MustCompile(pattern), reply: reply})
+	return p
+}
+
+// Err возвращает первую ошибку сценария: запрос, на который не осталось решений
+func (p *ScriptedProvider) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Remaining возвращает число неиспользованных решений последовательности
+func (p *ScriptedProvider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.decisions) - p.next
+}
+
+// Prompts возвращает промпты всех запросов по порядку - для разбора провала сценария
+func (p *ScriptedProvider) Prompts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.prompts...)
+}
+
+// Name возвращает имя провайдера для журналов
+func (p *ScriptedProvider) Name() string {
+	return "aitest"
+}
+
+// Complete отвечает на диалог правилом или следующим решением сценария. Расход
+// считается по длине текста (~4 символа на токен): бюджеты токенов и итоги задачи
+// проверяются так же, как с моделью.
+func (p *ScriptedProvider) Complete(ctx context.Context, messages []ai.Message, opts ai.CompletionOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	prompt, runes := "", 0
+	for _, message := range messages {
+		runes += utf8.RuneCountInString(message.Content)
+		if message.Role == ai.RoleUser {
+			prompt = message.Content
+		}
+	}
+
+	reply, err := p.reply(prompt)
+	if err != nil {
+		return "", err
+	}
+	if opts.Usage != nil {
+		*opts.Usage = ai.TokenUsage{Prompt: runes / 4, Completion: utf8.RuneCountInString(reply) / 4}
+	}
+	return reply, nil
+}
+
+// reply выбирает ответ на промпт: правило или следующее решение последовательности
+func (p *ScriptedProvider) reply(prompt string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prompts = append(p.prompts, prompt)
+
+	for _, r := range p.rules {
+		if r.pattern.MatchString(prompt) {
+			return r.reply, nil
+		}
+	}
+	if p.next < len(p.decisions) {
+		p.next++
+		return p.decisions[p.next-1], nil
+	}
+
+	err := fmt.Errorf("aitest: сценарий исчерпан - запрос %d, а решений задано %d и ни одно правило не подошло (промпт: «%s»)",
+		len(p.prompts), len(p.decisions), excerpt(prompt, 300))
+	if p.err == nil {
+		p.err = err
+	}
+	return "", err
+}
+
+// excerpt обрезает промпт для сообщения об ошибке
+func excerpt(s string, limit int) string {
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit]) + "..."
+	}
+	return s
+}
+
+func mustJSON(decision ai.Decision) string {
+	data, err := json.Marshal(decision)
+	if err != nil {
+		panic(fmt.Sprintf("aitest: decision is not serializable: %v", err))
+	}
+	return string(data)
+}
@@ -0,0 +1,61 @@
+package aitest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+func TestScriptedProviderExhausted(t *testing.T) {
+	model := NewScriptedProvider(ai.Decision{Action: "click", Text: "Найти"})
+	client := ai.NewClientWithProvider(model, "gpt-4o")
+	client.SetRetryPolicy(0, 0)
+
+	decision, err := client.MakeDecision(context.Background(), "найти чайник", "страница", nil, 0)
+	if err != nil {
+		t.Fatalf("first MakeDecision() error: %v", err)
+	}
+	if decision.Action != "click" || decision.Text != "Найти" {
+		t.Errorf("first decision = %+v", decision)
+	}
+	if model.Remaining() != 0 || model.Err() != nil {
+		t.Fatalf("after first decision: remaining %d, err %v", model.Remaining(), model.Err())
+	}
+
+	if _, err := client.MakeDecision(context.Background(), "найти чайник", "страница", nil, 0); err == nil {
+		t.Fatal("MakeDecision() past the script succeeded")
+	}
+	if err := model.Err(); err == nil || !strings.Contains(err.Error(), "сценарий исчерпан") {
+		t.Errorf("Err() = %v, want script exhausted", err)
+	}
+	if got := len(model.Prompts()); got != 2 {
+		t.Errorf("Prompts() = %d, want 2", got)
+	}
+}
+
+func TestScriptedProviderWhen(t *testing.T) {
+	model := NewScriptedProvider(ai.Decision{Action: "click", Text: "Далее"}).
+		When(`Найдено товаров: \d+`, ai.Decision{Action: "complete", IsComplete: true})
+	client := ai.NewClientWithProvider(model, "gpt-4o")
+
+	decision, err := client.MakeDecision(context.Background(), "задача", "Найдено товаров: 12", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decision.IsComplete || model.Remaining() != 1 {
+		t.Errorf("rule decision = %+v, remaining %d", decision, model.Remaining())
+	}
+
+	decision, err = client.MakeDecision(context.Background(), "задача", "Каталог", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Action != "click" || model.Remaining() != 0 {
+		t.Errorf("sequence decision = %+v, remaining %d", decision, model.Remaining())
+	}
+	if usage := client.Usage(); usage.Prompt == 0 || usage.Completion == 0 {
+		t.Errorf("Usage() = %+v, want tokens counted", usage)
+	}
+}
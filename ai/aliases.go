@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultActionAliases - синонимы, которые модели пишут вместо имен действий агента:
+// "type" вместо fill, "goto" вместо navigate, "tap" вместо click
+var defaultActionAliases = map[string]string{
+	"type": "fill", "type_text": "fill", "input": "fill", "input_text": "fill", "enter_text": "fill", "write": "fill", "fill_input": "fill",
+	"goto": "navigate", "go_to": "navigate", "open_url": "navigate", "visit": "navigate", "navigate_to": "navigate", "go_to_url": "navigate",
+	"tap": "click", "click_element": "click", "click_button": "click", "click_link": "click",
+	"press": "press_key", "keypress": "press_key", "key_press": "press_key", "hotkey": "press_key",
+	"back": "go_back", "navigate_back": "go_back", "history_back": "go_back",
+	"forward": "go_forward", "navigate_forward": "go_forward", "history_forward": "go_forward",
+	"refresh": "reload", "reload_page": "reload", "refresh_page": "reload",
+	"sleep": "wait", "wait_for": "wait",
+	"scroll_to": "scroll", "scroll_into_view": "scroll", "scroll_to_element": "scroll",
+	"select_option": "select", "choose": "select", "choose_option": "select", "dropdown": "select",
+	"toggle": "set_checkbox", "toggle_checkbox": "set_checkbox",
+	"upload_file": "upload", "download_file": "download", "switch_to_tab": "switch_tab", "switch_to_new_tab": "switch_new_tab", "wait_for_new_tab": "switch_new_tab", "read_page": "extract", "get_text": "extract_text",
+	"done": "complete", "finish": "complete", "finished": "complete", "final_answer": "complete", "task_complete": "complete",
+}
+
+// SetActionAliases добавляет синонимы действий (синоним -> имя действия агента) к
+// встроенным или переопределяет их; синоним с пустым действием отключает встроенный
+func (c *Client) SetActionAliases(aliases map[string]string) {
+	c.actionAliases = make(map[string]string, len(aliases))
+	for alias, action := range aliases {
+		c.actionAliases[actionKey(alias)] = actionKey(action)
+	}
+}
+
+// ParseActionAliases разбирает список синонимов "goto=navigate, tap=click"
+// (ACTION_ALIASES); "type=" отключает встроенный синоним. Действие должно быть в
+// реестре действий.
+func ParseActionAliases(s string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		alias, action, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(alias) == "" {
+			return nil, fmt.Errorf("некорректный синоним '%s': ожидается синоним=действие", part)
+		}
+		action = strings.TrimSpace(action)
+		if _, known := LookupAction(actionKey(action)); action != "" && !known {
+			return nil, fmt.Errorf("синоним '%s' ссылается на неизвестное действие '%s'", strings.TrimSpace(alias), action)
+		}
+		aliases[strings.TrimSpace(alias)] = action
+	}
+	return aliases, nil
+}
+
+// actionKey приводит имя действия к виду из системного промпта: "Go-To" -> "go_to"
+func actionKey(action string) string {
+	return strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(action)))
+}
+
+// normalizeAction заменяет синоним действия решения на имя действия агента. Синоним
+// завершения ("done", "finish") считается завершением задачи, даже если модель не
+// поставила is_complete.
+func (c *Client) normalizeAction(decision *Decision) {
+	key := actionKey(decision.Action)
+	action, ok := c.actionAliases[key]
+	if !ok {
+		action, ok = defaultActionAliases[key]
+	}
+	if !ok || action == "" {
+		if key != "" {
+			decision.Action = key
+		}
+		return
+	}
+	if action == "complete" && key != "complete" {
+		decision.IsComplete = true
+	}
+	decision.Action = action
+}
@@ -3,30 +3,69 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Angabebr/Golang-AI-agent/browser"
-	"github.com/sashabaranov/go-openai"
+	"github.com/Angabebr/Golang-AI-agent/console"
 )
 
 type Client struct {
-	client      *openai.Client
-	model       string
-	systemPrompt string
+	provider             Provider
+	model                string
+	systemPrompt         string
+	safeMode             bool
+	handoffDisabled      bool // модель не может передать шаг пользователю (запуск без пользователя)
+	resultSchema         string
+	preferredTargets     []string
+	refusalFallbackModel string
+	escalationModel      string // модель для решения после зацикливания
+	escalateNext         bool
+	contextTokens        int  // размер контекста модели, 0 - по известным моделям
+	compact              bool // компактный промпт для моделей с маленьким контекстом
+	pageTextRequested    bool
+	promptDowngrade      string            // профиль, на который перешло последнее решение из-за переполнения контекста
+	actionAliases        map[string]string // синонимы действий сверх встроенных (SetActionAliases)
+	usage                usageCounter
+	retry                retryPolicy // повторы после 429 и временных ошибок сервера (SetRetryPolicy)
 }
 
+// NewClient создает клиент OpenAI с ключом apiKey - сокращение для
+// NewClientWithProvider(NewOpenAIProvider(apiKey), model)
 func NewClient(apiKey, model string) *Client {
+	return NewClientWithProvider(NewOpenAIProvider(apiKey), model)
+}
+
+// NewClientWithProvider создает клиент, который обращается к модели model через
+// provider: свой бэкенд LLM или заглушку в тестах
+func NewClientWithProvider(provider Provider, model string) *Client {
 	if model == "" {
 		model = "gpt-4-turbo-preview"
 	}
 
-	return &Client{
-		client: openai.NewClient(apiKey),
-		model:  model,
+	c := &Client{
+		provider:     provider,
+		model:        model,
 		systemPrompt: "", // Будет использован дефолтный из MakeDecision
+		retry:        retryPolicy{maxRetries: defaultMaxRetries, baseDelay: defaultRetryDelay},
 	}
+	c.selectPromptProfile()
+	return c
+}
+
+// Model возвращает имя используемой модели
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Provider возвращает имя провайдера LLM ("custom", если провайдер не сообщает имя)
+func (c *Client) Provider() string {
+	return providerName(c.provider)
 }
 
 // GetSystemPrompt возвращает текущий системный промпт
@@ -39,83 +78,165 @@ func (c *Client) SetSystemPrompt(prompt string) {
 	c.systemPrompt = prompt
 }
 
+// SetSafeMode сообщает модели, что действия, изменяющие состояние, запрещены
+func (c *Client) SetSafeMode(enabled bool) {
+	c.safeMode = enabled
+}
+
+const safeModeInstructions = `
+
+РЕЖИМ SAFE-MODE (только чтение):
+- Тебе ЗАПРЕЩЕНО что-либо заказывать, покупать, оплачивать, удалять, отправлять или сохранять
+- Клики по кнопкам отправки, покупки и удаления, нажатие Enter и отправка форм будут заблокированы
+- Заполнять поля можно, но отправить их нельзя - для поиска переходи по URL с параметрами запроса (navigate)
+- Планируй решение только через чтение: навигация, просмотр, извлечение информации
+- Если задачу невозможно выполнить без изменений - заверши её (complete) и объясни в summary, что осталось сделать вручную`
+
+// SetPreferredTargets задает тексты элементов, которые в промпте отмечаются ⭐
+func (c *Client) SetPreferredTargets(targets []string) {
+	c.preferredTargets = targets
+}
+
+// PreferredTargets возвращает тексты предпочтительных элементов
+func (c *Client) PreferredTargets() []string {
+	return c.preferredTargets
+}
+
+const preferredTargetsInstructions = `
+
+ПРЕДПОЧТИТЕЛЬНЫЕ ЭЛЕМЕНТЫ:
+- Пользователь указал элементы, которые нужно выбирать в первую очередь - они отмечены ⭐ в списках кнопок и ссылок
+- Если на странице есть несколько похожих элементов, выбирай отмеченный ⭐
+- Для клика по отмеченному элементу указывай в "text" его текст без символа ⭐`
+
+// isPreferred сообщает, совпадает ли текст элемента с одним из предпочтительных
+func (c *Client) isPreferred(texts ...string) bool {
+	for _, target := range c.preferredTargets {
+		target = strings.ToLower(strings.Join(strings.Fields(target), " "))
+		for _, text := range texts {
+			if text != "" && strings.ToLower(strings.Join(strings.Fields(text), " ")) == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preferredMark возвращает префикс ⭐ для предпочтительного элемента
+func (c *Client) preferredMark(texts ...string) string {
+	if c.isPreferred(texts...) {
+		return "⭐ "
+	}
+	return ""
+}
+
+// SetHandoffEnabled сообщает модели, может ли она передать шаг пользователю (handoff)
+func (c *Client) SetHandoffEnabled(enabled bool) {
+	c.handoffDisabled = !enabled
+}
+
+const handoffInstructions = `
+
+ПЕРЕДАЧА ШАГА ПОЛЬЗОВАТЕЛЮ (крайняя мера):
+- Действие "handoff" с "instruction" (что сделать, например "Перетащите метку на карте к дому 5") приостанавливает агента, пока пользователь выполнит шаг вручную в браузере
+- Используй ТОЛЬКО если шаг невозможно выполнить доступными действиями (перетаскивание, карта, нестандартный виджет) и другие способы уже не сработали
+- НЕ используй handoff, чтобы узнать данные (для этого needs_input), и вместо обычных кликов и заполнения полей
+- После handoff страница будет проанализирована заново - продолжай с ее текущего состояния`
+
+// newMark возвращает префикс 🆕 для элемента, появившегося после последнего действия
+func newMark(isNew bool) string {
+	if isNew {
+		return "🆕 "
+	}
+	return ""
+}
+
+// writeNewElementsNote объясняет пометку 🆕, если на странице есть новые элементы
+func writeNewElementsNote(sb *strings.Builder, links []browser.Link, buttons []browser.Button) {
+	count := 0
+	for _, link := range links {
+		if link.New {
+			count++
+		}
+	}
+	for _, btn := range buttons {
+		if btn.New {
+			count++
+		}
+	}
+	if count == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n🆕 После твоего последнего действия появилось элементов: %d (открылось меню, список или окно) - они отмечены 🆕 и стоят в начале списков. Скорее всего, следующий шаг - среди них. Для click указывай текст без 🆕\n", count))
+}
+
+// SetResultSchema задает JSON Schema, которой должен соответствовать итоговый extracted_data
+func (c *Client) SetResultSchema(schema string) {
+	c.resultSchema = schema
+}
+
+// ResultSchema возвращает JSON Schema результата текущей задачи
+func (c *Client) ResultSchema() string {
+	return c.resultSchema
+}
+
 type Decision struct {
-	Action      string            `json:"action"`
-	Reasoning   string            `json:"reasoning"`
-	Selector    string            `json:"selector,omitempty"`
-	Text        string            `json:"text,omitempty"`
-	Value       string            `json:"value,omitempty"`
-	URL         string            `json:"url,omitempty"`
-	Key         string            `json:"key,omitempty"`         // Клавиша для нажатия (delete, enter, escape)
-	TabID       string            `json:"tab_id,omitempty"`      // ID вкладки для переключения/закрытия
-	TabIndex    int               `json:"tab_index,omitempty"`   // Индекс вкладки (1, 2, 3...)
-	WaitFor     string            `json:"wait_for,omitempty"`
-	NeedsInput  bool              `json:"needs_input"`
-	InputPrompt string            `json:"input_prompt,omitempty"`
-	IsComplete  bool              `json:"is_complete"`
-	Summary     string            `json:"summary,omitempty"`
-	Metadata    map[string]string `json:"metadata,omitempty"`
+	Action        string            `json:"action"`
+	Reasoning     string            `json:"reasoning"`
+	Selector      string            `json:"selector,omitempty"`
+	Text          string            `json:"text,omitempty"`
+	Value         string            `json:"value,omitempty"`
+	URL           string            `json:"url,omitempty"`
+	Key           string            `json:"key,omitempty"`       // Клавиша для нажатия (delete, enter, escape)
+	TabID         string            `json:"tab_id,omitempty"`    // ID вкладки для переключения/закрытия
+	TabIndex      int               `json:"tab_index,omitempty"` // Индекс вкладки (1, 2, 3...)
+	WaitFor       string            `json:"wait_for,omitempty"`
+	Question      string            `json:"question,omitempty"`     // Вопрос к документу для read_document
+	Frame         string            `json:"frame,omitempty"`        // iframe для click/fill: frame-1, frame-2... из списка фреймов
+	Values        []string          `json:"values,omitempty"`       // Варианты для set_choices
+	ForceReload   bool              `json:"force_reload,omitempty"` // navigate на текущий URL перезагружает страницу
+	Repeat        bool              `json:"repeat,omitempty"`       // намеренный повтор только что выполненного действия
+	Direction     string            `json:"direction,omitempty"`    // Направление scroll: down, up, top, bottom
+	Amount        int               `json:"amount,omitempty"`       // Прокрутка scroll в пикселях
+	NeedsInput    bool              `json:"needs_input"`
+	InputPrompt   string            `json:"input_prompt,omitempty"`
+	Instruction   string            `json:"instruction,omitempty"` // Что сделать пользователю вручную (handoff)
+	IsComplete    bool              `json:"is_complete"`
+	Summary       string            `json:"summary,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	ExtractedData json.RawMessage   `json:"extracted_data,omitempty"` // Структурированный результат задачи
 }
 
 func (c *Client) MakeDecision(ctx context.Context, task string, pageContent interface{}, history []string, maxTokens int) (*Decision, error) {
+	c.promptDowngrade = ""
 	prompt := c.buildPrompt(task, pageContent, history)
+	if c.compact {
+		prompt = c.buildCompactPrompt(task, pageContent, history)
+	}
 
 	// Используем кастомный системный промпт, если он установлен, иначе дефолтный
 	systemContent := c.systemPrompt
-	if systemContent == "" {
+	if systemContent == "" && c.compact {
+		systemContent = compactSystemPrompt
+	} else if systemContent == "" {
 		systemContent = `Ты - автономный AI-агент, который управляет веб-браузером для выполнения задач пользователя.
 
 Твоя задача - анализировать текущее состояние веб-страницы и АВТОНОМНО принимать решения о следующих действиях, БЕЗ использования заготовленных планов или шаблонов.
 
 Доступные действия:
-1. navigate - перейти на URL
-   - Можешь использовать URL из списка links.href ИЛИ указать прямой URL (например, "https://mail.ru")
-   - Заполни: "url" (полный URL, например "https://mail.ru" или из списка links)
-   
-2. click - кликнуть на элемент
-   - ОБЯЗАТЕЛЬНО заполни: "text" (видимый текст из списка buttons или links)
-   - Доступна дополнительная информация о кнопках: aria-label, title, action, контекст, id, class
-   - Используй эту информацию, чтобы лучше понять назначение кнопки
-   - Или если text не работает: "selector" (CSS селектор)
-   
-3. fill - заполнить поле ввода
-   - ОБЯЗАТЕЛЬНО заполни: "text" (placeholder, name, aria-label из списка inputs)
-   - ОБЯЗАТЕЛЬНО заполни: "value" (значение для ввода)
-   - Для полей поиска можно использовать общие термины: "искать", "search", "поиск"
-   - Или если text не работает: "selector" (CSS селектор) + "value"
-   
-4. press_key - нажать клавишу на клавиатуре
-   - ОБЯЗАТЕЛЬНО заполни: "key" (название клавиши)
-   - Доступные клавиши: "delete", "enter", "escape", "backspace", "tab", "space", "up", "down", "left", "right", "pageup", "pagedown", "home", "end"
-   - Примеры использования:
-     * Удалить письмо: сначала кликни на письмо, затем нажми "delete"
-     * Отправить форму: нажми "enter"
-     * Закрыть диалог: нажми "escape"
-   
-5. switch_tab - переключиться на другую вкладку
-   - ОБЯЗАТЕЛЬНО заполни: "tab_index" (номер вкладки из списка "Открытые вкладки браузера", например 1, 2, 3)
-   - Используй когда нужно переключиться между открытыми вкладками
-   - Пример: {"action": "switch_tab", "tab_index": 2}
-   
-6. close_tab - закрыть вкладку
-   - ОБЯЗАТЕЛЬНО заполни: "tab_index" (номер вкладки из списка "Открытые вкладки браузера")
-   - Используй для закрытия ненужных вкладок
-   - НЕ закрывай активную вкладку, если это последняя вкладка
-   
-7. wait - подождать
-   - Опционально: "wait_for" (селектор элемента)
-   
-8. extract - извлечь информацию (уже сделано автоматически)
-9. complete - задача выполнена ТОЛЬКО когда задача действительно выполнена
-
+` + ActionsPrompt() + `
 КРИТИЧЕСКИ ВАЖНО - ПРАВИЛА ЗАПОЛНЕНИЯ ПОЛЕЙ:
 - Для действия "navigate": Можешь использовать URL из списка links ИЛИ указать прямой URL (например, "https://mail.ru", "https://e.mail.ru")
 - Для действия "click": ВСЕГДА заполняй "text" из списка buttons/links
 - Для действия "fill": ВСЕГДА заполняй "text" (из inputs.placeholder, inputs.name, inputs.aria-label) И "value"
 - Для действия "press_key": ВСЕГДА заполняй "key" (название клавиши: "delete", "enter", "escape" и т.д.)
 - Для полей поиска можно использовать общие термины: "искать", "search", "поиск" - система найдет поле автоматически
+- Ограничения поля в фигурных скобках ({обязательное, от 1 до 10, формат /.../}) проверяет форма: вводи только значение, которое им соответствует (число в диапазоне, строку нужной длины и формата)
 - НЕ завершай задачу (complete) если просто не можешь найти ссылку - используй navigate с прямым URL
 - Для удаления писем можно использовать press_key с "delete" после выбора письма
+- Если нужны данные, которых нет на странице и в задаче (код из SMS, одноразовый пароль, выбор пользователя) - НЕ придумывай их: верни "needs_input": true и вопрос в "input_prompt", код подтверждения пользователь введет сам
+- Даты "сегодня", "завтра", "через 2 часа" считай от текущих даты и времени из запроса; в "value" можно писать шаблоны {{today}}, {{tomorrow}}, {{now+2h}}, {{weekday}} - агент подставит значения
+- Если страница показывает даты относительно ("вчера", "2 дня назад"), для задач по дате сравнивай точные значения из раздела "Даты на странице" с текущей датой
 - НЕ используй заготовленные селекторы - анализируй ТОЛЬКО данные текущей страницы
 - Отвечай ТОЛЬКО в формате JSON, без дополнительного текста до или после JSON
 
@@ -136,6 +257,15 @@ func (c *Client) MakeDecision(ctx context.Context, task string, pageContent inte
   "value": "ОБЯЗАТЕЛЬНО: что вводить"
 }
 
+Для заполнения поля внутри iframe:
+{
+  "action": "fill",
+  "reasoning": "объяснение",
+  "frame": "frame-1",
+  "text": "placeholder или name поля из списка фрейма",
+  "value": "что вводить"
+}
+
 Для навигации:
 {
   "action": "navigate",
@@ -159,40 +289,92 @@ func (c *Client) MakeDecision(ctx context.Context, task string, pageContent inte
 }`
 	}
 
-	messages := []openai.ChatCompletionMessage{
+	systemContent += c.promptSections()
+
+	messages := []Message{
 		{
-			Role:    openai.ChatMessageRoleSystem,
+			Role:    RoleSystem,
 			Content: systemContent,
 		},
 		{
-			Role:    openai.ChatMessageRoleUser,
+			Role:    RoleUser,
 			Content: prompt,
 		},
 	}
 
-	resp, err := c.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model:       c.model,
-			Messages:    messages,
-			Temperature: 0.7,
-			MaxTokens:   maxTokens,
-		},
-	)
+	model := c.decisionModel()
+	opts := CompletionOptions{Model: model, Temperature: 0.7, MaxTokens: maxTokens}
+	content, err := c.complete(ctx, messages, opts)
 
+	// Страница не поместилась в контекст: повтор того же промпта снова упадет, поэтому
+	// промпт сокращается - компактный, затем без страницы
+	for profile := c.PromptProfile(); err != nil && isContextOverflow(err); {
+		if profile = smallerProfile(profile); profile == "" {
+			break
+		}
+		console.Printf("📉 Промпт не поместился в контекст модели, повтор с профилем %s\n", profile)
+		c.promptDowngrade = profile
+		prompt = c.buildCompactPrompt(task, pageContent, history)
+		if profile == PromptBlind {
+			prompt = c.buildBlindPrompt(task, pageContent, history)
+		}
+		messages = []Message{
+			{Role: RoleSystem, Content: compactSystemPrompt + c.promptSections()},
+			{Role: RoleUser, Content: prompt},
+		}
+		content, err = c.complete(ctx, messages, opts)
+	}
+
+	// Ответ заблокировал фильтр провайдера: уговаривать его бесполезно, отказ сразу
+	// возвращается пользователю
+	if errors.Is(err, ErrContentFiltered) {
+		return nil, &RefusalError{Model: model, Text: strings.TrimSpace(content)}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get AI response: %w", err)
 	}
 
-	content := resp.Choices[0].Message.Content
+	// Отказ модели - текст без JSON; без этой проверки он превратился бы в "wait"
+	if isRefusal(content, false) {
+		content, err = c.retryAfterRefusal(ctx, messages, content, maxTokens)
+		if err != nil {
+			return nil, err
+		}
+	}
 	decision, err := parseDecision(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse decision: %w", err)
 	}
+	c.normalizeAction(decision)
 
 	return decision, nil
 }
 
+// promptSections возвращает разделы системного промпта, зависящие от настроек задачи:
+// safe-mode, предпочтительные элементы, передача шага пользователю, схема результата
+func (c *Client) promptSections() string {
+	var sections string
+	if c.safeMode {
+		sections += safeModeInstructions
+	}
+	if len(c.preferredTargets) > 0 {
+		sections += preferredTargetsInstructions
+	}
+	if !c.handoffDisabled {
+		sections += handoffInstructions
+	}
+	if c.resultSchema != "" {
+		sections += fmt.Sprintf(`
+
+ОЖИДАЕМЫЙ РЕЗУЛЬТАТ ЗАДАЧИ (JSON Schema):
+%s
+- Собирай на страницах данные для ВСЕХ полей схемы
+- При завершении (complete) ОБЯЗАТЕЛЬНО заполни "extracted_data" строго по этой схеме
+- Если какое-то поле невозможно заполнить, объясни причину в "summary"`, c.resultSchema)
+	}
+	return sections
+}
+
 func (c *Client) AnalyzePage(ctx context.Context, pageContent interface{}, task string) (string, error) {
 	prompt := fmt.Sprintf(`Проанализируй эту веб-страницу и опиши, что на ней находится и как можно выполнить задачу: "%s"
 
@@ -201,90 +383,95 @@ func (c *Client) AnalyzePage(ctx context.Context, pageContent interface{}, task
 
 Дай краткое описание страницы и возможных действий.`, task, pageContent)
 
-	messages := []openai.ChatCompletionMessage{
+	messages := []Message{
 		{
-			Role:    openai.ChatMessageRoleUser,
+			Role:    RoleUser,
 			Content: prompt,
 		},
 	}
 
-	resp, err := c.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model:       c.model,
-			Messages:    messages,
-			Temperature: 0.5,
-			MaxTokens:   500,
-		},
-	)
+	content, err := c.complete(ctx, messages, CompletionOptions{Model: c.model, Temperature: 0.5, MaxTokens: 500})
 
 	if err != nil {
 		return "", fmt.Errorf("failed to analyze page: %w", err)
 	}
 
-	return resp.Choices[0].Message.Content, nil
+	return content, nil
 }
 
-func (c *Client) CheckDestructiveAction(ctx context.Context, action string, context string) (bool, string, error) {
+// DestructiveCheck - результат LLM-проверки действия на деструктивность
+type DestructiveCheck struct {
+	IsDestructive bool   `json:"is_destructive"`
+	Severity      string `json:"severity"` // low, medium или high
+	Description   string `json:"description"`
+	Amount        string `json:"amount"`     // сумма операции, если есть (например, "5 000 ₽")
+	ItemCount     int    `json:"item_count"` // количество затрагиваемых объектов, если известно
+}
+
+func (c *Client) CheckDestructiveAction(ctx context.Context, action string, context string) (*DestructiveCheck, error) {
 	prompt := fmt.Sprintf(`Проверь, является ли это действие деструктивным (удаление, оплата, отправка важных данных, изменение настроек):
 
 Действие: %s
 Контекст: %s
 
+Оцени серьезность (severity):
+- low - легко обратимо или незначительно (удаление одного спам-письма, пометка как прочитанное)
+- medium - заметные изменения (изменение настроек аккаунта, отправка формы, отклик)
+- high - необратимо или связано с деньгами (оплата, оформление заказа, удаление аккаунта, массовое удаление)
+
 Ответь в формате JSON:
 {
   "is_destructive": true/false,
+  "severity": "low|medium|high",
   "description": "описание что произойдет",
+  "amount": "сумма операции, если видна (например, \"5 000 ₽\"), иначе пустая строка",
+  "item_count": количество затрагиваемых объектов или 0,
   "confirmation_question": "вопрос для пользователя"
 }`, action, context)
 
-	messages := []openai.ChatCompletionMessage{
+	messages := []Message{
 		{
-			Role:    openai.ChatMessageRoleSystem,
+			Role:    RoleSystem,
 			Content: "Ты проверяешь действия на деструктивность. Отвечай только в формате JSON.",
 		},
 		{
-			Role:    openai.ChatMessageRoleUser,
+			Role:    RoleUser,
 			Content: prompt,
 		},
 	}
 
-	resp, err := c.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model:       c.model,
-			Messages:    messages,
-			Temperature: 0.3,
-			MaxTokens:   200,
-		},
-	)
+	content, err := c.complete(ctx, messages, CompletionOptions{Model: c.model, Temperature: 0.3, MaxTokens: 250})
 
 	if err != nil {
-		return false, "", fmt.Errorf("failed to check destructive action: %w", err)
+		return nil, fmt.Errorf("failed to check destructive action: %w", err)
 	}
 
-	content := resp.Choices[0].Message.Content
-	isDestructive := strings.Contains(strings.ToLower(content), `"is_destructive": true`) ||
-		strings.Contains(strings.ToLower(content), `is_destructive: true`)
-
-	description := "Действие может привести к необратимым изменениям"
-	if strings.Contains(content, `"description"`) {
-		parts := strings.Split(content, `"description"`)
-		if len(parts) > 1 {
-			desc := strings.Trim(parts[1], `":,}`)
-			if desc != "" {
-				description = desc
-			}
+	check := &DestructiveCheck{}
+	if jsonMatch := regexp.MustCompile(`\{[\s\S]*\}`).FindString(content); jsonMatch == "" || json.Unmarshal([]byte(jsonMatch), check) != nil {
+		// Ответ не разобран как JSON - ищем флаг в тексте
+		check = &DestructiveCheck{
+			IsDestructive: strings.Contains(strings.ToLower(content), `"is_destructive": true`) ||
+				strings.Contains(strings.ToLower(content), `is_destructive: true`),
 		}
 	}
 
-	return isDestructive, description, nil
+	check.Severity = strings.ToLower(strings.TrimSpace(check.Severity))
+	if check.Description == "" {
+		check.Description = "Действие может привести к необратимым изменениям"
+	}
+
+	return check, nil
 }
 
 func (c *Client) buildPrompt(task string, pageContent interface{}, history []string) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Задача пользователя: %s\n\n", task))
+	writeClock(&sb, time.Now())
+
+	if len(c.preferredTargets) > 0 {
+		sb.WriteString(fmt.Sprintf("Предпочтительные элементы (выбирай их среди похожих): ⭐ %s\n\n", strings.Join(c.preferredTargets, ", ⭐ ")))
+	}
 
 	// История действий (только последние 5-7 для экономии токенов)
 	if len(history) > 0 {
@@ -306,7 +493,11 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 	if quickInfo, ok := pageContent.(*browser.QuickPageInfo); ok {
 		// Быстрая информация для простых действий
 		sb.WriteString(fmt.Sprintf("URL: %s\n", quickInfo.URL))
+		writeStatus(&sb, quickInfo.Status)
+		writeRoute(&sb, quickInfo.Route)
 		sb.WriteString(fmt.Sprintf("Title: %s\n", quickInfo.Title))
+		writeFormErrors(&sb, quickInfo.Errors)
+		writeNewElementsNote(&sb, quickInfo.Links, quickInfo.Buttons)
 		
 		if len(quickInfo.Links) > 0 {
 			sb.WriteString("\nДоступные ссылки (первые 15):\n")
@@ -316,7 +507,7 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 			}
 			for i := 0; i < maxLinks; i++ {
 				link := quickInfo.Links[i]
-				sb.WriteString(fmt.Sprintf("  - %s -> %s\n", link.Text, link.Href))
+				sb.WriteString(fmt.Sprintf("  - %s%s%s -> %s\n", newMark(link.New), c.preferredMark(link.Text), link.Text, link.Href))
 			}
 		}
 		
@@ -324,7 +515,7 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 			sb.WriteString("\nДоступные кнопки:\n")
 			for _, btn := range quickInfo.Buttons {
 				// Основная информация о кнопке
-				btnInfo := fmt.Sprintf("  - Текст: '%s'", btn.Text)
+				btnInfo := fmt.Sprintf("  - %s%sТекст: '%s'", newMark(btn.New), c.preferredMark(btn.Text, btn.AriaLabel, btn.Title), btn.Text)
 				
 				// Добавляем дополнительную информацию, если она есть
 				var details []string
@@ -352,9 +543,19 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 				sb.WriteString(btnInfo + "\n")
 			}
 		}
+		writeMedia(&sb, quickInfo.Media)
+		writeRanges(&sb, quickInfo.Ranges)
+		writeDates(&sb, quickInfo.Dates)
+		writePrices(&sb, quickInfo.Prices)
+		writeFrames(&sb, quickInfo.Frames)
+		writeTabs(&sb, quickInfo.Tabs)
 	} else if pc, ok := pageContent.(*browser.PageContent); ok {
 		sb.WriteString(fmt.Sprintf("URL: %s\n", pc.URL))
+		writeStatus(&sb, pc.Status)
+		writeRoute(&sb, pc.Route)
 		sb.WriteString(fmt.Sprintf("Title: %s\n", pc.Title))
+		writeFormErrors(&sb, pc.Errors)
+		writeNewElementsNote(&sb, pc.Links, pc.Buttons)
 		
 		if len(pc.Headings) > 0 {
 			sb.WriteString("\nЗаголовки:\n")
@@ -367,7 +568,7 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 			sb.WriteString("\nДоступные кнопки:\n")
 			for _, btn := range pc.Buttons {
 				// Основная информация о кнопке
-				btnInfo := fmt.Sprintf("  - Текст: '%s'", btn.Text)
+				btnInfo := fmt.Sprintf("  - %s%sТекст: '%s'", newMark(btn.New), c.preferredMark(btn.Text, btn.AriaLabel, btn.Title), btn.Text)
 				
 				// Добавляем дополнительную информацию, если она есть
 				var details []string
@@ -426,15 +627,11 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 			}
 		}
 		
+		// Количество ссылок и длину текста ограничивает браузер (ContentLimits)
 		if len(pc.Links) > 0 {
-			sb.WriteString("\nДоступные ссылки (первые 15):\n")
-			maxLinks := 15
-			if len(pc.Links) < maxLinks {
-				maxLinks = len(pc.Links)
-			}
-			for i := 0; i < maxLinks; i++ {
-				link := pc.Links[i]
-				sb.WriteString(fmt.Sprintf("  - %s -> %s\n", link.Text, link.Href))
+			sb.WriteString("\nДоступные ссылки:\n")
+			for _, link := range pc.Links {
+				sb.WriteString(fmt.Sprintf("  - %s%s%s -> %s\n", newMark(link.New), c.preferredMark(link.Text), link.Text, link.Href))
 			}
 		}
 		
@@ -451,17 +648,20 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 				if label == "" {
 					label = inp.ID
 				}
-				sb.WriteString(fmt.Sprintf("  - %s (%s)\n", label, inp.Type))
+				state := ""
+				if inp.Checked != nil {
+					state = " [ ]"
+					if *inp.Checked {
+						state = " [x]"
+					}
+				}
+				sb.WriteString(fmt.Sprintf("  - %s (%s)%s%s%s\n", label, inp.Type, state, optionsNote(inp, 30), constraintsNote(inp)))
 			}
 		}
 		
-		// Краткий текст страницы (первые 3000 символов)
+		// Краткий текст страницы
 		if len(pc.Text) > 0 {
-			textPreview := pc.Text
-			if len(textPreview) > 3000 {
-				textPreview = textPreview[:3000] + "..."
-			}
-			sb.WriteString(fmt.Sprintf("\nТекст страницы:\n%s\n", textPreview))
+			sb.WriteString(fmt.Sprintf("\nТекст страницы:\n%s\n", pc.Text))
 		}
 		
 		// Списки и таблицы для структурированных данных
@@ -497,17 +697,13 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 			}
 		}
 		
-		// Информация о вкладках браузера
-		if len(pc.Tabs) > 0 {
-			sb.WriteString("\nОткрытые вкладки браузера:\n")
-			for i, tab := range pc.Tabs {
-				activeMarker := ""
-				if tab.IsActive {
-					activeMarker = " [АКТИВНАЯ]"
-				}
-				sb.WriteString(fmt.Sprintf("  %d. %s - %s%s\n", i+1, tab.Title, tab.URL, activeMarker))
-			}
-		}
+		writeMedia(&sb, pc.Media)
+		writeRanges(&sb, pc.Ranges)
+		writeDates(&sb, pc.Dates)
+		writePrices(&sb, pc.Prices)
+		writeFrames(&sb, pc.Frames)
+
+		writeTabs(&sb, pc.Tabs)
 	} else {
 		// Fallback для других типов
 		sb.WriteString(fmt.Sprintf("%+v\n", pageContent))
@@ -518,6 +714,192 @@ func (c *Client) buildPrompt(task string, pageContent interface{}, history []str
 	return sb.String()
 }
 
+// writeMedia добавляет в промпт видео и аудио страницы с состоянием воспроизведения
+func writeMedia(sb *strings.Builder, media []browser.MediaElement) {
+	if len(media) == 0 {
+		return
+	}
+	sb.WriteString("\nМедиа на странице:\n")
+	for _, m := range media {
+		state := "на паузе"
+		if m.Playing {
+			state = "воспроизводится"
+		}
+		if m.Type == "embed" {
+			state = "встроенный плеер, управление через клик по нему"
+		}
+		line := fmt.Sprintf("  - %s [%s]", m.Type, state)
+		if m.Selector != "" {
+			line += fmt.Sprintf(" selector='%s'", m.Selector)
+		}
+		if m.Duration > 0 {
+			line += fmt.Sprintf(" %d/%d сек", m.CurrentTime, m.Duration)
+		}
+		if m.Muted {
+			line += " без звука"
+		}
+		if !m.Visible {
+			line += " (скрыт)"
+		}
+		if m.Src != "" {
+			line += " src=" + m.Src
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
+// writeStatus предупреждает модель, что страница загрузилась с ошибкой HTTP: оформленная
+// страница 404 или 500 похожа на обычную, и без статуса модель действует на ее содержимом.
+// Успешный статус не выводится.
+func writeStatus(sb *strings.Builder, status int) {
+	if status < 400 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("⚠️ HTTP-статус: %d %s - это страница ошибки, а не нужное содержимое. Проверь адрес, вернись назад или найди страницу через поиск/меню сайта\n", status, http.StatusText(status)))
+}
+
+// writeRoute добавляет в промпт маршрут SPA, чтобы модель видела переход без перезагрузки страницы
+func writeRoute(sb *strings.Builder, route string) {
+	if route == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("Маршрут SPA: %s (страница сменила адрес без перезагрузки)\n", route))
+}
+
+// writeFormErrors выводит ошибки проверки формы в начале описания страницы, чтобы модель
+// исправила указанные поля, а не повторяла ту же отправку
+func writeFormErrors(sb *strings.Builder, errors []string) {
+	if len(errors) == 0 {
+		return
+	}
+	sb.WriteString("\n⚠️ ОШИБКИ НА СТРАНИЦЕ (проверка формы) - исправь указанные поля, НЕ повторяй ту же отправку без изменений:\n")
+	for _, e := range errors {
+		sb.WriteString("  - " + e + "\n")
+	}
+}
+
+// writeTabs добавляет в промпт открытые вкладки браузера с номерами для switch_tab и close_tab
+func writeTabs(sb *strings.Builder, tabs []browser.TabInfo) {
+	if len(tabs) == 0 {
+		return
+	}
+	sb.WriteString("\nОткрытые вкладки браузера:\n")
+	for i, tab := range tabs {
+		activeMarker := ""
+		if tab.IsActive {
+			activeMarker = " [АКТИВНАЯ]"
+		}
+		sb.WriteString(fmt.Sprintf("  %d. %s - %s%s\n", i+1, tab.Title, tab.URL, activeMarker))
+	}
+}
+
+// writeRanges добавляет в промпт ползунки с границами, чтобы модель выбирала значение в допустимых пределах
+func writeRanges(sb *strings.Builder, ranges []browser.RangeControl) {
+	if len(ranges) == 0 {
+		return
+	}
+	sb.WriteString("\nПолзунки:\n")
+	for _, r := range ranges {
+		label := r.Label
+		if label == "" {
+			label = "без подписи"
+		}
+		line := fmt.Sprintf("  - %s [%s] selector='%s' min=%g max=%g текущее=%g", label, r.Kind, r.Selector, r.Min, r.Max, r.Value)
+		if r.Step > 0 {
+			line += fmt.Sprintf(" шаг=%g", r.Step)
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
+// writeDates добавляет в промпт точные значения дат, которые страница показывает
+// относительно ("вчера", "2 дня назад"), чтобы задачи по дате сравнивали настоящие даты
+func writeDates(sb *strings.Builder, dates []browser.PageDate) {
+	if len(dates) == 0 {
+		return
+	}
+	sb.WriteString("\nДаты на странице (показано -> точное значение):\n")
+	for _, d := range dates {
+		line := fmt.Sprintf("  - '%s' -> %s", d.Text, d.DateTime)
+		if d.Context != "" {
+			line += fmt.Sprintf(" (%s)", d.Context)
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
+// writePrices добавляет в промпт цены числом с кодом валюты, чтобы задачи вида "найди
+// самый дешевый" сравнивали числа, а не строки "1 299 ₽" и "$1,299.00"
+func writePrices(sb *strings.Builder, prices []browser.PagePrice) {
+	if len(prices) == 0 {
+		return
+	}
+	sb.WriteString("\nЦены на странице (показано -> число и валюта, сравнивай числа):\n")
+	for _, p := range prices {
+		line := fmt.Sprintf("  - '%s' -> %s", p.Text, strconv.FormatFloat(p.Value, 'f', -1, 64))
+		if p.Currency != "" {
+			line += " " + p.Currency
+		}
+		if p.Old {
+			line += ", старая цена"
+		}
+		if p.Context != "" {
+			line += fmt.Sprintf(" (%s)", p.Context)
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
+// constraintsNote - ограничения поля в промпте: " {обязательное, от 1 до 10}"
+func constraintsNote(inp browser.Input) string {
+	if constraints := inp.Constraints(); constraints != "" {
+		return " {" + constraints + "}"
+	}
+	return ""
+}
+
+// optionsNote - выбранный и доступные варианты выпадающего списка в промпте (не больше
+// limit): " = «Москва», варианты: Москва | Казань"
+func optionsNote(inp browser.Input, limit int) string {
+	if len(inp.Options) == 0 {
+		return ""
+	}
+	options := inp.Options
+	more := ""
+	if len(options) > limit {
+		options, more = options[:limit], " | ..."
+	}
+	return fmt.Sprintf(" = «%s», варианты: %s%s", inp.Value, strings.Join(options, " | "), more)
+}
+
+// writeFrames добавляет в промпт поля и кнопки внутри iframe с идентификаторами для поля "frame"
+func writeFrames(sb *strings.Builder, frames []browser.FrameContent) {
+	if len(frames) == 0 {
+		return
+	}
+	sb.WriteString("\nФреймы (iframe) на странице - для их элементов указывай \"frame\":\n")
+	for _, f := range frames {
+		origin := ""
+		if f.CrossOrigin {
+			origin = ", другой домен"
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%s%s):\n", f.ID, f.URL, origin))
+		for _, inp := range f.Inputs {
+			label := inp.Label
+			if label == "" {
+				label = inp.Placeholder
+			}
+			if label == "" {
+				label = inp.Name
+			}
+			sb.WriteString(fmt.Sprintf("    - поле: %s (%s)%s%s\n", label, inp.Type, optionsNote(inp, 30), constraintsNote(inp)))
+		}
+		for _, btn := range f.Buttons {
+			sb.WriteString(fmt.Sprintf("    - кнопка: %s\n", btn.Text))
+		}
+	}
+}
+
 func parseDecision(content string) (*Decision, error) {
 	content = strings.TrimSpace(content)
 	if strings.HasPrefix(content, "```json") {
@@ -533,8 +915,7 @@ func parseDecision(content string) (*Decision, error) {
 	jsonRegex := regexp.MustCompile(`\{[^{}]*"action"[^{}]*\}`)
 	jsonMatch := jsonRegex.FindString(content)
 	if jsonMatch == "" {
-		jsonRegex = regexp.MustCompile(`\{[\s\S]*?\}`)
-		jsonMatch = jsonRegex.FindString(content)
+		jsonMatch = extractJSONObject(content)
 	}
 
 	if jsonMatch != "" {
@@ -548,7 +929,12 @@ func parseDecision(content string) (*Decision, error) {
 	}
 
 	if err := json.Unmarshal([]byte(content), decision); err != nil {
-		return parseDecisionFallback(content)
+		// Запятые перед }, одинарные кавычки, переводы строк в значениях - исправляем
+		// и только если не помогло, извлекаем поля регулярными выражениями
+		*decision = Decision{Action: "wait"}
+		if err := json.Unmarshal([]byte(repairJSON(content)), decision); err != nil {
+			return parseDecisionFallback(content)
+		}
 	}
 
 	if decision.Metadata == nil {
@@ -595,7 +981,10 @@ func parseDecisionFallback(content string) (*Decision, error) {
 	decision.URL = extractString("url")
 	decision.Summary = extractString("summary")
 	decision.InputPrompt = extractString("input_prompt")
+	decision.Instruction = extractString("instruction")
 	decision.WaitFor = extractString("wait_for")
+	decision.Question = extractString("question")
+	decision.Frame = extractString("frame")
 	decision.IsComplete = extractBool("is_complete")
 	decision.NeedsInput = extractBool("needs_input")
 
@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdayNames - названия дней недели по языку ("ru", "en")
+var weekdayNames = map[string][7]string{
+	"ru": {"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	"en": {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+// WeekdayName возвращает название дня недели на языке lang ("ru" или "en"; иначе - по-русски)
+func WeekdayName(day time.Weekday, lang string) string {
+	names, ok := weekdayNames[lang]
+	if !ok {
+		names = weekdayNames["ru"]
+	}
+	return names[day]
+}
+
+// writeClock добавляет в промпт текущие дату, время, день недели и часовой пояс:
+// без них модель угадывает "завтра" и "в пятницу" по дате своего обучения
+func writeClock(sb *strings.Builder, now time.Time) {
+	zone, offset := now.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	tomorrow := now.AddDate(0, 0, 1)
+	sb.WriteString(fmt.Sprintf("Текущие дата и время: %s, %s (часовой пояс %s, UTC%s%02d:%02d). Завтра: %s, %s.\n\n",
+		now.Format("02.01.2006 15:04"), WeekdayName(now.Weekday(), "ru"),
+		zone, sign, offset/3600, offset%3600/60,
+		tomorrow.Format("02.01.2006"), WeekdayName(tomorrow.Weekday(), "ru")))
+}
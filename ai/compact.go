@@ -0,0 +1,280 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// Профили промпта
+const (
+	PromptFull    = "full"
+	PromptCompact = "compact"
+	PromptBlind   = "blind" // без содержимого страницы, только после переполнения контекста
+)
+
+const (
+	// compactContextLimit - модели с контекстом не больше этого получают компактный промпт
+	compactContextLimit = 16384
+	compactMaxElements  = 5    // ссылок, кнопок и полей каждого вида
+	compactHistory      = 5    // последних записей истории
+	compactHistoryRunes = 120  // длина записи истории
+	compactTextRunes    = 1500 // текст страницы по запросу extract
+)
+
+// knownContextTokens - размер контекста известных моделей. Имя совпадает целиком или
+// как начало имени до "-" или ":" (версии и теги: "gpt-4o-mini", "llama3:8b"), и
+// выигрывает самое длинное совпадение: "gpt-4-turbo-preview" - это gpt-4-turbo, а не
+// gpt-4, а "gpt-4.1" не совпадает с "gpt-4" вовсе.
+var knownContextTokens = []struct {
+	name   string
+	tokens int
+}{
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-1106", 128000},
+	{"gpt-4-0125", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo-instruct", 4096},
+	{"gpt-3.5-turbo", 16385},
+	{"llama3", 8192},
+	{"llama-3", 8192},
+	{"llama2", 4096},
+	{"mistral", 8192},
+	{"phi3", 4096},
+	{"gemma", 8192},
+}
+
+// ContextTokens возвращает размер контекста модели: заданный SetContextTokens или
+// известный для модели (0 - неизвестен)
+func (c *Client) ContextTokens() int {
+	if c.contextTokens > 0 {
+		return c.contextTokens
+	}
+	return knownModelTokens(c.model)
+}
+
+// knownModelTokens ищет модель в knownContextTokens (0 - неизвестна)
+func knownModelTokens(model string) int {
+	model = strings.ToLower(model)
+	best, tokens := 0, 0
+	for _, known := range knownContextTokens {
+		matches := model == known.name || strings.HasPrefix(model, known.name+"-") || strings.HasPrefix(model, known.name+":")
+		if matches && len(known.name) > best {
+			best, tokens = len(known.name), known.tokens
+		}
+	}
+	return tokens
+}
+
+// SetContextTokens задает размер контекста модели (AI_CONTEXT_TOKENS) и заново выбирает профиль промпта
+func (c *Client) SetContextTokens(tokens int) {
+	c.contextTokens = tokens
+	c.selectPromptProfile()
+}
+
+// PromptProfile возвращает профиль промпта: full или compact
+func (c *Client) PromptProfile() string {
+	if c.compact {
+		return PromptCompact
+	}
+	return PromptFull
+}
+
+// PromptProfileInfo описывает выбранный профиль промпта для вывода при запуске
+func (c *Client) PromptProfileInfo() string {
+	tokens := c.ContextTokens()
+	if tokens == 0 {
+		return fmt.Sprintf("%s (контекст модели %s неизвестен)", c.PromptProfile(), c.model)
+	}
+	return fmt.Sprintf("%s (контекст %d токенов)", c.PromptProfile(), tokens)
+}
+
+// selectPromptProfile включает компактный промпт для моделей с маленьким контекстом
+func (c *Client) selectPromptProfile() {
+	tokens := c.ContextTokens()
+	c.compact = tokens > 0 && tokens <= compactContextLimit
+}
+
+// RequestPageText включает текст страницы в следующий компактный промпт (действие extract)
+func (c *Client) RequestPageText() {
+	c.pageTextRequested = true
+}
+
+// compactSystemPrompt - короткий системный промпт для моделей с маленьким контекстом
+var compactSystemPrompt = `Ты - AI-агент, управляющий браузером. Выбери ОДНО следующее действие и ответь ТОЛЬКО JSON.
+
+Действия:
+` + compactActionsPrompt() + `
+Показаны только самые подходящие к задаче элементы. Если нужного нет - используй navigate или extract.
+НЕ повторяй действие, которое уже не сработало.
+
+Формат:
+{"action": "...", "reasoning": "кратко", "text": "...", "value": "...", "url": "...", "is_complete": false}`
+
+// buildCompactPrompt строит короткий промпт: быстрый анализ страницы, до 5 самых
+// подходящих к задаче элементов каждого вида, однострочная история и текст страницы
+// только после действия extract
+func (c *Client) buildCompactPrompt(task string, pageContent interface{}, history []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Задача: %s\n", task))
+	writeClock(&sb, time.Now())
+	if len(c.preferredTargets) > 0 {
+		sb.WriteString(fmt.Sprintf("Предпочтительные элементы: %s\n", strings.Join(c.preferredTargets, ", ")))
+	}
+
+	if len(history) > 0 {
+		sb.WriteString("\nИстория:\n")
+		for _, h := range history[max(len(history)-compactHistory, 0):] {
+			sb.WriteString("- " + truncateRunes(strings.Join(strings.Fields(h), " "), compactHistoryRunes) + "\n")
+		}
+	}
+
+	var url, route, title string
+	var status int
+	var formErrors []string
+	var links []browser.Link
+	var buttons []browser.Button
+	var inputs []browser.Input
+	var tabs []browser.TabInfo
+	text := ""
+	switch page := pageContent.(type) {
+	case *browser.QuickPageInfo:
+		url, route, title, formErrors = page.URL, page.Route, page.Title, page.Errors
+		status = page.Status
+		links, buttons, tabs = page.Links, page.Buttons, page.Tabs
+	case *browser.PageContent:
+		url, route, title, formErrors = page.URL, page.Route, page.Title, page.Errors
+		status = page.Status
+		links, buttons, inputs, tabs = page.Links, page.Buttons, page.Inputs, page.Tabs
+		text = page.Text
+	default:
+		sb.WriteString(fmt.Sprintf("\nСтраница: %+v\n", pageContent))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\nURL: %s\n", url))
+	writeStatus(&sb, status)
+	writeRoute(&sb, route)
+	sb.WriteString(fmt.Sprintf("Title: %s\n", title))
+	writeFormErrors(&sb, formErrors)
+	writeNewElementsNote(&sb, links, buttons)
+
+	// Появившиеся после последнего действия элементы важнее совпадения со словами задачи
+	words := taskWords(task)
+	if top := topElements(len(links), compactMaxElements, func(i int) int {
+		return withNewBonus(c.relevance(words, links[i].Text), links[i].New)
+	}); len(top) > 0 {
+		sb.WriteString("\nСсылки:\n")
+		for _, i := range top {
+			sb.WriteString(fmt.Sprintf("- %s%s -> %s\n", newMark(links[i].New), truncateRunes(links[i].Text, 60), links[i].Href))
+		}
+	}
+	if top := topElements(len(buttons), compactMaxElements, func(i int) int {
+		return withNewBonus(c.relevance(words, buttons[i].Text, buttons[i].AriaLabel, buttons[i].Title), buttons[i].New)
+	}); len(top) > 0 {
+		sb.WriteString("\nКнопки:\n")
+		for _, i := range top {
+			label := buttons[i].Text
+			if label == "" {
+				label = buttons[i].AriaLabel
+			}
+			sb.WriteString(fmt.Sprintf("- %s'%s'\n", newMark(buttons[i].New), truncateRunes(label, 60)))
+		}
+	}
+	if top := topElements(len(inputs), compactMaxElements, func(i int) int {
+		return c.relevance(words, inputs[i].Placeholder, inputs[i].Name, inputs[i].Label)
+	}); len(top) > 0 {
+		sb.WriteString("\nПоля:\n")
+		for _, i := range top {
+			in := inputs[i]
+			state := ""
+			if in.Checked != nil {
+				state = fmt.Sprintf(" checked=%t", *in.Checked)
+			}
+			sb.WriteString(fmt.Sprintf("- placeholder='%s' name='%s' label='%s'%s%s%s\n", in.Placeholder, in.Name, truncateRunes(in.Label, 40), state, optionsNote(in, 10), constraintsNote(in)))
+		}
+	}
+	if len(tabs) > 1 {
+		sb.WriteString(fmt.Sprintf("\nВкладок открыто: %d\n", len(tabs)))
+	}
+
+	if c.pageTextRequested && text != "" {
+		c.pageTextRequested = false
+		sb.WriteString("\nТекст страницы:\n" + truncateRunes(text, compactTextRunes) + "\n")
+	}
+
+	sb.WriteString("\nСледующее действие (JSON):")
+	return sb.String()
+}
+
+// taskWords возвращает значимые слова задачи в нижнем регистре
+func taskWords(task string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(task), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(w) >= 3 {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// relevance оценивает, насколько элемент подходит к задаче: совпадения слов задачи
+// (по первым 5 буквам, чтобы учесть окончания) и предпочтительные элементы
+func (c *Client) relevance(words []string, texts ...string) int {
+	joined := strings.ToLower(strings.Join(texts, " "))
+	if strings.TrimSpace(joined) == "" {
+		return -1
+	}
+	score := 0
+	for _, w := range words {
+		stem := w
+		if runes := []rune(w); len(runes) > 5 {
+			stem = string(runes[:5])
+		}
+		if strings.Contains(joined, stem) {
+			score += 2
+		}
+	}
+	if c.isPreferred(texts...) {
+		score += 10
+	}
+	return score
+}
+
+// withNewBonus поднимает оценку элемента, появившегося после последнего действия
+// (элементы без текста остаются пропущенными)
+func withNewBonus(score int, isNew bool) int {
+	if isNew && score >= 0 {
+		return score + 5
+	}
+	return score
+}
+
+// topElements возвращает индексы не более limit элементов с наибольшей оценкой
+// (при равной оценке сохраняется порядок на странице; элементы без текста пропускаются)
+func topElements(n, limit int, score func(int) int) []int {
+	type scored struct{ index, score int }
+	var items []scored
+	for i := 0; i < n; i++ {
+		if s := score(i); s >= 0 {
+			items = append(items, scored{i, s})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].score > items[j].score })
+	var top []int
+	for _, item := range items[:min(limit, len(items))] {
+		top = append(top, item.index)
+	}
+	return top
+}
@@ -0,0 +1,40 @@
+package ai
+
+import "testing"
+
+func TestKnownModelTokens(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"gpt-4", 8192},
+		{"gpt-4-0613", 8192},
+		{"gpt-4-turbo-preview", 128000},
+		{"gpt-4-1106-preview", 128000},
+		{"gpt-4-32k-0613", 32768},
+		{"gpt-4o", 128000},
+		{"GPT-4o-mini", 128000},
+		{"gpt-4.1-mini", 1047576},
+		{"gpt-4.5-preview", 0},
+		{"gpt-3.5-turbo-instruct", 4096},
+		{"gpt-3.5-turbo-0125", 16385},
+		{"llama3:8b", 8192},
+		{"llama3.1:70b", 0},
+		{"gpt-40", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := knownModelTokens(tt.model); got != tt.want {
+				t.Errorf("knownModelTokens(%q) = %d, want %d", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptProfileByModel(t *testing.T) {
+	for model, want := range map[string]string{"gpt-4": PromptCompact, "gpt-4.1": PromptFull, "gpt-4o-mini": PromptFull} {
+		if got := NewClientWithProvider(&fakeProvider{}, model).PromptProfile(); got != want {
+			t.Errorf("PromptProfile(%s) = %s, want %s", model, got, want)
+		}
+	}
+}
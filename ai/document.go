@@ -0,0 +1,126 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Бюджет текста документа. Короткий документ отправляется модели целиком;
+// длинный читается окнами с перекрытием, а по каждому окну обновляются заметки,
+// относящиеся к вопросу, - ответ строится по заметкам.
+const (
+	documentWindowChars   = 12000 // символов в одном окне
+	documentWindowOverlap = 500   // перекрытие окон, чтобы не разрезать числа и фразы
+	maxDocumentWindows    = 20    // дальше документ не читается
+	maxDocumentNotesChars = 3000
+)
+
+// AnswerFromDocument отвечает на вопрос пользователя по тексту документа
+func (c *Client) AnswerFromDocument(ctx context.Context, question, name, text string) (string, error) {
+	windows, truncated := splitDocument(text)
+	if len(windows) == 0 {
+		return "", fmt.Errorf("документ %s пуст", name)
+	}
+
+	if len(windows) == 1 {
+		return c.documentCompletion(ctx, fmt.Sprintf(`Документ "%s":
+---
+%s
+---
+
+Вопрос: %s
+
+Ответь на вопрос только по содержимому документа: кратко, с точными числами, датами и суммами из текста.
+Если ответа в документе нет, так и скажи.`, name, windows[0], question), 700)
+	}
+
+	notes := ""
+	for i, window := range windows {
+		var err error
+		notes, err = c.documentCompletion(ctx, fmt.Sprintf(`Ты читаешь длинный документ "%s" по частям (часть %d из %d).
+
+Вопрос пользователя: %s
+
+Заметки по предыдущим частям:
+%s
+
+Текущая часть:
+---
+%s
+---
+
+Обнови заметки: сохрани из текущей части только факты, нужные для ответа на вопрос (точные числа, даты, суммы,
+названия), и все важное из предыдущих заметок. Не больше %d символов. Если в части нет ничего полезного, верни
+заметки без изменений.`, name, i+1, len(windows), question, notesOrNone(notes), window, maxDocumentNotesChars), 900)
+		if err != nil {
+			return "", err
+		}
+		notes = truncateRunes(strings.TrimSpace(notes), maxDocumentNotesChars)
+	}
+
+	suffix := ""
+	if truncated {
+		suffix = fmt.Sprintf("\nДокумент прочитан не полностью: первые %d частей.", maxDocumentWindows)
+	}
+	return c.documentCompletion(ctx, fmt.Sprintf(`Заметки по документу "%s":
+%s
+%s
+Вопрос: %s
+
+Ответь на вопрос по заметкам: кратко, с точными числами, датами и суммами. Если ответа нет, так и скажи.`,
+		name, notesOrNone(notes), suffix, question), 700)
+}
+
+func (c *Client) documentCompletion(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	content, err := c.complete(
+		ctx,
+		[]Message{
+			{
+				Role:    RoleSystem,
+				Content: "Ты читаешь документы пользователя и отвечаешь на вопросы по их содержимому. Не выдумывай данные, которых нет в тексте.",
+			},
+			{
+				Role:    RoleUser,
+				Content: prompt,
+			},
+		},
+		CompletionOptions{Model: c.model, Temperature: 0.2, MaxTokens: maxTokens},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to read document: %w", err)
+	}
+	return strings.TrimSpace(content), nil
+}
+
+// splitDocument режет текст на окна с перекрытием; truncated - текст не поместился в лимит окон
+func splitDocument(text string) (windows []string, truncated bool) {
+	runes := []rune(strings.TrimSpace(text))
+	step := documentWindowChars - documentWindowOverlap
+	for start := 0; start < len(runes); start += step {
+		if len(windows) == maxDocumentWindows {
+			return windows, true
+		}
+		end := min(start+documentWindowChars, len(runes))
+		windows = append(windows, string(runes[start:end]))
+		if end == len(runes) {
+			break
+		}
+	}
+	return windows, false
+}
+
+func notesOrNone(notes string) string {
+	if notes == "" {
+		return "(пока нет)"
+	}
+	return notes
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "..."
+}
@@ -0,0 +1,33 @@
+package ai
+
+// SetEscalationModel задает более сильную модель, которая принимает одно решение,
+// когда агент застрял на повторении одного и того же действия (пустая строка - не
+// переключаться)
+func (c *Client) SetEscalationModel(model string) {
+	c.escalationModel = model
+}
+
+// EscalationModel возвращает модель для решения после зацикливания
+func (c *Client) EscalationModel() string {
+	return c.escalationModel
+}
+
+// EscalateNextDecision переключает следующий вызов MakeDecision на модель из
+// SetEscalationModel. Возвращает false, если такая модель не задана.
+func (c *Client) EscalateNextDecision() bool {
+	if c.escalationModel == "" || c.escalationModel == c.model {
+		return false
+	}
+	c.escalateNext = true
+	return true
+}
+
+// decisionModel возвращает модель для очередного решения; переключение на более
+// сильную модель действует один раз
+func (c *Client) decisionModel() string {
+	if c.escalateNext {
+		c.escalateNext = false
+		return c.escalationModel
+	}
+	return c.model
+}
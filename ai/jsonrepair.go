@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"strings"
+)
+
+// repairJSON исправляет типичные ошибки модели в JSON ответа:
+//   - запятые перед } и ]
+//   - строки и ключи в одинарных кавычках ('action': 'click')
+//   - переводы строк и табуляции внутри строк
+//   - неэкранированные кавычки внутри строк ("text": "Кнопка "Купить"")
+//
+// Кавычка внутри строки считается закрывающей, только если за ней (после пробелов)
+// идет , : } ] или конец текста.
+func repairJSON(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	sb.Grow(len(s) + 16)
+
+	inString := false
+	var quote rune
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+
+		if !inString {
+			switch ch {
+			case '"', '\'':
+				inString, quote = true, ch
+				sb.WriteRune('"')
+			case ',':
+				if next := nextNonSpace(runes, i+1); next == '}' || next == ']' {
+					continue
+				}
+				sb.WriteRune(ch)
+			default:
+				sb.WriteRune(ch)
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\\' && i+1 < len(runes):
+			i++
+			if runes[i] == '\'' {
+				// \' недопустимо в JSON - апостроф не требует экранирования
+				sb.WriteRune('\'')
+			} else {
+				sb.WriteRune('\\')
+				sb.WriteRune(runes[i])
+			}
+		case ch == quote:
+			if next := nextNonSpace(runes, i+1); next == 0 || strings.ContainsRune(",:}]", next) {
+				inString = false
+				sb.WriteRune('"')
+			} else if quote == '"' {
+				sb.WriteString(`\"`)
+			} else {
+				sb.WriteRune(ch)
+			}
+		case ch == '"':
+			// Двойная кавычка внутри строки в одинарных кавычках
+			sb.WriteString(`\"`)
+		case ch == '\n':
+			sb.WriteString(`\n`)
+		case ch == '\r':
+			sb.WriteString(`\r`)
+		case ch == '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
+
+// nextNonSpace возвращает первый непробельный символ начиная с позиции from (0 - конец текста)
+func nextNonSpace(runes []rune, from int) rune {
+	for j := from; j < len(runes); j++ {
+		switch runes[j] {
+		case ' ', '\n', '\r', '\t':
+			continue
+		}
+		return runes[j]
+	}
+	return 0
+}
+
+// extractJSONObject возвращает первый сбалансированный {...} из текста с учетом
+// строк в любых кавычках, чтобы вложенные объекты (extracted_data) не обрезались
+func extractJSONObject(s string) string {
+	start := strings.IndexByte(s, '{')
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	inString := false
+	var quote byte
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+		if inString {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'':
+			inString, quote = true, ch
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1]
+			}
+		}
+	}
+	return ""
+}
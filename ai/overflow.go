@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+	"github.com/sashabaranov/go-openai"
+)
+
+// blindHistory - записей истории в промпте без страницы
+const blindHistory = 3
+
+// contextOverflowMarkers - признаки ошибки переполнения контекста у OpenAI и
+// совместимых серверов (Ollama, vLLM, LM Studio), если код ошибки не передан
+var contextOverflowMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context length",
+	"context window",
+	"too many tokens",
+	"reduce the length",
+}
+
+// isContextOverflow сообщает, что запрос не поместился в контекст модели
+func isContextOverflow(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		if code, ok := apiErr.Code.(string); ok && code == "context_length_exceeded" {
+			return true
+		}
+	}
+	message := strings.ToLower(err.Error())
+	for _, marker := range contextOverflowMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// smallerProfile возвращает следующий, более короткий профиль промпта ("" - короче некуда)
+func smallerProfile(profile string) string {
+	switch profile {
+	case PromptFull:
+		return PromptCompact
+	case PromptCompact:
+		return PromptBlind
+	}
+	return ""
+}
+
+// PromptDowngrade возвращает профиль, на который пришлось перейти в последнем
+// MakeDecision из-за переполнения контекста ("" - промпт поместился). Следующее
+// решение снова строится с обычным профилем.
+func (c *Client) PromptDowngrade() string {
+	return c.promptDowngrade
+}
+
+// buildBlindPrompt строит промпт без содержимого страницы - последняя ступень, когда
+// не поместился даже компактный: задача, адрес и заголовок страницы и короткая история
+func (c *Client) buildBlindPrompt(task string, pageContent interface{}, history []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Задача: %s\n", task))
+	writeClock(&sb, time.Now())
+
+	if len(history) > 0 {
+		sb.WriteString("\nИстория:\n")
+		for _, h := range history[max(len(history)-blindHistory, 0):] {
+			sb.WriteString("- " + truncateRunes(strings.Join(strings.Fields(h), " "), compactHistoryRunes) + "\n")
+		}
+	}
+
+	switch page := pageContent.(type) {
+	case *browser.QuickPageInfo:
+		sb.WriteString(fmt.Sprintf("\nURL: %s\nTitle: %s\n", page.URL, truncateRunes(page.Title, 100)))
+	case *browser.PageContent:
+		sb.WriteString(fmt.Sprintf("\nURL: %s\nTitle: %s\n", page.URL, truncateRunes(page.Title, 100)))
+	}
+
+	sb.WriteString("\nСтраница слишком большая для контекста модели - элементы не показаны. " +
+		"Выбери действие без списка: navigate по прямому URL (поиск - через параметры адреса), go_back, press_key " +
+		"или click/fill по тексту, который знаешь из истории.\n")
+	sb.WriteString("\nСледующее действие (JSON):")
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestIsContextOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"openai code", &openai.APIError{Code: "context_length_exceeded", Message: "too long"}, true},
+		{"compatible server wording", errors.New("This model's maximum context length is 8192 tokens"), true},
+		{"ollama wording", errors.New("input exceeds the context window"), true},
+		{"rate limit", &openai.APIError{Code: "rate_limit_exceeded", Message: "slow down"}, false},
+		{"network", errors.New("connection reset by peer"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isContextOverflow(tt.err); got != tt.want {
+				t.Errorf("isContextOverflow(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeDecisionShrinksPromptOnOverflow(t *testing.T) {
+	overflow := fakeReply{err: &openai.APIError{Code: "context_length_exceeded", Message: "maximum context length exceeded"}}
+	page := &browser.QuickPageInfo{URL: "https://shop.example/catalog", Title: "Каталог"}
+
+	tests := []struct {
+		name      string
+		replies   []fakeReply
+		requests  int
+		downgrade string
+		lastHas   string
+		wantErr   bool
+	}{
+		{"fits", []fakeReply{{content: completeDecision}}, 1, "", "", false},
+		{"compact", []fakeReply{overflow, {content: completeDecision}}, 2, PromptCompact, "Задача: купить чайник", false},
+		{"blind", []fakeReply{overflow, overflow, {content: completeDecision}}, 3, PromptBlind, "элементы не показаны", false},
+		{"nothing fits", []fakeReply{overflow, overflow, overflow}, 3, PromptBlind, "элементы не показаны", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{replies: tt.replies}
+			client := NewClientWithProvider(provider, "gpt-4o")
+
+			_, err := client.MakeDecision(context.Background(), "купить чайник", page, []string{"шаг 1"}, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MakeDecision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(provider.requests) != tt.requests || client.PromptDowngrade() != tt.downgrade {
+				t.Errorf("requests = %d, downgrade = %q, want %d, %q", len(provider.requests), client.PromptDowngrade(), tt.requests, tt.downgrade)
+			}
+			last := provider.requests[len(provider.requests)-1]
+			if tt.downgrade != "" && !strings.HasPrefix(last[0].Content, compactSystemPrompt) {
+				t.Errorf("shrunk request kept the full system prompt")
+			}
+			if !strings.Contains(last[1].Content, tt.lastHas) {
+				t.Errorf("last prompt lacks %q:\n%s", tt.lastHas, last[1].Content)
+			}
+		})
+	}
+}
+
+func TestPromptDowngradeResetsNextDecision(t *testing.T) {
+	overflow := fakeReply{err: errors.New("context length exceeded")}
+	provider := &fakeProvider{replies: []fakeReply{overflow, {content: completeDecision}, {content: completeDecision}}}
+	client := NewClientWithProvider(provider, "gpt-4o")
+
+	if _, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if client.PromptDowngrade() != PromptCompact {
+		t.Fatalf("PromptDowngrade() = %q, want compact", client.PromptDowngrade())
+	}
+	if _, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if client.PromptDowngrade() != "" || strings.HasPrefix(provider.requests[2][0].Content, compactSystemPrompt) {
+		t.Errorf("next decision still shrunk: downgrade %q", client.PromptDowngrade())
+	}
+}
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/console"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Роли сообщений диалога с моделью
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message - сообщение диалога с моделью
+type Message struct {
+	Role    string
+	Content string
+}
+
+// CompletionOptions - параметры одного запроса к модели
+type CompletionOptions struct {
+	Model       string
+	Temperature float32
+	MaxTokens   int
+	// Usage, если задан, получает токены запроса и ответа по данным провайдера;
+	// провайдер, который их не знает, оставляет нули
+	Usage *TokenUsage
+}
+
+// ErrContentFiltered возвращает провайдер, если ответ модели заблокирован его
+// фильтром содержимого. Клиент считает такой ответ отказом модели.
+var ErrContentFiltered = errors.New("ответ заблокирован фильтром содержимого провайдера")
+
+// Provider - бэкенд LLM: OpenAI, совместимый сервер или своя реализация (Anthropic,
+// Ollama, Azure OpenAI, заглушка для тестов). Complete возвращает текст ответа модели
+// на диалог messages.
+type Provider interface {
+	Complete(ctx context.Context, messages []Message, opts CompletionOptions) (string, error)
+}
+
+// OpenAIProvider - провайдер на API OpenAI (github.com/sashabaranov/go-openai)
+type OpenAIProvider struct {
+	client *openai.Client
+	config openai.ClientConfig
+}
+
+// NewOpenAIProvider создает провайдер OpenAI с ключом apiKey
+func NewOpenAIProvider(apiKey string) *OpenAIProvider {
+	return NewOpenAIProviderWithConfig(openai.DefaultConfig(apiKey))
+}
+
+// NewOpenAIProviderWithConfig создает провайдер с настройками go-openai: свой BaseURL
+// для совместимых серверов или openai.DefaultAzureConfig для Azure OpenAI
+func NewOpenAIProviderWithConfig(config openai.ClientConfig) *OpenAIProvider {
+	return &OpenAIProvider{client: newOpenAIClient(config), config: config}
+}
+
+// newOpenAIClient создает клиент go-openai, транспорт которого запоминает Retry-After
+func newOpenAIClient(config openai.ClientConfig) *openai.Client {
+	httpClient := http.Client{}
+	if config.HTTPClient != nil {
+		httpClient = *config.HTTPClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = retryAfterTransport{base: base}
+	config.HTTPClient = &httpClient
+	return openai.NewClientWithConfig(config)
+}
+
+// retryAfterKey - ключ контекста запроса, под которым Complete ждет паузу Retry-After
+type retryAfterKey struct{}
+
+// retryAfterTransport передает в Complete паузу из заголовков ответа с ошибкой:
+// go-openai не сохраняет заголовки в APIError
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= http.StatusBadRequest {
+		if retryAfter, ok := req.Context().Value(retryAfterKey{}).(*time.Duration); ok {
+			*retryAfter = parseRetryAfter(resp.Header, time.Now())
+		}
+	}
+	return resp, err
+}
+
+// Name возвращает имя провайдера для журналов
+func (p *OpenAIProvider) Name() string {
+	if p.config.APIType == openai.APITypeAzure || p.config.APIType == openai.APITypeAzureAD {
+		return "azure-openai"
+	}
+	return "openai"
+}
+
+// SetTransport задает HTTP-транспорт запросов к API (nil - обычный транспорт)
+func (p *OpenAIProvider) SetTransport(rt http.RoundTripper) {
+	config := p.config
+	if rt != nil {
+		config.HTTPClient = &http.Client{Transport: rt}
+	}
+	p.client = newOpenAIClient(config)
+}
+
+func (p *OpenAIProvider) Complete(ctx context.Context, messages []Message, opts CompletionOptions) (string, error) {
+	request := openai.ChatCompletionRequest{
+		Model:       opts.Model,
+		Messages:    make([]openai.ChatCompletionMessage, 0, len(messages)),
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	}
+	for _, m := range messages {
+		request.Messages = append(request.Messages, openai.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+
+	var retryAfter time.Duration
+	resp, err := p.client.CreateChatCompletion(context.WithValue(ctx, retryAfterKey{}, &retryAfter), request)
+	if err != nil {
+		return "", openAITransientError(err, retryAfter)
+	}
+	if opts.Usage != nil {
+		*opts.Usage = TokenUsage{Prompt: resp.Usage.PromptTokens, Completion: resp.Usage.CompletionTokens}
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("empty AI response")
+	}
+	if resp.Choices[0].FinishReason == openai.FinishReasonContentFilter {
+		return resp.Choices[0].Message.Content, ErrContentFiltered
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// openAITransientError оборачивает в TransientError ответы 429, 500, 502 и 503, кроме
+// исчерпанной квоты: ее повтор не исправит
+func openAITransientError(err error, retryAfter time.Duration) error {
+	status := 0
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		if code, _ := apiErr.Code.(string); code == "insufficient_quota" {
+			return err
+		}
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return &TransientError{StatusCode: status, RetryAfter: retryAfter, Err: err}
+	}
+	return err
+}
+
+// providerName возвращает имя провайдера, если он его сообщает (метод Name)
+func providerName(p Provider) string {
+	if named, ok := p.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return "custom"
+}
+
+// complete отправляет запрос провайдеру, учитывает израсходованные токены и
+// повторяет запрос после временной ошибки (TransientError) по политике повторов.
+// Пауза перед повтором прерывается отменой ctx, а пауза дольше срока ctx не начинается.
+func (c *Client) complete(ctx context.Context, messages []Message, opts CompletionOptions) (string, error) {
+	var usage TokenUsage
+	if opts.Usage == nil {
+		opts.Usage = &usage
+	}
+	for attempt := 0; ; attempt++ {
+		*opts.Usage = TokenUsage{}
+		content, err := c.provider.Complete(ctx, messages, opts)
+		c.addUsage(*opts.Usage)
+
+		var transient *TransientError
+		if err == nil || !errors.As(err, &transient) || attempt >= c.retry.maxRetries {
+			return content, err
+		}
+		delay := c.retry.delay(attempt, transient)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// Пауза не уложится в срок задачи - повтор бессмысленен
+			return "", fmt.Errorf("повтор запроса к модели не уложится в срок (пауза %v): %w", delay, err)
+		}
+		console.Printf("⏳ Модель временно недоступна (HTTP %d), повтор %d из %d через %v...\n",
+			transient.StatusCode, attempt+1, c.retry.maxRetries, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("повтор запроса к модели прерван: %w (последняя ошибка: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeReply - ответ фейкового провайдера на один запрос
+type fakeReply struct {
+	content string
+	err     error
+}
+
+// fakeProvider отвечает заданными ответами по порядку и запоминает запросы
+type fakeProvider struct {
+	replies  []fakeReply
+	requests [][]Message
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, messages []Message, opts CompletionOptions) (string, error) {
+	p.requests = append(p.requests, messages)
+	if len(p.replies) == 0 {
+		return "", errors.New("fake provider: no replies left")
+	}
+	reply := p.replies[0]
+	p.replies = p.replies[1:]
+	if opts.Usage != nil {
+		*opts.Usage = TokenUsage{Prompt: 10, Completion: 5}
+	}
+	return reply.content, reply.err
+}
+
+const completeDecision = `{"action": "complete", "is_complete": true, "summary": "готово"}`
+
+func TestMakeDecisionRetriesTransientError(t *testing.T) {
+	provider := &fakeProvider{replies: []fakeReply{
+		{err: &TransientError{StatusCode: 429, Err: errors.New("rate limited")}},
+		{err: &TransientError{StatusCode: 503, Err: errors.New("unavailable")}},
+		{content: completeDecision},
+	}}
+	client := NewClientWithProvider(provider, "gpt-4o")
+	client.SetRetryPolicy(3, time.Millisecond)
+
+	decision, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0)
+	if err != nil {
+		t.Fatalf("MakeDecision() error: %v", err)
+	}
+	if !decision.IsComplete || len(provider.requests) != 3 {
+		t.Errorf("decision = %+v after %d requests, want complete after 3", decision, len(provider.requests))
+	}
+	if usage := client.Usage(); usage.Prompt != 30 {
+		t.Errorf("Usage().Prompt = %d, want every attempt counted (30)", usage.Prompt)
+	}
+}
+
+func TestMakeDecisionRetryLimit(t *testing.T) {
+	transient := &TransientError{StatusCode: 502, Err: errors.New("bad gateway")}
+	provider := &fakeProvider{replies: []fakeReply{{err: transient}, {err: transient}, {content: completeDecision}}}
+	client := NewClientWithProvider(provider, "gpt-4o")
+	client.SetRetryPolicy(1, time.Millisecond)
+
+	_, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0)
+	var got *TransientError
+	if !errors.As(err, &got) || got.StatusCode != 502 {
+		t.Errorf("MakeDecision() error = %v, want the transient error after the retry limit", err)
+	}
+	if len(provider.requests) != 2 {
+		t.Errorf("requests = %d, want 2", len(provider.requests))
+	}
+}
+
+func TestMakeDecisionContentFiltered(t *testing.T) {
+	provider := &fakeProvider{replies: []fakeReply{
+		{err: ErrContentFiltered},
+		{content: completeDecision},
+	}}
+	client := NewClientWithProvider(provider, "gpt-4o")
+
+	_, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0)
+	var refusal *RefusalError
+	if !errors.As(err, &refusal) {
+		t.Fatalf("MakeDecision() error = %v, want RefusalError", err)
+	}
+	if refusal.Model != "gpt-4o" || len(provider.requests) != 1 {
+		t.Errorf("RefusalError.Model = %q after %d requests, want gpt-4o without a retry", refusal.Model, len(provider.requests))
+	}
+}
+
+func TestMakeDecisionRefusalRetry(t *testing.T) {
+	refusal := "I'm sorry, but I can't help with that."
+	tests := []struct {
+		name     string
+		replies  []fakeReply
+		refused  bool
+		fallback string
+	}{
+		{"clarification helps", []fakeReply{{content: refusal}, {content: completeDecision}}, false, ""},
+		{"refused again", []fakeReply{{content: refusal}, {content: refusal}}, true, ""},
+		{"retry filtered", []fakeReply{{content: refusal}, {err: ErrContentFiltered}}, true, ""},
+		{"fallback model", []fakeReply{{content: refusal}, {content: completeDecision}}, false, "gpt-4o-mini"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &modelRecorder{fakeProvider: fakeProvider{replies: tt.replies}}
+			client := NewClientWithProvider(provider, "gpt-4o")
+			client.SetRefusalFallbackModel(tt.fallback)
+
+			decision, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0)
+			var declined *RefusalError
+			if tt.refused != errors.As(err, &declined) {
+				t.Fatalf("MakeDecision() = %+v, %v, refused want %v", decision, err, tt.refused)
+			}
+			if len(provider.requests) != 2 {
+				t.Errorf("requests = %d, want one retry", len(provider.requests))
+			}
+			if last := provider.requests[1]; last[len(last)-1].Content != refusalClarification {
+				t.Errorf("retry lacks the clarification")
+			}
+			if want := tt.fallback; want != "" && provider.models[1] != want {
+				t.Errorf("retry model = %q, want %q", provider.models[1], want)
+			}
+		})
+	}
+}
+
+// modelRecorder - fakeProvider, который запоминает модель каждого запроса
+type modelRecorder struct {
+	fakeProvider
+	models []string
+}
+
+func (p *modelRecorder) Complete(ctx context.Context, messages []Message, opts CompletionOptions) (string, error) {
+	p.models = append(p.models, opts.Model)
+	return p.fakeProvider.Complete(ctx, messages, opts)
+}
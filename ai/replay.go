@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Angabebr/Golang-AI-agent/console"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Exchange - запрос к API модели и ответ на него. Заголовки (и ключ API в них)
+// не записываются - только тела запроса и ответа.
+type Exchange struct {
+	Time     time.Time       `json:"time"`
+	Request  json.RawMessage `json:"request"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// SetTransport задает HTTP-транспорт запросов к API модели: запись обмена для
+// пакета воспроизведения (Recorder) или ответы из записи вместо сети (Replayer).
+// nil возвращает обычный транспорт. Действует на провайдеры с методом SetTransport
+// (OpenAIProvider); у остальных запись и воспроизведение недоступны.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	if p, ok := c.provider.(interface{ SetTransport(http.RoundTripper) }); ok {
+		p.SetTransport(rt)
+	}
+}
+
+// PageTextRequested сообщает, покажет ли компактный промпт текст страницы на следующем шаге
+func (c *Client) PageTextRequested() bool {
+	return c.pageTextRequested
+}
+
+// Recorder записывает каждый обмен с API модели в JSONL-поток
+type Recorder struct {
+	mu        sync.Mutex
+	base      http.RoundTripper
+	w         io.Writer
+	exchanges int
+}
+
+// NewRecorder создает транспорт, который пропускает запросы через base
+// (nil - http.DefaultTransport) и пишет обмен в w
+func NewRecorder(base http.RoundTripper, w io.Writer) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{base: base, w: w}
+}
+
+// Count возвращает число записанных обменов: границы обменов одного решения
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exchanges
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	exchange := Exchange{Time: time.Now(), Request: rawJSON(body), Status: resp.StatusCode, Response: rawJSON(respBody)}
+	line, err := json.Marshal(exchange)
+	if err == nil {
+		r.mu.Lock()
+		_, err = r.w.Write(append(line, '\n'))
+		r.exchanges++
+		r.mu.Unlock()
+	}
+	if err != nil {
+		console.Printf("⚠️  Не удалось записать обмен с моделью: %v\n", err)
+	}
+	return resp, nil
+}
+
+// rawJSON сохраняет тело как JSON; не-JSON (страница ошибки прокси) - как строку
+func rawJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return json.RawMessage("null")
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+// Replayer отвечает на запросы к API модели записанными ответами - без сети.
+// Запрос, который отличается от записанного (изменился промпт), все равно получает
+// записанный ответ, а отличие попадает в Drift.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	next      int
+	drift     []string
+}
+
+// NewReplayer создает транспорт воспроизведения
+func NewReplayer() *Replayer {
+	return &Replayer{}
+}
+
+// Serve задает обмены, которыми отвечать на следующие запросы (по порядку)
+func (r *Replayer) Serve(exchanges []Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges, r.next, r.drift = exchanges, 0, nil
+}
+
+// Drift возвращает описания запросов, отличающихся от записанных
+func (r *Replayer) Drift() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.drift...)
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next >= len(r.exchanges) {
+		return nil, fmt.Errorf("replay: нет записанного ответа для запроса %d", r.next+1)
+	}
+	exchange := r.exchanges[r.next]
+	r.next++
+	if diff := messagesDiff(exchange.Request, body); diff != "" {
+		r.drift = append(r.drift, fmt.Sprintf("запрос %d: %s", r.next, diff))
+	}
+
+	response := []byte(exchange.Response)
+	var text string
+	if json.Unmarshal(response, &text) == nil {
+		response = []byte(text)
+	}
+	return &http.Response{
+		StatusCode: exchange.Status,
+		Status:     http.StatusText(exchange.Status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(response)),
+		Request:    req,
+	}, nil
+}
+
+// messagesDiff сравнивает сообщения записанного и нового запроса и описывает
+// первое отличие (пустая строка - промпт не изменился)
+func messagesDiff(recorded, current []byte) string {
+	var was, now struct {
+		Messages []openai.ChatCompletionMessage `json:"messages"`
+	}
+	if json.Unmarshal(recorded, &was) != nil || json.Unmarshal(current, &now) != nil {
+		return ""
+	}
+	if len(was.Messages) != len(now.Messages) {
+		return fmt.Sprintf("сообщений %d вместо %d", len(now.Messages), len(was.Messages))
+	}
+	for i := range was.Messages {
+		if was.Messages[i].Content != now.Messages[i].Content {
+			return fmt.Sprintf("изменилось сообщение %s: %s", now.Messages[i].Role, firstDifference(was.Messages[i].Content, now.Messages[i].Content))
+		}
+	}
+	return ""
+}
+
+// firstDifference показывает окрестность первого различия двух текстов
+func firstDifference(a, b string) string {
+	ra, rb := []rune(a), []rune(b)
+	i := 0
+	for i < len(ra) && i < len(rb) && ra[i] == rb[i] {
+		i++
+	}
+	start := max(0, i-20)
+	excerpt := func(r []rune) string {
+		return string(r[min(start, len(r)):min(i+40, len(r))])
+	}
+	return fmt.Sprintf("было «%s», стало «%s»", excerpt(ra), excerpt(rb))
+}
@@ -0,0 +1,202 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Пороги отчета. Результат короче ReportThresholdChars остается в ответе модели как есть;
+// длинный делится на части по источникам (элементы массива, ключи объекта), каждая
+// часть пересказывается отдельным запросом, а пересказы объединяются в итоговый отчет.
+const (
+	ReportThresholdChars = 6000
+	reportChunkChars     = 8000
+)
+
+// ReportChunks делит extracted_data на части не длиннее reportChunkChars, не разрезая
+// элементы: массив - по элементам, объект - по ключам (обычно это источники), иначе - текстом.
+// maxChunks ограничивает число частей: соседние части склеиваются, чтобы уложиться в бюджет.
+func ReportChunks(data json.RawMessage, maxChunks int) []string {
+	items := reportItems(data)
+
+	var chunks []string
+	var current strings.Builder
+	for _, item := range items {
+		if current.Len() > 0 && current.Len()+len(item) > reportChunkChars {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(item)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	if maxChunks < 1 {
+		maxChunks = 1
+	}
+	for len(chunks) > maxChunks {
+		merged := make([]string, 0, (len(chunks)+1)/2)
+		for i := 0; i < len(chunks); i += 2 {
+			if i+1 < len(chunks) {
+				merged = append(merged, chunks[i]+"\n"+chunks[i+1])
+			} else {
+				merged = append(merged, chunks[i])
+			}
+		}
+		chunks = merged
+	}
+	return chunks
+}
+
+// Report строит итоговый отчет по большому результату задачи в формате Markdown:
+// части пересказываются по отдельности (map), затем объединяются по порядку (reduce).
+// calls - число запросов к модели, чтобы агент учел их в бюджете задачи.
+func (c *Client) Report(ctx context.Context, task string, chunks []string) (report string, calls int, err error) {
+	if len(chunks) == 0 {
+		return "", 0, fmt.Errorf("нет данных для отчета")
+	}
+
+	if len(chunks) == 1 {
+		report, err = c.reportCompletion(ctx, fmt.Sprintf(`Задача: %s
+
+Собранные данные:
+%s
+
+Составь отчет в формате Markdown. Перечисли ВСЕ элементы из данных, ничего не пропускай и не обобщай
+"и другие". Сохрани точные названия, числа, цены, даты и ссылки.`, task, chunks[0]), 3000)
+		return report, 1, err
+	}
+
+	parts := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		part, err := c.reportCompletion(ctx, fmt.Sprintf(`Задача: %s
+
+Часть %d из %d собранных данных:
+%s
+
+Перескажи эту часть в формате Markdown для итогового отчета: перечисли ВСЕ элементы части списком,
+с точными названиями, числами, ценами, датами и ссылками. Без вступления и выводов.`, task, i+1, len(chunks), chunk), 2500)
+		calls++
+		if err != nil {
+			return "", calls, err
+		}
+		parts = append(parts, part)
+	}
+
+	var sb strings.Builder
+	for i, part := range parts {
+		sb.WriteString(fmt.Sprintf("### Часть %d\n%s\n\n", i+1, part))
+	}
+	summary, err := c.reportCompletion(ctx, fmt.Sprintf(`Задача: %s
+
+Отчет собран по частям:
+%s
+Напиши краткое введение к отчету в формате Markdown (3-6 предложений): что найдено, сколько элементов,
+главные выводы по задаче. Не повторяй списки - они будут приложены ниже.`, task, sb.String()), 800)
+	calls++
+	if err != nil {
+		return "", calls, err
+	}
+
+	// Списки частей вставляются в отчет как есть и по порядку, чтобы объединение не потеряло элементы
+	var out strings.Builder
+	out.WriteString(summary)
+	out.WriteString("\n\n")
+	for _, part := range parts {
+		out.WriteString(strings.TrimSpace(part))
+		out.WriteString("\n\n")
+	}
+	return strings.TrimSpace(out.String()), calls, nil
+}
+
+func (c *Client) reportCompletion(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	content, err := c.complete(
+		ctx,
+		[]Message{
+			{
+				Role:    RoleSystem,
+				Content: "Ты составляешь отчеты по данным, собранным агентом в браузере. Не выдумывай данные и не пропускай элементы.",
+			},
+			{
+				Role:    RoleUser,
+				Content: prompt,
+			},
+		},
+		CompletionOptions{Model: c.model, Temperature: 0.2, MaxTokens: maxTokens},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to build report: %w", err)
+	}
+	return strings.TrimSpace(content), nil
+}
+
+// reportItems делит данные на неделимые элементы отчета
+func reportItems(data json.RawMessage) []string {
+	var array []json.RawMessage
+	if json.Unmarshal(data, &array) == nil {
+		items := make([]string, 0, len(array))
+		for _, item := range array {
+			items = append(items, compactJSON(item))
+		}
+		return items
+	}
+
+	var object map[string]json.RawMessage
+	if json.Unmarshal(data, &object) == nil {
+		// {"items": [...]} - делим вложенный массив, а не единственный ключ
+		if len(object) == 1 {
+			for _, value := range object {
+				if json.Unmarshal(value, &array) == nil {
+					return reportItems(value)
+				}
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		// Порядок ключей JSON не сохраняется в map - сортируем для стабильного отчета
+		sort.Strings(keys)
+		items := make([]string, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%q: %s", key, compactJSON(object[key])))
+		}
+		return items
+	}
+
+	return splitText(string(data), reportChunkChars)
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// splitText режет текст на куски по limit байт, не разрезая руны
+func splitText(text string, limit int) []string {
+	var parts []string
+	for len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		parts = append(parts, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// reportItemsJSON - массив из n элементов по ~size символов
+func reportItemsJSON(n, size int) json.RawMessage {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id":%d,"text":%q}`, i, strings.Repeat("x", size))
+	}
+	return json.RawMessage("[" + strings.Join(items, ",") + "]")
+}
+
+func TestReportChunks(t *testing.T) {
+	t.Run("array split by items", func(t *testing.T) {
+		chunks := ReportChunks(reportItemsJSON(10, 3000), 10)
+		if len(chunks) != 5 {
+			t.Fatalf("chunks = %d, want two 3000-char items per 8000-char chunk", len(chunks))
+		}
+		for i, chunk := range chunks {
+			if len(chunk) > reportChunkChars {
+				t.Errorf("chunk %d is %d chars, over the limit", i, len(chunk))
+			}
+			if !strings.Contains(chunk, fmt.Sprintf(`"id":%d,`, i*2)) || !strings.Contains(chunk, fmt.Sprintf(`"id":%d,`, i*2+1)) {
+				t.Errorf("chunk %d does not hold items %d and %d in order", i, i*2, i*2+1)
+			}
+		}
+	})
+
+	t.Run("budget merges neighbours in order", func(t *testing.T) {
+		chunks := ReportChunks(reportItemsJSON(10, 3000), 2)
+		if len(chunks) != 2 {
+			t.Fatalf("chunks = %d, want 2", len(chunks))
+		}
+		joined := strings.Join(chunks, "\n")
+		last := -1
+		for i := 0; i < 10; i++ {
+			pos := strings.Index(joined, fmt.Sprintf(`"id":%d,`, i))
+			if pos < last {
+				t.Fatalf("item %d out of order after merging", i)
+			}
+			last = pos
+		}
+	})
+
+	t.Run("single key object splits nested array", func(t *testing.T) {
+		data := json.RawMessage(fmt.Sprintf(`{"items": %s}`, reportItemsJSON(4, 5000)))
+		if chunks := ReportChunks(data, 10); len(chunks) != 4 {
+			t.Errorf("chunks = %d, want one per nested item", len(chunks))
+		}
+	})
+
+	t.Run("object split by sorted sources", func(t *testing.T) {
+		long := strings.Repeat("y", 5000)
+		data := json.RawMessage(fmt.Sprintf(`{"b.example": %q, "a.example": %q}`, long, long))
+		chunks := ReportChunks(data, 10)
+		if len(chunks) != 2 || !strings.HasPrefix(chunks[0], `"a.example"`) || !strings.HasPrefix(chunks[1], `"b.example"`) {
+			t.Errorf("chunks start with %q, want a.example then b.example", []string{chunks[0][:12], chunks[len(chunks)-1][:12]})
+		}
+	})
+
+	t.Run("text split keeps runes", func(t *testing.T) {
+		text := json.RawMessage(strings.Repeat("й", reportChunkChars))
+		chunks := ReportChunks(text, 10)
+		if len(chunks) != 2 {
+			t.Fatalf("chunks = %d, want 2", len(chunks))
+		}
+		for i, chunk := range chunks {
+			if !utf8.ValidString(chunk) {
+				t.Errorf("chunk %d cuts a rune", i)
+			}
+		}
+	})
+}
+
+func TestReportMergeOrder(t *testing.T) {
+	provider := &fakeProvider{replies: []fakeReply{
+		{content: "- первый"},
+		{content: "- второй"},
+		{content: "- третий"},
+		{content: "Найдено три элемента."},
+	}}
+	client := NewClientWithProvider(provider, "gpt-4o")
+
+	report, calls, err := client.Report(context.Background(), "собрать", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 4 || len(provider.requests) != 4 {
+		t.Errorf("calls = %d, requests = %d, want 3 parts and a merge", calls, len(provider.requests))
+	}
+	if want := "Найдено три элемента.\n\n- первый\n\n- второй\n\n- третий"; report != want {
+		t.Errorf("report = %q, want %q", report, want)
+	}
+	for i, chunk := range []string{"a", "b", "c"} {
+		prompt := provider.requests[i][1].Content
+		if !strings.Contains(prompt, fmt.Sprintf("Часть %d из 3", i+1)) || !strings.Contains(prompt, chunk) {
+			t.Errorf("request %d prompt = %q", i+1, prompt)
+		}
+	}
+	merge := provider.requests[3][1].Content
+	if strings.Index(merge, "- первый") > strings.Index(merge, "- третий") {
+		t.Errorf("merge prompt lists parts out of order: %q", merge)
+	}
+}
+
+func TestReportSingleChunk(t *testing.T) {
+	provider := &fakeProvider{replies: []fakeReply{{content: "# Отчет"}}}
+	client := NewClientWithProvider(provider, "gpt-4o")
+	report, calls, err := client.Report(context.Background(), "собрать", []string{"a"})
+	if err != nil || report != "# Отчет" || calls != 1 {
+		t.Errorf("Report() = %q, %d calls, %v, want one call", report, calls, err)
+	}
+	if _, _, err := client.Report(context.Background(), "собрать", nil); err == nil {
+		t.Error("Report() without chunks succeeded")
+	}
+}
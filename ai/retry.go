@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Повтор запросов к модели после ответа 429 или временной ошибки сервера
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Second
+	maxRetryDelay     = 30 * time.Second // потолок экспоненциальной паузы
+)
+
+// TransientError - временная ошибка провайдера: превышен лимит запросов (429) или
+// сервер временно недоступен (500, 502, 503). Клиент повторяет такой запрос по
+// политике SetRetryPolicy. Свой провайдер возвращает ее, чтобы получить повторы.
+type TransientError struct {
+	StatusCode int
+	RetryAfter time.Duration // пауза из заголовка Retry-After; 0 - сервер ее не указал
+	Err        error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// retryPolicy - сколько раз и с какой начальной паузой повторять временные ошибки
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// SetRetryPolicy задает повторы запроса после ответа 429 или временной ошибки
+// сервера: не больше maxRetries раз (0 - без повторов), пауза удваивается от
+// baseDelay, но не больше 30 секунд. Пауза из заголовка Retry-After важнее расчетной,
+// но и она не больше 30 секунд.
+func (c *Client) SetRetryPolicy(maxRetries int, baseDelay time.Duration) {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryDelay
+	}
+	c.retry = retryPolicy{maxRetries: maxRetries, baseDelay: baseDelay}
+}
+
+// delay возвращает паузу перед повтором номер attempt (с нуля). Retry-After тоже
+// ограничен maxRetryDelay: сервер, попросивший подождать час, не должен подвесить задачу.
+func (p retryPolicy) delay(attempt int, err *TransientError) time.Duration {
+	if err.RetryAfter > 0 {
+		return min(err.RetryAfter, maxRetryDelay)
+	}
+	delay := p.baseDelay
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// parseRetryAfter разбирает паузу из заголовков ответа: retry-after-ms (OpenAI) или
+// Retry-After в секундах либо датой. 0 - заголовка нет или он некорректен.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After-Ms"))); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
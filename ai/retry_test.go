@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// apiResponse - ответ тестового сервера API на один запрос
+type apiResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+// completionBody - успешный ответ chat/completions с текстом content
+func completionBody(content string) string {
+	return fmt.Sprintf(`{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":7,"total_tokens":19}}`, content)
+}
+
+func errorBody(code, message string) string {
+	return fmt.Sprintf(`{"error":{"message":%q,"type":"error","code":%q}}`, message, code)
+}
+
+// newAPIClient поднимает тестовый сервер API, который отвечает responses по порядку
+// (последний ответ повторяется), и клиент OpenAI к нему. Возвращает и число запросов.
+func newAPIClient(t *testing.T, responses ...apiResponse) (*Client, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[min(requests, len(responses)-1)]
+		requests++
+		for key, value := range resp.header {
+			w.Header().Set(key, value)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+
+	config := openai.DefaultConfig("test-key")
+	config.BaseURL = server.URL + "/v1"
+	client := NewClientWithProvider(NewOpenAIProviderWithConfig(config), "gpt-4o")
+	client.SetRetryPolicy(3, time.Millisecond)
+	return client, &requests
+}
+
+func TestRetryThenSuccess(t *testing.T) {
+	client, requests := newAPIClient(t,
+		apiResponse{status: 429, header: map[string]string{"Retry-After-Ms": "5"}, body: errorBody("rate_limit_exceeded", "slow down")},
+		apiResponse{status: 502, body: errorBody("", "bad gateway")},
+		apiResponse{status: 200, body: completionBody(completeDecision)},
+	)
+
+	decision, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0)
+	if err != nil {
+		t.Fatalf("MakeDecision() error: %v", err)
+	}
+	if !decision.IsComplete || *requests != 3 {
+		t.Errorf("decision = %+v after %d requests, want complete after 3", decision, *requests)
+	}
+	if usage := client.Usage(); usage.Prompt != 12 || usage.Completion != 7 {
+		t.Errorf("Usage() = %+v", usage)
+	}
+}
+
+func TestInsufficientQuotaNotRetried(t *testing.T) {
+	client, requests := newAPIClient(t,
+		apiResponse{status: 429, body: errorBody("insufficient_quota", "You exceeded your current quota")},
+		apiResponse{status: 200, body: completionBody(completeDecision)},
+	)
+
+	_, err := client.MakeDecision(context.Background(), "задача", "страница", nil, 0)
+	var transient *TransientError
+	if err == nil || errors.As(err, &transient) || !strings.Contains(err.Error(), "quota") {
+		t.Errorf("MakeDecision() error = %v, want the quota error without retries", err)
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	client, requests := newAPIClient(t,
+		apiResponse{status: 503, header: map[string]string{"Retry-After": "20"}, body: errorBody("", "overloaded")},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.MakeDecision(ctx, "задача", "страница", nil, 0)
+	var transient *TransientError
+	if !errors.As(err, &transient) || transient.StatusCode != 503 {
+		t.Errorf("MakeDecision() error = %v, want the 503 error", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || *requests != 1 {
+		t.Errorf("gave up after %v and %d requests, want at once after 1", elapsed, *requests)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{maxRetries: 5, baseDelay: time.Second}
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"first", 0, 0, time.Second},
+		{"doubled", 2, 0, 4 * time.Second},
+		{"capped", 10, 0, maxRetryDelay},
+		{"retry-after", 0, 5 * time.Second, 5 * time.Second},
+		{"retry-after capped", 0, time.Hour, maxRetryDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.delay(tt.attempt, &TransientError{RetryAfter: tt.retryAfter}); got != tt.want {
+				t.Errorf("delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"milliseconds", http.Header{"Retry-After-Ms": {"1500"}}, 1500 * time.Millisecond},
+		{"seconds", http.Header{"Retry-After": {"7"}}, 7 * time.Second},
+		{"date", http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute},
+		{"past date", http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, 0},
+		{"garbage", http.Header{"Retry-After": {"soon"}}, 0},
+		{"none", http.Header{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSafeModeInstructionsInSystemPrompt(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		provider := &fakeProvider{replies: []fakeReply{{content: completeDecision}}}
+		client := NewClientWithProvider(provider, "gpt-4o")
+		client.SetSafeMode(enabled)
+
+		if _, err := client.MakeDecision(context.Background(), "купить чайник", "страница", nil, 0); err != nil {
+			t.Fatal(err)
+		}
+		system := provider.requests[0][0]
+		if system.Role != RoleSystem {
+			t.Fatalf("first message role = %q, want system", system.Role)
+		}
+		if got := strings.Contains(system.Content, "РЕЖИМ SAFE-MODE"); got != enabled {
+			t.Errorf("safe-mode %v: instructions in system prompt = %v", enabled, got)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// ChooseTab выбирает среди вкладок ту, о которой говорит задача ("в той вкладке, где
+// открыт Ozon"). Возвращает номер вкладки в tabs, начиная с 1; 0 - ни одна не подходит.
+func (c *Client) ChooseTab(ctx context.Context, task string, tabs []browser.TabInfo) (int, error) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Задача: %s\n\nОткрытые вкладки:\n", task))
+	for i, tab := range tabs {
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, truncateRunes(tab.Title, 100), truncateRunes(tab.URL, 150)))
+	}
+	sb.WriteString(`
+В какой из вкладок нужно выполнять задачу? Ответь ТОЛЬКО JSON:
+{"tab": номер вкладки или 0, если ни одна не подходит}`)
+
+	content, err := c.complete(ctx, []Message{
+		{Role: RoleSystem, Content: "Ты выбираешь вкладку браузера для задачи пользователя. Отвечай только в формате JSON."},
+		{Role: RoleUser, Content: sb.String()},
+	}, CompletionOptions{Model: c.model, Temperature: 0, MaxTokens: 20})
+	if err != nil {
+		return 0, fmt.Errorf("failed to choose tab: %w", err)
+	}
+
+	var choice struct {
+		Tab int `json:"tab"`
+	}
+	if raw := extractJSONObject(content); raw == "" || json.Unmarshal([]byte(raw), &choice) != nil {
+		return 0, fmt.Errorf("не удалось разобрать выбор вкладки: %s", truncateRunes(strings.TrimSpace(content), 200))
+	}
+	if choice.Tab < 0 || choice.Tab > len(tabs) {
+		return 0, fmt.Errorf("модель выбрала несуществующую вкладку %d", choice.Tab)
+	}
+	return choice.Tab, nil
+}
@@ -0,0 +1,40 @@
+package ai
+
+import "sync"
+
+// TokenUsage - токены, израсходованные запросами к модели
+type TokenUsage struct {
+	Prompt     int `json:"prompt"`
+	Completion int `json:"completion"`
+}
+
+// Total возвращает общее число токенов
+func (u TokenUsage) Total() int {
+	return u.Prompt + u.Completion
+}
+
+// Sub возвращает расход с момента снимка prev
+func (u TokenUsage) Sub(prev TokenUsage) TokenUsage {
+	return TokenUsage{Prompt: u.Prompt - prev.Prompt, Completion: u.Completion - prev.Completion}
+}
+
+// usageCounter накапливает расход токенов по данным провайдера
+type usageCounter struct {
+	mu    sync.Mutex
+	usage TokenUsage
+}
+
+func (c *Client) addUsage(usage TokenUsage) {
+	c.usage.mu.Lock()
+	defer c.usage.mu.Unlock()
+	c.usage.usage.Prompt += usage.Prompt
+	c.usage.usage.Completion += usage.Completion
+}
+
+// Usage возвращает расход токенов клиентом с момента создания. Расход задачи -
+// разница снимков до и после нее (TokenUsage.Sub).
+func (c *Client) Usage() TokenUsage {
+	c.usage.mu.Lock()
+	defer c.usage.mu.Unlock()
+	return c.usage.usage
+}
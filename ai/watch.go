@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// conditionReplyTokens - ответ на проверку условия: короткий JSON
+const conditionReplyTokens = 80
+
+// ConditionCheck - результат дешевой проверки условия наблюдения
+type ConditionCheck struct {
+	Met      bool   `json:"met"`
+	Evidence string `json:"evidence"`
+	Tokens   int    `json:"-"` // токены запроса и ответа по данным провайдера
+}
+
+// CheckCondition проверяет по тексту страницы, выполнено ли условие наблюдения
+// ("есть билеты дешевле 5000"). Текст страницы обрезается так, чтобы запрос
+// с ответом уложился в budget токенов.
+func (c *Client) CheckCondition(ctx context.Context, condition, page string, budget int) (*ConditionCheck, error) {
+	const template = `Условие: %s
+
+Страница:
+---
+%s
+---
+
+Выполнено ли условие по содержимому страницы? Ответь ТОЛЬКО JSON:
+{"met": true/false, "evidence": "коротко: что на странице подтверждает ответ (цена, дата, текст)"}`
+
+	// Около 3 символов на токен для смеси кириллицы и латиницы
+	overhead := (utf8.RuneCountInString(template)+utf8.RuneCountInString(condition))/3 + conditionReplyTokens
+	if budget <= overhead {
+		return nil, fmt.Errorf("бюджет проверки %d токенов меньше минимального (%d)", budget, overhead+1)
+	}
+	prompt := fmt.Sprintf(template, condition, truncateRunes(page, (budget-overhead)*3))
+
+	var usage TokenUsage
+	content, err := c.complete(ctx, []Message{
+		{Role: RoleSystem, Content: "Ты проверяешь условие по содержимому веб-страницы. Отвечай только в формате JSON."},
+		{Role: RoleUser, Content: prompt},
+	}, CompletionOptions{Model: c.model, Temperature: 0, MaxTokens: conditionReplyTokens, Usage: &usage})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check condition: %w", err)
+	}
+
+	check := &ConditionCheck{}
+	if raw := extractJSONObject(content); raw == "" || json.Unmarshal([]byte(raw), check) != nil {
+		return nil, fmt.Errorf("не удалось разобрать ответ проверки условия: %s", truncateRunes(strings.TrimSpace(content), 200))
+	}
+	check.Evidence = strings.TrimSpace(check.Evidence)
+	check.Tokens = usage.Total()
+	return check, nil
+}
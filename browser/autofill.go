@@ -0,0 +1,57 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// autofillDisabledFeatures - функции Chrome, которые показывают поверх формы подсказки
+// автозаполнения и сохраненных паролей и предложения менеджера паролей. Дописываются
+// к --disable-features при запуске браузера.
+const autofillDisabledFeatures = "AutofillServerCommunication,PasswordManagerOnboarding,PasswordLeakDetection,AutofillShowTypePredictions"
+
+// dismissAutofill закрывает выпадающий список автозаполнения Chrome после ввода в поле:
+// иначе он перекрывает соседние поля и кнопку входа, а следующий Enter выбирает
+// сохраненный логин вместо отправки формы. Список закрывается клавишей Escape; на время
+// нажатия странице ставится перехватчик, чтобы Escape не закрыл модальное окно с формой,
+// если списка не было. Действует только для обычных полей ввода в фокусе.
+func dismissAutofill() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var blocked bool
+		if err := evaluate(`
+			(function() {
+				const el = document.activeElement;
+				const skip = ['checkbox', 'radio', 'range', 'file', 'submit', 'button', 'hidden', 'color'];
+				if (!el || el.tagName !== 'INPUT' || skip.includes((el.type || '').toLowerCase())) return false;
+				const block = e => {
+					if (e.key !== 'Escape') return;
+					e.stopImmediatePropagation();
+					e.preventDefault();
+				};
+				window.addEventListener('keydown', block, true);
+				window.addEventListener('keyup', block, true);
+				window.__agentEscapeBlock = () => {
+					window.removeEventListener('keydown', block, true);
+					window.removeEventListener('keyup', block, true);
+					delete window.__agentEscapeBlock;
+				};
+				// Перехватчик снимается и без второго вызова, если тот не выполнится
+				setTimeout(() => window.__agentEscapeBlock && window.__agentEscapeBlock(), 2000);
+				return true;
+			})()
+		`, &blocked).Do(ctx); err != nil || !blocked {
+			return nil
+		}
+
+		for _, kind := range []input.KeyType{input.KeyDown, input.KeyUp} {
+			if err := input.DispatchKeyEvent(kind).WithKey("Escape").WithCode("Escape").WithWindowsVirtualKeyCode(27).Do(ctx); err != nil {
+				break
+			}
+			time.Sleep(30 * time.Millisecond)
+		}
+		return evaluate(`window.__agentEscapeBlock && window.__agentEscapeBlock()`, nil).Do(ctx)
+	})
+}
@@ -5,98 +5,250 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
 )
 
 type Browser struct {
-	ctx             context.Context
+	ctx             context.Context // вкладка, в которой выполняются действия
 	cancel          context.CancelFunc
+	rootCtx         context.Context               // вкладка, открытая при запуске
+	tabs            map[target.ID]context.Context // подключенные вкладки (SwitchToTab)
+	tabCancels      map[target.ID]context.CancelFunc
 	allocCtx        context.Context
 	allocCancel     context.CancelFunc
 	keepAlive       context.Context
 	keepAliveCancel context.CancelFunc
+
+	userDataDir         string
+	profile             string
+	profileDirectory    string
+	headless            bool
+	ignoreCertErrors    bool
+	allowOldChrome      bool
+	caps                Capabilities
+	pdfOptions          PDFOptions
+	timeoutScale        float64
+	contentLimits       ContentLimits
+	extraHeaders        map[string]string
+	extractionSelectors ExtractionSelectors
+	autoScroll          bool       // прокрутка перед извлечением (SetAutoScroll)
+	downloadDir         string     // каталог загрузок (WithDownloadDir)
+	load                loadBudget // длительность операций и режим низкой мощности
+
+	logMu          sync.Mutex
+	logger         Logger   // сообщения chromedp (SetLogger), nil - log.Printf
+	ignorePatterns []string // дополнительный шум chromedp (WithIgnorePatterns)
+
+	framesMu     sync.Mutex
+	frameRefs    map[string]frameRef             // frame-N из последнего извлечения -> фрейм CDP
+	frameTargets map[cdp.FrameID]context.Context // подключения к кросс-доменным фреймам
+
+	routeMu     sync.Mutex
+	routeChange *RouteChange // смена маршрута SPA после последнего клика
+
+	changeMarkURL string // адрес страницы на момент MarkPageState
+
+	rawMu sync.Mutex // RunActions выполняются по одному, Restart ждет их завершения
+
+	eventsMu       sync.Mutex
+	fileChooser    *page.EventFileChooserOpened
+	fileChooserSeq int
+	crashedTabs    map[target.ID]bool       // вкладки, процесс отрисовки которых упал
+	pageStatuses   map[target.ID]int        // HTTP-статус основного документа вкладки
+	routes         map[target.ID]*tabRoutes // смены маршрута SPA в документе вкладки
+	routeSeq       int                      // сквозной номер смены маршрута (routeMark)
+	downloads      []*download              // загрузки файлов по порядку начала
+	openedTabs     []openedTab              // вкладки, открытые страницей и еще не выданные WaitForNewTab
+	openedSeq      int                      // сколько вкладок открыто страницами (MarkNewTabs)
+}
+
+// Option настраивает браузер при создании
+type Option func(*Browser)
+
+// WithProfile выбирает именованный профиль агента: cookies и сессии хранятся
+// в отдельном подкаталоге userDataDir/profiles/<name>
+func WithProfile(name string) Option {
+	return func(b *Browser) {
+		b.profile = name
+	}
+}
+
+// WithProfileDirectory выбирает профиль Chrome внутри user-data-dir
+// (флаг --profile-directory, например "Profile 2"). По умолчанию "Default".
+func WithProfileDirectory(dir string) Option {
+	return func(b *Browser) {
+		b.profileDirectory = dir
+	}
+}
+
+// WithIgnoreCertErrors разрешает сайты с самоподписанными и другими недоверенными
+// сертификатами (флаг --ignore-certificate-errors) - для внутренних и тестовых стендов.
+// Отключает проверку сертификатов для всех сайтов, поэтому по умолчанию выключено.
+func WithIgnoreCertErrors(ignore bool) Option {
+	return func(b *Browser) {
+		b.ignoreCertErrors = ignore
+	}
+}
+
+func NewBrowser(userDataDir string, headless bool, opts ...Option) (*Browser, error) {
+	b := &Browser{
+		userDataDir:      userDataDir,
+		headless:         headless,
+		profileDirectory: "Default",
+		contentLimits:    DefaultContentLimits(),
+		pdfOptions:       DefaultPDFOptions(),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.profileDirectory == "" {
+		b.profileDirectory = "Default"
+	}
+
+	if err := ValidateProfileName(b.profile); err != nil {
+		return nil, err
+	}
+	if b.profile == DefaultProfile {
+		b.profile = ""
+	}
+
+	keepAliveCtx, keepAliveCancel := context.WithCancel(context.Background())
+
+	b.keepAlive = keepAliveCtx
+	b.keepAliveCancel = keepAliveCancel
+
+	if err := b.start(); err != nil {
+		keepAliveCancel()
+		return nil, err
+	}
+
+	go b.keepAliveLoop()
+
+	return b, nil
 }
 
-func NewBrowser(userDataDir string, headless bool) (*Browser, error) {
+// start запускает процесс браузера и открывает вкладку, с которой работает агент
+func (b *Browser) start() error {
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", headless),
+		chromedp.Flag("headless", b.headless),
 		chromedp.Flag("disable-gpu", false),
 		chromedp.Flag("disable-dev-shm-usage", false),
 		chromedp.Flag("no-sandbox", false),
-		chromedp.UserDataDir(userDataDir),
+		chromedp.UserDataDir(b.ProfileDir()),
 		chromedp.WindowSize(1920, 1080),
 		chromedp.Flag("no-first-run", true),
 		chromedp.Flag("no-default-browser-check", true),
 		chromedp.Flag("disable-default-apps", true),
 		chromedp.Flag("disable-infobars", true),
 		chromedp.Flag("disable-popup-blocking", true),
-		chromedp.Flag("profile-directory", "Default"),
+		chromedp.Flag("profile-directory", b.profileDirectory),
 		chromedp.Flag("disable-extensions", false),
 		chromedp.Flag("disable-background-networking", true),
 		chromedp.Flag("disable-background-timer-throttling", true),
 		chromedp.Flag("disable-renderer-backgrounding", true),
 		chromedp.Flag("single-process", false),
-		chromedp.Flag("disable-features", "VizDisplayCompositor,TranslateUI"),
+		chromedp.Flag("disable-features", "VizDisplayCompositor,TranslateUI,"+autofillDisabledFeatures),
+		chromedp.Flag("disable-save-password-bubble", true),
+		chromedp.Flag("ignore-certificate-errors", b.ignoreCertErrors),
 	)
 
 	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(func(format string, v ...interface{}) {
-		msg := fmt.Sprintf(format, v...)
-
-		ignorePatterns := []string{
-			"could not unmarshal event",
-			"unexpected end of JSON input",
-			"unknown IPAddressSpace value",
-			"unknown PrivateNetworkRequestPolicy value",
-			"parse error",
-			"cookiePart",
-		}
-
-		shouldIgnore := false
-		for _, pattern := range ignorePatterns {
-			if contains(msg, pattern) {
-				shouldIgnore = true
-				break
-			}
-		}
-
-		if !shouldIgnore {
-		}
-	}))
-
-	keepAliveCtx, keepAliveCancel := context.WithCancel(context.Background())
-
-	b := &Browser{
-		ctx:             ctx,
-		cancel:          cancel,
-		allocCtx:        allocCtx,
-		allocCancel:     allocCancel,
-		keepAlive:       keepAliveCtx,
-		keepAliveCancel: keepAliveCancel,
-	}
+	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(b.logChromedp))
+
+	b.ctx = ctx
+	b.cancel = cancel
+	b.rootCtx = ctx
+	b.tabs = make(map[target.ID]context.Context)
+	b.tabCancels = make(map[target.ID]context.CancelFunc)
+	b.eventsMu.Lock()
+	b.crashedTabs = make(map[target.ID]bool)
+	b.pageStatuses = make(map[target.ID]int)
+	b.routes = make(map[target.ID]*tabRoutes)
+	b.downloads = nil
+	b.openedTabs = nil
+	b.eventsMu.Unlock()
+	b.allocCtx = allocCtx
+	b.allocCancel = allocCancel
 
 	if err := chromedp.Run(ctx,
 		chromedp.Navigate("about:blank"),
 		chromedp.WaitVisible("body", chromedp.ByQuery),
 	); err != nil {
-		keepAliveCancel()
-		return nil, fmt.Errorf("failed to start browser: %w\n\nВозможные причины:\n- Chrome/Chromium не установлен\n- Chrome заблокирован антивирусом\n- Недостаточно прав для запуска\n- Директория браузера занята другим процессом\n\nУстановите Chrome или Chromium: https://www.google.com/chrome/", err)
+		cancel()
+		allocCancel()
+		return fmt.Errorf("failed to start browser: %w\n\nВозможные причины:\n- Chrome/Chromium не установлен\n- Chrome заблокирован антивирусом\n- Недостаточно прав для запуска\n- Директория браузера занята другим процессом\n\nУстановите Chrome или Chromium: https://www.google.com/chrome/", err)
 	}
 
 	select {
 	case <-ctx.Done():
-		keepAliveCancel()
-		return nil, fmt.Errorf("browser context was canceled after initialization")
+		allocCancel()
+		return fmt.Errorf("browser context was canceled after initialization")
 	default:
 	}
 
-	go b.keepAliveLoop()
+	if err := b.detectCapabilities(); err != nil {
+		cancel()
+		allocCancel()
+		return err
+	}
 
-	return b, nil
+	b.watchNewTabs()
+	b.setupTab()
+	return nil
+}
+
+// setupTab настраивает текущую вкладку: подписки на события, общие скрипты и заголовки
+// запросов действуют только во вкладке, для которой их включили
+func (b *Browser) setupTab() {
+	if err := b.setupListeners(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+
+	if err := b.injectHelpers(); err != nil {
+		fmt.Printf("⚠️  failed to inject page helpers: %v\n", err)
+	}
+
+	if len(b.extraHeaders) > 0 {
+		if err := b.applyExtraHeaders(); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	if err := b.applyDownloadBehavior(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+// Restart закрывает браузер и запускает его заново с тем же профилем.
+// Используется, когда контекст chromedp перестал отвечать после долгой сессии.
+func (b *Browser) Restart() error {
+	b.rawMu.Lock()
+	defer b.rawMu.Unlock()
+	b.keepAliveCancel()
+	b.cancel()
+	b.allocCancel()
+
+	// Chrome освобождает блокировку профиля не сразу после завершения процесса
+	time.Sleep(2 * time.Second)
+
+	b.eventsMu.Lock()
+	b.fileChooser = nil
+	b.eventsMu.Unlock()
+
+	b.keepAlive, b.keepAliveCancel = context.WithCancel(context.Background())
+	if err := b.start(); err != nil {
+		return fmt.Errorf("failed to restart browser: %w", err)
+	}
+
+	go b.keepAliveLoop()
+	return nil
 }
 
 func (b *Browser) Navigate(url string) error {
@@ -148,41 +300,44 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
 	default:
 	}
+	if err := b.crashedError(); err != nil {
+		return nil, err
+	}
 
 	// Увеличиваем таймаут и добавляем повторные попытки
 	maxRetries := 3
 	var content PageContent
 	var err error
 
+	var elapsed time.Duration
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		ctx, cancel := context.WithTimeout(b.ctx, 45*time.Second)
+		start := time.Now()
 		
-		// Сначала прокручиваем страницу и ждем загрузки динамического контента
-		_ = 		chromedp.Run(ctx,
-			chromedp.Sleep(1*time.Second), // Ждем загрузки динамического контента
-			// Минимальный скроллинг только для загрузки ленивого контента
-			chromedp.Evaluate(`
-				if (document.body.scrollHeight > window.innerHeight * 2) {
+		// Ждем загрузки динамического контента
+		_ = chromedp.Run(ctx, chromedp.Sleep(1*time.Second))
+		if b.autoScroll {
+			// Минимальный скроллинг для загрузки ленивого контента; положение, до
+			// которого прокрутила модель, восстанавливается
+			_ = chromedp.Run(ctx,
+				chromedp.Evaluate(`
+				if (document.body && document.body.scrollHeight > window.innerHeight * 2) {
 					// Только если страница длинная - немного прокручиваем
-					window.scrollTo(0, window.innerHeight);
-					setTimeout(() => window.scrollTo(0, 0), 200);
+					const y = window.scrollY;
+					window.scrollTo(0, y + window.innerHeight);
+					setTimeout(() => window.scrollTo(0, y), 200);
 				}
 			`, nil),
-			chromedp.Sleep(500*time.Millisecond),
-		)
+				chromedp.Sleep(500*time.Millisecond),
+			)
+		}
 		
 		err = chromedp.Run(ctx,
-			chromedp.Evaluate(`
+			evaluate(`
 		(function() {
-			function isVisible(el) {
-				if (!el) return false;
-				const style = window.getComputedStyle(el);
-				return style.display !== 'none' && 
-					   style.visibility !== 'hidden' && 
-					   style.opacity !== '0' &&
-					   el.offsetWidth > 0 && 
-					   el.offsetHeight > 0;
-			}
+			const limits = ` + b.contentLimitsJS() + `;
+			` + useHelpersJS + `
+			` + b.extractionSelectorsJS() + `
 			
 			function isInViewport(el) {
 				if (!el) return false;
@@ -200,54 +355,17 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 			
 			// Умное извлечение текста - только видимая часть и важные элементы
 			const bodyText = document.body.innerText || '';
-			const textPreview = bodyText.length > 5000 ? bodyText.substring(0, 5000) + '...' : bodyText;
+			const textPreview = bodyText.length > limits.max_text_chars ? bodyText.substring(0, limits.max_text_chars) + '...' : bodyText;
 			
 			// Извлечение структурированных данных - УВЕЛИЧИВАЕМ лимиты
-			let links = Array.from(document.querySelectorAll('a')).slice(0, 200).map(a => {
-				const text = (a.innerText || a.textContent || '').trim();
-				const href = a.href;
+			let links = Array.from(document.querySelectorAll(withExtra('links', 'a'))).slice(0, Math.max(200, limits.max_links)).map(a => {
+				const text = withTitle(a, a.innerText || a.textContent || '');
+				const href = hrefOf(a);
 				const visible = isVisible(a);
 				return { text, href, visible };
 			}).filter(l => l.visible && l.text && l.href);
 			
-			// Функция для получения текста кнопки, включая иконки и символы
-			function getButtonText(b) {
-				// Сначала пробуем обычный текст
-				let text = (b.innerText || b.textContent || b.value || '').trim();
-				
-				// Если текста нет, пробуем aria-label, title
-				if (!text) {
-					text = (b.getAttribute('aria-label') || b.getAttribute('title') || '').trim();
-				}
-				
-				// Если текста все еще нет, ищем иконки и символы
-				if (!text) {
-					// Ищем SVG иконки
-					const svg = b.querySelector('svg');
-					if (svg) {
-						const svgText = svg.textContent || svg.getAttribute('aria-label') || '';
-						if (svgText) text = svgText.trim();
-					}
-					
-					// Ищем символы (+, -, ×, и т.д.)
-					const symbols = b.textContent.match(/[+×−−−]/);
-					if (symbols && symbols.length > 0) {
-						text = symbols[0];
-					}
-					
-					// Ищем по классам/ID для кнопок добавления
-					const className = (typeof b.className === 'string' ? b.className : (b.className ? b.className.toString() : '')).toLowerCase();
-					const id = (b.id || '').toLowerCase();
-					if (className.includes('add') || className.includes('cart') || className.includes('basket') || 
-						id.includes('add') || id.includes('cart') || id.includes('basket')) {
-						text = text || '+';
-					}
-				}
-				
-				return text;
-			}
-			
-			let buttons = Array.from(document.querySelectorAll('button, [role="button"], input[type="submit"], input[type="button"], a.button, .btn, [class*="button"], [class*="add"], [class*="cart"]')).slice(0, 200).map(b => {
+			let buttons = Array.from(document.querySelectorAll(withExtra('buttons', 'button, [role="button"], input[type="submit"], input[type="button"], a.button, .btn, [class*="button"], [class*="add"], [class*="cart"]'))).slice(0, Math.max(200, limits.max_buttons)).map(b => {
 				const text = getButtonText(b);
 				const visible = isVisible(b);
 				const enabled = !b.disabled && !b.hasAttribute('disabled');
@@ -321,14 +439,20 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 				};
 			}).filter(b => b.visible && b.enabled && (b.text || b.text === '+')); // Разрешаем кнопки с "+"
 			
-			const inputs = Array.from(document.querySelectorAll('input, textarea, select')).slice(0, 25).map(i => {
-				const type = i.type || (i.tagName.toLowerCase() === 'textarea' ? 'textarea' : 'text');
+			` + choiceHelpersJS + `
+			` + inputConstraintsJS + `
+			` + selectStateJS + `
+			const inputs = Array.from(document.querySelectorAll(withExtra('inputs', 'input, textarea, select, [role="checkbox"], [role="radio"], [role="switch"]'))).slice(0, limits.max_inputs).map(i => {
+				const choice = i.matches(choiceSelector);
+				const type = i.type || i.getAttribute('role') || (i.tagName.toLowerCase() === 'textarea' ? 'textarea' : 'text');
 				const placeholder = i.placeholder || '';
 				const name = i.name || '';
 				const id = i.id || '';
-				const label = i.labels && i.labels.length > 0 ? i.labels[0].textContent : '';
-				const visible = isVisible(i);
-				return { type, placeholder, name, id, label, visible };
+				const label = choice ? choiceLabel(i).substring(0, 80) : (i.labels && i.labels.length > 0 ? i.labels[0].textContent : '');
+				// Стилизованный флажок скрывает сам input, видна только подпись
+				const visible = choice ? choiceVisible(i) : isVisible(i);
+				const checked = choice ? isChecked(i) : undefined;
+				return { type, placeholder, name, id, label, visible, checked, ...inputConstraints(i), ...selectState(i) };
 			}).filter(i => i.visible);
 			
 			const headings = Array.from(document.querySelectorAll('h1, h2, h3, h4')).slice(0, 25).map(h => {
@@ -337,7 +461,7 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 			}).filter(h => h.text);
 			
 			// Извлечение списков и таблиц для структурированных данных
-			const lists = Array.from(document.querySelectorAll('ul, ol')).slice(0, 20).map(list => {
+			const lists = Array.from(document.querySelectorAll('ul, ol')).slice(0, limits.max_lists).map(list => {
 				const items = Array.from(list.querySelectorAll('li')).slice(0, 50).map(li => {
 					return (li.innerText || li.textContent || '').trim();
 				}).filter(item => item);
@@ -345,7 +469,7 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 			}).filter(list => list.length > 0);
 			
 			// Извлечение таблиц
-			const tables = Array.from(document.querySelectorAll('table')).slice(0, 10).map(table => {
+			const tables = Array.from(document.querySelectorAll('table')).slice(0, limits.max_tables).map(table => {
 				const rows = Array.from(table.querySelectorAll('tr')).slice(0, 50).map(tr => {
 					const cells = Array.from(tr.querySelectorAll('td, th')).map(cell => {
 						return (cell.innerText || cell.textContent || '').trim();
@@ -419,22 +543,37 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 				buttons = emailButtons.concat(buttons);
 			}
 			
+			// Положение элементов для проверки цели перед действием (VerifyTarget)
+			`+targetSnapshotJS+`;
+			
 			return {
 				url: window.location.href,
 				title: document.title,
 				text: textPreview,
-				links: links.slice(0, 200), // Ограничиваем итоговый размер
-				buttons: buttons.slice(0, 150),
+				links: links.slice(0, limits.max_links), // Ограничиваем итоговый размер
+				buttons: buttons.slice(0, limits.max_buttons),
 				inputs: inputs,
 				headings: headings,
 				lists: lists,
-				tables: tables
+				tables: tables,
+				media: `+mediaExtractionJS+`,
+				ranges: `+rangeExtractionJS+`,
+				dates: `+dateExtractionJS+`,
+				prices: `+priceExtractionJS+`,
+				errors: `+formErrorsJS+`
 			};
 		})()
 		`, &content),
 		)
 		
+		if err == nil {
+			content.Frames = b.extractFrames(ctx)
+			content.Route = b.currentRoute()
+			content.Status = b.currentStatus()
+		}
 		cancel()
+		elapsed = time.Since(start)
+		b.noteOperation(elapsed)
 		
 		if err == nil {
 			// Получаем информацию о всех вкладках
@@ -453,9 +592,10 @@ func (b *Browser) GetPageContent() (*PageContent, error) {
 		default:
 		}
 		
-		// Если это не последняя попытка, ждем перед повтором
+		// Если это не последняя попытка, ждем перед повтором - дольше, если
+		// попытка шла медленно и Chrome перегружен
 		if attempt < maxRetries {
-			time.Sleep(time.Duration(attempt) * time.Second)
+			time.Sleep(b.retryBackoff(attempt, elapsed))
 			continue
 		}
 	}
@@ -480,8 +620,9 @@ func (b *Browser) GetPageSummary() (string, error) {
 	}
 
 	err := chromedp.Run(ctx,
-		chromedp.Evaluate(`
+		evaluate(`
 		(function() {
+			` + useHelpersJS + `
 			const url = window.location.href;
 			const title = document.title;
 			
@@ -499,14 +640,14 @@ func (b *Browser) GetPageSummary() (string, error) {
 			const mainButtons = Array.from(document.querySelectorAll('button, [role="button"]')).slice(0, 5);
 			mainButtons.forEach(btn => {
 				const text = (btn.innerText || btn.textContent || '').trim();
-				if (text) keyElements.push('Button: ' + text);
+				if (text && isVisible(btn)) keyElements.push('Button: ' + text);
 			});
 			
 			// Основные ссылки
 			const mainLinks = Array.from(document.querySelectorAll('a')).slice(0, 5);
 			mainLinks.forEach(link => {
 				const text = (link.innerText || link.textContent || '').trim();
-				if (text && link.offsetParent !== null) {
+				if (text && isVisible(link)) {
 					keyElements.push('Link: ' + text);
 				}
 			});
@@ -549,6 +690,9 @@ func (b *Browser) GetQuickPageInfo() (*QuickPageInfo, error) {
 		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
 	default:
 	}
+	if err := b.crashedError(); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(b.ctx, 15*time.Second)
 	defer cancel()
@@ -561,52 +705,23 @@ func (b *Browser) GetQuickPageInfo() (*QuickPageInfo, error) {
 	var info QuickPageInfo
 
 	err := chromedp.Run(ctx,
-		chromedp.Evaluate(`
+		evaluate(`
 		(function() {
-			function isVisible(el) {
-				if (!el) return false;
-				const style = window.getComputedStyle(el);
-				return style.display !== 'none' && 
-					   style.visibility !== 'hidden' && 
-					   style.opacity !== '0' &&
-					   el.offsetWidth > 0 && 
-					   el.offsetHeight > 0;
-			}
+			` + useHelpersJS + `
+			` + b.extractionSelectorsJS() + `
 			
 			// Увеличиваем количество ссылок для быстрого метода
-			let links = Array.from(document.querySelectorAll('a')).slice(0, 100).map(a => {
-				const text = (a.innerText || a.textContent || '').trim();
-				const href = a.href;
+			let links = Array.from(document.querySelectorAll(withExtra('links', 'a'))).slice(0, 100).map(a => {
+				const text = withTitle(a, a.innerText || a.textContent || '');
+				const href = hrefOf(a);
 				if (isVisible(a) && text && href) {
 					return { text, href };
 				}
 				return null;
 			}).filter(l => l !== null);
 			
-			// Функция для получения текста кнопки, включая иконки
-			function getButtonText(b) {
-				let text = (b.innerText || b.textContent || b.value || '').trim();
-				if (!text) {
-					text = (b.getAttribute('aria-label') || b.getAttribute('title') || '').trim();
-				}
-				if (!text) {
-					// Ищем символы (+, -, ×)
-					const symbols = b.textContent.match(/[+×−−−]/);
-					if (symbols && symbols.length > 0) {
-						text = symbols[0];
-					}
-					// Ищем по классам для кнопок добавления
-					const className = (typeof b.className === 'string' ? b.className : (b.className ? b.className.toString() : '')).toLowerCase();
-					const id = (b.id || '').toLowerCase();
-					if (className.includes('add') || className.includes('cart') || id.includes('add') || id.includes('cart')) {
-						text = '+';
-					}
-				}
-				return text;
-			}
-			
 			// Увеличиваем количество кнопок и собираем полную информацию
-			let buttons = Array.from(document.querySelectorAll('button, [role="button"], input[type="submit"], input[type="button"], [class*="add"], [class*="cart"]')).slice(0, 150).map(b => {
+			let buttons = Array.from(document.querySelectorAll(withExtra('buttons', 'button, [role="button"], input[type="submit"], input[type="button"], [class*="add"], [class*="cart"]'))).slice(0, 150).map(b => {
 				const text = getButtonText(b);
 				if (!isVisible(b) || b.disabled || !text) {
 					return null;
@@ -717,11 +832,19 @@ func (b *Browser) GetQuickPageInfo() (*QuickPageInfo, error) {
 				buttons = emailButtons.concat(buttons);
 			}
 			
+			// Положение элементов для проверки цели перед действием (VerifyTarget)
+			`+targetSnapshotJS+`;
+			
 			return {
 				url: window.location.href,
 				title: document.title,
 				links: links,
-				buttons: buttons
+				buttons: buttons,
+				media: `+mediaExtractionJS+`,
+				ranges: `+rangeExtractionJS+`,
+				dates: `+dateExtractionJS+`,
+				prices: `+priceExtractionJS+`,
+				errors: `+formErrorsJS+`
 			};
 		})()
 		`, &info),
@@ -730,15 +853,31 @@ func (b *Browser) GetQuickPageInfo() (*QuickPageInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get quick page info: %w", err)
 	}
+	info.Frames = b.extractFrames(ctx)
+	info.Route = b.currentRoute()
+	info.Status = b.currentStatus()
+	// Ошибка получения вкладок не критична
+	if tabs, err := b.GetAllTabs(); err == nil {
+		info.Tabs = tabs
+	}
 
 	return &info, nil
 }
 
 type QuickPageInfo struct {
-	URL     string   `json:"url"`
-	Title   string   `json:"title"`
-	Links   []Link   `json:"links"`
-	Buttons []Button `json:"buttons"`
+	URL     string         `json:"url"`
+	Title   string         `json:"title"`
+	Links   []Link         `json:"links"`
+	Buttons []Button       `json:"buttons"`
+	Media   []MediaElement `json:"media,omitempty"`
+	Ranges  []RangeControl `json:"ranges,omitempty"`
+	Dates   []PageDate     `json:"dates,omitempty"`
+	Prices  []PagePrice    `json:"prices,omitempty"`
+	Frames  []FrameContent `json:"frames,omitempty"`
+	Route   string         `json:"route,omitempty"`  // маршрут SPA, если он отличается от адреса документа
+	Status  int            `json:"status,omitempty"` // HTTP-статус документа (GetCurrentStatus), 0 - неизвестен
+	Errors  []string       `json:"errors,omitempty"` // сообщения об ошибках проверки формы
+	Tabs    []TabInfo      `json:"tabs,omitempty"`   // открытые вкладки браузера
 }
 
 type TabInfo struct {
@@ -756,10 +895,18 @@ func (b *Browser) ClickElement(selector string) error {
 	default:
 	}
 
-	ctx, cancel := context.WithTimeout(b.ctx, 20*time.Second)
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(20*time.Second))
 	defer cancel()
 
-	return chromedp.Run(ctx,
+	// Элемент, скрытый до прокрутки (анимация появления, ленивая отрисовка), сначала
+	// прокручиваем в центр окна и даем ему показаться
+	if revealElement(ctx, selector) == revealPending {
+		_ = chromedp.Run(ctx, chromedp.Sleep(scrollRevealDelay))
+	}
+
+	chooserMark := b.fileChooserMark()
+	mark := b.routeMark()
+	err := chromedp.Run(ctx,
 		chromedp.WaitVisible(selector, chromedp.ByQuery),
 		// Удаляем target="_blank" чтобы не открывать новые вкладки
 		chromedp.Evaluate(fmt.Sprintf(`
@@ -769,8 +916,23 @@ func (b *Browser) ClickElement(selector string) error {
 			}
 		`, selector), nil),
 		chromedp.Click(selector, chromedp.ByQuery),
-		chromedp.Sleep(1*time.Second),
+		b.waitAfterClick(mark, 1*time.Second),
 	)
+	if err != nil {
+		// Модели важно знать, есть ли элемент на странице: прокрутить и раскрыть
+		// раздел или искать другой путь
+		checkCtx, cancelCheck := context.WithTimeout(b.ctx, 3*time.Second)
+		defer cancelCheck()
+		switch revealElement(checkCtx, selector) {
+		case revealMissing:
+			return fmt.Errorf("element %s not found - на странице нет такого элемента даже среди скрытых: %w", selector, err)
+		case revealPending, revealHidden:
+			return fmt.Errorf("element %s not found among shown elements: он есть на странице, но скрыт и не показался после прокрутки к нему - раскрой раздел или меню, где он лежит: %w", selector, err)
+		}
+		return err
+	}
+
+	return b.checkFileChooser(ctx, chooserMark)
 }
 
 func (b *Browser) ClickByText(text string) error {
@@ -781,25 +943,20 @@ func (b *Browser) ClickByText(text string) error {
 	default:
 	}
 
-	ctx, cancel := context.WithTimeout(b.ctx, 20*time.Second)
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(20*time.Second))
 	defer cancel()
 
 	escapedText := escapeJSString(text)
 
-	script := fmt.Sprintf(`
+	// revealPass - первый проход: к скрытому элементу с таким текстом скрипт прокручивает
+	clickScript := func(revealPass bool) string {
+		return fmt.Sprintf(`
 		(function() {
 			const searchText = '%s';
 			const searchLower = searchText.toLowerCase().trim();
+			const revealPass = %t;
 			
-			function isVisible(el) {
-				if (!el) return false;
-				const style = window.getComputedStyle(el);
-				return style.display !== 'none' && 
-					   style.visibility !== 'hidden' && 
-					   style.opacity !== '0' &&
-					   el.offsetWidth > 0 && 
-					   el.offsetHeight > 0;
-			}
+			` + useHelpersJS + `
 			
 			function isClickable(el) {
 				if (!el) return false;
@@ -852,81 +1009,23 @@ func (b *Browser) ClickByText(text string) error {
 					.trim();
 			}
 			
-			// Функция для получения текста элемента, включая иконки и символы
-			function getElementText(el) {
-				// Обычный текст
-				let text = (el.innerText || el.textContent || '').trim();
-				
-				// Если текста нет, пробуем aria-label, title
-				if (!text) {
-					text = (el.getAttribute('aria-label') || el.getAttribute('title') || '').trim();
-				}
-				
-				// Если текста нет, ищем символы (+, -, ×) в тексте
-				if (!text) {
-					const symbols = el.textContent.match(/[+×−−−]/);
-					if (symbols && symbols.length > 0) {
-						text = symbols[0];
-					}
-				}
-				
-				// Если текста нет, ищем символ "+" в SVG
-				if (!text) {
-					const svg = el.querySelector('svg');
-					if (svg) {
-						// Ищем текст в SVG
-						const svgText = svg.textContent || svg.getAttribute('aria-label') || '';
-						if (svgText && svgText.includes('+')) {
-							text = '+';
-						}
-						// Ищем path с признаками плюса
-						const paths = svg.querySelectorAll('path, line, circle, rect');
-						paths.forEach(path => {
-							const d = path.getAttribute('d') || '';
-							// Простая эвристика: если есть вертикальные и горизонтальные линии, это может быть плюс
-							if (d.includes('M') && d.includes('L') && !text) {
-								// Проверяем, есть ли в родительском элементе текст "+"
-								const parentText = (el.textContent || '').trim();
-								if (parentText === '+' || parentText.includes('+')) {
-									text = '+';
-								}
-							}
-						});
-					}
-				}
-				
-				// Если текста нет, ищем по классам/ID для кнопок добавления
-				if (!text) {
-					const className = (typeof el.className === 'string' ? el.className : (el.className ? el.className.toString() : '')).toLowerCase();
-					const id = (el.id || '').toLowerCase();
-					const dataTestid = (el.getAttribute('data-testid') || '').toLowerCase();
-					const dataQa = (el.getAttribute('data-qa') || '').toLowerCase();
-					
-					if (className.includes('add') || className.includes('cart') || className.includes('basket') ||
-						id.includes('add') || id.includes('cart') || id.includes('basket') ||
-						className.includes('plus') || className.includes('increment') ||
-						dataTestid.includes('add') || dataQa.includes('add')) {
-						text = '+';
-					}
-				}
-				
-				// Проверяем псевдоэлементы (::before, ::after) через computed styles
-				if (!text) {
-					const style = window.getComputedStyle(el, '::before');
-					const beforeContent = style.content;
-					if (beforeContent && (beforeContent.includes('+') || beforeContent === '"+"' || beforeContent === "'+'")) {
-						text = '+';
-					}
-					if (!text) {
-						const afterStyle = window.getComputedStyle(el, '::after');
-						const afterContent = afterStyle.content;
-						if (afterContent && (afterContent.includes('+') || afterContent === '"+"' || afterContent === "'+'")) {
-							text = '+';
-						}
-					}
-				}
-				
-				return text;
+			// Элемент с таким текстом, который есть на странице, но пока скрыт и покажется
+			// после прокрутки к нему (анимация появления, ленивая отрисовка)
+			function findHidden(exact) {
+				const matches = allElements.filter(el => {
+					if (isVisible(el) || !isRevealable(el)) return false;
+					const text = (el.textContent || el.getAttribute('aria-label') || '').replace(/\s+/g, ' ').trim().toLowerCase();
+					return text && (exact ? text === searchLower : text.includes(searchLower));
+				});
+				// Самый короткий текст - сам элемент, а не обертка вокруг него
+				matches.sort((a, b) => a.textContent.length - b.textContent.length);
+				return matches[0] || null;
+			}
+			
+			function hiddenResult(el) {
+				if (revealPass) el.scrollIntoView({ block: 'center' });
+				const text = (el.textContent || '').replace(/\s+/g, ' ').trim().substring(0, 40);
+				return { clicked: false, found: false, covered_by: '', hidden: el.tagName.toLowerCase() + (text ? ' "' + text + '"' : '') };
 			}
 			
 			const allElements = Array.from(document.querySelectorAll('*'));
@@ -937,12 +1036,21 @@ func (b *Browser) ClickByText(text string) error {
 				return text.toLowerCase() === searchLower;
 			});
 			
+			// Скрытый элемент с точно таким текстом важнее частичного совпадения среди
+			// видимых (частично с ним совпадает и вся страница): прокручиваем к нему, и
+			// повторный поиск найдет его уже видимым
+			if (!target) {
+				const hidden = findHidden(true);
+				if (hidden) return hiddenResult(hidden);
+			}
+			
 			// Поиск по частичному совпадению с учетом иконок
 			if (!target) {
 				target = allElements.find(el => {
 					if (!isVisible(el) || !isClickable(el)) return false;
 					const text = getElementText(el);
-					return text.toLowerCase().includes(searchLower) || searchLower.includes(text.toLowerCase());
+					// Пустой текст входит в любую строку - такие элементы не подходят
+					return text !== '' && (text.toLowerCase().includes(searchLower) || searchLower.includes(text.toLowerCase()));
 				});
 			}
 			
@@ -955,7 +1063,7 @@ func (b *Browser) ClickByText(text string) error {
 						if (!isVisible(el)) return false;
 						const text = getElementText(el);
 						// Проверяем по полному совпадению или по вхождению
-						return text.toLowerCase().includes(searchLower) || searchLower.includes(text.toLowerCase());
+						return text !== '' && (text.toLowerCase().includes(searchLower) || searchLower.includes(text.toLowerCase()));
 					});
 					if (target) break;
 				}
@@ -1008,7 +1116,7 @@ func (b *Browser) ClickByText(text string) error {
 						const height = el.offsetHeight;
 						
 						// Ищем круглые белые кнопки (типичные для кнопок добавления)
-						const isRound = borderRadius && (parseFloat(borderRadius) >= width / 2 || borderRadius.includes('50%'));
+						const isRound = borderRadius && (parseFloat(borderRadius) >= width / 2 || borderRadius.includes('50%%'));
 						const isWhite = bgColor && (bgColor.includes('255, 255, 255') || bgColor.includes('rgb(255, 255, 255)') || bgColor === 'white');
 						
 						if ((isRound || width === height) && width > 20 && width < 100) {
@@ -1065,12 +1173,62 @@ func (b *Browser) ClickByText(text string) error {
 				});
 			}
 			
+			// Обертка с единственной кнопкой (form, div) имеет тот же текст, что и кнопка, и
+			// стоит раньше в документе - клик по ней ничего не делает, поэтому спускаемся
+			// к вложенному элементу с тем же текстом, пока не дойдем до самого элемента управления
+			if (target) {
+				const sameText = getElementText(target).toLowerCase();
+				while (!target.matches('a, button, input, select, textarea, label, [role="button"], [role="link"]')) {
+					const inner = Array.from(target.children).find(child => isVisible(child) && getElementText(child).toLowerCase() === sameText);
+					if (!inner) break;
+					target = inner;
+				}
+			}
+			
 			if (target) {
 				// Предотвращаем открытие новых вкладок - убираем target="_blank"
 				if (target.tagName === 'A') {
 					target.removeAttribute('target');
 				}
 				
+				// Проверяем, не перекрыт ли элемент другим (тултип, оверлей, sticky-шапка)
+				function describeElement(el) {
+					let desc = el.tagName.toLowerCase();
+					if (el.id) desc += '#' + el.id;
+					const cls = (typeof el.className === 'string' ? el.className : '').trim().split(/\s+/).filter(c => c).slice(0, 2);
+					if (cls.length > 0) desc += '.' + cls.join('.');
+					const text = (el.innerText || el.textContent || '').trim().substring(0, 40);
+					if (text) desc += ' "' + text + '"';
+					return desc;
+				}
+				
+				function findOccluder(el) {
+					const rect = el.getBoundingClientRect();
+					const x = rect.left + rect.width / 2;
+					const y = rect.top + rect.height / 2;
+					if (x < 0 || y < 0 || x >= window.innerWidth || y >= window.innerHeight) {
+						return { outside: true, el: null };
+					}
+					const top = document.elementFromPoint(x, y);
+					if (!top || top === el || el.contains(top) || top.contains(el)) {
+						return { outside: false, el: null };
+					}
+					return { outside: false, el: top };
+				}
+				
+				// Элемент, прокрученный за границы контейнера (выпадающий список), сначала
+				// показываем в контейнере, иначе клик попадет в соседний элемент
+				revealInScrollContainers(target);
+				let occlusion = findOccluder(target);
+				if (occlusion.outside || occlusion.el) {
+					// Прокрутка в центр часто убирает перекрытие sticky-шапкой
+					target.scrollIntoView({ block: 'center', inline: 'center' });
+					occlusion = findOccluder(target);
+				}
+				if (occlusion.el) {
+					return { clicked: false, found: true, covered_by: describeElement(occlusion.el) };
+				}
+				
 				try {
 					target.click();
 				} catch (e) {
@@ -1081,28 +1239,60 @@ func (b *Browser) ClickByText(text string) error {
 					});
 					target.dispatchEvent(event);
 				}
-				return true;
+				return { clicked: true, found: true, covered_by: '' };
 			}
 			
-			return false;
+			const hidden = findHidden(true) || findHidden(false);
+			if (hidden) return hiddenResult(hidden);
+			return { clicked: false, found: false, covered_by: '' };
 		})()
-	`, escapedText)
+	`, escapedText, revealPass)
+	}
 
-	var clicked bool
+	var result clickResult
+	chooserMark := b.fileChooserMark()
+	mark := b.routeMark()
 	err := chromedp.Run(ctx,
-		chromedp.Evaluate(script, &clicked),
-		chromedp.Sleep(1*time.Second),
+		evaluate(clickScript(true), &result),
 	)
+	if err == nil && result.Hidden != "" {
+		// Элемент скрыт до прокрутки, и скрипт уже прокрутил к нему: ждем появления
+		// и ищем еще раз - без лишнего шага модели
+		result = clickResult{}
+		err = chromedp.Run(ctx,
+			chromedp.Sleep(scrollRevealDelay),
+			evaluate(clickScript(false), &result),
+		)
+	}
+	if err == nil {
+		err = chromedp.Run(ctx, b.waitAfterClick(mark, 1*time.Second))
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to click by text: %w", err)
 	}
 
-	if !clicked {
-		return fmt.Errorf("element with text '%s' not found", text)
+	if result.CoveredBy != "" {
+		return fmt.Errorf("element with text '%s' is covered by another element: %s - закрой перекрывающий элемент (escape, крестик) или прокрути страницу", text, result.CoveredBy)
 	}
 
-	return nil
+	if !result.Clicked && result.Hidden != "" {
+		return fmt.Errorf("element with text '%s' not found among shown elements: на странице есть скрытый элемент %s, но он не показался и после прокрутки к нему - раскрой раздел или меню, где он лежит, или прокрути страницу (scroll)", text, result.Hidden)
+	}
+
+	if !result.Clicked {
+		return fmt.Errorf("element with text '%s' not found - на странице нет такого элемента даже среди скрытых: проверь текст или выбери другой элемент", text)
+	}
+
+	return b.checkFileChooser(ctx, chooserMark)
+}
+
+// clickResult - результат поиска и клика по элементу в странице
+type clickResult struct {
+	Clicked   bool   `json:"clicked"`
+	Found     bool   `json:"found"`
+	CoveredBy string `json:"covered_by"`
+	Hidden    string `json:"hidden"` // скрытый элемент с искомым текстом, если видимого нет
 }
 
 func (b *Browser) FillInput(selector, value string) error {
@@ -1113,7 +1303,7 @@ func (b *Browser) FillInput(selector, value string) error {
 	default:
 	}
 
-	ctx, cancel := context.WithTimeout(b.ctx, 15*time.Second)
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(15*time.Second))
 	defer cancel()
 
 	return chromedp.Run(ctx,
@@ -1121,6 +1311,7 @@ func (b *Browser) FillInput(selector, value string) error {
 		chromedp.Clear(selector, chromedp.ByQuery),
 		chromedp.SendKeys(selector, value, chromedp.ByQuery),
 		chromedp.Sleep(500*time.Millisecond),
+		dismissAutofill(),
 	)
 }
 
@@ -1133,7 +1324,7 @@ func (b *Browser) PressKey(keyName string) error {
 	default:
 	}
 
-	ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
 	defer cancel()
 
 	// Карта соответствия названий клавиш к кодам
@@ -1256,7 +1447,8 @@ func (b *Browser) GetAllTabs() ([]TabInfo, error) {
 	return tabs, nil
 }
 
-// SwitchToTab переключается на вкладку по её ID
+// SwitchToTab переключается на вкладку по её ID: вкладка выводится на передний план,
+// и следующие действия браузера выполняются в ней
 func (b *Browser) SwitchToTab(tabID string) error {
 	// Проверяем, не отменен ли контекст браузера
 	select {
@@ -1268,11 +1460,14 @@ func (b *Browser) SwitchToTab(tabID string) error {
 	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
 	defer cancel()
 
-	return chromedp.Run(ctx,
+	if err := chromedp.Run(ctx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			return target.ActivateTarget(target.ID(tabID)).Do(ctx)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+	return b.attachTab(target.ID(tabID))
 }
 
 // CloseTab закрывает вкладку по её ID
@@ -1284,6 +1479,14 @@ func (b *Browser) CloseTab(tabID string) error {
 	default:
 	}
 
+	if chromedp.FromContext(b.rootCtx).Target.TargetID == target.ID(tabID) {
+		return fmt.Errorf("нельзя закрыть вкладку, открытую при запуске агента")
+	}
+	// Подключенная вкладка закрывается вместе со своим контекстом
+	if b.detachTab(target.ID(tabID)) {
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
 	defer cancel()
 
@@ -1302,7 +1505,7 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 	default:
 	}
 
-	ctx, cancel := context.WithTimeout(b.ctx, 20*time.Second)
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(20*time.Second))
 	defer cancel()
 
 	// Ждем загрузки страницы и появления динамического контента
@@ -1332,21 +1535,15 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 	if isCoverLetterField {
 		_ = chromedp.Run(ctx,
 			chromedp.Sleep(1*time.Second),
-			chromedp.Evaluate(`
+			evaluate(`
 				(function() {
+					` + useHelpersJS + `
 					// Ждем появления textarea на странице
 					const maxWait = 3000; // 3 секунды максимум
 					const startTime = Date.now();
 					while (Date.now() - startTime < maxWait) {
 						const textareas = Array.from(document.querySelectorAll('textarea'));
-						const visibleTextareas = textareas.filter(ta => {
-							const style = window.getComputedStyle(ta);
-							return style.display !== 'none' && 
-							       style.visibility !== 'hidden' && 
-							       style.opacity !== '0' &&
-							       ta.offsetWidth > 0 && 
-							       ta.offsetHeight > 0;
-						});
+						const visibleTextareas = textareas.filter(isVisible);
 						if (visibleTextareas.length > 0) {
 							return true;
 						}
@@ -1373,15 +1570,7 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 			const searchWords = searchText.split(/\s+/).filter(w => w.length > 2); // Разбиваем на слова
 			const isLongText = %t; // Передаем флаг из Go
 			
-			function isVisible(el) {
-				if (!el) return false;
-				const style = window.getComputedStyle(el);
-				return style.display !== 'none' && 
-					   style.visibility !== 'hidden' && 
-					   style.opacity !== '0' &&
-					   el.offsetWidth > 0 && 
-					   el.offsetHeight > 0;
-			}
+			` + useHelpersJS + `
 			
 			function matchesSearch(el, searchText, searchWords) {
 				const placeholder = (el.placeholder || '').toLowerCase();
@@ -1770,8 +1959,9 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 
 	var filled bool
 	err := chromedp.Run(ctx,
-		chromedp.Evaluate(script, &filled),
+		evaluate(script, &filled),
 		chromedp.Sleep(1*time.Second), // Увеличена задержка для обработки событий
+		dismissAutofill(),
 	)
 
 	if err != nil {
@@ -1783,15 +1973,7 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 		time.Sleep(1 * time.Second)
 		fallbackScript := fmt.Sprintf(`
 			(function() {
-				function isVisible(el) {
-					if (!el) return false;
-					const style = window.getComputedStyle(el);
-					return style.display !== 'none' && 
-						   style.visibility !== 'hidden' && 
-						   style.opacity !== '0' &&
-						   el.offsetWidth > 0 && 
-						   el.offsetHeight > 0;
-				}
+				` + useHelpersJS + `
 				
 				// Ищем любое видимое текстовое поле
 				const inputs = Array.from(document.querySelectorAll('input, textarea'));
@@ -1844,8 +2026,9 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 		`, escapedValue)
 		
 		err2 := chromedp.Run(ctx,
-			chromedp.Evaluate(fallbackScript, &filled),
+			evaluate(fallbackScript, &filled),
 			chromedp.Sleep(500*time.Millisecond),
+			dismissAutofill(),
 		)
 		
 		if err2 == nil && filled {
@@ -1859,7 +2042,7 @@ func (b *Browser) FillInputByPlaceholder(placeholder, value string) error {
 }
 
 func (b *Browser) WaitForElement(selector string, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(b.ctx, timeout)
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(timeout))
 	defer cancel()
 
 	return chromedp.Run(ctx,
@@ -1874,6 +2057,9 @@ func (b *Browser) GetCurrentURL() (string, error) {
 		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
 	default:
 	}
+	if err := b.crashedError(); err != nil {
+		return "", err
+	}
 
 	// Увеличиваем таймаут и добавляем повторные попытки
 	maxRetries := 2
@@ -1910,25 +2096,63 @@ func (b *Browser) GetCurrentURL() (string, error) {
 	return url, fmt.Errorf("failed to get URL after %d attempts: %w", maxRetries, err)
 }
 
+// Screenshot сохраняет снимок видимой области страницы в filename. Снимок нужен чаще всего
+// после ошибки, когда браузер может быть в плохом состоянии, поэтому недоступный браузер
+// и упавшая вкладка сообщаются понятной ошибкой, а сбой снимка повторяется один раз.
 func (b *Browser) Screenshot(filename string) error {
-	ctx, cancel := context.WithTimeout(b.ctx, 15*time.Second)
-	defer cancel()
+	// Проверяем, не отменен ли контекст браузера
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен, снимок экрана не сделан")
+	default:
+	}
+	if err := b.crashedError(); err != nil {
+		return fmt.Errorf("снимок экрана не сделан: %w", err)
+	}
 
+	maxRetries := 2
 	var buf []byte
-	err := chromedp.Run(ctx,
-		chromedp.CaptureScreenshot(&buf),
-	)
+	var err error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		ctx, cancel := context.WithTimeout(b.ctx, 15*time.Second)
+		err = chromedp.Run(ctx,
+			chromedp.CaptureScreenshot(&buf),
+		)
+		cancel()
 
+		if err == nil {
+			break
+		}
+
+		// Контекст браузера отменен во время снимка - повтор не поможет
+		select {
+		case <-b.ctx.Done():
+			return fmt.Errorf("browser context was canceled - браузер недоступен, снимок экрана не сделан")
+		default:
+		}
+		if b.RendererCrashed() {
+			return fmt.Errorf("снимок экрана не сделан: %w", ErrRendererCrashed)
+		}
+
+		if attempt < maxRetries {
+			time.Sleep(1 * time.Second)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to take screenshot: %w", err)
+		return fmt.Errorf("failed to take screenshot after %d attempts: %w", maxRetries, err)
 	}
 
-	return os.WriteFile(filename, buf, 0644)
+	if err := os.WriteFile(filename, buf, 0644); err != nil {
+		return fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	return nil
 }
 
 func (b *Browser) keepAliveLoop() {
-	ticker := time.NewTicker(30 * time.Second) // Уменьшаем интервал для более частых проверок
-	defer ticker.Stop()
+	// В режиме низкой мощности интервал длиннее, поэтому таймер заводится заново
+	timer := time.NewTimer(b.pollInterval(keepAliveInterval))
+	defer timer.Stop()
 
 	for {
 		select {
@@ -1936,13 +2160,18 @@ func (b *Browser) keepAliveLoop() {
 			return
 		case <-b.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
+			timer.Reset(b.pollInterval(keepAliveInterval))
 			// Проверяем, что контекст еще активен
 			select {
 			case <-b.ctx.Done():
 				return
 			default:
 			}
+			// Браузер недавно отвечал на другую операцию - лишняя проверка только нагрузит его
+			if b.skipKeepAlive() {
+				continue
+			}
 			
 			ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
 			var url string
@@ -1963,6 +2192,11 @@ func (b *Browser) keepAliveLoop() {
 	}
 }
 
+// IsAlive сообщает, работает ли еще контекст браузера
+func (b *Browser) IsAlive() bool {
+	return b.ctx.Err() == nil
+}
+
 func (b *Browser) Close() error {
 	b.keepAliveCancel()
 	b.cancel()
@@ -1971,21 +2205,30 @@ func (b *Browser) Close() error {
 }
 
 type PageContent struct {
-	URL      string       `json:"url"`
-	Title    string       `json:"title"`
-	Text     string       `json:"text"`
-	Links    []Link       `json:"links"`
-	Buttons  []Button     `json:"buttons"`
-	Inputs   []Input      `json:"inputs"`
-	Headings []Heading    `json:"headings"`
-	Lists    [][]string   `json:"lists,omitempty"`   // списки -> элементы
-	Tables   [][][]string `json:"tables,omitempty"`  // таблицы -> строки -> ячейки
-	Tabs     []TabInfo    `json:"tabs,omitempty"`    // открытые вкладки браузера
+	URL      string         `json:"url"`
+	Title    string         `json:"title"`
+	Text     string         `json:"text"`
+	Links    []Link         `json:"links"`
+	Buttons  []Button       `json:"buttons"`
+	Inputs   []Input        `json:"inputs"`
+	Headings []Heading      `json:"headings"`
+	Lists    [][]string     `json:"lists,omitempty"`  // списки -> элементы
+	Tables   [][][]string   `json:"tables,omitempty"` // таблицы -> строки -> ячейки
+	Tabs     []TabInfo      `json:"tabs,omitempty"`   // открытые вкладки браузера
+	Media    []MediaElement `json:"media,omitempty"`  // видео и аудио на странице
+	Ranges   []RangeControl `json:"ranges,omitempty"` // ползунки с границами и текущим значением
+	Dates    []PageDate     `json:"dates,omitempty"`  // даты с точным значением: "вчера" -> 2024-06-01 10:30
+	Prices   []PagePrice    `json:"prices,omitempty"` // цены числом с валютой: "1 299 ₽" -> 1299 RUB
+	Frames   []FrameContent `json:"frames,omitempty"` // поля и кнопки внутри iframe
+	Route    string         `json:"route,omitempty"`  // маршрут SPA, если он отличается от адреса документа
+	Status   int            `json:"status,omitempty"` // HTTP-статус документа (GetCurrentStatus), 0 - неизвестен
+	Errors   []string       `json:"errors,omitempty"` // сообщения об ошибках проверки формы: "Email: Email is invalid"
 }
 
 type Link struct {
 	Text string `json:"text"`
 	Href string `json:"href"`
+	New  bool   `json:"new,omitempty"` // появилась после предыдущего анализа страницы (отмечает агент)
 }
 
 type Button struct {
@@ -1999,6 +2242,7 @@ type Button struct {
 	DataAction string `json:"data_action,omitempty"` // data-action, data-testid, data-qa
 	Context    string `json:"context,omitempty"`     // где находится кнопка (header, footer, nav, etc)
 	OnClick    string `json:"onclick,omitempty"`     // onclick атрибут или краткое описание
+	New        bool   `json:"new,omitempty"`         // появилась после предыдущего анализа страницы (отмечает агент)
 }
 
 type Input struct {
@@ -2007,6 +2251,18 @@ type Input struct {
 	Name        string `json:"name"`
 	ID          string `json:"id,omitempty"`
 	Label       string `json:"label,omitempty"`
+	Checked     *bool  `json:"checked,omitempty"` // состояние флажка или переключателя
+	// Выпадающий список <select>: варианты и выбранный вариант
+	Options []string `json:"options,omitempty"`
+	Value   string   `json:"value,omitempty"`
+	// Ограничения HTML5-проверки: значение вне их форма отклонит
+	Required  bool   `json:"required,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength int    `json:"minlength,omitempty"`
+	MaxLength int    `json:"maxlength,omitempty"`
+	Min       string `json:"min,omitempty"` // число или дата - как в атрибуте
+	Max       string `json:"max,omitempty"`
+	Step      string `json:"step,omitempty"`
 }
 
 type Heading struct {
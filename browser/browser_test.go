@@ -0,0 +1,33 @@
+package browser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestBrowser запускает браузер без окна с временным профилем. Без Chrome в
+// системе тест пропускается: проверки страниц требуют настоящего браузера.
+func newTestBrowser(t *testing.T) *Browser {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("browser tests skipped in -short mode")
+	}
+	b, err := NewBrowser(t.TempDir(), true)
+	if err != nil {
+		t.Skipf("Chrome unavailable: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+// servePage отдает html тестовой страницей и возвращает ее адрес
+func servePage(t *testing.T, html string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(html))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinChromeMajor - минимальная поддерживаемая основная версия Chrome/Chromium.
+// Протокол CDP более старых сборок расходится с chromedp: события не разбираются
+// ("could not unmarshal event"), а перехваты работают не полностью.
+const MinChromeMajor = 110
+
+// Capabilities - возможности запущенного браузера, зависящие от его версии.
+// Функции, которые опираются на эти возможности, проверяют флаги заранее и
+// отключаются осознанно, а не падают с ошибкой во время выполнения.
+type Capabilities struct {
+	Product        string // строка версии из CDP, например "Chrome/120.0.6099.109"
+	Major          int    // основная версия; 0 - определить не удалось
+	Supported      bool   // версия не ниже MinChromeMajor (или не определена)
+	FileChooser    bool   // перехват системного диалога выбора файла
+	DownloadEvents bool   // события начала и хода загрузок
+	Screencast     bool   // трансляция кадров страницы
+}
+
+// Degraded сообщает, что браузер работает с отключенными функциями
+func (c Capabilities) Degraded() bool {
+	return !c.Supported
+}
+
+// Disabled возвращает названия отключенных функций
+func (c Capabilities) Disabled() []string {
+	var disabled []string
+	if !c.FileChooser {
+		disabled = append(disabled, "перехват диалога выбора файла")
+	}
+	if !c.DownloadEvents {
+		disabled = append(disabled, "события загрузок")
+	}
+	if !c.Screencast {
+		disabled = append(disabled, "трансляция экрана")
+	}
+	return disabled
+}
+
+// UnsupportedChromeError возвращается при запуске браузера старее MinChromeMajor
+type UnsupportedChromeError struct {
+	Product string
+	Major   int
+}
+
+func (e *UnsupportedChromeError) Error() string {
+	return fmt.Sprintf("версия браузера %s (основная версия %d) не поддерживается: нужен Chrome/Chromium %d или новее.\n"+
+		"Обновите браузер или запустите в ограниченном режиме с BROWSER_ALLOW_OLD_CHROME=true "+
+		"(без перехвата диалога выбора файла, событий загрузок и трансляции экрана)", e.Product, e.Major, MinChromeMajor)
+}
+
+// ParseChromeMajor извлекает основную версию из строки продукта CDP:
+// "Chrome/120.0.6099.109", "HeadlessChrome/119.0.6045.105" -> 120, 119.
+// Для нераспознанной строки возвращает 0.
+func ParseChromeMajor(product string) int {
+	_, version, ok := strings.Cut(product, "/")
+	if !ok {
+		return 0
+	}
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// capabilitiesFor определяет возможности по строке версии. Нераспознанную версию
+// считаем поддерживаемой: отказывать в запуске без уверенности хуже, чем шум в логе.
+func capabilitiesFor(product string) Capabilities {
+	major := ParseChromeMajor(product)
+	supported := major == 0 || major >= MinChromeMajor
+	return Capabilities{
+		Product:        product,
+		Major:          major,
+		Supported:      supported,
+		FileChooser:    supported,
+		DownloadEvents: supported,
+		Screencast:     supported,
+	}
+}
+
+// WithAllowOldChrome разрешает запуск браузера старее MinChromeMajor в ограниченном
+// режиме: зависящие от версии функции отключаются (см. Capabilities). По умолчанию
+// такой браузер не запускается.
+func WithAllowOldChrome(allow bool) Option {
+	return func(b *Browser) {
+		b.allowOldChrome = allow
+	}
+}
+
+// Capabilities возвращает возможности запущенного браузера
+func (b *Browser) Capabilities() Capabilities {
+	return b.caps
+}
+
+// detectCapabilities определяет версию браузера сразу после запуска. Браузер старее
+// MinChromeMajor - UnsupportedChromeError, если ограниченный режим не разрешен.
+func (b *Browser) detectCapabilities() error {
+	product, err := b.Version()
+	if err != nil {
+		// Версию узнать не удалось - работаем как с поддерживаемой
+		b.caps = capabilitiesFor("")
+		return nil
+	}
+	b.caps = capabilitiesFor(product)
+	if b.caps.Supported {
+		return nil
+	}
+	if !b.allowOldChrome {
+		return &UnsupportedChromeError{Product: product, Major: b.caps.Major}
+	}
+	fmt.Printf("⚠️  Браузер %s старее поддерживаемой версии %d - ограниченный режим, отключено: %s. Обновите Chrome/Chromium до %d или новее\n",
+		product, MinChromeMajor, strings.Join(b.caps.Disabled(), ", "), MinChromeMajor)
+	return nil
+}
@@ -0,0 +1,74 @@
+package browser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseChromeMajor(t *testing.T) {
+	tests := []struct {
+		product string
+		want    int
+	}{
+		{"Chrome/120.0.6099.109", 120},
+		{"HeadlessChrome/119.0.6045.105", 119},
+		{"Chromium/99", 99},
+		{"Chrome/", 0},
+		{"Chrome/abc.1", 0},
+		{"Chrome/-5.0", 0},
+		{"unknown", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseChromeMajor(tt.product); got != tt.want {
+			t.Errorf("ParseChromeMajor(%q) = %d, want %d", tt.product, got, tt.want)
+		}
+	}
+}
+
+func TestCapabilitiesFor(t *testing.T) {
+	tests := []struct {
+		product   string
+		supported bool
+	}{
+		{"Chrome/120.0.6099.109", true},
+		{"Chrome/110.0.5481.77", true},
+		{"HeadlessChrome/109.0.5414.119", false},
+		{"", true}, // версию определить не удалось - не отказываем
+	}
+	for _, tt := range tests {
+		caps := capabilitiesFor(tt.product)
+		if caps.Supported != tt.supported || caps.Degraded() == tt.supported {
+			t.Errorf("capabilitiesFor(%q) = %+v, want supported %v", tt.product, caps, tt.supported)
+		}
+		if caps.FileChooser != tt.supported || caps.DownloadEvents != tt.supported || caps.Screencast != tt.supported {
+			t.Errorf("capabilitiesFor(%q) feature flags = %+v, want all %v", tt.product, caps, tt.supported)
+		}
+		if disabled := caps.Disabled(); (len(disabled) == 0) != tt.supported {
+			t.Errorf("capabilitiesFor(%q).Disabled() = %q", tt.product, disabled)
+		}
+	}
+}
+
+func TestUnsupportedChromeError(t *testing.T) {
+	var err error = &UnsupportedChromeError{Product: "HeadlessChrome/100.0.4896.60", Major: 100}
+	var unsupported *UnsupportedChromeError
+	if !errors.As(err, &unsupported) {
+		t.Fatal("errors.As(UnsupportedChromeError) failed")
+	}
+	msg := err.Error()
+	for _, want := range []string{"HeadlessChrome/100.0.4896.60", "110 или новее", "BROWSER_ALLOW_OLD_CHROME=true"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Error() = %q, want it to mention %q", msg, want)
+		}
+	}
+}
+
+func TestCapabilitiesOfRunningBrowser(t *testing.T) {
+	b := newTestBrowser(t)
+	caps := b.Capabilities()
+	if caps.Major < MinChromeMajor || caps.Degraded() {
+		t.Errorf("Capabilities() = %+v, want a supported browser for the test run", caps)
+	}
+}
@@ -0,0 +1,130 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// changePoll - как часто WaitForNavigationOrMutation проверяет страницу
+const changePoll = 100 * time.Millisecond
+
+// ErrPageUnchanged - за время ожидания адрес не сменился и DOM не изменился:
+// действие, скорее всего, ничего не сделало
+var ErrPageUnchanged = errors.New("страница не изменилась")
+
+// changeMarkScript ставит в документ метку с MutationObserver на body. Изменением
+// считается только то, что могло сделать действие: элементы добавлены или удалены,
+// у элементов сменились hidden, open, aria-expanded или disabled, либо что угодно
+// (текст, атрибуты) изменилось внутри элемента действия - того, по которому кликнули
+// или в который перешел фокус. Часы, счетчики, карусели и анимации меняют текст,
+// class и style в других местах страницы и изменением не считаются. Ввод в поле не
+// меняет DOM, поэтому события input и change тоже считаются изменением. Метка живет
+// только в текущем документе: после перехода ее нет.
+const changeMarkScript = `(function() {
+	const old = window.__agentChange;
+	if (old && old.stop) old.stop();
+	const mark = {changed: false, acted: null};
+	const ignoredTags = new Set(['SCRIPT', 'STYLE', 'LINK', 'META', 'NOSCRIPT', 'TEMPLATE']);
+	const stateAttributes = new Set(['hidden', 'open', 'aria-expanded', 'disabled']);
+	const structural = nodes => Array.from(nodes).some(n => n.nodeType === 1 && !ignoredTags.has(n.tagName));
+	const insideActed = node => {
+		const el = node && node.nodeType === 1 ? node : node && node.parentElement;
+		return !!(mark.acted && el && mark.acted.contains(el));
+	};
+	const meaningful = m => {
+		if (insideActed(m.target)) return true;
+		if (m.type === 'childList') return structural(m.addedNodes) || structural(m.removedNodes);
+		if (m.type === 'attributes') return stateAttributes.has(m.attributeName);
+		return false;
+	};
+	const noteTarget = e => { if (e.target && e.target.nodeType === 1) mark.acted = e.target; };
+	const events = ['pointerdown', 'mousedown', 'click', 'focusin'];
+	const changed = () => {
+		mark.changed = true;
+		mark.stop();
+	};
+	mark.stop = () => {
+		if (mark.observer) mark.observer.disconnect();
+		events.forEach(type => document.removeEventListener(type, noteTarget, true));
+		document.removeEventListener('input', changed, true);
+		document.removeEventListener('change', changed, true);
+	};
+	if (document.body) {
+		mark.observer = new MutationObserver(records => { if (records.some(meaningful)) changed(); });
+		mark.observer.observe(document.body, {childList: true, subtree: true, attributes: true, characterData: true});
+	}
+	events.forEach(type => document.addEventListener(type, noteTarget, true));
+	document.addEventListener('input', changed, true);
+	document.addEventListener('change', changed, true);
+	window.__agentChange = mark;
+	return location.href;
+})()`
+
+// MarkPageState запоминает текущую страницу перед действием: следующий вызов
+// WaitForNavigationOrMutation сравнивает страницу с этой меткой, поэтому изменение,
+// случившееся во время самого действия, не пропадает.
+func (b *Browser) MarkPageState() error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var href string
+	if err := chromedp.Run(ctx, evaluate(changeMarkScript, &href)); err != nil {
+		b.changeMarkURL = ""
+		return fmt.Errorf("failed to mark page state: %w", err)
+	}
+	b.changeMarkURL = href
+	return nil
+}
+
+// WaitForNavigationOrMutation ждет, пока сменится адрес страницы или DOM body заметно
+// изменится (см. changeMarkScript), но не дольше timeout; без таймаута - ErrPageUnchanged. Сравнивает с меткой
+// MarkPageState, а без метки ставит ее сама и ждет изменений с момента вызова.
+func (b *Browser) WaitForNavigationOrMutation(timeout time.Duration) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if b.changeMarkURL == "" {
+		if err := b.MarkPageState(); err != nil {
+			return err
+		}
+	}
+	baseline := b.changeMarkURL
+	b.changeMarkURL = ""
+
+	ctx, cancel := context.WithTimeout(b.ctx, timeout+2*time.Second)
+	defer cancel()
+
+	var state struct {
+		Href    string `json:"href"`
+		Marked  bool   `json:"marked"`
+		Changed bool   `json:"changed"`
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		// Во время перехода скрипт может не выполниться - проверяем снова
+		err := chromedp.Run(ctx, evaluate(`({href: location.href, marked: !!window.__agentChange,
+			changed: !!(window.__agentChange && window.__agentChange.changed)})`, &state))
+		if err == nil && (state.Href != baseline || !state.Marked || state.Changed) {
+			return nil
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			if err != nil && ctx.Err() == nil {
+				return fmt.Errorf("failed to check page change: %w", err)
+			}
+			return ErrPageUnchanged
+		}
+		time.Sleep(b.pollInterval(changePoll))
+	}
+}
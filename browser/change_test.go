@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// busyPage - часы, карусель и бегущий счетчик меняют страницу постоянно, кнопки - по-разному
+const busyPage = `<p id="clock"></p>
+<div id="carousel"><div class="slide">1</div><div class="slide">2</div></div>
+<span id="counter">0</span>
+<button id="dead">Ничего не делает</button>
+<button id="toggle" aria-pressed="false" onclick="this.setAttribute('aria-pressed', 'true')">Избранное</button>
+<button id="more" onclick="document.getElementById('list').insertAdjacentHTML('beforeend', '<li>Товар</li>')">Показать еще</button>
+<button id="menu" aria-expanded="false" onclick="document.getElementById('panel').hidden = false">Меню</button>
+<div id="panel" hidden>Панель</div>
+<ul id="list"></ul>
+<input id="q" placeholder="Поиск">
+<script>
+	setInterval(() => {
+		document.getElementById('clock').textContent = new Date().toISOString();
+		document.getElementById('counter').firstChild.data = String(Date.now());
+		document.querySelectorAll('.slide').forEach(s => s.classList.toggle('active'));
+		document.getElementById('carousel').style.transform = 'translateX(' + (Date.now() % 100) + 'px)';
+	}, 30);
+</script>`
+
+func TestWaitForNavigationOrMutationIgnoresBackgroundChurn(t *testing.T) {
+	b := newTestBrowser(t)
+	if err := b.Navigate(servePage(t, busyPage)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		act     func() error
+		changed bool
+	}{
+		{"dead button", func() error { return b.ClickElement("#dead") }, false},
+		{"own attribute", func() error { return b.ClickElement("#toggle") }, true},
+		{"new element", func() error { return b.ClickElement("#more") }, true},
+		{"revealed panel", func() error { return b.ClickElement("#menu") }, true},
+		{"typing", func() error { return b.FillInput("#q", "чайник") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := b.MarkPageState(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.act(); err != nil {
+				t.Fatal(err)
+			}
+			err := b.WaitForNavigationOrMutation(500 * time.Millisecond)
+			if changed := err == nil; changed != tt.changed || (err != nil && !errors.Is(err, ErrPageUnchanged)) {
+				t.Errorf("WaitForNavigationOrMutation() = %v, want changed %v", err, tt.changed)
+			}
+		})
+	}
+}
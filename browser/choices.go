@@ -0,0 +1,301 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// choiceHelpersJS - функции для флажков и переключателей: нативных (input checkbox/radio)
+// и ARIA (role=checkbox, radio, switch). choiceLabel(el) - подпись из aria-label,
+// aria-labelledby, <label for> или обертки <label>, соседнего текста; isChecked(el) -
+// текущее состояние; choiceVisible(el) - элемент или его подпись видимы (у стилизованных
+// флажков сам input часто скрыт через opacity: 0). Использует isVisible.
+const choiceHelpersJS = `const choiceSelector = 'input[type="checkbox"], input[type="radio"], [role="checkbox"], [role="radio"], [role="switch"], [role="menuitemcheckbox"]';
+				const cleanText = t => (t || '').replace(/\s+/g, ' ').trim();
+				function choiceLabel(el) {
+					const aria = cleanText(el.getAttribute('aria-label'));
+					if (aria) return aria;
+					const labelledBy = el.getAttribute('aria-labelledby');
+					if (labelledBy) {
+						const text = cleanText(labelledBy.split(/\s+/).map(id => document.getElementById(id)).filter(Boolean).map(n => n.innerText || n.textContent).join(' '));
+						if (text) return text;
+					}
+					if (el.labels && el.labels.length > 0) {
+						const text = cleanText(el.labels[0].innerText || el.labels[0].textContent);
+						if (text) return text;
+					}
+					const wrap = el.closest('label');
+					if (wrap && cleanText(wrap.innerText)) return cleanText(wrap.innerText);
+					if (el.tagName !== 'INPUT') {
+						const text = cleanText(el.innerText || el.textContent);
+						if (text) return text;
+					}
+					const next = el.nextElementSibling;
+					if (next && cleanText(next.innerText) && cleanText(next.innerText).length <= 60) return cleanText(next.innerText);
+					return el.value && el.value !== 'on' ? el.value : (el.name || el.id || '');
+				}
+				function isChecked(el) {
+					if (el.tagName === 'INPUT') return el.checked;
+					return el.getAttribute('aria-checked') === 'true' || el.getAttribute('aria-pressed') === 'true';
+				}
+				function choiceVisible(el) {
+					if (isVisible(el)) return true;
+					const label = (el.labels && el.labels[0]) || el.closest('label');
+					return !!label && isVisible(label);
+				}`
+
+// setChoiceJS - функция setChoice(el, checked): переводит флажок в нужное состояние
+// кликом по элементу, затем по его подписи, а для нативного input в крайнем случае
+// через сеттер checked с событиями input/change. Возвращает, достигнуто ли состояние.
+const setChoiceJS = `function setChoice(el, checked) {
+				if (isChecked(el) === checked) return true;
+				if (el.disabled || el.getAttribute('aria-disabled') === 'true') return false;
+				el.scrollIntoView({block: 'center'});
+				el.click();
+				if (isChecked(el) !== checked && el.tagName === 'INPUT') {
+					const label = (el.labels && el.labels[0]) || el.closest('label');
+					if (label) label.click();
+				}
+				if (isChecked(el) !== checked && el.tagName === 'INPUT') {
+					// Сеттер прототипа - чтобы React и другие фреймворки увидели изменение
+					Object.getOwnPropertyDescriptor(HTMLInputElement.prototype, 'checked').set.call(el, checked);
+					el.dispatchEvent(new Event('input', {bubbles: true}));
+					el.dispatchEvent(new Event('change', {bubbles: true}));
+				}
+				return isChecked(el) === checked;
+			}`
+
+// choiceMatchJS - функция choiceMatches(label, value): подпись варианта совпадает со
+// значением целиком или отдельным словом ("M" подходит к "M (46-48)", но не к "XM"),
+// длинные значения - по вхождению
+const choiceMatchJS = `function choiceMatches(label, value) {
+				const l = cleanText(label).toLowerCase();
+				const v = cleanText(value).toLowerCase();
+				if (!l || !v) return false;
+				if (l === v) return true;
+				if (l.split(/[\s,;:()\/]+/).includes(v)) return true;
+				return v.length > 2 && l.includes(v);
+			}`
+
+type choiceResult struct {
+	Found   bool     `json:"found"`
+	OK      bool     `json:"ok"`
+	Checked bool     `json:"checked"`
+	Label   string   `json:"label"`
+	Error   string   `json:"error"`
+	Missing []string `json:"missing"`
+	Failed  []string `json:"failed"`
+	Options []string `json:"options"`
+}
+
+// SetCheckbox ставит или снимает флажок (или выбирает переключатель) по селектору или
+// подписи и проверяет итоговое состояние. Подходят нативные input, флажки внутри <label>
+// и ARIA-флажки (role=checkbox/switch с aria-label).
+func (b *Browser) SetCheckbox(labelOrSelector string, checked bool) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(15*time.Second))
+	defer cancel()
+
+	script := `
+		(function() {
+			` + useHelpersJS + `
+			` + choiceHelpersJS + `
+			` + setChoiceJS + `
+			` + choiceMatchJS + `
+			const query = '` + escapeJSString(labelOrSelector) + `';
+			const wanted = ` + strconv.FormatBool(checked) + `;
+
+			let el = null;
+			try { el = document.querySelector(query); } catch (e) {}
+			if (el && !el.matches(choiceSelector)) {
+				el = el.control || el.querySelector(choiceSelector);
+			}
+			if (!el) {
+				const all = Array.from(document.querySelectorAll(choiceSelector)).filter(choiceVisible);
+				const q = cleanText(query).toLowerCase();
+				el = all.find(c => choiceLabel(c).toLowerCase() === q) || all.find(c => choiceMatches(choiceLabel(c), query));
+			}
+			if (!el) return {found: false};
+
+			const label = choiceLabel(el).substring(0, 80);
+			const radio = el.type === 'radio' || el.getAttribute('role') === 'radio';
+			if (radio && !wanted && isChecked(el)) {
+				return {found: true, ok: false, checked: true, label, error: 'выбранный переключатель нельзя снять - выбери другой вариант группы'};
+			}
+			const ok = setChoice(el, wanted);
+			return {found: true, ok, checked: isChecked(el), label};
+		})()
+	`
+
+	var result choiceResult
+	if err := chromedp.Run(ctx, evaluate(script, &result)); err != nil {
+		return fmt.Errorf("failed to set checkbox: %w", err)
+	}
+	if !result.Found {
+		return fmt.Errorf("флажок '%s' не найден", labelOrSelector)
+	}
+	if result.Error != "" {
+		return fmt.Errorf("флажок '%s': %s", result.Label, result.Error)
+	}
+	if !result.OK {
+		return fmt.Errorf("флажок '%s' не переключился: состояние checked=%t (элемент отключен или страница отменила изменение)", result.Label, result.Checked)
+	}
+	return nil
+}
+
+// SetChoices находит группу вариантов по подписи рядом (legend у fieldset, aria-label
+// группы, label у select, заголовок над списком флажков) и за один проход выбирает все
+// перечисленные значения: отмечает флажки, выбирает опции <select multiple> или
+// role=listbox. Остальные варианты группы не меняются. Не найденные значения
+// возвращаются в ошибке вместе со списком доступных.
+func (b *Browser) SetChoices(groupHint string, values []string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("не указаны значения для выбора")
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(20*time.Second))
+	defer cancel()
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	script := `
+		(function() {
+			` + useHelpersJS + `
+			` + choiceHelpersJS + `
+			` + setChoiceJS + `
+			` + choiceMatchJS + `
+			const hint = '` + escapeJSString(groupHint) + `';
+			const values = ` + string(valuesJSON) + `;
+			const optionSelector = 'option, [role="option"]';
+			const hasOptions = el => el.matches('select, [role="listbox"]');
+
+			function groupLabel(el) {
+				if (el.tagName === 'FIELDSET') {
+					const legend = el.querySelector('legend');
+					if (legend && cleanText(legend.innerText)) return cleanText(legend.innerText);
+				}
+				return choiceLabel(el);
+			}
+			function hasChoices(el) {
+				return hasOptions(el) || Array.from(el.querySelectorAll(choiceSelector)).some(choiceVisible) ||
+					!!el.querySelector('select[multiple], [role="listbox"]');
+			}
+
+			// Явные группы: fieldset, role=group/radiogroup, списки
+			const groups = Array.from(document.querySelectorAll('fieldset, [role="group"], [role="radiogroup"], select, [role="listbox"]'))
+				.filter(g => (isVisible(g) || g.tagName === 'SELECT') && hasChoices(g));
+			const q = cleanText(hint).toLowerCase();
+			let group = null;
+			if (q) {
+				group = groups.find(g => groupLabel(g).toLowerCase() === q) ||
+					groups.find(g => groupLabel(g).toLowerCase().startsWith(q)) ||
+					groups.find(g => choiceMatches(groupLabel(g), hint));
+			}
+
+			// Подпись рядом: короткий текст с подсказкой, ближайший предок с вариантами
+			if (!group && q) {
+				const captions = Array.from(document.querySelectorAll('legend, label, h1, h2, h3, h4, h5, h6, dt, strong, b, span, div, p'))
+					.filter(el => {
+						const text = cleanText(el.innerText);
+						return text && text.length <= 80 && el.children.length <= 3 && text.toLowerCase().includes(q) &&
+							!el.matches(choiceSelector) && isVisible(el);
+					});
+				for (const caption of captions) {
+					let node = caption.parentElement;
+					for (let i = 0; i < 5 && node && node !== document.body; i++, node = node.parentElement) {
+						if (hasChoices(node)) { group = node; break; }
+					}
+					if (group) break;
+				}
+			}
+			if (!group) {
+				return {found: false, options: groups.map(groupLabel).filter(Boolean).slice(0, 15)};
+			}
+
+			// Список вариантов: select/listbox внутри группы или сама группа
+			const list = hasOptions(group) ? group : (Array.from(group.querySelectorAll(choiceSelector)).some(choiceVisible) ? null : group.querySelector('select[multiple], select, [role="listbox"]'));
+			const missing = [];
+			const failed = [];
+			let changed = 0;
+
+			if (list) {
+				const options = Array.from(list.querySelectorAll(optionSelector));
+				const optionLabel = o => cleanText(o.label || o.innerText || o.textContent || o.value);
+				for (const value of values) {
+					const option = options.find(o => optionLabel(o).toLowerCase() === cleanText(value).toLowerCase() || o.value === value) ||
+						options.find(o => choiceMatches(optionLabel(o), value));
+					if (!option) { missing.push(value); continue; }
+					if (option.tagName === 'OPTION') {
+						if (option.disabled) { failed.push(value); continue; }
+						if (!option.selected) {
+							if (!list.multiple) list.value = option.value;
+							option.selected = true;
+							changed++;
+						}
+					} else if (option.getAttribute('aria-selected') !== 'true') {
+						option.scrollIntoView({block: 'nearest'});
+						option.click();
+						if (option.getAttribute('aria-selected') === 'true') changed++; else failed.push(value);
+					}
+				}
+				if (list.tagName === 'SELECT' && changed > 0) {
+					list.dispatchEvent(new Event('input', {bubbles: true}));
+					list.dispatchEvent(new Event('change', {bubbles: true}));
+				}
+				return {found: true, label: groupLabel(group).substring(0, 80), missing, failed, changed,
+					options: options.map(optionLabel).filter(Boolean).slice(0, 30)};
+			}
+
+			const items = Array.from(group.querySelectorAll(choiceSelector)).filter(choiceVisible);
+			for (const value of values) {
+				const item = items.find(c => choiceLabel(c).toLowerCase() === cleanText(value).toLowerCase() || c.value === value) ||
+					items.find(c => choiceMatches(choiceLabel(c), value));
+				if (!item) { missing.push(value); continue; }
+				const before = isChecked(item);
+				if (!setChoice(item, true)) { failed.push(value); continue; }
+				if (!before) changed++;
+			}
+			return {found: true, label: groupLabel(group).substring(0, 80), missing, failed, changed,
+				options: items.map(choiceLabel).filter(Boolean).slice(0, 30)};
+		})()
+	`
+
+	var result choiceResult
+	if err := chromedp.Run(ctx, evaluate(script, &result)); err != nil {
+		return fmt.Errorf("failed to set choices: %w", err)
+	}
+	if !result.Found {
+		if len(result.Options) > 0 {
+			return fmt.Errorf("группа вариантов '%s' не найдена. Группы на странице: %s", groupHint, strings.Join(result.Options, ", "))
+		}
+		return fmt.Errorf("группа вариантов '%s' не найдена", groupHint)
+	}
+	if len(result.Missing) > 0 {
+		return fmt.Errorf("в группе '%s' нет вариантов: %s. Доступные варианты: %s",
+			result.Label, strings.Join(result.Missing, ", "), strings.Join(result.Options, ", "))
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("в группе '%s' не удалось выбрать: %s (вариант отключен или страница отменила выбор)",
+			result.Label, strings.Join(result.Failed, ", "))
+	}
+	return nil
+}
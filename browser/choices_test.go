@@ -0,0 +1,112 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+// choicesPage - флажки в обертке <label>, с <label for>, ARIA-флажок, группа в fieldset
+// и список с множественным выбором
+const choicesPage = `<form>
+	<label><input type="checkbox" name="news"> Получать новости</label>
+	<input type="checkbox" id="terms" name="terms"><label for="terms">Согласен с условиями</label>
+	<div role="checkbox" id="stock" aria-checked="false" aria-label="Только в наличии" tabindex="0"
+		style="width:20px;height:20px;border:1px solid"
+		onclick="this.setAttribute('aria-checked', String(this.getAttribute('aria-checked') !== 'true'))"></div>
+	<fieldset>
+		<legend>Цвет</legend>
+		<label><input type="checkbox" name="color" value="red"> Красный</label>
+		<label><input type="checkbox" name="color" value="blue"> Синий</label>
+		<label><input type="checkbox" name="color" value="green"> Зеленый</label>
+	</fieldset>
+	<label for="sizes">Размеры</label>
+	<select id="sizes" multiple><option>S</option><option>M</option><option>L</option></select>
+</form>`
+
+func TestSetCheckbox(t *testing.T) {
+	b := newTestBrowser(t)
+	if err := b.Navigate(servePage(t, choicesPage)); err != nil {
+		t.Fatal(err)
+	}
+	checked := func(js string) bool {
+		t.Helper()
+		var state bool
+		if err := chromedp.Run(b.ctx, chromedp.Evaluate(js, &state)); err != nil {
+			t.Fatal(err)
+		}
+		return state
+	}
+
+	tests := []struct {
+		name, target string
+		state        string
+	}{
+		{"label-wrapped", "Получать новости", `document.querySelector('[name=news]').checked`},
+		{"label for", "Согласен с условиями", `document.getElementById('terms').checked`},
+		{"aria-labelled", "Только в наличии", `document.getElementById('stock').getAttribute('aria-checked') === 'true'`},
+		{"selector", "[name=news]", `document.querySelector('[name=news]').checked`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range []bool{true, false} {
+				if err := b.SetCheckbox(tt.target, want); err != nil {
+					t.Fatalf("SetCheckbox(%q, %v): %v", tt.target, want, err)
+				}
+				if got := checked(tt.state); got != want {
+					t.Errorf("SetCheckbox(%q, %v) left checked=%v", tt.target, want, got)
+				}
+			}
+		})
+	}
+
+	if err := b.SetCheckbox("Подписка на смс", true); err == nil {
+		t.Error("SetCheckbox() of a missing checkbox succeeded")
+	}
+
+	if err := b.SetCheckbox("Получать новости", true); err != nil {
+		t.Fatal(err)
+	}
+	content, err := b.GetPageContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range content.Inputs {
+		if input.Name == "news" && (input.Checked == nil || !*input.Checked) {
+			t.Errorf("page analysis reports news checkbox as %v, want checked", input.Checked)
+		}
+	}
+}
+
+func TestSetChoices(t *testing.T) {
+	b := newTestBrowser(t)
+	if err := b.Navigate(servePage(t, choicesPage)); err != nil {
+		t.Fatal(err)
+	}
+	values := func(js string) string {
+		t.Helper()
+		var got string
+		if err := chromedp.Run(b.ctx, chromedp.Evaluate(js, &got)); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if err := b.SetChoices("Цвет", []string{"Красный", "Зеленый"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := values(`Array.from(document.querySelectorAll('[name=color]:checked')).map(c => c.value).join(',')`); got != "red,green" {
+		t.Errorf("checked colors = %q, want red,green", got)
+	}
+
+	if err := b.SetChoices("Размеры", []string{"S", "L"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := values(`Array.from(document.getElementById('sizes').selectedOptions).map(o => o.value).join(',')`); got != "S,L" {
+		t.Errorf("selected sizes = %q, want S,L", got)
+	}
+
+	if err := b.SetChoices("Цвет", []string{"Фиолетовый"}); err == nil {
+		t.Error("SetChoices() with a missing value succeeded")
+	}
+}
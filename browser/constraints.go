@@ -0,0 +1,54 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inputConstraintsJS - функция inputConstraints(el): ограничения HTML5-проверки поля
+// (required, pattern, minlength/maxlength, min/max/step). Пустые значения не возвращаются,
+// чтобы не раздувать результат анализа страницы.
+const inputConstraintsJS = `function inputConstraints(el) {
+				const c = {};
+				if (el.required || el.getAttribute('aria-required') === 'true') c.required = true;
+				const attr = name => (el.getAttribute(name) || '').trim().substring(0, 100);
+				if (attr('pattern')) c.pattern = attr('pattern');
+				if (el.minLength > 0) c.minlength = el.minLength;
+				if (el.maxLength > 0 && el.maxLength < 524288) c.maxlength = el.maxLength;
+				if (attr('min')) c.min = attr('min');
+				if (attr('max')) c.max = attr('max');
+				if (attr('step') && attr('step') !== 'any') c.step = attr('step');
+				return c;
+			}`
+
+// Constraints описывает ограничения поля для модели: "обязательное, от 1 до 10, шаг 1".
+// Пустая строка - ограничений нет.
+func (in Input) Constraints() string {
+	var parts []string
+	if in.Required {
+		parts = append(parts, "обязательное")
+	}
+	switch {
+	case in.Min != "" && in.Max != "":
+		parts = append(parts, fmt.Sprintf("от %s до %s", in.Min, in.Max))
+	case in.Min != "":
+		parts = append(parts, "не меньше "+in.Min)
+	case in.Max != "":
+		parts = append(parts, "не больше "+in.Max)
+	}
+	if in.Step != "" {
+		parts = append(parts, "шаг "+in.Step)
+	}
+	switch {
+	case in.MinLength > 0 && in.MaxLength > 0:
+		parts = append(parts, fmt.Sprintf("длина %d-%d символов", in.MinLength, in.MaxLength))
+	case in.MinLength > 0:
+		parts = append(parts, fmt.Sprintf("не короче %d символов", in.MinLength))
+	case in.MaxLength > 0:
+		parts = append(parts, fmt.Sprintf("не длиннее %d символов", in.MaxLength))
+	}
+	if in.Pattern != "" {
+		parts = append(parts, "формат /"+in.Pattern+"/")
+	}
+	return strings.Join(parts, ", ")
+}
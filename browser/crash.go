@@ -0,0 +1,146 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrRendererCrashed - процесс отрисовки вкладки упал: браузер работает, но любое
+// обращение к странице ждет таймаута. Вкладку восстанавливает RecoverRenderer.
+var ErrRendererCrashed = errors.New("renderer crashed - процесс вкладки упал, нужна перезагрузка вкладки")
+
+// CrashInfo описывает страницу ошибки Chrome вместо содержимого сайта
+type CrashInfo struct {
+	Crashed bool   `json:"crashed"`
+	URL     string `json:"url"`
+	Reason  string `json:"reason"` // текст ошибки или код (STATUS_ACCESS_VIOLATION, ERR_CONNECTION_RESET)
+}
+
+// DetectCrashPage проверяет, не показывает ли вкладка страницу ошибки chrome-error://
+// (сбой загрузки, ERR_CONNECTION_RESET и т.п.). Страница "Aw, Snap!" упавшего процесса
+// вкладки в DOM не попадает - такой сбой сообщает RendererCrashed.
+func (b *Browser) DetectCrashPage() (*CrashInfo, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var info CrashInfo
+	err := chromedp.Run(ctx, chromedp.Evaluate(`
+		(function() {
+			const url = window.location.href;
+			if (!url.startsWith('chrome-error://')) {
+				return {crashed: false, url: url, reason: ''};
+			}
+			const text = document.body ? (document.body.innerText || '') : '';
+			const code = text.match(/\b(ERR_[A-Z_]+|STATUS_[A-Z_]+|RESULT_CODE_[A-Z_]+)\b/);
+			return {crashed: true, url: url, reason: code ? code[0] : 'chrome-error'};
+		})()
+	`, &info))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check crash page: %w", err)
+	}
+	return &info, nil
+}
+
+// Reload перезагружает текущую страницу и ждет появления body
+func (b *Browser) Reload() error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 45*time.Second)
+	defer cancel()
+
+	if err := chromedp.Run(ctx,
+		chromedp.Reload(),
+		chromedp.WaitVisible("body", chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second),
+	); err != nil {
+		return fmt.Errorf("failed to reload page: %w", err)
+	}
+	return nil
+}
+
+// RendererCrashed сообщает, что процесс отрисовки текущей вкладки упал (событие
+// Inspector.targetCrashed). В отличие от отмены контекста браузер при этом жив.
+func (b *Browser) RendererCrashed() bool {
+	tabID := chromedp.FromContext(b.ctx).Target.TargetID
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return b.crashedTabs[tabID]
+}
+
+// crashedError возвращает ErrRendererCrashed без обращения к упавшей вкладке
+func (b *Browser) crashedError() error {
+	if b.RendererCrashed() {
+		return ErrRendererCrashed
+	}
+	return nil
+}
+
+// RecoverRenderer перезагружает вкладку с упавшим процессом отрисовки, не перезапуская
+// браузер: Chrome поднимает для той же вкладки новый процесс и открывает ее адрес.
+// Если перезагрузка не помогла, адрес открывается заново. Возвращает адрес вкладки.
+func (b *Browser) RecoverRenderer() (string, error) {
+	tabID := chromedp.FromContext(b.ctx).Target.TargetID
+	ctx, cancel := context.WithTimeout(b.ctx, 45*time.Second)
+	defer cancel()
+
+	// Адрес упавшей вкладки знает процесс браузера, а не страница
+	var url string
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		info, err := target.GetTargetInfo().WithTargetID(tabID).Do(ctx)
+		if err == nil {
+			url = info.URL
+		}
+		return err
+	})); err != nil {
+		return "", fmt.Errorf("failed to get crashed tab info: %w", err)
+	}
+
+	b.eventsMu.Lock()
+	delete(b.crashedTabs, tabID)
+	b.eventsMu.Unlock()
+
+	err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error { return page.Reload().Do(ctx) }),
+		chromedp.WaitVisible("body", chromedp.ByQuery),
+	)
+	if err != nil && url != "" {
+		err = chromedp.Run(ctx, chromedp.Navigate(url), chromedp.WaitVisible("body", chromedp.ByQuery))
+	}
+	if err != nil {
+		return url, fmt.Errorf("failed to reload crashed tab: %w", err)
+	}
+	return url, nil
+}
+
+// crashRenderer роняет процесс отрисовки текущей вкладки (Page.crash) - для тестов
+// восстановления после сбоя
+func (b *Browser) crashRenderer() error {
+	// Упавшая вкладка не отвечает на команду - ждем только события о сбое
+	ctx, cancel := context.WithTimeout(b.ctx, 2*time.Second)
+	defer cancel()
+	chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error { return page.Crash().Do(ctx) }))
+
+	for i := 0; i < 20 && !b.RendererCrashed(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !b.RendererCrashed() {
+		return fmt.Errorf("вкладка не упала после Page.crash")
+	}
+	return nil
+}
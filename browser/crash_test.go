@@ -0,0 +1,43 @@
+package browser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestRecoverRenderer(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<form><input id="q"><button type="submit">Найти</button></form>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.crashRenderer(); err != nil {
+		t.Fatal(err)
+	}
+	if !b.RendererCrashed() {
+		t.Fatal("RendererCrashed() = false after Page.crash")
+	}
+	// Действия в упавшей вкладке не ждут таймаута
+	if _, err := b.SubmitEnclosingForm("#q", nil); !errors.Is(err, ErrRendererCrashed) {
+		t.Errorf("SubmitEnclosingForm() in a crashed tab = %v, want ErrRendererCrashed", err)
+	}
+
+	recovered, err := b.RecoverRenderer()
+	if err != nil {
+		t.Fatalf("RecoverRenderer() error: %v", err)
+	}
+	if !strings.HasPrefix(recovered, url) || b.RendererCrashed() {
+		t.Errorf("RecoverRenderer() = %q, crashed %v, want %q", recovered, b.RendererCrashed(), url)
+	}
+	var inputs int
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(`document.querySelectorAll('#q').length`, &inputs)); err != nil || inputs != 1 {
+		t.Errorf("page after recovery: %d inputs, %v", inputs, err)
+	}
+	if info, err := b.DetectCrashPage(); err != nil || info.Crashed {
+		t.Errorf("DetectCrashPage() after recovery = %+v, %v", info, err)
+	}
+}
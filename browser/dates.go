@@ -0,0 +1,64 @@
+package browser
+
+// PageDate - дата на странице: отображаемый текст ("2 дня назад", "вчера") и точное
+// значение из атрибута datetime, title или data-timestamp
+type PageDate struct {
+	Text     string `json:"text"`
+	DateTime string `json:"datetime"`          // "2024-06-01 10:30" в часовом поясе браузера или исходное значение, если его не удалось разобрать
+	Context  string `json:"context,omitempty"` // начало текста строки списка или карточки, к которой относится дата
+}
+
+// dateExtractionJS - JS-выражение, возвращающее даты страницы: элементы <time>, элементы
+// с data-timestamp и элементы с относительным временем в тексте и точной датой в title
+// (так показывают время почтовые клиенты и ленты), не больше 30. Встраивается в скрипты
+// GetPageContent и GetQuickPageInfo и использует их isVisible.
+const dateExtractionJS = `(function() {
+				const clean = s => (s || '').replace(/\s+/g, ' ').trim();
+				const pad = n => String(n).padStart(2, '0');
+				// Точное значение в местном времени браузера; дата без времени остается датой
+				const normalize = raw => {
+					raw = clean(raw);
+					if (!raw) return '';
+					if (/^\d{4}-\d{2}-\d{2}$/.test(raw)) return raw;
+					let d = null;
+					if (/^\d{10}(\d{3})?$/.test(raw)) {
+						d = new Date(raw.length === 10 ? Number(raw) * 1000 : Number(raw));
+					} else if (/\d{4}/.test(raw)) {
+						// Без года Date.parse подставляет 2001 - такие значения не разбираем
+						const t = Date.parse(raw);
+						if (!isNaN(t)) d = new Date(t);
+					}
+					if (!d || isNaN(d.getTime())) return raw;
+					return d.getFullYear() + '-' + pad(d.getMonth() + 1) + '-' + pad(d.getDate()) +
+						' ' + pad(d.getHours()) + ':' + pad(d.getMinutes());
+				};
+				const relativeRe = /назад|вчера|сегодня|позавчера|только что|ago|yesterday|today|just now|^\d{1,2}:\d{2}$|^\d+\s*(мин|ч|д|нед|мес|m|h|d|w)\.?$/i;
+				const contextOf = el => {
+					const item = el.closest('li, tr, article, [role="row"], [role="listitem"], [role="article"]') || el.parentElement;
+					const text = item ? clean(item.innerText) : '';
+					return text.length > 80 ? text.substring(0, 80) + '...' : text;
+				};
+
+				const dates = [];
+				const seen = new Set();
+				const add = (el, raw) => {
+					if (dates.length >= 30 || seen.has(el) || !isVisible(el)) return;
+					const datetime = normalize(raw);
+					const text = clean(el.innerText || el.textContent).substring(0, 60);
+					if (!datetime || datetime === text) return;
+					seen.add(el);
+					dates.push({text: text, datetime: datetime, context: contextOf(el)});
+				};
+
+				document.querySelectorAll('time').forEach(el => add(el, el.getAttribute('datetime') || el.getAttribute('title') || el.textContent));
+				document.querySelectorAll('[data-timestamp], [data-time], [data-datetime]').forEach(el =>
+					add(el, el.getAttribute('data-timestamp') || el.getAttribute('data-time') || el.getAttribute('data-datetime')));
+				document.querySelectorAll('[title]').forEach(el => {
+					if (dates.length >= 30 || el.children.length > 2) return;
+					const title = el.getAttribute('title');
+					// "1 июня 2024 г., 10:00" Date.parse не разбирает - такой title передается как есть
+					if (!/\d{4}/.test(title)) return;
+					if (relativeRe.test(clean(el.innerText))) add(el, title);
+				});
+				return dates;
+			})()`
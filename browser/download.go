@@ -0,0 +1,194 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+)
+
+// downloadPoll - как часто WaitForDownload проверяет ход загрузки
+const downloadPoll = 200 * time.Millisecond
+
+// download - загрузка по событиям Browser.downloadWillBegin и Browser.downloadProgress
+type download struct {
+	guid     string
+	url      string
+	name     string // имя, предложенное сайтом
+	state    cdpbrowser.DownloadProgressState
+	received float64
+	total    float64
+	taken    bool // файл уже выдан WaitForDownload
+}
+
+// WithDownloadDir задает каталог загрузок (DOWNLOADS_DIR): файлы, которые скачивает
+// страница, сохраняются в него, а WaitForDownload сообщает путь к готовому файлу.
+// Без каталога загрузки идут в папку Chrome по умолчанию, и агент о них не знает.
+func WithDownloadDir(dir string) Option {
+	return func(b *Browser) {
+		b.downloadDir = dir
+	}
+}
+
+// DownloadDir возвращает каталог загрузок (пусто - не задан)
+func (b *Browser) DownloadDir() string {
+	return b.downloadDir
+}
+
+// applyDownloadBehavior направляет загрузки вкладки в каталог загрузок. Файл пишется
+// под именем GUID загрузки - настоящее имя файлу дает WaitForDownload, поэтому путь
+// известен точно, даже если Chrome переименовал бы совпадающий файл.
+func (b *Browser) applyDownloadBehavior() error {
+	if b.downloadDir == "" || !b.caps.DownloadEvents {
+		return nil
+	}
+	dir, err := filepath.Abs(b.downloadDir)
+	if err != nil {
+		return fmt.Errorf("invalid downloads directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+	if err := chromedp.Run(ctx, cdpbrowser.SetDownloadBehavior(cdpbrowser.SetDownloadBehaviorBehaviorAllowAndName).
+		WithDownloadPath(dir).
+		WithEventsEnabled(true)); err != nil {
+		return fmt.Errorf("failed to set download behavior: %w", err)
+	}
+	return nil
+}
+
+// noteDownload обновляет состояние загрузки по событию. Вызывается под eventsMu.
+func (b *Browser) noteDownload(ev interface{}) {
+	switch e := ev.(type) {
+	case *cdpbrowser.EventDownloadWillBegin:
+		b.downloads = append(b.downloads, &download{guid: e.GUID, url: e.URL, name: e.SuggestedFilename,
+			state: cdpbrowser.DownloadProgressStateInProgress})
+	case *cdpbrowser.EventDownloadProgress:
+		for _, d := range b.downloads {
+			if d.guid == e.GUID {
+				d.state, d.received, d.total = e.State, e.ReceivedBytes, e.TotalBytes
+				break
+			}
+		}
+	}
+}
+
+// MarkDownloads возвращает метку очереди загрузок перед кликом: WaitForDownload с этой
+// меткой принимает только загрузки, начатые после нее, а не оставшиеся от прошлых действий
+func (b *Browser) MarkDownloads() int {
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return len(b.downloads)
+}
+
+// nextDownload - первая еще не выданная загрузка, начатая после метки since
+func nextDownload(downloads []*download, since int) *download {
+	for _, d := range downloads[min(max(since, 0), len(downloads)):] {
+		if !d.taken {
+			return d
+		}
+	}
+	return nil
+}
+
+// WaitForDownload ждет, пока завершится загрузка, начатая после метки MarkDownloads
+// (кликом по ссылке или кнопке "Скачать"), и возвращает путь к файлу в каталоге
+// загрузок. Каждая загрузка выдается один раз; загрузки, начатые до метки, не выдаются.
+func (b *Browser) WaitForDownload(since int, timeout time.Duration) (string, error) {
+	if b.downloadDir == "" {
+		return "", fmt.Errorf("каталог загрузок не задан (DOWNLOADS_DIR) - файл сохраняется в папку Chrome по умолчанию, и путь к нему неизвестен")
+	}
+	if !b.caps.DownloadEvents {
+		return "", fmt.Errorf("события загрузок не поддерживаются этой версией браузера")
+	}
+
+	deadline := time.Now().Add(b.actionTimeout(timeout))
+	for {
+		select {
+		case <-b.ctx.Done():
+			return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+		default:
+		}
+
+		b.eventsMu.Lock()
+		var d download
+		next := nextDownload(b.downloads, since)
+		started := next != nil
+		if started {
+			if next.state != cdpbrowser.DownloadProgressStateInProgress {
+				next.taken = true
+			}
+			d = *next
+		}
+		b.eventsMu.Unlock()
+
+		switch {
+		case started && d.state == cdpbrowser.DownloadProgressStateCompleted:
+			return b.finishDownload(d)
+		case started && d.state == cdpbrowser.DownloadProgressStateCanceled:
+			return "", fmt.Errorf("загрузка %s отменена", d.name)
+		}
+		if time.Now().After(deadline) {
+			if !started {
+				return "", fmt.Errorf("загрузка не началась за %v - ссылка открыла страницу, а не файл (для PDF в окне просмотра используй read_document)", b.actionTimeout(timeout))
+			}
+			progress := ""
+			if d.total > 0 {
+				progress = fmt.Sprintf(" (%.0f%%)", d.received/d.total*100)
+			}
+			return "", fmt.Errorf("загрузка %s не завершилась за %v%s", d.name, b.actionTimeout(timeout), progress)
+		}
+		time.Sleep(b.pollInterval(downloadPoll))
+	}
+}
+
+// finishDownload переименовывает файл загрузки из GUID в имя, предложенное сайтом;
+// если такой файл уже есть, к имени добавляется номер
+func (b *Browser) finishDownload(d download) (string, error) {
+	dir, err := filepath.Abs(b.downloadDir)
+	if err != nil {
+		return "", err
+	}
+	saved := filepath.Join(dir, d.guid)
+	name := downloadFileName(d.name)
+	if name == "" {
+		name = d.guid
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	target := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+	if err := os.Rename(saved, target); err != nil {
+		// Файл остался под именем GUID - путь к нему все равно верный
+		if _, statErr := os.Stat(saved); statErr == nil {
+			return saved, nil
+		}
+		return "", fmt.Errorf("загруженный файл не найден: %w", err)
+	}
+	return target, nil
+}
+
+// downloadFileName оставляет от имени файла, предложенного сайтом, безопасную часть
+func downloadFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Trim(strings.TrimSpace(name), ".")
+}
@@ -0,0 +1,47 @@
+package browser
+
+import (
+	"testing"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+)
+
+func TestNextDownload(t *testing.T) {
+	stale := &download{guid: "stale", state: cdpbrowser.DownloadProgressStateCompleted}
+	taken := &download{guid: "taken", state: cdpbrowser.DownloadProgressStateCompleted, taken: true}
+	fresh := &download{guid: "fresh", state: cdpbrowser.DownloadProgressStateInProgress}
+	downloads := []*download{stale, taken, fresh}
+
+	tests := []struct {
+		name  string
+		since int
+		want  *download
+	}{
+		{"everything", 0, stale},
+		{"after the click skips older downloads", 1, fresh},
+		{"nothing started yet", 3, nil},
+		{"mark from before a restart", 10, nil},
+	}
+	for _, tt := range tests {
+		if got := nextDownload(downloads, tt.since); got != tt.want {
+			t.Errorf("%s: nextDownload(since=%d) = %v, want %v", tt.name, tt.since, got, tt.want)
+		}
+	}
+}
+
+func TestDownloadFileName(t *testing.T) {
+	tests := map[string]string{
+		"report.pdf":             "report.pdf",
+		"../../etc/passwd":       "passwd",
+		`..\..\Windows\win.ini`:  "win.ini",
+		"statement<2024>?.csv":   "statement2024.csv",
+		"  ..hidden  ":           "hidden",
+		"":                       "",
+		"счет № 15 от 01.02.pdf": "счет № 15 от 01.02.pdf",
+	}
+	for in, want := range tests {
+		if got := downloadFileName(in); got != want {
+			t.Errorf("downloadFileName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
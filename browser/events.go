@@ -0,0 +1,58 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/inspector"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// setupListeners подписывается на события CDP текущей вкладки и включает нужные перехваты
+func (b *Browser) setupListeners() error {
+	tabID := chromedp.FromContext(b.ctx).Target.TargetID
+	chromedp.ListenTarget(b.ctx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *page.EventFileChooserOpened:
+			b.eventsMu.Lock()
+			b.fileChooser = e
+			b.fileChooserSeq++
+			b.eventsMu.Unlock()
+		case *inspector.EventTargetCrashed:
+			b.eventsMu.Lock()
+			b.crashedTabs[tabID] = true
+			b.eventsMu.Unlock()
+		case *network.EventRequestWillBeSent, *network.EventResponseReceived:
+			b.eventsMu.Lock()
+			b.notePageStatus(tabID, ev)
+			b.eventsMu.Unlock()
+		case *page.EventFrameNavigated, *page.EventNavigatedWithinDocument:
+			b.eventsMu.Lock()
+			b.notePageStatus(tabID, ev)
+			b.noteRoute(tabID, ev)
+			b.eventsMu.Unlock()
+		case *cdpbrowser.EventDownloadWillBegin, *cdpbrowser.EventDownloadProgress:
+			b.eventsMu.Lock()
+			b.noteDownload(ev)
+			b.eventsMu.Unlock()
+		}
+	})
+
+	// Системный диалог выбора файла chromedp не контролирует - перехватываем его,
+	// чтобы он не открывался, а агент получал понятную ошибку
+	if !b.caps.FileChooser {
+		return nil
+	}
+	if err := chromedp.Run(b.ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return page.SetInterceptFileChooserDialog(true).Do(ctx)
+		}),
+	); err != nil {
+		return fmt.Errorf("failed to enable file chooser interception: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package browser_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// Журнал ответов сети через домен CDP, который пакет не оборачивает: подписка
+// регистрируется на Context(), а домен включается через RunActions.
+func ExampleBrowser_RunActions() {
+	dir, err := os.MkdirTemp("", "agent-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := browser.NewBrowser(dir, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer b.Close()
+
+	chromedp.ListenTarget(b.Context(), func(ev interface{}) {
+		if resp, ok := ev.(*network.EventResponseReceived); ok {
+			log.Println(resp.Response.Status, resp.Response.URL)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := b.RunActions(ctx, network.Enable()); err != nil {
+		log.Fatal(err)
+	}
+	if err := b.Navigate("https://example.com"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// maxFetchSize ограничивает размер скачиваемого документа
+const maxFetchSize = 30 << 20
+
+// FetchedFile - файл, скачанный от имени сессии браузера
+type FetchedFile struct {
+	Name        string
+	URL         string
+	ContentType string
+	Data        []byte
+}
+
+// LinkURL возвращает адрес видимой ссылки с указанным текстом (точное совпадение
+// приоритетнее частичного). Текст сравнивается без учета регистра, также
+// проверяются aria-label, title и имя файла в атрибуте download.
+func (b *Browser) LinkURL(text string) (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	script := `
+		(function() {
+			` + useHelpersJS + `
+			const text = '` + escapeJSString(strings.ToLower(strings.TrimSpace(text))) + `';
+			let partial = '';
+			for (const a of document.querySelectorAll('a[href]')) {
+				if (!isVisible(a)) continue;
+				const own = [a.innerText, a.getAttribute('aria-label'), a.title, a.getAttribute('download')]
+					.filter(Boolean).map(t => t.toLowerCase().trim().replace(/\s+/g, ' '));
+				if (own.some(t => t === text)) return a.href;
+				if (!partial && own.some(t => t.includes(text))) partial = a.href;
+			}
+			return partial;
+		})()
+	`
+
+	var href string
+	if err := chromedp.Run(ctx, evaluate(script, &href)); err != nil {
+		return "", fmt.Errorf("failed to find link: %w", err)
+	}
+	if href == "" {
+		return "", fmt.Errorf("ссылка '%s' не найдена на странице", text)
+	}
+	return href, nil
+}
+
+// Fetch скачивает файл по адресу с cookies и User-Agent браузера, поэтому
+// документы из личного кабинета доступны так же, как при клике по ссылке.
+func (b *Browser) Fetch(rawURL string) (*FetchedFile, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	parsed, err := neturl.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("некорректный адрес документа: %s", rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	var cookies []*network.Cookie
+	var userAgent string
+	err = chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			cookies, err = network.GetCookies().WithUrls([]string{rawURL}).Do(ctx)
+			return err
+		}),
+		chromedp.Evaluate(`navigator.userAgent`, &userAgent),
+	)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read browser cookies: %w", err)
+	}
+
+	reqCtx, reqCancel := context.WithTimeout(b.ctx, 60*time.Second)
+	defer reqCancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range b.extraHeaders {
+		req.Header.Set(name, value)
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for _, c := range cookies {
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("сервер вернул %s для %s", resp.Status, rawURL)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if len(data) > maxFetchSize {
+		return nil, fmt.Errorf("документ больше %d МБ", maxFetchSize>>20)
+	}
+
+	return &FetchedFile{
+		Name:        fetchedFileName(resp),
+		URL:         resp.Request.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Data:        data,
+	}, nil
+}
+
+// fetchedFileName берет имя файла из Content-Disposition, иначе из пути адреса
+func fetchedFileName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return path.Base(params["filename"])
+	}
+	name := path.Base(resp.Request.URL.Path)
+	if name == "/" || name == "." || name == "" {
+		return resp.Request.URL.Host
+	}
+	if unescaped, err := neturl.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
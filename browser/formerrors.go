@@ -0,0 +1,45 @@
+package browser
+
+// formErrorsJS извлекает сообщения об ошибках проверки формы только по явной
+// разметке: тексты у полей с aria-invalid или :user-invalid (aria-errormessage,
+// aria-describedby, validationMessage браузера) и видимые role=alert. Классы вроде
+// "error" и "danger" не учитываются: ими размечены и кнопки удаления, и баннеры,
+// и скрытые шаблоны сообщений. Сообщение у поля дополняется его подписью: "Email: Email is invalid".
+const formErrorsJS = `(function() {
+				const errors = [];
+				const seen = new Set();
+				const clean = t => (t || '').replace(/\s+/g, ' ').trim();
+				const add = (text, field) => {
+					text = clean(text);
+					if (text.length < 3 || text.length > 200 || seen.has(text.toLowerCase()) || errors.length >= 10) return;
+					seen.add(text.toLowerCase());
+					errors.push(field && !text.toLowerCase().includes(field.toLowerCase()) ? field + ': ' + text : text);
+				};
+				const byIds = ids => (ids || '').split(/\s+/).map(id => id && document.getElementById(id)).filter(Boolean);
+				const fieldName = el => clean((el.labels && el.labels.length > 0 ? el.labels[0].innerText : '') || el.getAttribute('aria-label') || el.placeholder || el.name || el.id).substring(0, 60);
+
+				let invalid = Array.from(document.querySelectorAll('[aria-invalid="true"]'));
+				try {
+					invalid = invalid.concat(Array.from(document.querySelectorAll('input:user-invalid, select:user-invalid, textarea:user-invalid')));
+				} catch (e) {}
+				for (const el of new Set(invalid)) {
+					if (!isVisible(el)) continue;
+					const field = fieldName(el);
+					const messages = byIds(el.getAttribute('aria-errormessage')).concat(byIds(el.getAttribute('aria-describedby')))
+						.filter(m => isVisible(m) && clean(m.innerText));
+					if (messages.length > 0) {
+						messages.forEach(m => add(m.innerText, field));
+					} else if (el.validationMessage) {
+						add(el.validationMessage, field);
+					}
+				}
+
+				document.querySelectorAll('[role="alert"]').forEach(el => {
+					if (errors.length >= 10 || el.querySelector('input, select, textarea, form, button') || !isVisible(el)) return;
+					const text = clean(el.innerText);
+					// Длинный текст - уведомление или баннер, а не сообщение об ошибке
+					if (text.split(' ').length > 30) return;
+					add(text, '');
+				});
+				return errors;
+			})()`
@@ -0,0 +1,30 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageContentFormErrors(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<form>
+		<label for="email">Email</label>
+		<input id="email" aria-invalid="true" aria-describedby="email-msg" value="x">
+		<div id="email-msg">Некорректный адрес</div>
+		<div role="alert">Проверьте данные формы</div>
+		<div class="error-banner">Ошибка доставки в ваш регион</div>
+		<button class="btn-danger" type="button">Удалить аккаунт</button>
+		<span class="field-error" style="display: none">Шаблон скрытой ошибки</span>
+	</form>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+	pc, err := b.GetPageContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Email: Некорректный адрес|Проверьте данные формы"
+	if got := strings.Join(pc.Errors, "|"); got != want {
+		t.Errorf("Errors = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,373 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrFrameGone - фрейм, выбранный моделью, уже отсоединен от страницы (фреймы
+// пересоздаются при перерисовке). Нужен новый анализ страницы.
+var ErrFrameGone = errors.New("фрейм больше не существует на странице")
+
+// maxFrames - сколько дочерних фреймов с элементами попадает в извлечение
+const maxFrames = 5
+
+// FrameContent - элементы внутри iframe (форма оплаты, виджет входа, капча)
+type FrameContent struct {
+	ID          string   `json:"id"` // идентификатор для поля "frame" решения: frame-1, frame-2...
+	Name        string   `json:"name,omitempty"`
+	URL         string   `json:"url"`
+	CrossOrigin bool     `json:"cross_origin,omitempty"`
+	Inputs      []Input  `json:"inputs,omitempty"`
+	Buttons     []Button `json:"buttons,omitempty"`
+}
+
+// frameElementsJS извлекает поля и кнопки документа фрейма
+const frameElementsJS = `(function() {
+			` + useHelpersJS + `
+			if (window.innerWidth < 20 || window.innerHeight < 10) return null;
+			const labelOf = el => {
+				if (el.labels && el.labels.length > 0) return el.labels[0].textContent.trim();
+				return el.getAttribute('aria-label') || el.getAttribute('data-placeholder') || '';
+			};
+			` + inputConstraintsJS + `
+			` + selectStateJS + `
+			const inputs = Array.from(document.querySelectorAll('input, textarea, select'))
+				.filter(el => !['hidden', 'submit', 'button'].includes(el.type) && isVisible(el))
+				.slice(0, 20)
+				.map(el => ({type: el.type || el.tagName.toLowerCase(), placeholder: el.placeholder || '', name: el.name || '', id: el.id || '', label: labelOf(el).substring(0, 80), ...inputConstraints(el), ...selectState(el)}));
+			const buttons = Array.from(document.querySelectorAll('button, [role="button"], input[type="submit"], input[type="button"], a[href]'))
+				.filter(isVisible)
+				.map(el => ({text: withTitle(el, el.innerText || el.value || el.getAttribute('aria-label') || '').substring(0, 80), type: el.tagName.toLowerCase()}))
+				.filter(b => b.text)
+				.slice(0, 20);
+			return {inputs: inputs, buttons: buttons};
+		})()`
+
+// frameRef - фрейм из последнего извлечения. Кросс-доменный фрейм при изоляции сайтов
+// живет в отдельном процессе (out-of-process iframe): у него своя цель CDP с тем же
+// идентификатором, что и у фрейма, и команды идут в нее, а не во вкладку.
+type frameRef struct {
+	id     cdp.FrameID
+	remote bool
+}
+
+// maxFrameOwners - сколько элементов iframe страницы проверяется на кросс-доменные фреймы
+const maxFrameOwners = 20
+
+// extractFrames извлекает элементы дочерних фреймов страницы. Каждый фрейм читается
+// в собственном изолированном контексте выполнения; кросс-доменные фреймы (платежные
+// формы) Chrome держит в отдельных процессах, и они читаются через свои цели CDP.
+func (b *Browser) extractFrames(ctx context.Context) []FrameContent {
+	var tree *page.FrameTree
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		tree, err = page.GetFrameTree().Do(ctx)
+		return err
+	})); err != nil || tree == nil {
+		return nil
+	}
+
+	refs := make(map[string]frameRef)
+	var frames []FrameContent
+	add := func(ref frameRef, content FrameContent) {
+		content.ID = fmt.Sprintf("frame-%d", len(frames)+1)
+		refs[content.ID] = ref
+		frames = append(frames, content)
+	}
+	local := map[cdp.FrameID]bool{tree.Frame.ID: true}
+	var walk func(node *page.FrameTree)
+	walk = func(node *page.FrameTree) {
+		for _, child := range node.ChildFrames {
+			local[child.Frame.ID] = true
+			if len(frames) >= maxFrames {
+				continue
+			}
+			ref := frameRef{id: child.Frame.ID}
+			var content FrameContent
+			if err := b.evaluateInFrame(ctx, ref, frameElementsJS, &content); err == nil && (len(content.Inputs) > 0 || len(content.Buttons) > 0) {
+				content.Name = child.Frame.Name
+				content.URL = child.Frame.URL
+				content.CrossOrigin = child.Frame.SecurityOrigin != tree.Frame.SecurityOrigin
+				add(ref, content)
+			}
+			walk(child)
+		}
+	}
+	walk(tree)
+
+	// Фреймов из других процессов нет в дереве страницы - их находим по элементам iframe
+	for _, owner := range b.remoteFrameOwners(ctx, tree.Frame.ID, local) {
+		if len(frames) >= maxFrames {
+			break
+		}
+		ref := frameRef{id: owner.id, remote: true}
+		var content FrameContent
+		if err := b.evaluateInFrame(ctx, ref, frameElementsJS, &content); err != nil || (len(content.Inputs) == 0 && len(content.Buttons) == 0) {
+			continue
+		}
+		content.Name = owner.name
+		content.URL = b.remoteFrameURL(ctx, ref)
+		content.CrossOrigin = true
+		add(ref, content)
+	}
+
+	b.framesMu.Lock()
+	b.frameRefs = refs
+	b.framesMu.Unlock()
+	return frames
+}
+
+// frameOwner - элемент iframe страницы, фрейм которого не входит в дерево фреймов вкладки
+type frameOwner struct {
+	id   cdp.FrameID
+	name string
+}
+
+// remoteFrameOwners находит элементы iframe основного документа, фреймы которых живут
+// в другом процессе. Элементы читаются через Runtime и DOM.describeNode: DOM.getDocument
+// сбросил бы идентификаторы узлов, с которыми работает chromedp.
+func (b *Browser) remoteFrameOwners(ctx context.Context, mainFrame cdp.FrameID, local map[cdp.FrameID]bool) []frameOwner {
+	var owners []frameOwner
+	chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		contextID, err := page.CreateIsolatedWorld(mainFrame).WithWorldName(agentWorldName).Do(ctx)
+		if err != nil {
+			return err
+		}
+		const group = "agent-frame-owners"
+		defer runtime.ReleaseObjectGroup(group).Do(ctx)
+		for i := 0; i < maxFrameOwners; i++ {
+			obj, _, err := runtime.Evaluate(fmt.Sprintf(`document.querySelectorAll('iframe, frame')[%d]`, i)).
+				WithContextID(contextID).WithObjectGroup(group).Do(ctx)
+			if err != nil || obj == nil || obj.ObjectID == "" {
+				return err
+			}
+			node, err := dom.DescribeNode().WithObjectID(obj.ObjectID).Do(ctx)
+			if err != nil || node.FrameID == "" || local[node.FrameID] {
+				continue
+			}
+			owners = append(owners, frameOwner{id: node.FrameID, name: node.AttributeValue("name")})
+		}
+		return nil
+	}))
+	return owners
+}
+
+// remoteFrameURL возвращает адрес документа кросс-доменного фрейма
+func (b *Browser) remoteFrameURL(ctx context.Context, ref frameRef) string {
+	frameCtx, err := b.frameContext(ctx, ref)
+	if err != nil {
+		return ""
+	}
+	tree, err := page.GetFrameTree().Do(frameCtx)
+	if err != nil || tree == nil {
+		return ""
+	}
+	return tree.Frame.URL
+}
+
+// frameTarget подключается к цели CDP кросс-доменного фрейма. Подключение создается
+// один раз на фрейм и живет, пока открыта вкладка: отмена контекста chromedp
+// закрывает цель, а закрывать фрейм страницы нельзя.
+func (b *Browser) frameTarget(id cdp.FrameID) (*chromedp.Target, error) {
+	b.framesMu.Lock()
+	defer b.framesMu.Unlock()
+	if frameCtx, ok := b.frameTargets[id]; ok {
+		if c := chromedp.FromContext(frameCtx); c != nil && c.Target != nil && frameCtx.Err() == nil {
+			return c.Target, nil
+		}
+	}
+	// Первый Run подключается к цели, и подключение живет столько же, сколько контекст
+	frameCtx, _ := chromedp.NewContext(b.ctx, chromedp.WithTargetID(target.ID(id)))
+	if err := chromedp.Run(frameCtx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFrameGone, err)
+	}
+	if b.frameTargets == nil {
+		b.frameTargets = make(map[cdp.FrameID]context.Context)
+	}
+	b.frameTargets[id] = frameCtx
+	return chromedp.FromContext(frameCtx).Target, nil
+}
+
+// frameContext возвращает ctx, команды CDP в котором идут в процесс фрейма: во вкладку
+// для фреймов того же процесса и в цель фрейма для кросс-доменных
+func (b *Browser) frameContext(ctx context.Context, ref frameRef) (context.Context, error) {
+	if !ref.remote {
+		return cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target), nil
+	}
+	frameTarget, err := b.frameTarget(ref.id)
+	if err != nil {
+		return nil, err
+	}
+	return cdp.WithExecutor(ctx, frameTarget), nil
+}
+
+// evaluateInFrame выполняет скрипт в изолированном контексте фрейма
+func (b *Browser) evaluateInFrame(ctx context.Context, ref frameRef, script string, res interface{}) error {
+	frameCtx, err := b.frameContext(ctx, ref)
+	if err != nil {
+		return err
+	}
+	contextID, err := page.CreateIsolatedWorld(ref.id).WithWorldName(agentWorldName).Do(frameCtx)
+	if err != nil {
+		return err
+	}
+	inWorld := func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithContextID(contextID)
+	}
+	// Изолированный контекст создается заново, общих функций в нем еще нет
+	if err := ensureHelpers(inWorld).Do(frameCtx); err != nil {
+		return err
+	}
+	return chromedp.Evaluate(script, res, inWorld).Do(frameCtx)
+}
+
+// resolveFrame находит фрейм по идентификатору из извлечения и проверяет, что фрейм
+// все еще присоединен к странице; кросс-доменный фрейм проверяется подключением к его цели
+func (b *Browser) resolveFrame(ctx context.Context, frame string) (frameRef, error) {
+	b.framesMu.Lock()
+	ref, ok := b.frameRefs[strings.TrimSpace(frame)]
+	b.framesMu.Unlock()
+	if !ok {
+		return frameRef{}, fmt.Errorf("%w: %s", ErrFrameGone, frame)
+	}
+	if ref.remote {
+		if _, err := b.frameTarget(ref.id); err != nil {
+			return frameRef{}, fmt.Errorf("%w: %s", ErrFrameGone, frame)
+		}
+		return ref, nil
+	}
+
+	var tree *page.FrameTree
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		tree, err = page.GetFrameTree().Do(ctx)
+		return err
+	})); err != nil {
+		return frameRef{}, fmt.Errorf("failed to read frame tree: %w", err)
+	}
+	if !frameInTree(tree, ref.id) {
+		return frameRef{}, fmt.Errorf("%w: %s", ErrFrameGone, frame)
+	}
+	return ref, nil
+}
+
+func frameInTree(node *page.FrameTree, id cdp.FrameID) bool {
+	if node == nil || node.Frame == nil {
+		return false
+	}
+	if node.Frame.ID == id {
+		return true
+	}
+	for _, child := range node.ChildFrames {
+		if frameInTree(child, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// runInFrame выполняет скрипт во фрейме, выбранном моделью. Отказ CDP в доступе
+// к фрейму возвращается отдельной понятной ошибкой.
+func (b *Browser) runInFrame(frame, script string, res interface{}) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	ref, err := b.resolveFrame(ctx, frame)
+	if err != nil {
+		return err
+	}
+	if err := b.evaluateInFrame(ctx, ref, script, res); err != nil {
+		if strings.Contains(err.Error(), "No frame") || strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("%w: %s", ErrFrameGone, frame)
+		}
+		return fmt.Errorf("нет доступа к фрейму %s через CDP: %w", frame, err)
+	}
+	return nil
+}
+
+// ClickByTextInFrame кликает по кнопке или ссылке с текстом внутри фрейма
+func (b *Browser) ClickByTextInFrame(frame, text string) error {
+	var found bool
+	err := b.runInFrame(frame, `
+		(function() {
+			`+useHelpersJS+`
+			const text = '`+escapeJSString(text)+`'.toLowerCase().trim();
+			const candidates = Array.from(document.querySelectorAll('button, [role="button"], input[type="submit"], input[type="button"], a, label')).filter(isVisible);
+			const own = el => (el.innerText || el.value || el.getAttribute('aria-label') || '').toLowerCase().trim();
+			const el = candidates.find(c => own(c) === text) || candidates.find(c => own(c).includes(text));
+			if (!el) return false;
+			el.scrollIntoView({block: 'center'});
+			el.click();
+			return true;
+		})()
+	`, &found)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("element with text '%s' not found in %s", text, frame)
+	}
+	return nil
+}
+
+// FillInputInFrame заполняет поле внутри фрейма (например, номер карты в платежном iframe).
+// Поле ищется по placeholder, name, id, label или aria-label; текст вводится как с клавиатуры,
+// чтобы сработали маски и проверки платежных виджетов.
+func (b *Browser) FillInputInFrame(frame, target, value string) error {
+	var found bool
+	err := b.runInFrame(frame, `
+		(function() {
+			`+useHelpersJS+`
+			const target = '`+escapeJSString(target)+`'.toLowerCase().trim();
+			const fields = Array.from(document.querySelectorAll('input, textarea')).filter(el => !['hidden', 'submit', 'button'].includes(el.type) && isVisible(el));
+			const describe = el => [el.placeholder, el.name, el.id, el.getAttribute('aria-label'), el.labels && el.labels.length > 0 ? el.labels[0].textContent : '']
+				.filter(Boolean).join(' ').toLowerCase();
+			const el = fields.find(f => describe(f).split(' ').includes(target)) || fields.find(f => describe(f).includes(target)) || (fields.length === 1 ? fields[0] : null);
+			if (!el) return false;
+			el.scrollIntoView({block: 'center'});
+			el.focus();
+			el.select && el.select();
+			return true;
+		})()
+	`, &found)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("input '%s' not found in %s", target, frame)
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+	ref, err := b.resolveFrame(ctx, frame)
+	if err != nil {
+		return err
+	}
+	frameCtx, err := b.frameContext(ctx, ref)
+	if err != nil {
+		return err
+	}
+	// Фокус уже во фрейме - ввод с клавиатуры попадает в выбранное поле
+	if err := input.InsertText(value).Do(frameCtx); err != nil {
+		return fmt.Errorf("failed to type into %s: %w", frame, err)
+	}
+	return nil
+}
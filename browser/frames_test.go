@@ -0,0 +1,26 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+)
+
+func TestFrameInTree(t *testing.T) {
+	tree := &page.FrameTree{
+		Frame: &cdp.Frame{ID: "main"},
+		ChildFrames: []*page.FrameTree{
+			{Frame: &cdp.Frame{ID: "login"}},
+			{Frame: &cdp.Frame{ID: "ads"}, ChildFrames: []*page.FrameTree{{Frame: &cdp.Frame{ID: "nested"}}}},
+		},
+	}
+	for id, want := range map[cdp.FrameID]bool{"main": true, "login": true, "nested": true, "payment": false} {
+		if got := frameInTree(tree, id); got != want {
+			t.Errorf("frameInTree(%s) = %v, want %v", id, got, want)
+		}
+	}
+	if frameInTree(nil, "main") {
+		t.Error("frameInTree(nil) must be false")
+	}
+}
@@ -0,0 +1,83 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// ParseHeaders разбирает заголовки вида "Accept-Language: ru-RU,ru;q=0.9|X-Test: 1".
+// Заголовки разделяются "|", потому что ";" и "," встречаются в значениях.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, part := range strings.Split(s, "|") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("ожидается 'Заголовок: значение', получено %q", part)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// WithExtraHeaders задает HTTP-заголовки, которые браузер добавляет ко всем запросам вкладки
+func WithExtraHeaders(headers map[string]string) Option {
+	return func(b *Browser) {
+		b.extraHeaders = copyHeaders(headers)
+	}
+}
+
+// SetExtraHeaders задает HTTP-заголовки (например, Accept-Language: ru-RU) для
+// последующих переходов и запросов страницы. Пустой map снимает заголовки.
+// Заголовки сохраняются и применяются заново после Restart.
+func (b *Browser) SetExtraHeaders(headers map[string]string) error {
+	b.extraHeaders = copyHeaders(headers)
+	return b.applyExtraHeaders()
+}
+
+// ExtraHeaders возвращает заданные дополнительные заголовки
+func (b *Browser) ExtraHeaders() map[string]string {
+	return copyHeaders(b.extraHeaders)
+}
+
+// applyExtraHeaders передает заголовки в Chrome через Network.setExtraHTTPHeaders
+func (b *Browser) applyExtraHeaders() error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	headers := make(network.Headers, len(b.extraHeaders))
+	for name, value := range b.extraHeaders {
+		headers[name] = value
+	}
+	if err := chromedp.Run(ctx, network.SetExtraHTTPHeaders(headers)); err != nil {
+		return fmt.Errorf("failed to set extra headers: %w", err)
+	}
+	return nil
+}
+
+func copyHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+	return copied
+}
@@ -0,0 +1,230 @@
+package browser
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// pageHelpersJS - общие функции встраиваемых скриптов: isVisible, isRevealable, withTitle,
+// getButtonText, getElementText, revealInScrollContainers и lastFocused. Скрипт внедряется в каждый
+// новый документ вкладки один раз (Page.addScriptToEvaluateOnNewDocument) в изолированный
+// мир агента и публикует функции в его window.__agentHelpers, поэтому методы браузера
+// передают по CDP только собственную логику, а исправления общих функций действуют во
+// всех методах сразу.
+const pageHelpersJS = `(function() {
+	if (window.__agentHelpers) return;
+
+	` + isVisibleJS + `
+
+	` + isRevealableJS + `
+
+	` + revealInScrollContainersJS + `
+
+	` + lastFocusedJS + `
+
+	// Текст с учетом атрибута title: у кнопки-иконки без текста или только с символом
+	// (🗑, ×) и у обрезанного текста ("Отчет за третий кв…") смысл элемента - в title,
+	// который на странице не виден
+	function withTitle(el, text) {
+		text = (text || '').trim();
+		const title = (el.getAttribute('title') || '').replace(/\s+/g, ' ').trim();
+		if (!title) return text;
+		if (!/[\p{L}\p{N}]/u.test(text)) return title;
+		const truncated = text.match(/^(.*?)\s*(…|\.\.\.)$/);
+		if (truncated && title.length > truncated[1].length) return title;
+		return text;
+	}
+
+	// Текст кнопки, включая иконки и символы
+	function getButtonText(b) {
+		// Сначала пробуем обычный текст
+		let text = (b.innerText || b.textContent || b.value || '').trim();
+
+		// Если текста нет, пробуем aria-label, title
+		if (!text) {
+			text = (b.getAttribute('aria-label') || b.getAttribute('title') || '').trim();
+		}
+		text = withTitle(b, text);
+
+		// Если текста все еще нет, ищем иконки и символы
+		if (!text) {
+			// Ищем SVG иконки
+			const svg = b.querySelector('svg');
+			if (svg) {
+				const svgText = svg.textContent || svg.getAttribute('aria-label') || '';
+				if (svgText) text = svgText.trim();
+			}
+
+			// Ищем символы (+, -, ×, и т.д.)
+			const symbols = b.textContent.match(/[+×−−−]/);
+			if (symbols && symbols.length > 0) {
+				text = symbols[0];
+			}
+
+			// Ищем по классам/ID для кнопок добавления
+			const className = (typeof b.className === 'string' ? b.className : (b.className ? b.className.toString() : '')).toLowerCase();
+			const id = (b.id || '').toLowerCase();
+			if (className.includes('add') || className.includes('cart') || className.includes('basket') ||
+				id.includes('add') || id.includes('cart') || id.includes('basket')) {
+				text = text || '+';
+			}
+		}
+
+		return text;
+	}
+
+	// Текст элемента для поиска по тексту, включая иконки, символы и псевдоэлементы
+	function getElementText(el) {
+		// Обычный текст
+		let text = (el.innerText || el.textContent || '').trim();
+
+		// Если текста нет, пробуем aria-label, title
+		if (!text) {
+			text = (el.getAttribute('aria-label') || el.getAttribute('title') || '').trim();
+		}
+		text = withTitle(el, text);
+
+		// Если текста нет, ищем символы (+, -, ×) в тексте
+		if (!text) {
+			const symbols = el.textContent.match(/[+×−−−]/);
+			if (symbols && symbols.length > 0) {
+				text = symbols[0];
+			}
+		}
+
+		// Если текста нет, ищем символ "+" в SVG
+		if (!text) {
+			const svg = el.querySelector('svg');
+			if (svg) {
+				// Ищем текст в SVG
+				const svgText = svg.textContent || svg.getAttribute('aria-label') || '';
+				if (svgText && svgText.includes('+')) {
+					text = '+';
+				}
+				// Ищем path с признаками плюса
+				const paths = svg.querySelectorAll('path, line, circle, rect');
+				paths.forEach(path => {
+					const d = path.getAttribute('d') || '';
+					// Простая эвристика: если есть вертикальные и горизонтальные линии, это может быть плюс
+					if (d.includes('M') && d.includes('L') && !text) {
+						// Проверяем, есть ли в родительском элементе текст "+"
+						const parentText = (el.textContent || '').trim();
+						if (parentText === '+' || parentText.includes('+')) {
+							text = '+';
+						}
+					}
+				});
+			}
+		}
+
+		// Если текста нет, ищем по классам/ID для кнопок добавления
+		if (!text) {
+			const className = (typeof el.className === 'string' ? el.className : (el.className ? el.className.toString() : '')).toLowerCase();
+			const id = (el.id || '').toLowerCase();
+			const dataTestid = (el.getAttribute('data-testid') || '').toLowerCase();
+			const dataQa = (el.getAttribute('data-qa') || '').toLowerCase();
+
+			if (className.includes('add') || className.includes('cart') || className.includes('basket') ||
+				id.includes('add') || id.includes('cart') || id.includes('basket') ||
+				className.includes('plus') || className.includes('increment') ||
+				dataTestid.includes('add') || dataQa.includes('add')) {
+				text = '+';
+			}
+		}
+
+		// Проверяем псевдоэлементы (::before, ::after) через computed styles
+		if (!text) {
+			const style = window.getComputedStyle(el, '::before');
+			const beforeContent = style.content;
+			if (beforeContent && (beforeContent.includes('+') || beforeContent === '"+"' || beforeContent === "'+'")) {
+				text = '+';
+			}
+			if (!text) {
+				const afterStyle = window.getComputedStyle(el, '::after');
+				const afterContent = afterStyle.content;
+				if (afterContent && (afterContent.includes('+') || afterContent === '"+"' || afterContent === "'+'")) {
+					text = '+';
+				}
+			}
+		}
+
+		return text;
+	}
+
+	Object.defineProperty(window, '__agentHelpers', {
+		value: Object.freeze({isVisible, isRevealable, withTitle, getButtonText, getElementText, revealInScrollContainers, lastFocused}),
+		enumerable: false
+	});
+})()`
+
+// useHelpersJS подключает общие функции в начале встраиваемого скрипта.
+// Скрипт с ним выполняется через evaluate.
+const useHelpersJS = `const {isVisible, isRevealable, withTitle, getButtonText, getElementText, revealInScrollContainers, lastFocused} = window.__agentHelpers;`
+
+// agentWorldName - имя изолированного мира, в котором выполняются скрипты агента.
+// У мира свои глобальные объекты: страница не видит window.__agentHelpers и другие
+// служебные данные агента и не может их подменить, а скрипты агента не зависят от
+// того, что страница переопределила в своих глобальных объектах. DOM у миров общий.
+const agentWorldName = "agent"
+
+// injectHelpers регистрирует общие функции для всех следующих документов вкладки
+func (b *Browser) injectHelpers() error {
+	return chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(pageHelpersJS).WithWorldName(agentWorldName).Do(ctx)
+		return err
+	}))
+}
+
+// agentWorld возвращает параметр Evaluate, который выполняет скрипт в изолированном
+// мире агента основного фрейма вкладки. Мир с тем же именем создается для фрейма
+// один раз, повторный вызов возвращает его же контекст.
+func agentWorld(ctx context.Context) (chromedp.EvaluateOption, error) {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return nil, chromedp.ErrInvalidTarget
+	}
+	// Основной фрейм вкладки имеет тот же ID, что и сама вкладка
+	contextID, err := page.CreateIsolatedWorld(cdp.FrameID(c.Target.TargetID)).WithWorldName(agentWorldName).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithContextID(contextID)
+	}, nil
+}
+
+// evaluate выполняет скрипт агента в изолированном мире основного фрейма вкладки,
+// подключив общие функции. Все скрипты, которые пользуются общими функциями или
+// служебными данными window.__agent*, выполняются через него.
+func evaluate(script string, res interface{}) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		inWorld, err := agentWorld(ctx)
+		if err != nil {
+			return err
+		}
+		if err := ensureHelpers(inWorld).Do(ctx); err != nil {
+			return err
+		}
+		return chromedp.Evaluate(script, res, inWorld).Do(ctx)
+	})
+}
+
+// ensureHelpers добавляет общие функции в текущий документ, если их там нет:
+// документ был открыт до внедрения (about:blank при запуске) или это изолированный
+// контекст фрейма. Обычно это одна короткая проверка.
+func ensureHelpers(opts ...chromedp.EvaluateOption) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var ready bool
+		if err := chromedp.Evaluate(`!!window.__agentHelpers`, &ready, opts...).Do(ctx); err != nil {
+			return err
+		}
+		if ready {
+			return nil
+		}
+		return chromedp.Evaluate(pageHelpersJS, nil, opts...).Do(ctx)
+	})
+}
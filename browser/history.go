@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrHistoryStart - вкладка уже на первой странице своей истории, назад вернуться нельзя
+var ErrHistoryStart = errors.New("вкладка на первой странице истории, назад вернуться нельзя")
+
+// ErrHistoryEnd - вкладка на последней странице своей истории, вперед перейти нельзя
+var ErrHistoryEnd = errors.New("вкладка на последней странице истории, вперед перейти нельзя")
+
+// GoBack возвращается на предыдущую страницу вкладки. На первой странице истории
+// возвращает ErrHistoryStart, а не ждет перехода, которого не будет.
+func (b *Browser) GoBack() error {
+	_, err := b.GoBackN(1)
+	return err
+}
+
+// GoForward переходит на следующую страницу истории вкладки (после GoBack). На
+// последней странице истории возвращает ErrHistoryEnd.
+func (b *Browser) GoForward() error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(30*time.Second))
+	defer cancel()
+
+	err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			current, entries, err := page.GetNavigationHistory().Do(ctx)
+			if err != nil {
+				return err
+			}
+			if current < 0 || int(current)+1 >= len(entries) {
+				return ErrHistoryEnd
+			}
+			return page.NavigateToHistoryEntry(entries[current+1].ID).Do(ctx)
+		}),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Sleep(1*time.Second),
+	)
+	if errors.Is(err, ErrHistoryEnd) {
+		return ErrHistoryEnd
+	}
+	if err != nil {
+		return fmt.Errorf("failed to go forward: %w", err)
+	}
+	return nil
+}
+
+// GoBackN возвращается на n страниц назад по истории вкладки одним переходом (например,
+// из глубины карточек товаров обратно к результатам поиска). Если предыдущих страниц
+// меньше n, переходит к первой странице истории. Возвращает число сделанных шагов.
+func (b *Browser) GoBackN(n int) (int, error) {
+	select {
+	case <-b.ctx.Done():
+		return 0, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	if n < 1 {
+		n = 1
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(30*time.Second))
+	defer cancel()
+
+	steps := 0
+	err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			current, entries, err := page.GetNavigationHistory().Do(ctx)
+			if err != nil {
+				return err
+			}
+			if current <= 0 || int(current) >= len(entries) {
+				return ErrHistoryStart
+			}
+			target := max(int(current)-n, 0)
+			steps = int(current) - target
+			return page.NavigateToHistoryEntry(entries[target].ID).Do(ctx)
+		}),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Sleep(1*time.Second),
+	)
+	if errors.Is(err, ErrHistoryStart) {
+		return 0, ErrHistoryStart
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to go back %d pages: %w", n, err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,54 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// pageHTMLJS - копия документа без скриптов, обработчиков событий и введенных данных:
+// значения полей (пароли, токены CSRF в скрытых полях), текст textarea и адреса
+// iframe убираются, разметка и видимый текст страницы остаются
+const pageHTMLJS = `(() => {
+	const root = document.documentElement.cloneNode(true);
+	root.querySelectorAll('script, noscript, template').forEach(el => el.remove());
+	root.querySelectorAll('input, textarea, select, option').forEach(el => {
+		el.removeAttribute('value');
+		if (el.tagName === 'TEXTAREA') el.textContent = '';
+	});
+	root.querySelectorAll('iframe').forEach(el => { el.removeAttribute('src'); el.removeAttribute('srcdoc'); });
+	root.querySelectorAll('*').forEach(el => {
+		for (const attr of Array.from(el.attributes)) {
+			if (/^on/i.test(attr.name) || /^javascript:/i.test(attr.value)) el.removeAttribute(attr.name);
+		}
+	});
+	// Текущие значения полей живут в свойствах, а не в атрибутах - их клон не переносит,
+	// но отметки флажков оставляем: от них зависит поведение агента
+	const live = document.querySelectorAll('input[type="checkbox"], input[type="radio"]');
+	root.querySelectorAll('input[type="checkbox"], input[type="radio"]').forEach((el, i) => {
+		if (live[i] && live[i].checked) el.setAttribute('checked', '');
+	});
+	const doctype = document.doctype ? '<!DOCTYPE ' + document.doctype.name + '>\n' : '';
+	return doctype + root.outerHTML;
+})()`
+
+// GetPageHTML возвращает очищенный HTML текущей страницы: снимок для отчета об
+// ошибке, который можно отправить разработчику (без скриптов и введенных данных)
+func (b *Browser) GetPageHTML() (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	var html string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(pageHTMLJS, &html)); err != nil {
+		return "", fmt.Errorf("failed to get page HTML: %w", err)
+	}
+	return html, nil
+}
@@ -0,0 +1,112 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// IdleWarning - предупреждение о завершении сессии из-за бездействия
+// ("Are you still there?", "Сеанс скоро завершится")
+type IdleWarning struct {
+	Found  bool   `json:"found"`
+	Text   string `json:"text"`   // текст окна предупреждения
+	Button string `json:"button"` // кнопка, которая оставляет пользователя в системе ("" - не найдена)
+}
+
+// idleWarningAttr отмечает кнопку "остаться в системе" для клика по селектору
+const idleWarningAttr = "data-agent-idle-stay"
+
+// idleWarningJS ищет видимое окно (диалог, модальное окно, всплывающий блок поверх страницы)
+// с текстом о скором выходе из системы и кнопку продления сессии в нем. Выбирается только
+// явная кнопка "Остаться"/"Продолжить"/"Продлить": общие "Да" и "OK" могут означать и выход,
+// а кнопки выхода ("Выйти", "Log out") не выбираются никогда. Блоки без ролей и классов
+// ищутся только среди детей body и под центром экрана, а не перебором всех div.
+const idleWarningJS = `(function() {
+				` + useHelpersJS + `
+				const clean = t => (t || '').replace(/\s+/g, ' ').trim();
+				const warning = /(session|you).{0,40}(expir|time ?out|timed out|log(ged)? ?out|sign(ed)? ?out)|still (there|here|active)|are you there|(due to|because of) inactivity|been (idle|inactive)|(сесси|сеанс).{0,40}(истек|заверш|закончит|прерв|будет закрыт)|вы (все |всё )?(еще|ещё) (здесь|тут)|бездейств|неактивност|(выведен|разлогинен|выйдете) из (системы|аккаунта)|продлить (сесси|сеанс)|продолжить (сесси|сеанс)/i;
+				const stay = /^(stay|keep me|continue|extend|resume|i'?m (still )?here|i am (still )?here|продолж|остать|продл|я (здесь|тут|на месте))/i;
+				const leave = /(log ?out|sign ?out|log off|end session|выйти|выход|завершить)/i;
+
+				document.querySelectorAll('[` + idleWarningAttr + `]').forEach(el => el.removeAttribute('` + idleWarningAttr + `'));
+				const candidates = new Set(document.querySelectorAll('dialog[open], [role="dialog"], [role="alertdialog"], [aria-modal="true"], .modal, [class*="modal" i], [class*="dialog" i], [class*="popup" i], [class*="timeout" i], [class*="idle" i], [class*="session" i]'));
+				// Окна без ролей и классов - блоки с fixed прямо в body или под центром экрана
+				const fixed = el => el instanceof Element && window.getComputedStyle(el).position === 'fixed';
+				if (document.body) Array.from(document.body.children).forEach(el => { if (fixed(el)) candidates.add(el); });
+				for (let el = document.elementFromPoint(window.innerWidth / 2, window.innerHeight / 2); el && el !== document.body; el = el.parentElement) {
+					if (fixed(el)) candidates.add(el);
+				}
+
+				for (const box of candidates) {
+					if (!isVisible(box)) continue;
+					const text = clean(box.innerText);
+					if (text.length === 0 || text.length > 1000 || !warning.test(text)) continue;
+					const buttons = Array.from(box.querySelectorAll('button, a, [role="button"], input[type="button"], input[type="submit"]'))
+						.filter(el => isVisible(el))
+						.map(el => ({el, text: clean(el.innerText || el.value || el.getAttribute('aria-label'))}))
+						.filter(btn => btn.text && !leave.test(btn.text));
+					const best = buttons.find(btn => stay.test(btn.text));
+					if (best) best.el.setAttribute('` + idleWarningAttr + `', '');
+					return {found: true, text: text.substring(0, 300), button: best ? best.text.substring(0, 60) : ''};
+				}
+				return {found: false, text: '', button: ''};
+			})()`
+
+// DetectIdleWarning ищет на странице предупреждение о завершении сессии из-за
+// бездействия и кнопку, которая оставляет пользователя в системе
+func (b *Browser) DetectIdleWarning() (*IdleWarning, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var warning IdleWarning
+	if err := chromedp.Run(ctx, evaluate(idleWarningJS, &warning)); err != nil {
+		return nil, fmt.Errorf("failed to check idle warning: %w", err)
+	}
+	return &warning, nil
+}
+
+// DismissIdleWarning нажимает в найденном DetectIdleWarning предупреждении кнопку
+// продления сессии и проверяет, что окно закрылось. Кнопка нажимается, только если на
+// ней все еще тот же текст, который видел вызывающий и проверил перед нажатием.
+func (b *Browser) DismissIdleWarning(warning *IdleWarning) error {
+	if warning == nil || !warning.Found {
+		return nil
+	}
+	if warning.Button == "" {
+		return fmt.Errorf("в предупреждении о бездействии нет кнопки продления сессии")
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+	var current string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`(function() {
+		const el = document.querySelector('[`+idleWarningAttr+`]');
+		return el ? (el.innerText || el.value || el.getAttribute('aria-label') || '').replace(/\s+/g, ' ').trim().substring(0, 60) : '';
+	})()`, &current)); err != nil {
+		return fmt.Errorf("failed to check %q: %w", warning.Button, err)
+	}
+	if current != warning.Button {
+		return fmt.Errorf("кнопка %q в предупреждении о бездействии изменилась или пропала", warning.Button)
+	}
+	if err := chromedp.Run(ctx,
+		chromedp.Click("["+idleWarningAttr+"]", chromedp.ByQuery, chromedp.NodeVisible),
+		chromedp.Sleep(1*time.Second),
+	); err != nil {
+		return fmt.Errorf("failed to click %q: %w", warning.Button, err)
+	}
+
+	after, err := b.DetectIdleWarning()
+	if err == nil && after.Found {
+		return fmt.Errorf("предупреждение о бездействии не закрылось после нажатия %q", warning.Button)
+	}
+	return nil
+}
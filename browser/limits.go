@@ -0,0 +1,113 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContentLimits ограничивает объем данных, извлекаемых GetPageContent.
+// Чем меньше лимиты, тем короче промпт и дешевле шаг, но тем больше шанс,
+// что нужный элемент не попадет в выборку. Нулевое поле - значение по умолчанию.
+type ContentLimits struct {
+	MaxLinks     int `json:"max_links"`
+	MaxButtons   int `json:"max_buttons"`
+	MaxInputs    int `json:"max_inputs"`
+	MaxTextChars int `json:"max_text_chars"`
+	MaxTables    int `json:"max_tables"`
+	MaxLists     int `json:"max_lists"`
+}
+
+// DefaultContentLimits возвращает лимиты извлечения по умолчанию. Они повторяют то,
+// что модель видела до появления настройки: 15 ссылок и 3000 символов текста.
+func DefaultContentLimits() ContentLimits {
+	return ContentLimits{
+		MaxLinks:     15,
+		MaxButtons:   150,
+		MaxInputs:    25,
+		MaxTextChars: 3000,
+		MaxTables:    10,
+		MaxLists:     20,
+	}
+}
+
+// withDefaults заменяет незаданные (нулевые и отрицательные) лимиты значениями по умолчанию
+func (l ContentLimits) withDefaults() ContentLimits {
+	def := DefaultContentLimits()
+	fill := func(v *int, d int) {
+		if *v <= 0 {
+			*v = d
+		}
+	}
+	fill(&l.MaxLinks, def.MaxLinks)
+	fill(&l.MaxButtons, def.MaxButtons)
+	fill(&l.MaxInputs, def.MaxInputs)
+	fill(&l.MaxTextChars, def.MaxTextChars)
+	fill(&l.MaxTables, def.MaxTables)
+	fill(&l.MaxLists, def.MaxLists)
+	return l
+}
+
+// String возвращает лимиты в формате ParseContentLimits
+func (l ContentLimits) String() string {
+	return fmt.Sprintf("links=%d,buttons=%d,inputs=%d,text=%d,tables=%d,lists=%d",
+		l.MaxLinks, l.MaxButtons, l.MaxInputs, l.MaxTextChars, l.MaxTables, l.MaxLists)
+}
+
+// ParseContentLimits разбирает строку вида "links=30,buttons=100,text=3000".
+// Неуказанные лимиты берутся по умолчанию.
+func ParseContentLimits(s string) (ContentLimits, error) {
+	limits := DefaultContentLimits()
+	fields := map[string]*int{
+		"links":   &limits.MaxLinks,
+		"buttons": &limits.MaxButtons,
+		"inputs":  &limits.MaxInputs,
+		"text":    &limits.MaxTextChars,
+		"tables":  &limits.MaxTables,
+		"lists":   &limits.MaxLists,
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return limits, fmt.Errorf("некорректный лимит '%s': ожидается имя=число", part)
+		}
+		field, ok := fields[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return limits, fmt.Errorf("неизвестный лимит '%s' (допустимо: links, buttons, inputs, text, tables, lists)", key)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 0 {
+			return limits, fmt.Errorf("лимит '%s' должен быть положительным числом", key)
+		}
+		*field = n
+	}
+	return limits, nil
+}
+
+// WithContentLimits задает лимиты извлечения содержимого страницы
+func WithContentLimits(l ContentLimits) Option {
+	return func(b *Browser) {
+		b.contentLimits = l.withDefaults()
+	}
+}
+
+// SetContentLimits меняет лимиты извлечения; действует со следующего GetPageContent
+func (b *Browser) SetContentLimits(l ContentLimits) {
+	b.contentLimits = l.withDefaults()
+}
+
+// ContentLimits возвращает текущие лимиты извлечения
+func (b *Browser) ContentLimits() ContentLimits {
+	return b.contentLimits.withDefaults()
+}
+
+// contentLimitsJS возвращает лимиты как JS-объект для встраивания в скрипт извлечения
+func (b *Browser) contentLimitsJS() string {
+	data, _ := json.Marshal(b.ContentLimits()) // структура из целых чисел сериализуется всегда
+	return string(data)
+}
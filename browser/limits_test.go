@@ -0,0 +1,48 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseContentLimits(t *testing.T) {
+	def := DefaultContentLimits()
+	tests := []struct {
+		in      string
+		want    ContentLimits
+		wantErr string
+	}{
+		{"", def, ""},
+		{"links=30", ContentLimits{30, def.MaxButtons, def.MaxInputs, def.MaxTextChars, def.MaxTables, def.MaxLists}, ""},
+		{" Text = 1500 , lists=5,", ContentLimits{def.MaxLinks, def.MaxButtons, def.MaxInputs, 1500, def.MaxTables, 5}, ""},
+		{"links", def, "ожидается имя=число"},
+		{"images=5", def, "неизвестный лимит"},
+		{"links=0", def, "положительным числом"},
+	}
+	for _, tt := range tests {
+		got, err := ParseContentLimits(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseContentLimits(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseContentLimits(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestContentLimitsDefaults(t *testing.T) {
+	// Без настройки модель видит столько же ссылок и текста, сколько до появления лимитов
+	if def := DefaultContentLimits(); def.MaxLinks != 15 || def.MaxTextChars != 3000 {
+		t.Errorf("DefaultContentLimits() = %v, want links=15 and text=3000", def)
+	}
+	partial := ContentLimits{MaxLinks: 40, MaxTextChars: -1}.withDefaults()
+	if partial.MaxLinks != 40 || partial.MaxTextChars != 3000 || partial.MaxButtons != 150 {
+		t.Errorf("withDefaults() = %v", partial)
+	}
+	if s := DefaultContentLimits().String(); s != "links=15,buttons=150,inputs=25,text=3000,tables=10,lists=20" {
+		t.Errorf("String() = %q", s)
+	}
+}
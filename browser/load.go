@@ -0,0 +1,165 @@
+package browser
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// keepAliveInterval - как часто keep-alive проверяет вкладку
+	keepAliveInterval = 30 * time.Second
+	// keepAliveQuiet - keep-alive не проверяет вкладку, если другая операция
+	// выполнялась за это время: браузер и так отвечает
+	keepAliveQuiet = 20 * time.Second
+	// slowOperation - операция дольше порога считается медленной
+	slowOperation = 10 * time.Second
+	// slowStreakLowPower - столько медленных операций подряд включают режим низкой мощности
+	slowStreakLowPower = 3
+	// lowPowerFactor - во столько раз режим низкой мощности удлиняет интервалы опроса
+	lowPowerFactor = 3
+)
+
+// Причины режима низкой мощности
+const (
+	LowPowerConfig = "config" // включен настройкой (WithLowPower)
+	LowPowerAuto   = "auto"   // включен после нескольких медленных операций подряд
+)
+
+// LoadStats - нагрузка агента на браузер: режим низкой мощности и пропущенная работа.
+// Объясняет медленную работу на слабом компьютере.
+type LoadStats struct {
+	LowPower         bool          `json:"low_power"`
+	LowPowerReason   string        `json:"low_power_reason,omitempty"` // config или auto
+	LowPowerSince    time.Time     `json:"low_power_since,omitempty"`
+	Operations       int           `json:"operations"`      // учтенные операции: извлечение, ожидание готовности
+	SlowOperations   int           `json:"slow_operations"` // из них дольше slowOperation
+	SlowestOperation time.Duration `json:"slowest_operation"`
+	KeepAliveProbes  int           `json:"keep_alive_probes"`
+	KeepAliveSkipped int           `json:"keep_alive_skipped"` // пропущено: браузер недавно отвечал
+}
+
+// loadBudget отслеживает длительность операций с браузером, чтобы фоновые
+// проверки и повторы не нагружали и без того медленный Chrome
+type loadBudget struct {
+	mu         sync.Mutex
+	lastOp     time.Time
+	slowStreak int
+	stats      LoadStats
+}
+
+// WithLowPower включает режим низкой мощности с запуска (LOW_POWER): интервалы опроса
+// страницы и keep-alive удлиняются, повторы извлечения ждут дольше
+func WithLowPower(enabled bool) Option {
+	return func(b *Browser) {
+		if enabled {
+			b.load.setLowPower(LowPowerConfig)
+		}
+	}
+}
+
+// LoadStats возвращает статистику нагрузки на браузер за сессию
+func (b *Browser) LoadStats() LoadStats {
+	b.load.mu.Lock()
+	defer b.load.mu.Unlock()
+	return b.load.stats
+}
+
+// LowPower сообщает, работает ли браузер в режиме низкой мощности
+func (b *Browser) LowPower() bool {
+	b.load.mu.Lock()
+	defer b.load.mu.Unlock()
+	return b.load.stats.LowPower
+}
+
+// String - строка для команды stats
+func (s LoadStats) String() string {
+	mode := "обычный"
+	switch s.LowPowerReason {
+	case LowPowerConfig:
+		mode = "низкая мощность (LOW_POWER)"
+	case LowPowerAuto:
+		mode = fmt.Sprintf("низкая мощность (авто с %s: %d медленных операций подряд)",
+			s.LowPowerSince.Format("15:04:05"), slowStreakLowPower)
+	}
+	return fmt.Sprintf("Режим браузера: %s\n  операций %d, медленных (>%v) %d, самая долгая %.1fs\n  keep-alive: проверок %d, пропущено %d",
+		mode, s.Operations, slowOperation, s.SlowOperations, s.SlowestOperation.Seconds(), s.KeepAliveProbes, s.KeepAliveSkipped)
+}
+
+func (l *loadBudget) setLowPower(reason string) {
+	if l.stats.LowPower {
+		return
+	}
+	l.stats.LowPower = true
+	l.stats.LowPowerReason = reason
+	l.stats.LowPowerSince = time.Now()
+}
+
+// noteOperation учитывает завершенную операцию: отмечает, что браузер недавно
+// отвечал, и после slowStreakLowPower медленных операций подряд включает режим
+// низкой мощности. Обратно режим не выключается - слабый компьютер быстрее не станет.
+func (b *Browser) noteOperation(d time.Duration) {
+	l := &b.load
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastOp = time.Now()
+	l.stats.Operations++
+	if d > l.stats.SlowestOperation {
+		l.stats.SlowestOperation = d
+	}
+	if d <= slowOperation {
+		l.slowStreak = 0
+		return
+	}
+	l.stats.SlowOperations++
+	l.slowStreak++
+	if l.slowStreak >= slowStreakLowPower && !l.stats.LowPower {
+		l.setLowPower(LowPowerAuto)
+		fmt.Printf("🐢 Браузер отвечает медленно (%d операций дольше %v подряд) - включен режим низкой мощности\n",
+			slowStreakLowPower, slowOperation)
+	}
+}
+
+// touchOperation отмечает, что браузер только что ответил, не учитывая длительность:
+// ожидание готовности длится столько, сколько меняется страница, а не сколько думает Chrome
+func (b *Browser) touchOperation() {
+	b.load.mu.Lock()
+	b.load.lastOp = time.Now()
+	b.load.mu.Unlock()
+}
+
+// skipKeepAlive сообщает, что проверку keep-alive можно пропустить: другая
+// операция выполнялась недавно, и лишний запрос только нагрузит Chrome
+func (b *Browser) skipKeepAlive() bool {
+	l := &b.load
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	quiet := keepAliveQuiet
+	if l.stats.LowPower {
+		quiet *= lowPowerFactor
+	}
+	if !l.lastOp.IsZero() && time.Since(l.lastOp) < quiet {
+		l.stats.KeepAliveSkipped++
+		return true
+	}
+	l.stats.KeepAliveProbes++
+	return false
+}
+
+// pollInterval возвращает интервал опроса с учетом режима низкой мощности
+func (b *Browser) pollInterval(d time.Duration) time.Duration {
+	if b.LowPower() {
+		return d * lowPowerFactor
+	}
+	return d
+}
+
+// retryBackoff - пауза перед повтором извлечения: если предыдущая попытка шла
+// дольше slowOperation, Chrome перегружен, и ждать нужно дольше
+func (b *Browser) retryBackoff(attempt int, previous time.Duration) time.Duration {
+	backoff := time.Duration(attempt) * time.Second
+	if previous > slowOperation {
+		backoff = time.Duration(attempt) * 5 * time.Second
+	}
+	return b.pollInterval(backoff)
+}
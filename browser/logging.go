@@ -0,0 +1,84 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Уровни сообщений chromedp, передаваемых Logger
+const (
+	LogLevelInfo  = "info"
+	LogLevelError = "error"
+)
+
+// Logger получает сообщения chromedp, которые не отфильтрованы шаблонами игнорирования
+type Logger func(level, msg string)
+
+// defaultIgnorePatterns - шум chromedp: события новых версий Chrome, которые cdproto
+// не умеет разбирать, и обрывки ответов при закрытии вкладок
+var defaultIgnorePatterns = []string{
+	"could not unmarshal event",
+	"unexpected end of JSON input",
+	"unknown IPAddressSpace value",
+	"unknown PrivateNetworkRequestPolicy value",
+	"parse error",
+	"cookiePart",
+	"unhandled page event",
+}
+
+// defaultLogger выводит сообщения chromedp через log.Printf
+func defaultLogger(level, msg string) {
+	log.Printf("chromedp %s: %s", level, msg)
+}
+
+// WithLogger задает получателя сообщений chromedp (по умолчанию - log.Printf)
+func WithLogger(logger Logger) Option {
+	return func(b *Browser) {
+		b.logger = logger
+	}
+}
+
+// WithIgnorePatterns добавляет подстроки, сообщения chromedp с которыми не передаются
+// Logger, к стандартному списку шума
+func WithIgnorePatterns(patterns ...string) Option {
+	return func(b *Browser) {
+		b.ignorePatterns = append(b.ignorePatterns, patterns...)
+	}
+}
+
+// SetLogger заменяет получателя сообщений chromedp: предупреждения браузера видны при
+// отладке, а в библиотечном режиме их можно направить в свой журнал. nil - log.Printf.
+func (b *Browser) SetLogger(logger func(level, msg string)) {
+	b.logMu.Lock()
+	defer b.logMu.Unlock()
+	b.logger = logger
+}
+
+// logChromedp - функция chromedp.WithLogf: ошибки chromedp приходят с префиксом
+// "ERROR: ", шум из ignorePatterns отбрасывается
+func (b *Browser) logChromedp(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	for _, pattern := range defaultIgnorePatterns {
+		if contains(msg, pattern) {
+			return
+		}
+	}
+	for _, pattern := range b.ignorePatterns {
+		if pattern != "" && contains(msg, pattern) {
+			return
+		}
+	}
+
+	level := LogLevelInfo
+	if rest, ok := strings.CutPrefix(msg, "ERROR: "); ok {
+		level, msg = LogLevelError, rest
+	}
+	b.logMu.Lock()
+	logger := b.logger
+	b.logMu.Unlock()
+	if logger == nil {
+		logger = defaultLogger
+	}
+	logger(level, msg)
+}
@@ -0,0 +1,115 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Состояния входа на сайт
+const (
+	LoginStateLoggedIn  = "logged_in"
+	LoginStateLoggedOut = "logged_out"
+	LoginStateUnknown   = "unknown"
+)
+
+// LoginState - результат проверки, выполнен ли вход на текущем сайте
+type LoginState struct {
+	State    string `json:"state"`
+	Evidence string `json:"evidence,omitempty"` // признак, по которому определено состояние
+}
+
+// LoggedIn сообщает, что вход на сайт выполнен
+func (s *LoginState) LoggedIn() bool {
+	return s != nil && s.State == LoginStateLoggedIn
+}
+
+// IsLoggedIn проверяет наличие видимого признака авторизованного пользователя по
+// CSS селектору (аватар, меню аккаунта, ссылка выхода), например ".user-avatar, a[href*=logout]"
+func (b *Browser) IsLoggedIn(indicatorSelector string) (bool, error) {
+	select {
+	case <-b.ctx.Done():
+		return false, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var found bool
+	err := chromedp.Run(ctx, evaluate(loginIndicatorJS(indicatorSelector), &found))
+	if err != nil {
+		return false, fmt.Errorf("failed to check login indicator: %w", err)
+	}
+	return found, nil
+}
+
+// loginIndicatorJS - проверка видимого элемента по селектору признака входа
+func loginIndicatorJS(indicatorSelector string) string {
+	return `
+		(function() {
+			` + useHelpersJS + `
+			try {
+				return Array.from(document.querySelectorAll('` + escapeJSString(indicatorSelector) + `')).some(isVisible);
+			} catch (e) {
+				return false;
+			}
+		})()
+	`
+}
+
+// loginStateJS - эвристика состояния входа: ссылки выхода и меню аккаунта означают,
+// что вход выполнен; ссылки "Войти" и поле пароля без признаков аккаунта - что нет
+const loginStateJS = `
+		(function() {
+			` + useHelpersJS + `
+			const describe = el => (el.innerText || el.getAttribute('aria-label') || el.title || el.getAttribute('href') || '').trim().split('\n')[0].substring(0, 60);
+			const controls = Array.from(document.querySelectorAll('a, button, [role="button"], [role="menuitem"]')).filter(isVisible);
+			const label = el => ((el.innerText || '') + ' ' + (el.getAttribute('aria-label') || '') + ' ' + (el.title || '')).toLowerCase().replace(/\s+/g, ' ').trim();
+			const href = el => (el.getAttribute('href') || '').toLowerCase();
+
+			const logoutText = /(^|\s)(выйти|выход|log ?out|sign ?out)(\s|$)/;
+			const logoutHref = /(logout|log-out|signout|sign-out|exit)(\b|[/?.#_-]|$)/;
+			const logout = controls.find(el => logoutText.test(label(el)) || logoutHref.test(href(el)));
+			if (logout) return {state: 'logged_in', evidence: 'ссылка выхода «' + describe(logout) + '»'};
+
+			const accountText = /(личный кабинет|мой профиль|мой аккаунт|my account|account menu|профиль пользователя|user menu)/;
+			const account = controls.find(el => accountText.test(label(el)));
+			const avatar = Array.from(document.querySelectorAll('[class*="avatar" i], [data-testid*="avatar" i], img[alt*="avatar" i], [aria-label*="аккаунт" i], [aria-label*="account" i]')).find(isVisible);
+
+			const loginText = /^(войти|вход|log ?in|sign ?in|войти или зарегистрироваться|вход и регистрация|авторизоваться)$/;
+			const loginHref = /(login|signin|sign-in|auth)(\b|[/?.#_-]|$)/;
+			const login = controls.find(el => loginText.test(label(el)) || (loginHref.test(href(el)) && label(el).length < 40));
+			const password = Array.from(document.querySelectorAll('input[type="password"]')).find(isVisible);
+
+			if ((account || avatar) && !login && !password) {
+				return {state: 'logged_in', evidence: account ? 'меню аккаунта «' + describe(account) + '»' : 'аватар пользователя'};
+			}
+			if (password) return {state: 'logged_out', evidence: 'форма входа с полем пароля'};
+			if (login && !account && !avatar) return {state: 'logged_out', evidence: 'ссылка входа «' + describe(login) + '»'};
+			return {state: 'unknown', evidence: ''};
+		})()
+`
+
+// DetectLoginState определяет состояние входа эвристически: ссылки выхода и меню
+// аккаунта означают, что вход выполнен; ссылки "Войти" и поле пароля без признаков
+// аккаунта - что нет. Если признаков нет, состояние unknown.
+func (b *Browser) DetectLoginState() (*LoginState, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var state LoginState
+	err := chromedp.Run(ctx, evaluate(loginStateJS, &state))
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect login state: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,72 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// loginCheckSettle - сколько после загрузки ждать признаков входа: меню аккаунта на
+// тяжелых сайтах (почта, кабинеты) отрисовывается скриптами позже события load
+const loginCheckSettle = 5 * time.Second
+
+// CheckLoginInBackground открывает url в фоновой вкладке, определяет состояние входа
+// и закрывает вкладку. Активная вкладка агента и ее страница не меняются.
+// indicatorSelector - признак входа из SetLoginIndicator (пустой - только эвристика).
+func (b *Browser) CheckLoginInBackground(url, indicatorSelector string) (*LoginState, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	var tabID target.ID
+	err := chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		c := chromedp.FromContext(ctx)
+		var err error
+		tabID, err = target.CreateTarget("about:blank").WithBackground(true).Do(cdp.WithExecutor(ctx, c.Browser))
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть фоновую вкладку: %w", err)
+	}
+
+	tabCtx, tabCancel := chromedp.NewContext(b.ctx, chromedp.WithTargetID(tabID))
+	defer func() {
+		chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return target.CloseTarget(tabID).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser))
+		}))
+		tabCancel()
+	}()
+
+	ctx, cancel := context.WithTimeout(tabCtx, b.actionTimeout(30*time.Second))
+	defer cancel()
+	if err := chromedp.Run(ctx, chromedp.Navigate(url)); err != nil {
+		return nil, fmt.Errorf("не удалось открыть %s: %w", url, err)
+	}
+
+	state := &LoginState{State: LoginStateUnknown}
+	deadline := time.Now().Add(loginCheckSettle)
+	for {
+		if indicatorSelector != "" {
+			var found bool
+			if err := chromedp.Run(ctx, evaluate(loginIndicatorJS(indicatorSelector), &found)); err != nil {
+				return nil, fmt.Errorf("failed to check login indicator: %w", err)
+			}
+			if found {
+				return &LoginState{State: LoginStateLoggedIn, Evidence: "признак " + indicatorSelector}, nil
+			}
+		}
+		if err := chromedp.Run(ctx, evaluate(loginStateJS, state)); err != nil {
+			return nil, fmt.Errorf("failed to detect login state: %w", err)
+		}
+		if state.State != LoginStateUnknown || time.Now().After(deadline) {
+			return state, nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
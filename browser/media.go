@@ -0,0 +1,144 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// MediaElement описывает видео или аудио на странице
+type MediaElement struct {
+	Type        string `json:"type"`     // video, audio или embed (плеер во фрейме, управлять нельзя)
+	Selector    string `json:"selector"` // селектор для PlayMedia/PauseMedia
+	Src         string `json:"src,omitempty"`
+	Playing     bool   `json:"playing"`
+	Muted       bool   `json:"muted,omitempty"`
+	CurrentTime int    `json:"current_time,omitempty"` // секунды
+	Duration    int    `json:"duration,omitempty"`     // секунды, 0 - неизвестно или трансляция
+	Visible     bool   `json:"visible"`
+	Controls    bool   `json:"controls,omitempty"`
+}
+
+// mediaMarkerAttr - атрибут, которым помечаются найденные медиа-элементы
+const mediaMarkerAttr = "data-agent-media"
+
+// mediaExtractionJS - JS-выражение, возвращающее список медиа-элементов страницы.
+// Встраивается в скрипты GetPageContent и GetQuickPageInfo и использует их isVisible.
+const mediaExtractionJS = `(function() {
+				const attr = '` + mediaMarkerAttr + `';
+				const media = Array.from(document.querySelectorAll('video, audio')).slice(0, 10).map((el, i) => {
+					el.setAttribute(attr, String(i));
+					const source = el.querySelector('source');
+					return {
+						type: el.tagName.toLowerCase(),
+						selector: '[' + attr + '="' + i + '"]',
+						src: (el.currentSrc || el.src || (source ? source.src : '') || '').substring(0, 300),
+						playing: !el.paused && !el.ended && el.readyState > 2,
+						muted: el.muted,
+						current_time: isFinite(el.currentTime) ? Math.round(el.currentTime) : 0,
+						duration: isFinite(el.duration) ? Math.round(el.duration) : 0,
+						visible: isVisible(el),
+						controls: el.controls
+					};
+				});
+				// Встроенные плееры (YouTube, Vimeo, VK, Rutube) работают во фреймах других доменов - только сообщаем о них
+				const embedRe = /youtube\.com\/embed|youtube-nocookie|player\.vimeo|vk\.com\/video_ext|rutube\.ru\/play|dzen\.ru\/embed/i;
+				Array.from(document.querySelectorAll('iframe')).filter(f => embedRe.test(f.src || '')).slice(0, 5).forEach((f, i) => {
+					media.push({
+						type: 'embed',
+						selector: '',
+						src: (f.src || '').substring(0, 300),
+						playing: false,
+						visible: isVisible(f)
+					});
+				});
+				return media;
+			})()`
+
+// mediaActionResult - результат PlayMedia/PauseMedia
+type mediaActionResult struct {
+	Found   bool   `json:"found"`
+	Playing bool   `json:"playing"`
+	Error   string `json:"error"`
+}
+
+// PlayMedia запускает воспроизведение видео или аудио. Пустой селектор - первый
+// видимый медиа-элемент страницы. Команда выполняется как жест пользователя,
+// поэтому политика автовоспроизведения браузера ее не блокирует.
+func (b *Browser) PlayMedia(selector string) error {
+	result, err := b.controlMedia(selector, `
+		try {
+			await el.play();
+		} catch (e) {
+			// Без звука браузер разрешает воспроизведение почти всегда
+			el.muted = true;
+			try { await el.play(); } catch (e2) { return {found: true, playing: false, error: String(e2)}; }
+		}`)
+	if err != nil {
+		return err
+	}
+	if !result.Playing {
+		return fmt.Errorf("не удалось запустить воспроизведение: %s", result.Error)
+	}
+	return nil
+}
+
+// PauseMedia ставит видео или аудио на паузу. Пустой селектор - первый
+// воспроизводимый медиа-элемент страницы.
+func (b *Browser) PauseMedia(selector string) error {
+	result, err := b.controlMedia(selector, `el.pause();`)
+	if err != nil {
+		return err
+	}
+	if result.Playing {
+		return fmt.Errorf("медиа-элемент продолжает воспроизведение")
+	}
+	return nil
+}
+
+func (b *Browser) controlMedia(selector, command string) (*mediaActionResult, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	script := `
+		(async function() {
+			const selector = '` + escapeJSString(selector) + `';
+			let el = null;
+			if (selector) {
+				try { el = document.querySelector(selector); } catch (e) {}
+			} else {
+				const all = Array.from(document.querySelectorAll('video, audio'));
+				el = all.find(m => !m.paused) || all.find(m => m.getBoundingClientRect().width > 0) || all[0] || null;
+			}
+			if (!el) return {found: false, playing: false, error: 'медиа-элемент не найден'};
+			if (el.tagName !== 'VIDEO' && el.tagName !== 'AUDIO') {
+				const inner = el.querySelector('video, audio');
+				if (!inner) return {found: false, playing: false, error: 'элемент не является video/audio'};
+				el = inner;
+			}
+			` + command + `
+			await new Promise(r => setTimeout(r, 300));
+			return {found: true, playing: !el.paused && !el.ended, error: ''};
+		})()
+	`
+
+	var result mediaActionResult
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &result, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithAwaitPromise(true).WithUserGesture(true)
+	})); err != nil {
+		return nil, fmt.Errorf("failed to control media: %w", err)
+	}
+	if !result.Found {
+		return nil, fmt.Errorf("%s: %s", result.Error, selector)
+	}
+	return &result, nil
+}
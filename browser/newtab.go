@@ -0,0 +1,113 @@
+package browser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// newTabPoll - как часто WaitForNewTab проверяет, открылась ли вкладка
+const newTabPoll = 100 * time.Millisecond
+
+// openedTab - вкладка, открытая страницей и еще не выданная WaitForNewTab
+type openedTab struct {
+	id     target.ID
+	opener target.ID // вкладка-источник
+	seq    int       // номер по порядку открытия (метка MarkNewTabs)
+}
+
+// watchNewTabs подписывается на создание и закрытие вкладок браузера. Запоминаются
+// только вкладки, открытые страницей (ссылка с target="_blank", window.open): у них
+// есть вкладка-источник. Фоновые вкладки самого агента источника не имеют.
+func (b *Browser) watchNewTabs() {
+	chromedp.ListenBrowser(b.rootCtx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *target.EventTargetCreated:
+			if e.TargetInfo == nil || e.TargetInfo.Type != "page" || e.TargetInfo.OpenerID == "" {
+				return
+			}
+			b.eventsMu.Lock()
+			b.openedSeq++
+			b.openedTabs = append(b.openedTabs, openedTab{id: e.TargetInfo.TargetID, opener: e.TargetInfo.OpenerID, seq: b.openedSeq})
+			b.eventsMu.Unlock()
+		case *target.EventTargetDestroyed:
+			b.eventsMu.Lock()
+			for i, tab := range b.openedTabs {
+				if tab.id == e.TargetID {
+					b.openedTabs = append(b.openedTabs[:i], b.openedTabs[i+1:]...)
+					break
+				}
+			}
+			b.eventsMu.Unlock()
+		}
+	})
+}
+
+// MarkNewTabs возвращает метку очереди открытых страницей вкладок перед кликом:
+// WaitForNewTab с этой меткой не выдает вкладки, открытые до нее другими вкладками
+func (b *Browser) MarkNewTabs() int {
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return b.openedSeq
+}
+
+// takeOpenedTab выбирает из очереди первую вкладку, открытую после метки since или
+// текущей вкладкой current, к которой агент еще не подключался. Вкладки до выбранной,
+// которые не подошли, из очереди удаляются: их открыли прошлые действия или другие
+// вкладки, и за результат следующего клика они не выдаются. Если ничего не подошло,
+// очередь возвращается без изменений: вкладка может появиться к следующей проверке.
+func takeOpenedTab(tabs []openedTab, since int, current target.ID, attached func(target.ID) bool) (target.ID, []openedTab) {
+	for i, tab := range tabs {
+		if (tab.seq > since || tab.opener == current) && !attached(tab.id) {
+			return tab.id, tabs[i+1:]
+		}
+	}
+	return "", tabs
+}
+
+// WaitForNewTab ждет, пока страница откроет новую вкладку (после клика по ссылке
+// "открыть в новой вкладке"), и возвращает ее ID. Подходит вкладка, открытая после
+// метки since (MarkNewTabs перед кликом) или текущей вкладкой: клик обычно успевает
+// открыть ее до вызова. Каждая вкладка выдается один раз, а вкладки, на которые агент
+// уже переключался, и вкладки, открытые до метки другими вкладками, пропускаются.
+func (b *Browser) WaitForNewTab(since int, timeout time.Duration) (string, error) {
+	current := chromedp.FromContext(b.ctx).Target.TargetID
+	deadline := time.Now().Add(b.actionTimeout(timeout))
+	for {
+		select {
+		case <-b.ctx.Done():
+			return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+		default:
+		}
+
+		b.eventsMu.Lock()
+		var found target.ID
+		found, b.openedTabs = takeOpenedTab(b.openedTabs, since, current, func(id target.ID) bool {
+			_, attached := b.tabs[id]
+			return attached
+		})
+		b.eventsMu.Unlock()
+		if found != "" {
+			return string(found), nil
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("новая вкладка не открылась за %v - ссылка открылась в текущей вкладке или не сработала", b.actionTimeout(timeout))
+		}
+		time.Sleep(b.pollInterval(newTabPoll))
+	}
+}
+
+// SwitchToNewTab ждет новую вкладку (WaitForNewTab) и переключается на нее
+func (b *Browser) SwitchToNewTab(since int, timeout time.Duration) (string, error) {
+	tabID, err := b.WaitForNewTab(since, timeout)
+	if err != nil {
+		return "", err
+	}
+	if err := b.SwitchToTab(tabID); err != nil {
+		return "", fmt.Errorf("failed to switch to new tab: %w", err)
+	}
+	return tabID, nil
+}
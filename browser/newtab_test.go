@@ -0,0 +1,74 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/target"
+)
+
+func TestTakeOpenedTab(t *testing.T) {
+	const current = target.ID("CURRENT")
+	queue := []openedTab{
+		{id: "OLD", opener: "OTHER", seq: 1},
+		{id: "MINE", opener: current, seq: 2},
+		{id: "SEEN", opener: "OTHER", seq: 4},
+		{id: "NEW", opener: "OTHER", seq: 5},
+	}
+	attached := func(id target.ID) bool { return id == "SEEN" }
+
+	tests := []struct {
+		name  string
+		since int
+		want  target.ID
+		rest  int
+	}{
+		{"opened by the current tab before the click", 3, "MINE", 2},
+		{"stale tabs of other pages are dropped", 2, "MINE", 2},
+		{"all tabs after the mark", 0, "OLD", 3},
+		{"nothing after the mark", 5, "MINE", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest := takeOpenedTab(append([]openedTab(nil), queue...), tt.since, current, attached)
+			if got != tt.want || len(rest) != tt.rest {
+				t.Errorf("takeOpenedTab(since %d) = %s with %d left, want %s with %d", tt.since, got, len(rest), tt.want, tt.rest)
+			}
+		})
+	}
+
+	// Вкладка другой страницы до метки и уже открытая агентом не выдаются
+	got, rest := takeOpenedTab([]openedTab{{id: "OLD", opener: "OTHER", seq: 1}, {id: "SEEN", opener: "OTHER", seq: 4}}, 3, current, attached)
+	if got != "" || len(rest) != 2 {
+		t.Errorf("takeOpenedTab() = %s with %d left, want nothing and the queue unchanged", got, len(rest))
+	}
+
+	got, rest = takeOpenedTab(append([]openedTab(nil), queue[2:]...), 3, current, attached)
+	if got != "NEW" || len(rest) != 0 {
+		t.Errorf("takeOpenedTab() = %s with %d left, want NEW", got, len(rest))
+	}
+}
+
+func TestTakeOpenedTabEmptyPollKeepsQueue(t *testing.T) {
+	const current = target.ID("CURRENT")
+	attached := func(target.ID) bool { return false }
+
+	// Клик еще не открыл вкладку: проверка ничего не находит и не трогает очередь
+	queue := []openedTab{{id: "OTHER-PAGE", opener: "OTHER", seq: 1}}
+	got, queue := takeOpenedTab(queue, 1, current, attached)
+	if got != "" || len(queue) != 1 || queue[0].id != "OTHER-PAGE" {
+		t.Fatalf("empty poll = %s, queue %+v, want nothing and the queue unchanged", got, queue)
+	}
+
+	// К следующей проверке вкладка открылась
+	queue = append(queue, openedTab{id: "PREVIEW", opener: current, seq: 2})
+	got, queue = takeOpenedTab(queue, 1, current, attached)
+	if got != "PREVIEW" || len(queue) != 0 {
+		t.Errorf("next poll = %s, queue %+v, want PREVIEW and an empty queue", got, queue)
+	}
+
+	// Вкладка другой страницы до метки не выдается, но и не теряется, пока ничего не выбрано
+	queue = []openedTab{{id: "EARLIER", opener: "OTHER", seq: 3}}
+	if got, rest := takeOpenedTab(queue, 3, current, attached); got != "" || len(rest) != 1 {
+		t.Errorf("takeOpenedTab(since 3) = %s with %d left, want nothing and the queue unchanged", got, len(rest))
+	}
+}
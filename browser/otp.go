@@ -0,0 +1,204 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// OTPField описывает найденное на странице поле одноразового кода (OTP/2FA)
+type OTPField struct {
+	Found     bool     `json:"found"`
+	Boxes     int      `json:"boxes"`     // количество полей: 1 - одно поле для всего кода, >1 - по символу в поле
+	Length    int      `json:"length"`    // ожидаемая длина кода, 0 - неизвестна
+	Selectors []string `json:"selectors"` // селекторы полей в порядке ввода
+	Filled    bool     `json:"filled"`    // все поля уже заполнены
+	Hint      string   `json:"hint"`      // текст рядом с полем ("Введите код из СМС")
+}
+
+// otpMarkerAttr - атрибут, которым помечаются найденные поля OTP
+const otpMarkerAttr = "data-agent-otp"
+
+// DetectOTP ищет на странице поля для ввода одноразового кода. Поле считается полем
+// кода, только если это сказано явно: autocomplete=one-time-code или формулировка про
+// одноразовый код / код подтверждения в атрибутах поля или тексте рядом. Ячейки по
+// одной цифре или числовое поле на 4-6 цифр сами по себе ничего не значат: так
+// выглядят и промокод, и почтовый индекс. Поля промокода, индекса и карты не
+// считаются полем кода никогда.
+func (b *Browser) DetectOTP() (*OTPField, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	script := `
+		(function() {
+			const attr = '` + otpMarkerAttr + `';
+			const hintRe = /(код\s+(из|в)\s+(смс|sms|сообщени)|код\s+подтверждения|одноразов\S*\s+(код|парол)|one[-\s]?time\s+(code|password)|verification\s+code|2fa|двухфакторн|authenticator)/i;
+			const ownRe = /(otp|one.?time|sms.?code|verification.?code|verify.?code|2fa|totp|mfa|код.?подтвержд|код.?из.?(смс|sms)|смс.?код)/i;
+			const excludeRe = /(промо|promo|coupon|купон|подар|gift|voucher|ваучер|postal|post.?code|zip|индекс|почтов|cvv|cvc|card)/i;
+			` + useHelpersJS + `
+			const ownText = el => [el.placeholder, el.getAttribute('aria-label'), el.name, el.id,
+				el.labels && el.labels.length ? el.labels[0].innerText : ''].filter(Boolean).join(' ');
+			const excluded = el => {
+				const autocomplete = (el.getAttribute('autocomplete') || '').toLowerCase();
+				return autocomplete.startsWith('cc-') || autocomplete === 'postal-code' || excludeRe.test(ownText(el));
+			};
+			const textInputs = Array.from(document.querySelectorAll('input')).filter(el => {
+				const type = (el.type || 'text').toLowerCase();
+				return ['text', 'tel', 'number', 'password', ''].includes(type) && !el.disabled && !el.readOnly && isVisible(el) && !excluded(el);
+			});
+			document.querySelectorAll('[' + attr + ']').forEach(el => el.removeAttribute(attr));
+
+			const hintFor = el => {
+				let node = el;
+				for (let i = 0; i < 5 && node; i++, node = node.parentElement) {
+					const text = (node.innerText || '').trim();
+					const match = text.match(hintRe);
+					if (match) {
+						const line = text.split('\n').find(l => hintRe.test(l)) || match[0];
+						return line.trim().substring(0, 120);
+					}
+				}
+				const own = ownText(el);
+				return ownRe.test(own) || hintRe.test(own) ? own.substring(0, 120) : '';
+			};
+
+			const result = els => {
+				els.forEach((el, i) => el.setAttribute(attr, String(i)));
+				const maxLength = els.length === 1 ? parseInt(els[0].getAttribute('maxlength') || '0', 10) : els.length;
+				return {
+					found: true,
+					boxes: els.length,
+					length: maxLength > 0 && maxLength <= 12 ? maxLength : 0,
+					selectors: els.map((el, i) => '[' + attr + '="' + i + '"]'),
+					filled: els.every(el => el.value && el.value.length > 0),
+					hint: hintFor(els[0])
+				};
+			};
+
+			// 1. autocomplete=one-time-code - браузер и сайт сами говорят, что это код
+			const oneTime = textInputs.filter(el => (el.getAttribute('autocomplete') || '').toLowerCase() === 'one-time-code');
+			if (oneTime.length > 0) {
+				return result(oneTime);
+			}
+
+			// 2. Группа полей по одному символу с общим контейнером и текстом про код
+			const single = textInputs.filter(el => el.maxLength === 1 || el.getAttribute('maxlength') === '1');
+			const groups = new Map();
+			single.forEach(el => {
+				let container = el.parentElement;
+				// Поля часто обернуты в отдельные div - поднимаемся, пока контейнер не включит соседей
+				for (let i = 0; i < 3 && container && container.querySelectorAll('input').length < 2; i++) {
+					container = container.parentElement;
+				}
+				if (!container) return;
+				if (!groups.has(container)) groups.set(container, []);
+				groups.get(container).push(el);
+			});
+			for (const els of groups.values()) {
+				if (els.length >= 4 && els.length <= 8 && hintFor(els[0])) {
+					return result(els);
+				}
+			}
+
+			// 3. Одно поле с формулировкой про код в атрибутах или рядом
+			for (const el of textInputs) {
+				if (ownRe.test(ownText(el)) || hintFor(el)) {
+					if (el.type === 'password' && !/otp|code|код/i.test(ownText(el))) continue;
+					return result([el]);
+				}
+			}
+
+			return {found: false, boxes: 0, length: 0, selectors: [], filled: false, hint: ''};
+		})()
+	`
+
+	var field OTPField
+	if err := chromedp.Run(ctx, evaluate(script, &field)); err != nil {
+		return nil, fmt.Errorf("failed to detect OTP field: %w", err)
+	}
+	return &field, nil
+}
+
+// FillOTP вводит код в найденное поле OTP. Если код разбит на несколько полей,
+// каждый символ вводится в свое поле - с настоящими событиями клавиатуры,
+// чтобы виджеты с автопереходом фокуса работали как при ручном вводе.
+func (b *Browser) FillOTP(field *OTPField, code string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	if field == nil || !field.Found || len(field.Selectors) == 0 {
+		return fmt.Errorf("поле для кода подтверждения не найдено")
+	}
+
+	// Пользователь мог ввести код с пробелами или дефисами ("123 456", "123-456")
+	code = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, code)
+	if code == "" {
+		return fmt.Errorf("пустой код подтверждения")
+	}
+
+	symbols := []rune(code)
+	if field.Boxes > 1 && len(symbols) != field.Boxes {
+		return fmt.Errorf("код содержит %d символов, а на странице %d полей", len(symbols), field.Boxes)
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 20*time.Second)
+	defer cancel()
+
+	if field.Boxes <= 1 {
+		return chromedp.Run(ctx,
+			chromedp.Clear(field.Selectors[0], chromedp.ByQuery),
+			chromedp.SendKeys(field.Selectors[0], code, chromedp.ByQuery),
+			chromedp.Sleep(300*time.Millisecond),
+		)
+	}
+
+	for i, selector := range field.Selectors {
+		if err := chromedp.Run(ctx,
+			chromedp.SetValue(selector, "", chromedp.ByQuery),
+			chromedp.SendKeys(selector, string(symbols[i]), chromedp.ByQuery),
+			chromedp.Sleep(100*time.Millisecond),
+		); err != nil {
+			return fmt.Errorf("failed to fill OTP box %d: %w", i+1, err)
+		}
+	}
+
+	// Проверяем, что виджет не перераспределил символы по полям
+	var values []string
+	check := `Array.from(document.querySelectorAll('[` + otpMarkerAttr + `]')).sort((a, b) => a.getAttribute('` + otpMarkerAttr + `') - b.getAttribute('` + otpMarkerAttr + `')).map(el => el.value)`
+	if err := chromedp.Run(ctx, chromedp.Evaluate(check, &values)); err == nil && len(values) == len(symbols) {
+		if wrong := otpMismatches(values, symbols); wrong > 0 {
+			return fmt.Errorf("код введен некорректно: %d из %d полей содержат не тот символ", wrong, len(symbols))
+		}
+	}
+	return nil
+}
+
+// otpMismatches считает поля, значение которых не совпадает с символом кода. Ошибка
+// ввода попадает в историю и журнал задачи, поэтому сообщается только количество:
+// ни введенные, ни ожидаемые символы одноразового кода в нее не попадают.
+func otpMismatches(values []string, symbols []rune) int {
+	wrong := 0
+	for i, value := range values {
+		if i >= len(symbols) || value != string(symbols[i]) {
+			wrong++
+		}
+	}
+	return wrong
+}
@@ -0,0 +1,57 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestDetectOTP(t *testing.T) {
+	b := newTestBrowser(t)
+	tests := []struct {
+		name  string
+		html  string
+		found bool
+		boxes int
+	}{
+		{"one-time-code", `<input autocomplete="one-time-code" maxlength="6">`, true, 1},
+		{"code from sms", `<p>Введите код из СМС</p><input inputmode="numeric" maxlength="6">`, true, 1},
+		{"boxes with wording", `<p>Код подтверждения</p><div>` +
+			`<input maxlength="1"><input maxlength="1"><input maxlength="1"><input maxlength="1"></div>`, true, 4},
+		{"bare numeric field", `<input type="tel" maxlength="6">`, false, 0},
+		{"bare boxes", `<div><input maxlength="1"><input maxlength="1"><input maxlength="1"><input maxlength="1"></div>`, false, 0},
+		{"promo code", `<p>Код подтверждения придет в СМС после оплаты</p><input name="promocode" placeholder="Промокод" maxlength="6">`, false, 0},
+		{"postal index", `<label for="zip">Индекс</label><input id="zip" inputmode="numeric" maxlength="6">`, false, 0},
+		{"card security code", `<input autocomplete="cc-csc" placeholder="Код подтверждения" maxlength="3">`, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := b.Navigate(servePage(t, `<form>`+tt.html+`</form>`)); err != nil {
+				t.Fatal(err)
+			}
+			field, err := b.DetectOTP()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if field.Found != tt.found || field.Boxes != tt.boxes {
+				t.Errorf("DetectOTP() = found %v, %d boxes, want %v, %d", field.Found, field.Boxes, tt.found, tt.boxes)
+			}
+		})
+	}
+}
+
+func TestOTPMismatches(t *testing.T) {
+	tests := []struct {
+		values []string
+		code   string
+		want   int
+	}{
+		{[]string{"1", "2", "3", "4"}, "1234", 0},
+		{[]string{"2", "3", "4", ""}, "1234", 4},
+		{[]string{"1", "2", "", "4"}, "1234", 1},
+		{[]string{"1", "22", "3", "4"}, "1234", 1},
+	}
+	for _, tt := range tests {
+		if got := otpMismatches(tt.values, []rune(tt.code)); got != tt.want {
+			t.Errorf("otpMismatches(%q, %q) = %d, want %d", tt.values, tt.code, got, tt.want)
+		}
+	}
+}
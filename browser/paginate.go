@@ -0,0 +1,340 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Параметры обхода страниц результатов
+const (
+	paginateChangeWait  = 10 * time.Second // сколько ждать смены списка после клика "следующая"
+	paginateSettleDelay = 500 * time.Millisecond
+	paginateStableRuns  = 2 // столько подгрузок ленты подряд без новых строк = лента закончилась
+)
+
+// Стратегии обхода страниц
+const (
+	PaginateNext   = "next"   // клик по кнопке следующей страницы
+	PaginateScroll = "scroll" // подгрузка бесконечной ленты прокруткой
+)
+
+// PaginateField - поле строки результата: селектор внутри элемента списка и
+// атрибут (пусто - видимый текст). Пустой селектор - сам элемент списка.
+type PaginateField struct {
+	Name     string `json:"name"`
+	Selector string `json:"sel"`
+	Attr     string `json:"attr"`
+}
+
+// PaginateSpec описывает сбор списка по нескольким страницам
+type PaginateSpec struct {
+	ItemSelector string          // элемент списка: карточка вакансии, строка таблицы
+	Fields       []PaginateField // пусто - текст элемента и первая ссылка в нем
+	NextHint     string          // текст или селектор кнопки следующей страницы; пусто - типичные "Следующая", "Next", rel=next
+	MaxPages     int             // для ленты - предел подгрузок
+	MaxItems     int
+}
+
+// ScrapedRow - строка результата с указанием страницы, на которой она найдена
+type ScrapedRow struct {
+	Page   int               `json:"page"`
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+}
+
+// PaginateResult - итог обхода страниц
+type PaginateResult struct {
+	Rows       []ScrapedRow `json:"rows"`
+	Pages      int          `json:"pages"`    // обработано страниц (для ленты - подгрузок)
+	Strategy   string       `json:"strategy"` // next или scroll
+	StopReason string       `json:"stop_reason"`
+	Duplicates int          `json:"duplicates"` // повторы строк, пропущенные при сборе
+}
+
+// pageScrape - строки одной страницы
+type pageScrape struct {
+	URL   string              `json:"url"`
+	Rows  []map[string]string `json:"rows"`
+	Error string              `json:"error"`
+}
+
+// nextControl - результат поиска кнопки следующей страницы
+type nextControl struct {
+	Found    bool   `json:"found"`
+	Disabled bool   `json:"disabled"`
+	Clicked  bool   `json:"clicked"`
+	Label    string `json:"label"`
+}
+
+// PaginateScrape собирает строки списка со всех страниц результатов без запросов
+// к модели: сбор -> клик по "следующей странице" -> ожидание смены списка, пока
+// кнопка не исчезнет или не станет неактивной, либо до MaxPages/MaxItems. Если
+// кнопки следующей страницы нет уже на первой странице, список считается
+// бесконечной лентой и подгружается прокруткой. Повторяющиеся строки пропускаются.
+func (b *Browser) PaginateScrape(spec PaginateSpec) (*PaginateResult, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if strings.TrimSpace(spec.ItemSelector) == "" {
+		return nil, fmt.Errorf("не указан селектор элементов списка")
+	}
+	if spec.MaxPages <= 0 {
+		spec.MaxPages = 1
+	}
+
+	result := &PaginateResult{Strategy: PaginateNext}
+	seen := make(map[string]bool)
+	// addRows добавляет новые строки и сообщает, сколько их было
+	addRows := func(scrape *pageScrape, page int) int {
+		added := 0
+		for _, fields := range scrape.Rows {
+			key, _ := json.Marshal(fields)
+			if seen[string(key)] {
+				result.Duplicates++
+				continue
+			}
+			if spec.MaxItems > 0 && len(result.Rows) >= spec.MaxItems {
+				break
+			}
+			seen[string(key)] = true
+			result.Rows = append(result.Rows, ScrapedRow{Page: page, URL: scrape.URL, Fields: fields})
+			added++
+		}
+		return added
+	}
+
+	for page := 1; ; page++ {
+		scrape, err := b.scrapeItems(spec)
+		if err != nil {
+			return result, err
+		}
+		if page == 1 && len(scrape.Rows) == 0 {
+			return result, fmt.Errorf("по селектору %q не найдено ни одного элемента списка", spec.ItemSelector)
+		}
+		addRows(scrape, page)
+		result.Pages = page
+
+		if spec.MaxItems > 0 && len(result.Rows) >= spec.MaxItems {
+			result.StopReason = fmt.Sprintf("достигнут предел строк (%d)", spec.MaxItems)
+			return result, nil
+		}
+		if page >= spec.MaxPages {
+			result.StopReason = fmt.Sprintf("достигнут предел страниц (%d)", spec.MaxPages)
+			return result, nil
+		}
+
+		before, _ := b.listState(spec.ItemSelector)
+		next, err := b.clickNextPage(spec.NextHint)
+		if err != nil {
+			return result, err
+		}
+		switch {
+		case !next.Found && page == 1:
+			return b.scrollScrape(spec, result, addRows)
+		case !next.Found:
+			result.StopReason = "кнопки следующей страницы больше нет"
+			return result, nil
+		case next.Disabled:
+			result.StopReason = "кнопка следующей страницы неактивна - это последняя страница"
+			return result, nil
+		}
+
+		if !b.waitListChange(spec.ItemSelector, before) {
+			result.StopReason = fmt.Sprintf("список не сменился после клика по %q", next.Label)
+			return result, nil
+		}
+	}
+}
+
+// scrollScrape подгружает бесконечную ленту прокруткой и собирает новые строки после каждой подгрузки
+func (b *Browser) scrollScrape(spec PaginateSpec, result *PaginateResult, addRows func(*pageScrape, int) int) (*PaginateResult, error) {
+	result.Strategy = PaginateScroll
+	idle := 0
+	for round := 2; round <= spec.MaxPages; round++ {
+		if _, err := b.ScrollUntilStable(spec.ItemSelector, 1, scrollLoadWait+5*time.Second); err != nil {
+			return result, err
+		}
+		scrape, err := b.scrapeItems(spec)
+		if err != nil {
+			return result, err
+		}
+		result.Pages = round
+		if addRows(scrape, round) > 0 {
+			idle = 0
+		} else if idle++; idle >= paginateStableRuns {
+			result.StopReason = "лента больше не подгружает новые элементы"
+			return result, nil
+		}
+		if spec.MaxItems > 0 && len(result.Rows) >= spec.MaxItems {
+			result.StopReason = fmt.Sprintf("достигнут предел строк (%d)", spec.MaxItems)
+			return result, nil
+		}
+	}
+	result.StopReason = fmt.Sprintf("достигнут предел подгрузок ленты (%d)", spec.MaxPages)
+	return result, nil
+}
+
+// scrapeItems читает поля всех элементов списка на текущей странице
+func (b *Browser) scrapeItems(spec PaginateSpec) (*pageScrape, error) {
+	itemSelector, _ := json.Marshal(spec.ItemSelector)
+	fields, _ := json.Marshal(spec.Fields)
+	if spec.Fields == nil {
+		fields = []byte("[]")
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(15*time.Second))
+	defer cancel()
+
+	script := `
+		(function() {
+			const itemSelector = ` + string(itemSelector) + `;
+			const fields = ` + string(fields) + `;
+			const clean = s => (s || '').replace(/\s+/g, ' ').trim();
+			let items;
+			try { items = Array.from(document.querySelectorAll(itemSelector)); } catch (e) {
+				return {url: location.href, rows: [], error: 'некорректный селектор ' + itemSelector};
+			}
+			const rows = items.map(item => {
+				const row = {};
+				if (!fields.length) {
+					row.text = clean(item.innerText || item.textContent).substring(0, 300);
+					const link = item.matches('a[href]') ? item : item.querySelector('a[href]');
+					if (link) row.href = link.href;
+					return row;
+				}
+				fields.forEach(f => {
+					let el = item;
+					if (f.sel) {
+						try { el = item.querySelector(f.sel); } catch (e) { el = null; }
+					}
+					let value = '';
+					if (el && f.attr) {
+						value = (f.attr === 'href' || f.attr === 'src') && el[f.attr] ? el[f.attr] : (el.getAttribute(f.attr) || '');
+					} else if (el) {
+						value = clean(el.innerText || el.textContent);
+					}
+					row[f.name] = value.substring(0, 500);
+				});
+				return row;
+			}).filter(row => Object.values(row).some(v => v));
+			return {url: location.href, rows: rows};
+		})()
+	`
+
+	var scrape pageScrape
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &scrape)); err != nil {
+		return nil, fmt.Errorf("failed to scrape list items: %w", err)
+	}
+	if scrape.Error != "" {
+		return nil, fmt.Errorf("%s", scrape.Error)
+	}
+	return &scrape, nil
+}
+
+// listState - признак содержимого списка: адрес, число элементов, первый и последний элемент
+type listState struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+func (b *Browser) listState(itemSelector string) (listState, error) {
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(5*time.Second))
+	defer cancel()
+
+	script := `
+		(function() {
+			let items = [];
+			try { items = document.querySelectorAll('` + escapeJSString(itemSelector) + `'); } catch (e) {}
+			const text = el => el ? (el.innerText || el.textContent || '').substring(0, 200) : '';
+			return {key: location.href + '|' + items.length + '|' + text(items[0]) + '|' + text(items[items.length - 1]), count: items.length};
+		})()
+	`
+	var state listState
+	err := chromedp.Run(ctx, chromedp.Evaluate(script, &state))
+	return state, err
+}
+
+// waitListChange ждет, пока после перехода на следующую страницу сменится список:
+// новая страница по ссылке, подмена элементов без перезагрузки или "показать еще"
+func (b *Browser) waitListChange(itemSelector string, before listState) bool {
+	deadline := time.Now().Add(b.actionTimeout(paginateChangeWait))
+	for time.Now().Before(deadline) {
+		time.Sleep(b.pollInterval(scrollPollInterval))
+		select {
+		case <-b.ctx.Done():
+			return false
+		default:
+		}
+		// Во время перезагрузки страницы скрипт может не выполниться - просто ждем дальше
+		after, err := b.listState(itemSelector)
+		if err != nil || after.Key == before.Key || after.Count == 0 {
+			continue
+		}
+		time.Sleep(paginateSettleDelay)
+		return true
+	}
+	return false
+}
+
+// clickNextPage находит кнопку следующей страницы по подсказке (текст или селектор) или
+// по типичным подписям и rel=next и кликает по ней, если она активна. Принимается только
+// элемент блока пагинации (nav, role=navigation, класс или aria-label с "pag") или ссылка
+// rel=next: "Далее" или ">" в форме или мастере оформления - не следующая страница списка.
+func (b *Browser) clickNextPage(hint string) (*nextControl, error) {
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	script := `
+		(function() {
+			` + useHelpersJS + `
+			const hint = '` + escapeJSString(strings.TrimSpace(hint)) + `';
+			const clean = s => (s || '').replace(/\s+/g, ' ').trim().toLowerCase();
+			const label = el => clean(getElementText(el) || el.getAttribute('aria-label') || el.getAttribute('title'));
+			const disabled = el => el.disabled || el.getAttribute('aria-disabled') === 'true' ||
+				/(^|[\s_-])disabled/.test(typeof el.className === 'string' ? el.className : '') || !!el.closest('.disabled, [aria-disabled="true"]');
+
+			const pager = el => el.matches('[rel="next"]') || !!el.closest('nav, [role="navigation"], [class*="paginat" i], [class*="pager" i], [aria-label*="pag" i], [aria-label*="страниц" i]');
+
+			let el = null;
+			if (hint) {
+				try { el = Array.from(document.querySelectorAll(hint)).find(c => isVisible(c) && pager(c)) || null; } catch (e) {}
+			}
+			if (!el) {
+				const candidates = Array.from(document.querySelectorAll('a, button, [role="button"], [role="link"]')).filter(c => isVisible(c) && pager(c));
+				const texts = hint ? [clean(hint)] : ['следующая', 'следующая страница', 'вперед', 'вперёд', 'next', 'next page', 'показать еще', 'показать ещё', 'load more', '›', '»', '→'];
+				for (const text of texts) {
+					el = candidates.find(c => label(c) === text) ||
+						(text.length >= 3 ? candidates.find(c => label(c).startsWith(text) && label(c).length <= text.length + 20) : null);
+					if (el) break;
+				}
+				if (!el && !hint) {
+					el = Array.from(document.querySelectorAll('a[rel="next"], [aria-label*="next" i], [aria-label*="следующ" i]')).find(c => isVisible(c) && pager(c)) || null;
+				}
+			}
+			if (!el) return {found: false};
+
+			const result = {found: true, label: (getElementText(el) || el.getAttribute('aria-label') || '').trim().substring(0, 50)};
+			if (disabled(el)) {
+				result.disabled = true;
+				return result;
+			}
+			el.scrollIntoView({block: 'center'});
+			el.click();
+			result.clicked = true;
+			return result;
+		})()
+	`
+
+	var next nextControl
+	if err := chromedp.Run(ctx, evaluate(script, &next)); err != nil {
+		return nil, fmt.Errorf("failed to find next page control: %w", err)
+	}
+	return &next, nil
+}
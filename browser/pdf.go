@@ -0,0 +1,105 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// paperSizes - размеры бумаги в дюймах (ширина, высота), как их принимает CDP
+var paperSizes = map[string][2]float64{
+	"a3":     {11.69, 16.54},
+	"a4":     {8.27, 11.69},
+	"a5":     {5.83, 8.27},
+	"letter": {8.5, 11},
+	"legal":  {8.5, 14},
+}
+
+// PDFOptions - параметры сохранения страницы в PDF
+type PDFOptions struct {
+	Paper           string // a3, a4, a5, letter, legal
+	Landscape       bool
+	PrintBackground bool // фоновые цвета и изображения (без них квитанции теряют заливку таблиц)
+}
+
+// DefaultPDFOptions - A4, книжная ориентация, с фоном
+func DefaultPDFOptions() PDFOptions {
+	return PDFOptions{Paper: "a4", PrintBackground: true}
+}
+
+// ParsePaperSize проверяет название размера бумаги: "A4", "Letter"
+func ParsePaperSize(value string) (string, error) {
+	paper := strings.ToLower(strings.TrimSpace(value))
+	if _, ok := paperSizes[paper]; !ok {
+		return "", fmt.Errorf("неизвестный размер бумаги %q (доступны: A3, A4, A5, Letter, Legal)", value)
+	}
+	return paper, nil
+}
+
+// WithPDFOptions задает параметры PrintToPDF
+func WithPDFOptions(opts PDFOptions) Option {
+	return func(b *Browser) {
+		b.pdfOptions = opts
+	}
+}
+
+// PrintToPDF сохраняет текущую страницу в PDF-файл. Перед печатью включается
+// эмуляция носителя print: страницы со стилями @media print (квитанции, статьи)
+// прячут меню и рекламу именно по этому признаку. После печати эмуляция снимается.
+func (b *Browser) PrintToPDF(filename string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	opts := b.pdfOptions
+	size, ok := paperSizes[opts.Paper]
+	if !ok {
+		size = paperSizes["a4"]
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(60*time.Second))
+	defer cancel()
+
+	var data []byte
+	err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return emulation.SetEmulatedMedia().WithMedia("print").Do(ctx)
+		}),
+		// Стили print и обработчики matchMedia применяются не мгновенно
+		chromedp.Sleep(300*time.Millisecond),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			data, _, err = page.PrintToPDF().
+				WithPaperWidth(size[0]).
+				WithPaperHeight(size[1]).
+				WithLandscape(opts.Landscape).
+				WithPrintBackground(opts.PrintBackground).
+				WithPreferCSSPageSize(true).
+				Do(ctx)
+			return err
+		}),
+	)
+	// Эмуляцию снимаем и после ошибки, иначе страница останется в режиме печати
+	_ = chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return emulation.SetEmulatedMedia().WithMedia("").Do(ctx)
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to print page to PDF: %w", err)
+	}
+
+	if dir := filepath.Dir(filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create PDF directory: %w", err)
+		}
+	}
+	return os.WriteFile(filename, data, 0644)
+}
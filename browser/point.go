@@ -0,0 +1,117 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ElementInfo - элемент в точке страницы: тег, текст, адрес ссылки и селектор,
+// по которому его можно найти снова
+type ElementInfo struct {
+	Tag      string `json:"tag"`
+	Text     string `json:"text"`
+	Href     string `json:"href,omitempty"`
+	Selector string `json:"selector"`
+}
+
+func (e *ElementInfo) String() string {
+	desc := e.Tag
+	if e.Text != "" {
+		desc += fmt.Sprintf(" «%s»", e.Text)
+	}
+	if e.Href != "" {
+		desc += " -> " + e.Href
+	}
+	return desc
+}
+
+// elementAtPointJS описывает верхний элемент в точке окна. Точка чаще попадает во
+// вложенный span или иконку - описывается ближайший элемент управления (ссылка,
+// кнопка, поле), а если его нет - сам элемент.
+const elementAtPointJS = `(function(x, y) {
+	` + useHelpersJS + `
+	const top = document.elementFromPoint(x, y);
+	if (!top) return null;
+	const el = top.closest('a[href], button, input, select, textarea, label, [role="button"], [role="link"], [onclick]') || top;
+
+	function selectorOf(el) {
+		const parts = [];
+		for (let node = el; node && node.nodeType === 1 && node !== document.documentElement; node = node.parentElement) {
+			if (node.id && document.querySelectorAll('#' + CSS.escape(node.id)).length === 1) {
+				parts.unshift('#' + CSS.escape(node.id));
+				break;
+			}
+			let part = node.tagName.toLowerCase();
+			const parent = node.parentElement;
+			if (parent) {
+				const same = Array.from(parent.children).filter(c => c.tagName === node.tagName);
+				if (same.length > 1) part += ':nth-of-type(' + (same.indexOf(node) + 1) + ')';
+			}
+			parts.unshift(part);
+		}
+		return parts.join(' > ');
+	}
+
+	const link = el.closest('a[href]');
+	return {
+		tag: el.tagName.toLowerCase(),
+		text: getElementText(el).replace(/\s+/g, ' ').substring(0, 200),
+		href: link ? link.href : '',
+		selector: selectorOf(el),
+	};
+})(%d, %d)`
+
+// ElementAtPoint возвращает элемент, который виден в точке (x, y) окна браузера
+// (CSS-пиксели от левого верхнего угла видимой области, как на снимке экрана без
+// прокрутки). nil - в точке ничего нет (за пределами окна). Для точки внутри iframe
+// возвращается сам iframe.
+func (b *Browser) ElementAtPoint(x, y int) (*ElementInfo, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	var info *ElementInfo
+	if err := chromedp.Run(ctx, evaluate(fmt.Sprintf(elementAtPointJS, x, y), &info)); err != nil {
+		return nil, fmt.Errorf("failed to get element at point: %w", err)
+	}
+	return info, nil
+}
+
+// ClickAt кликает в точку (x, y) окна настоящим событием мыши. Сначала проверяется,
+// что в точке есть элемент, а если задан expect - что его текст или адрес ссылки
+// содержит expect: модель, выбравшая точку по снимку экрана, могла промахнуться.
+// Возвращает элемент, по которому пришелся клик.
+func (b *Browser) ClickAt(x, y int, expect string) (*ElementInfo, error) {
+	info, err := b.ElementAtPoint(x, y)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, fmt.Errorf("в точке (%d, %d) нет элемента - точка за пределами окна", x, y)
+	}
+	if want := strings.ToLower(strings.TrimSpace(expect)); want != "" &&
+		!strings.Contains(strings.ToLower(info.Text), want) && !strings.Contains(strings.ToLower(info.Href), want) {
+		return info, fmt.Errorf("в точке (%d, %d) находится %s, а не «%s» - клик не выполнен", x, y, info, expect)
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	mark := b.routeMark()
+	if err := chromedp.Run(ctx,
+		chromedp.MouseClickXY(float64(x), float64(y)),
+		b.waitAfterClick(mark, 1*time.Second),
+	); err != nil {
+		return info, fmt.Errorf("failed to click at point: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,114 @@
+package browser
+
+// PagePrice - цена на странице: отображаемый текст ("1 299 ₽", "$1,299.00") и
+// число с кодом валюты, которые можно сравнивать между собой
+type PagePrice struct {
+	Text     string  `json:"text"`
+	Value    float64 `json:"value"`              // 1299 для "1 299 ₽", 1299.5 для "1.299,50 €"
+	Currency string  `json:"currency,omitempty"` // код ISO 4217: RUB, USD, EUR; пусто, если валюта не указана
+	Old      bool    `json:"old,omitempty"`      // зачеркнутая цена до скидки
+	Context  string  `json:"context,omitempty"`  // название товара или начало текста карточки, к которой относится цена
+}
+
+// priceExtractionJS - JS-выражение, возвращающее цены страницы, не больше 40. Цена -
+// число рядом с символом или кодом валюты ("1 299 ₽", "$1,299.00", "1.299,00 €",
+// "от 990 руб.") или значение itemprop="price". Разделители разрядов (пробел,
+// точка, запятая, апостроф) убираются, десятичный разделитель определяется по
+// положению: последний из двух разных знаков или единственный знак, после которого
+// не ровно три цифры. Встраивается в скрипты GetPageContent и GetQuickPageInfo и
+// использует их isVisible.
+const priceExtractionJS = `(function() {
+				if (!document.body) return [];
+				const clean = s => (s || '').replace(/\s+/g, ' ').trim();
+				const currencies = [
+					[/^(₽|руб\.?|р\.|rub)$/i, 'RUB'], [/^(\$|usd|us\$)$/i, 'USD'], [/^(€|eur)$/i, 'EUR'],
+					[/^(£|gbp)$/i, 'GBP'], [/^(¥|jpy)$/i, 'JPY'], [/^(cny|юаней|юань)$/i, 'CNY'], [/^(₸|тг\.?|kzt)$/i, 'KZT'],
+					[/^(₴|грн\.?|uah)$/i, 'UAH'], [/^(byn)$/i, 'BYN'], [/^(₺)$/i, 'TRY'], [/^(₹|inr)$/i, 'INR']
+				];
+				const currencyRe = '₽|руб\\.?|р\\.|rub|us\\$|\\$|usd|€|eur|£|gbp|¥|jpy|cny|юаней|юань|₸|тг\\.?|kzt|₴|грн\\.?|uah|byn|₺|₹|inr';
+				const numberRe = '\\d{1,3}(?:[ .,’\']\\d{3})+(?:[.,]\\d{1,2})?|\\d+(?:[.,]\\d{1,2})?';
+				const priceRe = new RegExp('(?<![\\p{L}\\d])(?:(' + currencyRe + ') ?(' + numberRe + ')|(' + numberRe + ') ?(' + currencyRe + '))(?![\\p{L}\\d])', 'iu');
+				const currencyOf = s => {
+					const found = currencies.find(([re]) => re.test(clean(s)));
+					return found ? found[1] : '';
+				};
+				// "1 299" -> 1299, "1.299,50" -> 1299.5, "1,299.00" -> 1299, "12,99" -> 12.99, "1.299" -> 1299
+				const parseNumber = raw => {
+					let s = raw.replace(/[\s’']/g, '');
+					const dot = s.lastIndexOf('.');
+					const comma = s.lastIndexOf(',');
+					if (dot >= 0 && comma >= 0) {
+						const decimal = dot > comma ? '.' : ',';
+						s = s.split(decimal === '.' ? ',' : '.').join('').replace(',', '.');
+					} else if (dot >= 0 || comma >= 0) {
+						const parts = s.split(dot >= 0 ? '.' : ',');
+						s = parts.length > 2 || parts[1].length === 3 ? parts.join('') : parts.join('.');
+					}
+					const value = parseFloat(s);
+					return isNaN(value) ? null : value;
+				};
+				const isOld = el => !!el.closest('del, s, strike, [class*="old-price"], [class*="price-old"], [class*="old_price"], [class*="price_old"], [class*="oldPrice"]') ||
+					window.getComputedStyle(el).textDecorationLine.includes('line-through');
+				// Название товара: заголовок или ссылка карточки без цены, иначе текст карточки без самой цены
+				const cardSelector = 'li, tr, article, [role="listitem"], [role="row"], [class*="card"], [class*="product"], [class*="item"]';
+				const contextOf = (el, priceText) => {
+					let card = el.closest(cardSelector);
+					for (let depth = 0; card && depth < 3; depth++) {
+						const title = Array.from(card.querySelectorAll('h1, h2, h3, h4, h5, [itemprop="name"], a[href]'))
+							.map(t => clean(t.innerText)).find(t => t && !priceRe.test(t));
+						const text = title || clean(card.innerText).replace(priceText, '').replace(/[\s\-–—:,]+$/, '').trim();
+						if (text) return text.length > 80 ? text.substring(0, 80) + '...' : text;
+						card = card.parentElement ? card.parentElement.closest(cardSelector) : null;
+					}
+					return '';
+				};
+
+				const prices = [];
+				const add = (el, text, value, currency) => {
+					if (prices.length >= 40 || value === null) return;
+					prices.push({text: text, value: value, currency: currency, old: isOld(el), context: contextOf(el, text)});
+				};
+
+				// Разметка schema.org: число и валюта в атрибутах
+				const marked = [];
+				document.querySelectorAll('[itemprop="price"]').forEach(el => {
+					if (!isVisible(el)) return;
+					const match = clean(el.getAttribute('content') || el.textContent).match(new RegExp(numberRe));
+					if (!match) return;
+					const scope = el.closest('[itemscope]') || el.parentElement;
+					const meta = scope ? scope.querySelector('[itemprop="priceCurrency"]') : null;
+					const code = meta ? clean(meta.getAttribute('content') || meta.textContent).toUpperCase() : '';
+					const text = clean(el.textContent) || match[0];
+					const shown = text.match(priceRe);
+					marked.push(el);
+					add(el, text.substring(0, 40), parseNumber(match[0]), code || (shown ? currencyOf(shown[1] || shown[4]) : ''));
+				});
+
+				// Для каждого текста с цифрой или знаком валюты - ближайший предок с коротким текстом,
+				// в котором есть цена: "<span>1 299</span><span>₽</span>" дает цену у общего родителя
+				const candidates = new Set();
+				const walker = document.createTreeWalker(document.body, NodeFilter.SHOW_TEXT);
+				for (let node = walker.nextNode(); node && candidates.size < 300; node = walker.nextNode()) {
+					if (!/[\d₽$€£¥₸₴₺₹]/.test(node.nodeValue)) continue;
+					let el = node.parentElement;
+					for (let depth = 0; el && depth < 3; depth++, el = el.parentElement) {
+						if (el.matches('script, style, noscript, option, textarea')) break;
+						const text = clean(el.textContent);
+						if (text.length > 60) break;
+						if (priceRe.test(text)) {
+							candidates.add(el);
+							break;
+						}
+					}
+				}
+				const list = Array.from(candidates);
+				for (const el of list) {
+					if (prices.length >= 40) break;
+					// Обертка вокруг другой цены ("Чайник <b>2 390 ₽</b>") - цена уже учтена во вложенном элементе
+					if (list.some(other => other !== el && el.contains(other))) continue;
+					if (marked.some(m => m.contains(el) || el.contains(m)) || !isVisible(el)) continue;
+					const match = clean(el.textContent).match(priceRe);
+					add(el, match[0], parseNumber(match[2] || match[3]), currencyOf(match[1] || match[4]));
+				}
+				return prices;
+			})()`
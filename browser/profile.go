@@ -0,0 +1,169 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile - имя профиля, который хранится прямо в корне user-data
+const DefaultProfile = "default"
+
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ValidateProfileName проверяет имя профиля: только латиница, цифры, "_" и "-"
+func ValidateProfileName(name string) error {
+	if name == "" || profileNameRegex.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("некорректное имя профиля %q: допустимы латинские буквы, цифры, '_' и '-'", name)
+}
+
+// Profile возвращает имя активного профиля
+func (b *Browser) Profile() string {
+	if b.profile == "" {
+		return DefaultProfile
+	}
+	return b.profile
+}
+
+// ProfileDir возвращает каталог данных активного профиля
+func (b *Browser) ProfileDir() string {
+	if b.profile == "" || b.profile == DefaultProfile {
+		return b.userDataDir
+	}
+	return filepath.Join(b.userDataDir, "profiles", b.profile)
+}
+
+// ProfileDirectory возвращает профиль Chrome внутри user-data-dir (--profile-directory)
+func (b *Browser) ProfileDirectory() string {
+	return b.profileDirectory
+}
+
+// SwitchProfile перезапускает браузер с другим профилем. Cookies и сессии профилей
+// не пересекаются. Открытые вкладки закрываются - вызывайте только между задачами.
+func (b *Browser) SwitchProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		name = ""
+	}
+	if name == b.profile {
+		return nil
+	}
+
+	previous := b.profile
+	b.profile = name
+	if err := os.MkdirAll(b.ProfileDir(), 0755); err != nil {
+		b.profile = previous
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	if err := b.Restart(); err != nil {
+		return fmt.Errorf("failed to switch to profile %s: %w", b.Profile(), err)
+	}
+	return nil
+}
+
+// ChromeProfile - профиль Chrome внутри user-data-dir
+type ChromeProfile struct {
+	Directory string // каталог профиля: "Default", "Profile 2"
+	Name      string // имя профиля в интерфейсе Chrome: "Work"
+	Active    bool
+}
+
+// ChromeProfiles возвращает профили Chrome в каталоге данных активного профиля агента.
+// Имена берутся из файла "Local State"; каталоги, которых в нем нет, находятся по файлу
+// Preferences внутри.
+func (b *Browser) ChromeProfiles() ([]ChromeProfile, error) {
+	dir := b.ProfileDir()
+	names := make(map[string]string)
+	if data, err := os.ReadFile(filepath.Join(dir, "Local State")); err == nil {
+		var state struct {
+			Profile struct {
+				InfoCache map[string]struct {
+					Name string `json:"name"`
+				} `json:"info_cache"`
+			} `json:"profile"`
+		}
+		if err := json.Unmarshal(data, &state); err == nil {
+			for directory, info := range state.Profile.InfoCache {
+				names[directory] = info.Name
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user data directory: %w", err)
+	}
+	for _, entry := range entries {
+		if _, ok := names[entry.Name()]; ok || !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), "Preferences")); err == nil {
+			names[entry.Name()] = ""
+		}
+	}
+	if _, ok := names[b.profileDirectory]; !ok {
+		names[b.profileDirectory] = ""
+	}
+
+	profiles := make([]ChromeProfile, 0, len(names))
+	for directory, name := range names {
+		profiles = append(profiles, ChromeProfile{Directory: directory, Name: name, Active: directory == b.profileDirectory})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Directory < profiles[j].Directory })
+	return profiles, nil
+}
+
+// FindChromeProfile ищет профиль Chrome по имени из интерфейса ("Work") или по каталогу
+// ("Profile 2") без учета регистра
+func (b *Browser) FindChromeProfile(query string) (ChromeProfile, error) {
+	profiles, err := b.ChromeProfiles()
+	if err != nil {
+		return ChromeProfile{}, err
+	}
+	query = strings.TrimSpace(query)
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Directory, query) {
+			return profile, nil
+		}
+	}
+	var found []ChromeProfile
+	for _, profile := range profiles {
+		if profile.Name != "" && strings.EqualFold(profile.Name, query) {
+			found = append(found, profile)
+		}
+	}
+	switch len(found) {
+	case 1:
+		return found[0], nil
+	case 0:
+		return ChromeProfile{}, fmt.Errorf("профиль Chrome %q не найден в %s", query, b.ProfileDir())
+	}
+	var dirs []string
+	for _, profile := range found {
+		dirs = append(dirs, profile.Directory)
+	}
+	return ChromeProfile{}, fmt.Errorf("профилей с именем %q несколько (%s) - укажите каталог", query, strings.Join(dirs, ", "))
+}
+
+// SwitchProfileDirectory перезапускает браузер с другим профилем Chrome внутри того же
+// user-data-dir (--profile-directory). Открытые вкладки закрываются - вызывайте только
+// между задачами.
+func (b *Browser) SwitchProfileDirectory(dir string) error {
+	if dir == "" || dir == b.profileDirectory {
+		return nil
+	}
+	b.profileDirectory = dir
+	if err := b.Restart(); err != nil {
+		return fmt.Errorf("failed to switch to Chrome profile %s: %w", dir, err)
+	}
+	return nil
+}
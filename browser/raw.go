@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// rawActionTimeout - таймаут RunActions, если ctx вызывающего не задает более ранний срок
+const rawActionTimeout = 30 * time.Second
+
+// RunActions выполняет произвольные действия chromedp во вкладке агента - для
+// возможностей, которые пакет еще не оборачивает (отдельные домены CDP и т.п.).
+// Действия выполняются с тем же таймаутом, что и остальные действия браузера
+// (с учетом масштаба таймаутов), и прерываются при отмене ctx. Вызовы RunActions
+// из разных горутин выполняются по одному, а Restart ждет завершения текущего
+// вызова, поэтому действия не попадут в наполовину перезапущенный браузер.
+//
+// Агент о побочных эффектах этих действий не знает: переход на другую страницу,
+// закрытие вкладки или изменение DOM не попадут в его историю, проверки маршрута
+// и снимки страницы. Подписки на события регистрируйте на Context(): контекст
+// действий отменяется после их выполнения вместе с подписками (см. пример
+// журнала ответов сети в ExampleBrowser_RunActions).
+func (b *Browser) RunActions(ctx context.Context, actions ...chromedp.Action) error {
+	b.rawMu.Lock()
+	defer b.rawMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	runCtx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(rawActionTimeout))
+	defer cancel()
+	// Контекст chromedp должен происходить от вкладки, поэтому отмену ctx переносим вручную
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	if err := chromedp.Run(runCtx, actions...); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	b.touchOperation()
+	return nil
+}
+
+// Context возвращает контекст chromedp вкладки агента - для chromedp.ListenTarget и
+// других подписок. Контекст только для чтения: не отменяйте его и не выполняйте в
+// нем действия напрямую (для этого есть RunActions). После Restart контекст
+// становится недействительным, и его нужно получить заново.
+func (b *Browser) Context() context.Context {
+	return b.ctx
+}
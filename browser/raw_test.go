@@ -0,0 +1,88 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+func TestRunActionsCanceled(t *testing.T) {
+	browserCtx, cancelBrowser := context.WithCancel(context.Background())
+	cancelBrowser()
+	b := &Browser{ctx: browserCtx}
+	if err := b.RunActions(context.Background(), chromedp.Sleep(time.Millisecond)); err == nil || !strings.Contains(err.Error(), "браузер недоступен") {
+		t.Errorf("RunActions() on a closed browser = %v, want browser unavailable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = &Browser{ctx: context.Background()}
+	if err := b.RunActions(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunActions() with a canceled ctx = %v, want context.Canceled", err)
+	}
+}
+
+func TestRunActionsNetworkListener(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<p>Сеть</p>`)
+
+	var mu sync.Mutex
+	var statuses []int64
+	chromedp.ListenTarget(b.Context(), func(ev interface{}) {
+		if resp, ok := ev.(*network.EventResponseReceived); ok && strings.HasPrefix(resp.Response.URL, url) {
+			mu.Lock()
+			statuses = append(statuses, resp.Response.Status)
+			mu.Unlock()
+		}
+	})
+	if err := b.RunActions(context.Background(), network.Enable()); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) == 0 || statuses[0] != 200 {
+		t.Errorf("listener saw statuses %v, want 200 for the page", statuses)
+	}
+}
+
+func TestRunActionsSerialized(t *testing.T) {
+	b := newTestBrowser(t)
+
+	var mu sync.Mutex
+	running, overlap := 0, false
+	step := chromedp.ActionFunc(func(ctx context.Context) error {
+		mu.Lock()
+		running++
+		overlap = overlap || running > 1
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.RunActions(context.Background(), step); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if overlap {
+		t.Error("concurrent RunActions calls overlapped")
+	}
+}
@@ -0,0 +1,93 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// readyQuietWindow - страница считается устоявшейся, если DOM не менялся столько времени
+const readyQuietWindow = 150 * time.Millisecond
+
+// readyPollInterval - как часто страница проверяет, затих ли DOM
+const readyPollInterval = 50 * time.Millisecond
+
+// WaitForReady ждет, пока страница загрузится (document.readyState) и DOM перестанет
+// меняться на readyQuietWindow, но не дольше maxWait. Возвращает время ожидания:
+// страница, которая не меняется после действия, готова почти сразу, а постоянно
+// обновляющаяся (таймеры, анимации) ждет не больше maxWait. Истечение maxWait - не
+// ошибка: действие уже выполнено, и агент продолжает с тем, что успело загрузиться.
+func (b *Browser) WaitForReady(maxWait time.Duration) (time.Duration, error) {
+	select {
+	case <-b.ctx.Done():
+		return 0, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if maxWait <= 0 {
+		return 0, nil
+	}
+
+	start := time.Now()
+	// Запас на переход: во время навигации выполнение скрипта прерывается
+	ctx, cancel := context.WithTimeout(b.ctx, maxWait+2*time.Second)
+	defer cancel()
+
+	wait := func(remaining time.Duration) error {
+		script := fmt.Sprintf(`new Promise(resolve => {
+			const quiet = %d, deadline = Date.now() + %d;
+			let last = Date.now();
+			const observer = new MutationObserver(() => { last = Date.now(); });
+			observer.observe(document, {childList: true, subtree: true, attributes: true, characterData: true});
+			(function check() {
+				const now = Date.now();
+				if (now >= deadline || (document.readyState === 'complete' && now - last >= quiet)) {
+					observer.disconnect();
+					resolve(now < deadline);
+					return;
+				}
+				setTimeout(check, %d);
+			})();
+		})`, readyQuietWindow.Milliseconds(), remaining.Milliseconds(), b.pollInterval(readyPollInterval).Milliseconds())
+		var settled bool
+		return chromedp.Run(ctx, chromedp.Evaluate(script, &settled, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}))
+	}
+
+	err := wait(maxWait)
+	if remaining := maxWait - time.Since(start); err != nil && ctx.Err() == nil && remaining > 0 {
+		// Документ сменился во время ожидания (переход после клика) - ждем новый
+		err = wait(remaining)
+	}
+	b.touchOperation()
+	return time.Since(start), err
+}
+
+// pageSignatureJS - адрес и число форм, полей, кнопок и ссылок страницы
+const pageSignatureJS = `[location.href,
+	document.forms.length,
+	document.querySelectorAll('input:not([type="hidden"]), textarea, select').length,
+	document.querySelectorAll('button, [role="button"], input[type="submit"], input[type="button"]').length,
+	document.links.length].join('|')`
+
+// PageSignature возвращает короткий отпечаток состояния страницы: адрес и число форм,
+// полей, кнопок и ссылок. Одинаковый отпечаток до и после действия означает, что
+// действие страницу заметно не изменило. Текст в отпечаток не входит: часы, таймеры
+// и счетчики меняют его на неизменной странице.
+func (b *Browser) PageSignature() (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var signature string
+	err := chromedp.Run(ctx, chromedp.Evaluate(pageSignatureJS, &signature))
+	return signature, err
+}
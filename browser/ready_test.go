@@ -0,0 +1,70 @@
+package browser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestPageSignature(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<p id="clock">12:00:00</p>
+		<form><input name="q"><button type="submit">Найти</button></form>
+		<ul id="list"><li><a href="/1">Товар 1</a></li></ul>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+	signature := func() string {
+		s, err := b.PageSignature()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	run := func(js string) {
+		if err := chromedp.Run(b.ctx, chromedp.Evaluate(js, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := signature()
+	run(`document.getElementById('clock').textContent = '12:00:01 - осталось 59 секунд'`)
+	if got := signature(); got != before {
+		t.Errorf("text change altered the signature: %q -> %q", before, got)
+	}
+
+	run(`document.getElementById('list').insertAdjacentHTML('beforeend', '<li><a href="/2">Товар 2</a></li>')`)
+	if got := signature(); got == before {
+		t.Errorf("new list link kept the signature %q", got)
+	}
+
+	before = signature()
+	run(`document.forms[0].insertAdjacentHTML('beforeend', '<input name="code">')`)
+	if got := signature(); got == before {
+		t.Errorf("new form field kept the signature %q", got)
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	b := newTestBrowser(t)
+	if err := b.Navigate(servePage(t, `<p>Статичная страница</p>`)); err != nil {
+		t.Fatal(err)
+	}
+	if waited, err := b.WaitForReady(0); err != nil || waited != 0 {
+		t.Errorf("WaitForReady(0) = %v, %v, want no wait", waited, err)
+	}
+	waited, err := b.WaitForReady(3 * time.Second)
+	if err != nil || waited > time.Second {
+		t.Errorf("static page: WaitForReady() = %v, %v, want ready well before the cap", waited, err)
+	}
+
+	if err := b.Navigate(servePage(t, `<p id="clock"></p>
+		<script>setInterval(() => { document.getElementById('clock').textContent = Date.now(); }, 20);</script>`)); err != nil {
+		t.Fatal(err)
+	}
+	waited, err = b.WaitForReady(500 * time.Millisecond)
+	if err != nil || waited < 500*time.Millisecond || waited > 2*time.Second {
+		t.Errorf("busy page: WaitForReady() = %v, %v, want the 500ms cap", waited, err)
+	}
+}
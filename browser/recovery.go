@@ -0,0 +1,176 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// SetTimeoutMultiplier увеличивает таймауты действий (клик, заполнение, ожидание).
+// Используется для повтора после таймаута; 1 - обычные таймауты.
+func (b *Browser) SetTimeoutMultiplier(m float64) {
+	b.timeoutScale = m
+}
+
+// actionTimeout возвращает таймаут действия с учетом множителя
+func (b *Browser) actionTimeout(d time.Duration) time.Duration {
+	if b.timeoutScale <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * b.timeoutScale)
+}
+
+// ScrollIntoView прокручивает страницу к элементу по селектору или видимому тексту
+func (b *Browser) ScrollIntoView(selector, text string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	script := fmt.Sprintf(`
+		(function() {
+			`+useHelpersJS+`
+			const selector = '%s';
+			const text = '%s'.toLowerCase().trim();
+			let el = null;
+			if (selector) {
+				try { el = document.querySelector(selector); } catch (e) {}
+			}
+			if (!el && text) {
+				const candidates = document.querySelectorAll('button, a, input, textarea, select, [role="button"], [role="link"], label, [onclick]');
+				for (const c of candidates) {
+					if (!isVisible(c)) continue;
+					const own = (c.innerText || c.value || c.placeholder || c.getAttribute('aria-label') || c.title || '').toLowerCase().trim();
+					if (own === text) { el = c; break; }
+					if (!el && own.includes(text)) el = c;
+				}
+			}
+			if (!el) return false;
+			el.scrollIntoView({block: 'center', inline: 'center'});
+			return true;
+		})()
+	`, escapeJSString(selector), escapeJSString(text))
+
+	var found bool
+	if err := chromedp.Run(ctx,
+		evaluate(script, &found),
+		chromedp.Sleep(500*time.Millisecond),
+	); err != nil {
+		return fmt.Errorf("failed to scroll to element: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("element not found for scroll")
+	}
+	return nil
+}
+
+// SimilarElements возвращает тексты видимых кнопок, ссылок и полей, похожих на text
+func (b *Browser) SimilarElements(text string, limit int) ([]string, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	var texts []string
+	if err := chromedp.Run(ctx, evaluate(`
+		(function() {
+			`+useHelpersJS+`
+			const seen = new Set();
+			const result = [];
+			document.querySelectorAll('button, a, input, textarea, select, [role="button"], [role="link"], [role="tab"], [role="menuitem"]').forEach(el => {
+				if (!isVisible(el)) return;
+				const t = (el.innerText || el.value || el.placeholder || el.getAttribute('aria-label') || el.title || '').trim().replace(/\s+/g, ' ');
+				if (t && t.length <= 80 && !seen.has(t)) {
+					seen.add(t);
+					result.push(t);
+				}
+			});
+			return result;
+		})()
+	`, &texts)); err != nil {
+		return nil, fmt.Errorf("failed to collect elements: %w", err)
+	}
+
+	type scored struct {
+		text  string
+		score float64
+	}
+	var candidates []scored
+	for _, t := range texts {
+		if score := textSimilarity(text, t); score >= 0.3 {
+			candidates = append(candidates, scored{t, score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	var result []string
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		result = append(result, candidates[i].text)
+	}
+	return result, nil
+}
+
+// textSimilarity оценивает похожесть строк от 0 до 1: вхождение, общие слова и расстояние Левенштейна
+func textSimilarity(a, b string) float64 {
+	a = strings.ToLower(strings.TrimSpace(a))
+	b = strings.ToLower(strings.TrimSpace(b))
+	if a == "" || b == "" {
+		return 0
+	}
+	if strings.Contains(b, a) || strings.Contains(a, b) {
+		return 0.9
+	}
+
+	wordsA := strings.Fields(a)
+	common := 0
+	for _, w := range wordsA {
+		if len([]rune(w)) > 2 && strings.Contains(b, w) {
+			common++
+		}
+	}
+	wordScore := float64(common) / float64(len(wordsA))
+
+	ra, rb := []rune(a), []rune(b)
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	editScore := 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+
+	if wordScore > editScore {
+		return wordScore
+	}
+	return editScore
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,165 @@
+package browser
+
+import (
+	"context"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	routePollInterval = 100 * time.Millisecond
+	routeSettleDelay  = 300 * time.Millisecond // время на отрисовку нового маршрута SPA
+)
+
+// RouteChange - смена маршрута SPA без загрузки нового документа
+type RouteChange struct {
+	Seq  int    `json:"seq"`
+	Kind string `json:"kind"` // history (pushState, replaceState, назад-вперед) или hash
+	URL  string `json:"url"`
+}
+
+// maxRouteChanges - сколько последних смен маршрута хранится для вкладки
+const maxRouteChanges = 50
+
+// tabRoutes - смены адреса основного документа вкладки без загрузки нового документа
+type tabRoutes struct {
+	document string // адрес, с которым загрузился документ
+	current  string
+	changes  []RouteChange
+}
+
+// noteRoute запоминает смены маршрута SPA по событиям CDP: Page.navigatedWithinDocument
+// приходит на pushState, replaceState, переход назад-вперед внутри документа и смену
+// якоря. Страница не видит перехвата - history не подменяется. Загрузка нового
+// документа начинает историю маршрутов заново. Вызывается под eventsMu.
+func (b *Browser) noteRoute(tabID target.ID, ev interface{}) {
+	switch e := ev.(type) {
+	case *page.EventFrameNavigated:
+		if e.Frame != nil && string(e.Frame.ID) == string(tabID) {
+			url := e.Frame.URL + e.Frame.URLFragment
+			b.routes[tabID] = &tabRoutes{document: url, current: url}
+		}
+	case *page.EventNavigatedWithinDocument:
+		if string(e.FrameID) != string(tabID) {
+			return
+		}
+		routes := b.routes[tabID]
+		if routes == nil {
+			routes = &tabRoutes{}
+			b.routes[tabID] = routes
+		}
+		if e.URL == routes.current {
+			return
+		}
+		kind := "history"
+		if routes.current != "" && stripFragment(e.URL) == stripFragment(routes.current) {
+			kind = "hash"
+		}
+		b.routeSeq++
+		routes.current = e.URL
+		routes.changes = append(routes.changes, RouteChange{Seq: b.routeSeq, Kind: kind, URL: e.URL})
+		if len(routes.changes) > maxRouteChanges {
+			routes.changes = routes.changes[1:]
+		}
+	}
+}
+
+// stripFragment возвращает адрес без якоря
+func stripFragment(url string) string {
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
+
+// spaRoute возвращает маршрут SPA, если он не совпадает с адресом загруженного
+// документа: путь hash-маршрутизатора (#/inbox) или адрес после pushState
+func spaRoute(routes *tabRoutes) string {
+	if routes == nil || routes.current == "" {
+		return ""
+	}
+	current, err := neturl.Parse(routes.current)
+	if err != nil {
+		return ""
+	}
+	if hashRoute := strings.TrimPrefix(current.Fragment, "!"); strings.HasPrefix(hashRoute, "/") {
+		return hashRoute
+	}
+	if len(routes.changes) == 0 || routes.current == routes.document {
+		return ""
+	}
+	route := current.RequestURI()
+	if current.Fragment != "" {
+		route += "#" + current.EscapedFragment()
+	}
+	return route
+}
+
+// routeMark возвращает номер последней смены маршрута во всех вкладках
+func (b *Browser) routeMark() int {
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return b.routeSeq
+}
+
+// routeChangeSince возвращает последнюю смену маршрута текущей вкладки после отметки mark
+func (b *Browser) routeChangeSince(mark int) *RouteChange {
+	tabID := chromedp.FromContext(b.ctx).Target.TargetID
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	routes := b.routes[tabID]
+	if routes == nil || len(routes.changes) == 0 {
+		return nil
+	}
+	if last := routes.changes[len(routes.changes)-1]; last.Seq > mark {
+		return &last
+	}
+	return nil
+}
+
+// currentRoute возвращает маршрут SPA текущей вкладки (пусто, если он совпадает с адресом документа)
+func (b *Browser) currentRoute() string {
+	tabID := chromedp.FromContext(b.ctx).Target.TargetID
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return spaRoute(b.routes[tabID])
+}
+
+// waitAfterClick ждет реакции страницы на клик. Смена маршрута SPA - признак
+// успешного клика: ожидание завершается раньше, а смена запоминается для LastRouteChange.
+// Без смены маршрута ожидание длится wait, как обычная пауза после клика.
+func (b *Browser) waitAfterClick(mark int, wait time.Duration) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		b.setRouteChange(nil)
+		deadline := time.Now().Add(wait)
+		for time.Now().Before(deadline) {
+			if change := b.routeChangeSince(mark); change != nil {
+				b.setRouteChange(change)
+				return chromedp.Sleep(routeSettleDelay).Do(ctx)
+			}
+			if err := chromedp.Sleep(routePollInterval).Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Browser) setRouteChange(change *RouteChange) {
+	b.routeMu.Lock()
+	b.routeChange = change
+	b.routeMu.Unlock()
+}
+
+// LastRouteChange возвращает смену маршрута SPA, вызванную последним кликом
+// (nil, если клик не менял маршрут)
+func (b *Browser) LastRouteChange() *RouteChange {
+	b.routeMu.Lock()
+	defer b.routeMu.Unlock()
+	return b.routeChange
+}
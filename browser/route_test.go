@@ -0,0 +1,99 @@
+package browser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+func TestNoteRoute(t *testing.T) {
+	const tab = target.ID("TAB")
+	mainFrame := cdp.FrameID(tab)
+	load := func(url string) *page.EventFrameNavigated {
+		return &page.EventFrameNavigated{Frame: &cdp.Frame{ID: mainFrame, URL: url}}
+	}
+	within := func(url string) *page.EventNavigatedWithinDocument {
+		return &page.EventNavigatedWithinDocument{FrameID: mainFrame, URL: url}
+	}
+
+	tests := []struct {
+		name      string
+		events    []interface{}
+		wantKinds []string
+		wantRoute string
+	}{
+		{"document load", []interface{}{load("https://mail.example/")}, nil, ""},
+		{"pushState", []interface{}{load("https://mail.example/"), within("https://mail.example/inbox?page=2")}, []string{"history"}, "/inbox?page=2"},
+		{"hash router", []interface{}{load("https://mail.example/"), within("https://mail.example/#/inbox")}, []string{"hash"}, "/inbox"},
+		{"hash router loaded directly", []interface{}{load("https://mail.example/#!/drafts")}, nil, "/drafts"},
+		{"same URL ignored", []interface{}{load("https://mail.example/a"), within("https://mail.example/a")}, nil, ""},
+		{"back to the document URL", []interface{}{load("https://mail.example/a"), within("https://mail.example/b"), within("https://mail.example/a")}, []string{"history", "history"}, ""},
+		{"new document resets", []interface{}{load("https://mail.example/"), within("https://mail.example/inbox"), load("https://mail.example/login")}, nil, ""},
+		{"iframe ignored", []interface{}{load("https://mail.example/"), &page.EventNavigatedWithinDocument{FrameID: "CHILD", URL: "https://ads.example/x"}}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Browser{routes: make(map[target.ID]*tabRoutes)}
+			for _, ev := range tt.events {
+				b.noteRoute(tab, ev)
+			}
+			routes := b.routes[tab]
+			var kinds []string
+			for _, change := range routes.changes {
+				kinds = append(kinds, change.Kind)
+			}
+			if len(kinds) != len(tt.wantKinds) {
+				t.Fatalf("changes = %+v, want kinds %v", routes.changes, tt.wantKinds)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.wantKinds[i] {
+					t.Errorf("changes = %+v, want kinds %v", routes.changes, tt.wantKinds)
+				}
+			}
+			if got := spaRoute(routes); got != tt.wantRoute {
+				t.Errorf("spaRoute() = %q, want %q", got, tt.wantRoute)
+			}
+		})
+	}
+}
+
+func TestAgentScriptsIsolatedFromPage(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<button onclick="history.pushState({}, '', '/inbox')">Входящие</button>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetPageContent(); err != nil {
+		t.Fatal(err)
+	}
+
+	var leaked []string
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(`Object.getOwnPropertyNames(window).filter(n => n.startsWith('__agent'))`, &leaked)); err != nil {
+		t.Fatal(err)
+	}
+	if len(leaked) > 0 {
+		t.Errorf("page sees agent globals %v", leaked)
+	}
+	var native bool
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(`history.pushState.toString().includes('[native code]')`, &native)); err != nil || !native {
+		t.Errorf("history.pushState is wrapped on the page: %v", err)
+	}
+
+	if err := b.ClickByText("Входящие"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for b.LastRouteChange() == nil && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if change := b.LastRouteChange(); change == nil || change.Kind != "history" {
+		t.Errorf("LastRouteChange() = %+v, want a history change", change)
+	}
+	if route := b.currentRoute(); route != "/inbox" {
+		t.Errorf("currentRoute() = %q, want /inbox", route)
+	}
+}
@@ -0,0 +1,49 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScreenshotCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b := &Browser{ctx: ctx}
+
+	path := filepath.Join(t.TempDir(), "error.png")
+	err := b.Screenshot(path)
+	if err == nil || !strings.Contains(err.Error(), "браузер недоступен") {
+		t.Errorf("Screenshot() on a canceled context = %v, want a browser-unavailable error", err)
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Errorf("screenshot file written despite the error: %v", statErr)
+	}
+}
+
+func TestScreenshot(t *testing.T) {
+	b := newTestBrowser(t)
+	if err := b.Navigate(servePage(t, `<h1>Снимок</h1>`)); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "page.png")
+	if err := b.Screenshot(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Errorf("screenshot file = %d bytes, %v, want a PNG", len(data), err)
+	}
+
+	if err := b.crashRenderer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Screenshot(path); !errors.Is(err, ErrRendererCrashed) {
+		t.Errorf("Screenshot() of a crashed tab = %v, want ErrRendererCrashed", err)
+	}
+}
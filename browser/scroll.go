@@ -0,0 +1,359 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Параметры ожидания подгрузки после прокрутки
+const (
+	scrollPollInterval = 300 * time.Millisecond
+	scrollLoadWait     = 2 * time.Second        // сколько ждать роста страницы после одной прокрутки
+	scrollStableRounds = 2                      // столько прокруток подряд без роста = список загружен
+	scrollRevealDelay  = 700 * time.Millisecond // время на анимацию появления или ленивую отрисовку после прокрутки к скрытому элементу
+)
+
+// ScrollResult - итог прокрутки страницы до конца
+type ScrollResult struct {
+	Scrolls     int    `json:"scrolls"`
+	StartHeight int    `json:"start_height"`
+	Height      int    `json:"height"`
+	Stable      bool   `json:"stable"`              // false - остановились по лимиту прокруток или таймауту
+	Container   string `json:"container,omitempty"` // прокручиваемый контейнер, если прокручивалась не сама страница
+}
+
+// scrollLoadBoxJS - функция loadBox(query): что прокручивать для подгрузки ленты.
+// Для элемента по селектору - ближайший прокручиваемый предок (или сам элемент),
+// иначе scrollBox(): страница или самый большой контейнер с прокруткой. Ищет
+// scrollBoxJS, поэтому вставляется после него.
+const scrollLoadBoxJS = `function loadBox(query) {
+				if (!query) return scrollBox();
+				let el = null;
+				try { el = document.querySelector(query); } catch (e) {}
+				if (!el) return null;
+				for (let node = el; node && node !== document.body && node !== document.documentElement; node = node.parentElement) {
+					const overflow = window.getComputedStyle(node).overflowY;
+					if (/(auto|scroll|overlay)/.test(overflow) && node.scrollHeight > node.clientHeight + 1) return node;
+				}
+				return document.scrollingElement || document.documentElement;
+			}`
+
+// ScrollUntilStable прокручивает бесконечную ленту вниз, пока она подгружает новые
+// элементы: после каждой прокрутки ждет роста scrollHeight и останавливается,
+// когда высота не меняется несколько прокруток подряд, либо по maxScrolls/timeout.
+// Прокручивается ближайший прокручиваемый предок элемента по selector (лента внутри
+// контейнера с overflow), а без selector - страница или ее главный контейнер с
+// прокруткой. В конце положение прокрутки возвращается к исходному - подгруженные
+// элементы остаются в DOM, а модель видит ту же часть страницы, что и до прокрутки.
+func (b *Browser) ScrollUntilStable(selector string, maxScrolls int, timeout time.Duration) (*ScrollResult, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	if maxScrolls <= 0 {
+		maxScrolls = 1
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(timeout))
+	defer cancel()
+
+	var start struct {
+		Found     bool   `json:"found"`
+		Height    int    `json:"height"`
+		Top       int    `json:"top"`
+		Container string `json:"container"`
+	}
+	script := `(function() {
+			` + useHelpersJS + `
+			` + scrollBoxJS + `
+			` + scrollLoadBoxJS + `
+			const box = loadBox('` + escapeJSString(selector) + `');
+			if (!box) return {found: false};
+			window.__agentLoadBox = box;
+			const position = scrollPosition(box, -1);
+			return {found: true, height: box.scrollHeight, top: position.y, container: position.container};
+		})()`
+	if err := chromedp.Run(ctx, evaluate(script, &start)); err != nil {
+		return nil, fmt.Errorf("failed to read page height: %w", err)
+	}
+	if !start.Found {
+		return nil, fmt.Errorf("element not found for scroll: %s", selector)
+	}
+	result := &ScrollResult{StartHeight: start.Height, Height: start.Height, Container: start.Container}
+
+	// -1 - контейнер убран из DOM (приложение перерисовало ленту)
+	const boxJS = `(window.__agentLoadBox && window.__agentLoadBox.isConnected ? window.__agentLoadBox : null)`
+	unchanged := 0
+	for result.Scrolls < maxScrolls {
+		var height int
+		if err := chromedp.Run(ctx, evaluate(`(function() {
+			const box = `+boxJS+`;
+			if (!box) return -1;
+			box.scrollTop = box.scrollHeight;
+			return box.scrollHeight;
+		})()`, &height)); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return result, fmt.Errorf("failed to scroll: %w", err)
+		}
+		if height < 0 {
+			break
+		}
+		result.Scrolls++
+
+		grew := false
+		deadline := time.Now().Add(scrollLoadWait)
+		for time.Now().Before(deadline) && ctx.Err() == nil {
+			time.Sleep(b.pollInterval(scrollPollInterval))
+			var current int
+			if err := chromedp.Run(ctx, evaluate(`(function() { const box = `+boxJS+`; return box ? box.scrollHeight : -1; })()`, &current)); err != nil {
+				break
+			}
+			if current > result.Height {
+				result.Height = current
+				grew = true
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		if grew {
+			unchanged = 0
+			continue
+		}
+		unchanged++
+		if unchanged >= scrollStableRounds {
+			result.Stable = true
+			break
+		}
+	}
+
+	// Возврат к исходному положению - отдельным контекстом, общий мог истечь по таймауту
+	restoreCtx, restoreCancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer restoreCancel()
+	_ = chromedp.Run(restoreCtx, evaluate(fmt.Sprintf(`(function() {
+		const box = `+boxJS+`;
+		if (box) box.scrollTop = %d;
+		window.__agentLoadBox = null;
+	})()`, start.Top), nil))
+
+	return result, nil
+}
+
+// Направления Browser.Scroll
+const (
+	ScrollDown   = "down"
+	ScrollUp     = "up"
+	ScrollTop    = "top"
+	ScrollBottom = "bottom"
+)
+
+// ScrollPosition - положение прокрутки после Browser.Scroll
+type ScrollPosition struct {
+	Y         int    `json:"y"`                   // прокручено от начала, px
+	Height    int    `json:"height"`              // высота прокручиваемой области, px
+	Viewport  int    `json:"viewport"`            // видимая высота, px
+	Moved     bool   `json:"moved"`               // прокрутка сдвинула область
+	Container string `json:"container,omitempty"` // контейнер с прокруткой, если страница сама не прокручивается
+}
+
+// AtBottom сообщает, что область прокручена до конца
+func (p *ScrollPosition) AtBottom() bool {
+	return p.Y+p.Viewport >= p.Height-2
+}
+
+// WithAutoScroll включает прокрутку страницы перед извлечением в GetPageContent
+func WithAutoScroll(enabled bool) Option {
+	return func(b *Browser) {
+		b.autoScroll = enabled
+	}
+}
+
+// SetAutoScroll включает или выключает прокрутку перед извлечением: GetPageContent
+// на длинной странице прокручивает ее на экран вниз и возвращает обратно, чтобы
+// подгрузился ленивый контент. По умолчанию выключена - страницу прокручивает
+// модель действием scroll, и положение прокрутки, которое она видит, не сбрасывается.
+func (b *Browser) SetAutoScroll(enabled bool) {
+	b.autoScroll = enabled
+}
+
+// scrollBoxJS - функция scrollBox(): прокручиваемая область страницы. Если сама
+// страница не прокручивается (приложение с фиксированным макетом), это самый
+// большой видимый контейнер с прокруткой - лента, список писем.
+const scrollBoxJS = `function scrollBox() {
+				const root = document.scrollingElement || document.documentElement;
+				if (root.scrollHeight > window.innerHeight + 1) return root;
+				let best = null;
+				for (const el of document.querySelectorAll('body *')) {
+					if (el.scrollHeight <= el.clientHeight + 1 || el.clientHeight < 100) continue;
+					const overflow = window.getComputedStyle(el).overflowY;
+					if (overflow !== 'auto' && overflow !== 'scroll') continue;
+					if (!isVisible(el)) continue;
+					if (!best || el.clientHeight * el.clientWidth > best.clientHeight * best.clientWidth) best = el;
+				}
+				return best || root;
+			}
+			function scrollPosition(box, before) {
+				const root = document.scrollingElement || document.documentElement;
+				const isRoot = box === root;
+				const name = isRoot ? '' : (box.getAttribute('aria-label') || (box.id ? '#' + box.id : '') ||
+					box.tagName.toLowerCase() + (typeof box.className === 'string' && box.className.trim() ? '.' + box.className.trim().split(/\s+/)[0] : ''));
+				return {y: Math.round(box.scrollTop), height: box.scrollHeight, viewport: isRoot ? window.innerHeight : box.clientHeight,
+					moved: Math.round(box.scrollTop) !== before, container: name};
+			}`
+
+// normalizeScrollDirection приводит направление прокрутки к down/up/top/bottom
+func normalizeScrollDirection(direction string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case "down", "вниз":
+		return ScrollDown, nil
+	case "up", "вверх":
+		return ScrollUp, nil
+	case "top", "start", "home", "наверх", "в начало":
+		return ScrollTop, nil
+	case "bottom", "end", "в конец", "до конца":
+		return ScrollBottom, nil
+	}
+	return "", fmt.Errorf("неизвестное направление прокрутки '%s': используй down, up, top или bottom", direction)
+}
+
+// Scroll прокручивает страницу в направлении direction: down и up - на amount
+// пикселей (0 - на 80% высоты окна), top и bottom - в начало и в конец. Если страница
+// сама не прокручивается, прокручивается самый большой контейнер с прокруткой.
+// После прокрутки ждет подгрузки ленивого контента и возвращает новое положение.
+func (b *Browser) Scroll(direction string, amount int) (*ScrollPosition, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if err := b.crashedError(); err != nil {
+		return nil, err
+	}
+	direction, err := normalizeScrollDirection(direction)
+	if err != nil {
+		return nil, err
+	}
+	if amount < 0 {
+		amount = 0
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	script := fmt.Sprintf(`(function() {
+			`+useHelpersJS+`
+			`+scrollBoxJS+`
+			const box = scrollBox();
+			const before = Math.round(box.scrollTop);
+			const view = box === (document.scrollingElement || document.documentElement) ? window.innerHeight : box.clientHeight;
+			const step = %d > 0 ? %d : Math.round(view * 0.8);
+			switch ('%s') {
+				case 'down': box.scrollTop = before + step; break;
+				case 'up': box.scrollTop = before - step; break;
+				case 'top': box.scrollTop = 0; break;
+				case 'bottom': box.scrollTop = box.scrollHeight; break;
+			}
+			window.__agentScrollBox = box;
+			return scrollPosition(box, before);
+		})()`, amount, amount, direction)
+
+	var moved ScrollPosition
+	if err := chromedp.Run(ctx, evaluate(script, &moved)); err != nil {
+		return nil, fmt.Errorf("failed to scroll: %w", err)
+	}
+	if !moved.Moved {
+		return &moved, nil
+	}
+
+	// Лента подгружает элементы после прокрутки: ждем и перечитываем высоту
+	var position ScrollPosition
+	err = chromedp.Run(ctx,
+		chromedp.Sleep(scrollRevealDelay),
+		evaluate(`(function() {
+			`+useHelpersJS+`
+			`+scrollBoxJS+`
+			const box = window.__agentScrollBox && window.__agentScrollBox.isConnected ? window.__agentScrollBox : scrollBox();
+			return scrollPosition(box, -1);
+		})()`, &position),
+	)
+	if err != nil {
+		return &moved, nil
+	}
+	position.Moved = true
+	return &position, nil
+}
+
+// ScrollToElement прокручивает страницу так, чтобы элемент по CSS-селектору оказался
+// в центре окна, и ждет, пока он станет видимым: на лентах с ленивой загрузкой
+// (hh.ru) содержимое карточки появляется только после прокрутки к ней.
+func (b *Browser) ScrollToElement(selector string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if err := b.crashedError(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	script := fmt.Sprintf(`(function() {
+		let el = null;
+		try { el = document.querySelector('%s'); } catch (e) {}
+		if (!el) return false;
+		el.scrollIntoView({block: "center"});
+		return true;
+	})()`, escapeJSString(selector))
+
+	var found bool
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &found)); err != nil {
+		return fmt.Errorf("failed to scroll to element: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("element not found for scroll: %s", selector)
+	}
+	if err := chromedp.Run(ctx, chromedp.WaitVisible(selector, chromedp.ByQuery)); err != nil {
+		return fmt.Errorf("element %s is not visible after scroll: %w", selector, err)
+	}
+	return nil
+}
+
+// Состояния элемента по селектору для revealElement
+const (
+	revealMissing = "missing" // элемента нет в документе
+	revealShown   = "shown"   // элемент видим
+	revealPending = "pending" // элемент скрыт до прокрутки, к нему прокручено
+	revealHidden  = "hidden"  // элемент скрыт, и прокрутка его не покажет
+)
+
+// revealElement прокручивает к элементу по селектору, если он скрыт до прокрутки
+// (isRevealable), и возвращает его состояние
+func revealElement(ctx context.Context, selector string) string {
+	script := fmt.Sprintf(`(function() {
+		`+useHelpersJS+`
+		let el = null;
+		try { el = document.querySelector('%s'); } catch (e) {}
+		if (!el) return '%s';
+		if (isVisible(el)) return '%s';
+		if (!isRevealable(el)) return '%s';
+		el.scrollIntoView({block: "center"});
+		return '%s';
+	})()`, escapeJSString(selector), revealMissing, revealShown, revealHidden, revealPending)
+
+	state := revealMissing
+	if err := chromedp.Run(ctx, evaluate(script, &state)); err != nil {
+		return ""
+	}
+	return state
+}
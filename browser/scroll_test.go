@@ -0,0 +1,53 @@
+package browser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestScrollUntilStableContainer(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<div id="feed" style="height: 300px; overflow-y: auto">
+		<ul id="list"></ul>
+	</div>
+	<script>
+		const feed = document.getElementById('feed'), list = document.getElementById('list');
+		let loaded = 0;
+		function more() {
+			for (let i = 0; i < 20 && loaded < 100; i++) {
+				const li = document.createElement('li');
+				li.style.height = '40px';
+				li.textContent = 'Сообщение ' + (++loaded);
+				list.appendChild(li);
+			}
+		}
+		more();
+		feed.scrollTop = 120;
+		feed.addEventListener('scroll', () => {
+			if (feed.scrollTop + feed.clientHeight >= feed.scrollHeight - 10) setTimeout(more, 100);
+		});
+	</script>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := b.ScrollUntilStable("#list li", 20, 30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Stable || result.Container != "#feed" || result.Height <= result.StartHeight {
+		t.Errorf("result = %+v, want the #feed container loaded to the end", result)
+	}
+	var loaded, top int
+	if err := chromedp.Run(b.ctx,
+		chromedp.Evaluate(`loaded`, &loaded),
+		chromedp.Evaluate(`feed.scrollTop`, &top),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if loaded != 100 || top != 120 {
+		t.Errorf("loaded %d items, scrollTop %d, want 100 items and the position kept at 120", loaded, top)
+	}
+}
@@ -0,0 +1,31 @@
+package browser
+
+// revealInScrollContainersJS - функция revealInScrollContainers(el) из общих функций
+// pageHelpersJS. Элемент внутри контейнера с overflow (выпадающий список, лента
+// сообщений) может быть прокручен за видимую область контейнера, но при этом иметь
+// размер и проходить isVisible. Функция проверяет все прокручиваемые контейнеры
+// от ближайшего к внешним и прокручивает их так, чтобы элемент оказался в центре
+// видимой области. Возвращает true, если что-то было прокручено.
+const revealInScrollContainersJS = `function revealInScrollContainers(el) {
+				let scrolled = false;
+				for (let node = el.parentElement; node && node !== document.body && node !== document.documentElement; node = node.parentElement) {
+					const style = window.getComputedStyle(node);
+					const clipsY = /(auto|scroll|hidden|clip|overlay)/.test(style.overflowY) && node.scrollHeight > node.clientHeight;
+					const clipsX = /(auto|scroll|hidden|clip|overlay)/.test(style.overflowX) && node.scrollWidth > node.clientWidth;
+					if (!clipsY && !clipsX) continue;
+					// Видимая область контейнера без рамок и полос прокрутки
+					const box = node.getBoundingClientRect();
+					const top = box.top + node.clientTop;
+					const left = box.left + node.clientLeft;
+					const rect = el.getBoundingClientRect();
+					if (clipsY && (rect.top < top || rect.bottom > top + node.clientHeight)) {
+						node.scrollTop += rect.top - top - (node.clientHeight - Math.min(rect.height, node.clientHeight)) / 2;
+						scrolled = true;
+					}
+					if (clipsX && (rect.left < left || rect.right > left + node.clientWidth)) {
+						node.scrollLeft += rect.left - left - (node.clientWidth - Math.min(rect.width, node.clientWidth)) / 2;
+						scrolled = true;
+					}
+				}
+				return scrolled;
+			}`
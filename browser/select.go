@@ -0,0 +1,153 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// selectStateJS - функция selectState(el): для <select> - видимые варианты (не больше
+// 30) и выбранное значение, для остальных полей - пустой объект. Если список лежит
+// внутри <label>, подпись заменяется текстом label без вариантов.
+const selectStateJS = `function selectState(el) {
+				if (el.tagName !== 'SELECT') return {};
+				const text = o => (o.label || o.textContent || o.value || '').replace(/\s+/g, ' ').trim();
+				const options = Array.from(el.options).filter(o => !o.hidden).map(text).filter(Boolean);
+				const state = {options: options.slice(0, 30), value: Array.from(el.selectedOptions).map(text).join(', ')};
+				const wrap = el.labels && el.labels.length > 0 ? el.labels[0] : null;
+				if (wrap && wrap.contains(el)) {
+					state.label = Array.from(wrap.childNodes).filter(n => !n.contains(el)).map(n => n.textContent)
+						.join(' ').replace(/\s+/g, ' ').trim().substring(0, 80);
+				}
+				return state;
+			}`
+
+// selectOptionScript выбирает вариант в <select>: список по CSS-селектору, подписи,
+// первому варианту-заглушке ("Выберите город"), name или id, а без подсказки - по
+// наличию варианта. Значение ставится сеттером прототипа, затем события input и change.
+func selectOptionScript(hint, wanted string) string {
+	return `(function() {
+			` + useHelpersJS + `
+			` + choiceHelpersJS + `
+			` + choiceMatchJS + `
+			const hint = '` + escapeJSString(hint) + `';
+			const wanted = '` + escapeJSString(wanted) + `';
+			const optionText = o => cleanText(o.label || o.textContent || o.value);
+			// Текст подписи без вариантов списка, если <select> лежит внутри <label>
+			const ownText = label => cleanText(Array.from(label.childNodes)
+				.filter(n => n.nodeType === 3 || (n.nodeType === 1 && !n.matches('select')))
+				.map(n => n.textContent).join(' '));
+			const selectLabel = el => cleanText(el.getAttribute('aria-label')) ||
+				(el.labels && el.labels.length > 0 ? ownText(el.labels[0]) : '') || el.name || el.id || '';
+			const findOption = el => {
+				const options = Array.from(el.options);
+				const v = cleanText(wanted).toLowerCase();
+				return options.find(o => optionText(o).toLowerCase() === v || o.value === wanted) ||
+					options.find(o => choiceMatches(optionText(o), wanted));
+			};
+
+			const selects = Array.from(document.querySelectorAll('select'));
+			let el = null;
+			if (hint) {
+				try {
+					const bySelector = document.querySelector(hint);
+					if (bySelector && bySelector.tagName === 'SELECT') el = bySelector;
+				} catch (e) {}
+			}
+			if (!el && hint) {
+				const h = cleanText(hint).toLowerCase();
+				const keys = s => [selectLabel(s), s.name, s.id, s.options.length > 0 ? optionText(s.options[0]) : '']
+					.map(k => cleanText(k).toLowerCase()).filter(Boolean);
+				el = selects.find(s => keys(s).includes(h)) || selects.find(s => keys(s).some(k => k.includes(h)));
+			}
+			if (!el && !hint) {
+				// Скрытый нативный список под стилизованным виджетом тоже подходит, но видимый - раньше
+				const withOption = selects.filter(findOption);
+				el = withOption.find(isVisible) || withOption[0] || null;
+			}
+			if (!el) {
+				return {found: false, options: selects.map(selectLabel).filter(Boolean).slice(0, 15)};
+			}
+
+			const label = selectLabel(el).substring(0, 80);
+			const options = Array.from(el.options).filter(o => !o.hidden).map(optionText).filter(Boolean).slice(0, 30);
+			if (el.disabled) {
+				return {found: true, label, error: 'список отключен', options};
+			}
+			const option = findOption(el);
+			if (!option) {
+				return {found: true, label, missing: [wanted], options};
+			}
+			if (option.disabled) {
+				return {found: true, label, failed: [optionText(option)], options};
+			}
+
+			el.scrollIntoView({block: 'center'});
+			el.focus();
+			// Сеттер прототипа - чтобы React и Vue увидели изменение
+			Object.getOwnPropertyDescriptor(HTMLSelectElement.prototype, 'value').set.call(el, option.value);
+			if (el.selectedIndex !== option.index) el.selectedIndex = option.index;
+			el.dispatchEvent(new Event('input', {bubbles: true}));
+			el.dispatchEvent(new Event('change', {bubbles: true}));
+			return {found: true, ok: el.selectedIndex === option.index, label: label + ' = ' + optionText(option), options};
+		})()`
+}
+
+// SelectOption выбирает вариант optionText в выпадающем списке <select>. Список
+// ищется по CSS-селектору selector, а если селектор ничего не нашел - по подписи,
+// варианту-заглушке ("Выберите город"), name или id; пустой selector - список, в
+// котором есть такой вариант. Вариант сравнивается с текстом и value, затем по
+// слову ("M" - "M (46-48)"). После выбора отправляются события input и change.
+func (b *Browser) SelectOption(selector, optionText string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if err := b.crashedError(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	var result choiceResult
+	if err := chromedp.Run(ctx, evaluate(selectOptionScript(selector, optionText), &result)); err != nil {
+		return fmt.Errorf("failed to select option: %w", err)
+	}
+	return selectResultError(selector, optionText, &result)
+}
+
+// SelectOptionInFrame - SelectOption в документе фрейма frame-N
+func (b *Browser) SelectOptionInFrame(frame, selector, optionText string) error {
+	var result choiceResult
+	if err := b.runInFrame(frame, selectOptionScript(selector, optionText), &result); err != nil {
+		return err
+	}
+	return selectResultError(selector, optionText, &result)
+}
+
+// selectResultError превращает итог выбора в ошибку со списками, по которым модель
+// может исправить решение
+func selectResultError(selector, optionText string, result *choiceResult) error {
+	switch {
+	case !result.Found && selector == "":
+		return fmt.Errorf("выпадающий список с вариантом '%s' не найден", optionText)
+	case !result.Found && len(result.Options) > 0:
+		return fmt.Errorf("выпадающий список '%s' не найден. Списки на странице: %s", selector, strings.Join(result.Options, ", "))
+	case !result.Found:
+		return fmt.Errorf("выпадающий список '%s' не найден", selector)
+	case result.Error != "":
+		return fmt.Errorf("в списке '%s' нельзя выбрать вариант: %s", result.Label, result.Error)
+	case len(result.Missing) > 0:
+		return fmt.Errorf("в списке '%s' нет варианта '%s'. Доступные варианты: %s", result.Label, optionText, strings.Join(result.Options, ", "))
+	case len(result.Failed) > 0:
+		return fmt.Errorf("в списке '%s' вариант '%s' отключен", result.Label, result.Failed[0])
+	case !result.OK:
+		return fmt.Errorf("в списке '%s' вариант '%s' не выбран: страница отменила выбор", result.Label, optionText)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// GetSelectedText возвращает текст, выделенный на странице (пользователем или SelectText).
+// Выделение внутри полей ввода window.getSelection() не видит - оно читается из активного поля.
+func (b *Browser) GetSelectedText() (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var text string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`
+		(function() {
+			const el = document.activeElement;
+			if (el && (el.tagName === 'INPUT' || el.tagName === 'TEXTAREA') && typeof el.selectionStart === 'number' && el.selectionEnd > el.selectionStart) {
+				return el.value.substring(el.selectionStart, el.selectionEnd);
+			}
+			const selection = window.getSelection();
+			return selection ? selection.toString() : '';
+		})()
+	`, &text)); err != nil {
+		return "", fmt.Errorf("failed to read selection: %w", err)
+	}
+	return text, nil
+}
+
+// SelectText выделяет весь текст элемента по CSS селектору; прочитать выделение - GetSelectedText
+func (b *Browser) SelectText(selector string) error {
+	return b.selectText(`
+		let el = null;
+		try { el = document.querySelector('` + escapeJSString(selector) + `'); } catch (e) {}
+		if (!el) return false;
+	`)
+}
+
+// SelectTextByContent выделяет наименьший видимый блок (абзац, ячейку, пункт списка),
+// содержащий указанный текст
+func (b *Browser) SelectTextByContent(text string) error {
+	return b.selectText(`
+		` + useHelpersJS + `
+		const needle = '` + escapeJSString(text) + `'.toLowerCase().replace(/\s+/g, ' ').trim();
+		let el = null;
+		for (const candidate of document.querySelectorAll('p, li, td, th, dd, blockquote, pre, h1, h2, h3, h4, h5, h6, span, div, article, section')) {
+			const own = (candidate.innerText || '').toLowerCase().replace(/\s+/g, ' ');
+			if (!own.includes(needle) || !isVisible(candidate)) continue;
+			// Ищем самый вложенный подходящий элемент
+			if (!el || el.contains(candidate)) el = candidate;
+		}
+		if (!el) return false;
+	`)
+}
+
+// selectText выполняет скрипт поиска элемента el и выделяет его содержимое
+func (b *Browser) selectText(findScript string) error {
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	script := `
+		(function() {
+			` + findScript + `
+			el.scrollIntoView({block: 'center'});
+			if (el.tagName === 'INPUT' || el.tagName === 'TEXTAREA') {
+				el.focus();
+				el.select();
+				return true;
+			}
+			const range = document.createRange();
+			range.selectNodeContents(el);
+			const selection = window.getSelection();
+			selection.removeAllRanges();
+			selection.addRange(range);
+			return true;
+		})()
+	`
+
+	var found bool
+	if err := chromedp.Run(ctx, evaluate(script, &found)); err != nil {
+		return fmt.Errorf("failed to select text: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("element for text selection not found")
+	}
+	return nil
+}
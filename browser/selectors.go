@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ExtractionSelectors - дополнительные CSS-селекторы извлечения элементов для сайтов
+// с собственными компонентами (<my-button>, [data-action="buy"]), которые не находят
+// селекторы по умолчанию
+type ExtractionSelectors struct {
+	Links   []string `json:"links"`
+	Buttons []string `json:"buttons"`
+	Inputs  []string `json:"inputs"`
+}
+
+// SetExtractionSelectors добавляет селекторы к стандартным целям извлечения ссылок,
+// кнопок и полей ввода; действует со следующего GetPageContent и GetQuickPageInfo.
+// Некорректные селекторы пропускаются в браузере и не ломают извлечение.
+func (b *Browser) SetExtractionSelectors(links, buttons, inputs []string) {
+	b.extractionSelectors = ExtractionSelectors{
+		Links:   cleanSelectors(links),
+		Buttons: cleanSelectors(buttons),
+		Inputs:  cleanSelectors(inputs),
+	}
+}
+
+// WithExtractionSelectors задает дополнительные селекторы извлечения при создании браузера
+func WithExtractionSelectors(links, buttons, inputs []string) Option {
+	return func(b *Browser) {
+		b.SetExtractionSelectors(links, buttons, inputs)
+	}
+}
+
+// ExtractionSelectors возвращает дополнительные селекторы извлечения
+func (b *Browser) ExtractionSelectors() ExtractionSelectors {
+	return b.extractionSelectors
+}
+
+// ParseSelectorList разбирает список селекторов, разделенных ";": "my-button; [data-cta]"
+// (запятая остается частью селектора)
+func ParseSelectorList(s string) []string {
+	return cleanSelectors(strings.Split(s, ";"))
+}
+
+func cleanSelectors(selectors []string) []string {
+	var clean []string
+	for _, s := range selectors {
+		if s = strings.TrimSpace(s); s != "" {
+			clean = append(clean, s)
+		}
+	}
+	return clean
+}
+
+// extractionSelectorsJS объявляет в скрипте извлечения функции withExtra(kind, base) -
+// стандартный селектор вида kind, дополненный корректными пользовательскими селекторами,
+// и hrefOf(el) - адрес ссылки, в том числе у компонента с атрибутом href вместо <a>
+func (b *Browser) extractionSelectorsJS() string {
+	sel := b.extractionSelectors
+	for _, list := range []*[]string{&sel.Links, &sel.Buttons, &sel.Inputs} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	data, _ := json.Marshal(sel) // списки строк сериализуются всегда
+	return `const extraSelectors = ` + string(data) + `;
+			function withExtra(kind, base) {
+				const valid = extraSelectors[kind].filter(s => {
+					try { document.querySelector(s); return true; } catch (e) { return false; }
+				});
+				return [base].concat(valid).join(', ');
+			}
+			function hrefOf(el) {
+				if (el.href && typeof el.href === 'string') return el.href;
+				const raw = el.getAttribute('href');
+				if (!raw) return '';
+				try { return new URL(raw, location.href).href; } catch (e) { return raw; }
+			}`
+}
@@ -0,0 +1,250 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// RangeControl описывает ползунок на странице: <input type=range> или
+// кастомный слайдер (role="slider", noUiSlider, rc-slider и т.п.)
+type RangeControl struct {
+	Kind     string  `json:"kind"` // range (нативный) или slider (кастомный, управляется перетаскиванием)
+	Label    string  `json:"label"`
+	Selector string  `json:"selector"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Step     float64 `json:"step,omitempty"`
+	Value    float64 `json:"value"`
+}
+
+// rangeMarkerAttr - атрибут, которым помечаются найденные ползунки
+const rangeMarkerAttr = "data-agent-range"
+
+// rangeLabelJS - функция rangeLabel(el): подпись ползунка из label, aria-атрибутов или текста рядом
+const rangeLabelJS = `function rangeLabel(el) {
+				const aria = el.getAttribute('aria-label') || '';
+				if (aria) return aria.trim();
+				const labelledBy = el.getAttribute('aria-labelledby');
+				if (labelledBy) {
+					const ref = document.getElementById(labelledBy.split(' ')[0]);
+					if (ref && ref.innerText) return ref.innerText.trim();
+				}
+				if (el.labels && el.labels.length > 0) return el.labels[0].textContent.trim();
+				let node = el.parentElement;
+				for (let i = 0; i < 4 && node; i++, node = node.parentElement) {
+					const text = (node.innerText || '').trim().split('\n')[0];
+					if (text && text.length <= 60) return text;
+				}
+				return el.name || el.id || '';
+			}`
+
+// rangeExtractionJS - JS-выражение, возвращающее ползунки страницы. Встраивается
+// в скрипты GetPageContent и GetQuickPageInfo и использует их isVisible.
+const rangeExtractionJS = `(function() {
+				const attr = '` + rangeMarkerAttr + `';
+				` + rangeLabelJS + `
+				const num = (v, d) => { const n = parseFloat(v); return isFinite(n) ? n : d; };
+				const controls = [];
+				document.querySelectorAll('input[type="range"], [role="slider"]').forEach(el => {
+					if (controls.length >= 10 || !isVisible(el)) return;
+					const i = controls.length;
+					el.setAttribute(attr, String(i));
+					const native = el.tagName === 'INPUT';
+					controls.push({
+						kind: native ? 'range' : 'slider',
+						label: rangeLabel(el).substring(0, 80),
+						selector: '[' + attr + '="' + i + '"]',
+						min: native ? num(el.min, 0) : num(el.getAttribute('aria-valuemin'), 0),
+						max: native ? num(el.max, 100) : num(el.getAttribute('aria-valuemax'), 100),
+						step: native ? num(el.step, 1) : 0,
+						value: native ? num(el.value, 0) : num(el.getAttribute('aria-valuenow'), 0)
+					});
+				});
+				return controls;
+			})()`
+
+// rangeSideJS - функции rangeSide(q) и pickHandle(list, side) для ползунков с двумя
+// ручками ("Цена от" / "Цена до"): у обеих ручек часто одна подпись на всю дорожку,
+// поэтому ручка выбирается по слову-стороне в запросе и положению на дорожке
+const rangeSideJS = `const maxWords = /(^|\s)(до|по|max|макс\S*|to|upper|верхн\S*)(\s|$)/i;
+			const minWords = /(^|\s)(от|min|мин\S*|from|lower|нижн\S*)(\s|$)/i;
+			function rangeSide(q) {
+				if (maxWords.test(q)) return 'max';
+				if (minWords.test(q)) return 'min';
+				return '';
+			}
+			function pickHandle(list, side) {
+				if (list.length <= 1) return list[0] || null;
+				const sorted = list.slice().sort((a, b) => a.getBoundingClientRect().left - b.getBoundingClientRect().left);
+				if (side === 'min') return sorted[0];
+				if (side === 'max') return sorted[sorted.length - 1];
+				return null;
+			}`
+
+// rangeSetAttr - атрибут, которым SetRange помечает ползунок, чтобы прочитать значение после перетаскивания
+const rangeSetAttr = "data-agent-range-set"
+
+// SetRange устанавливает значение ползунка по селектору или подписи ("Цена до") и
+// возвращает установленное значение. Нативный <input type=range> получает значение с
+// событиями input/change, кастомный слайдер перетаскивается мышью в позицию,
+// рассчитанную по границам дорожки и min/max. Значение ограничивается границами
+// ползунка (aria-valuemin/aria-valuemax у кастомного), поэтому результат может
+// отличаться от запрошенного. У слайдера с двумя ручками ручка выбирается по
+// подписи: "от"/"min" - левая, "до"/"max" - правая; без уточнения - ошибка.
+func (b *Browser) SetRange(selectorOrLabel string, value float64) (float64, error) {
+	select {
+	case <-b.ctx.Done():
+		return 0, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(15*time.Second))
+	defer cancel()
+
+	script := `
+		(function() {
+			` + useHelpersJS + `
+			` + rangeLabelJS + `
+			` + rangeSideJS + `
+			const query = '` + escapeJSString(selectorOrLabel) + `';
+			const target = ` + strconv.FormatFloat(value, 'f', -1, 64) + `;
+			const mark = '` + rangeSetAttr + `';
+			const num = (v, d) => { const n = parseFloat(v); return isFinite(n) ? n : d; };
+			document.querySelectorAll('[' + mark + ']').forEach(e => e.removeAttribute(mark));
+
+			let el = null;
+			try { el = document.querySelector(query); } catch (e) {}
+			if (!el) {
+				const q = query.toLowerCase().trim();
+				const side = rangeSide(q);
+				const all = Array.from(document.querySelectorAll('input[type="range"], [role="slider"]')).filter(isVisible);
+				const labelOf = c => rangeLabel(c).toLowerCase();
+				let matches = all.filter(c => labelOf(c) === q);
+				if (matches.length === 0) matches = all.filter(c => labelOf(c).includes(q));
+				if (matches.length === 0 && side) {
+					// "Цена до" при общей подписи "Цена" у обеих ручек
+					const base = q.replace(side === 'max' ? maxWords : minWords, ' ').trim();
+					if (base) matches = all.filter(c => labelOf(c).includes(base));
+				}
+				if (matches.length === 0 && all.length === 1) matches = all;
+				if (matches.length === 0) return {found: false};
+				el = pickHandle(matches, side);
+				if (!el) return {found: true, error: 'под подпись подходят несколько ручек - уточни "от" или "до" либо укажи selector'};
+			}
+			el.scrollIntoView({block: 'center'});
+
+			if (el.tagName === 'INPUT' && el.type === 'range') {
+				const min = num(el.min, 0), max = num(el.max, 100), step = num(el.step, 1) || 1;
+				let v = Math.min(max, Math.max(min, target));
+				v = min + Math.round((v - min) / step) * step;
+				// Сеттер прототипа - чтобы React и другие фреймворки увидели изменение
+				const setter = Object.getOwnPropertyDescriptor(HTMLInputElement.prototype, 'value').set;
+				setter.call(el, String(v));
+				el.dispatchEvent(new Event('input', {bubbles: true}));
+				el.dispatchEvent(new Event('change', {bubbles: true}));
+				return {found: true, native: true, value: num(el.value, v)};
+			}
+
+			// Кастомный слайдер: дорожка - ближайший предок заметно шире ручки
+			const handle = el.getBoundingClientRect();
+			let track = el.parentElement;
+			while (track && track.getBoundingClientRect().width < handle.width * 3) track = track.parentElement;
+			if (!track) return {found: true, native: false, error: 'не найдена дорожка слайдера'};
+			const rect = track.getBoundingClientRect();
+			const min = num(el.getAttribute('aria-valuemin') || el.dataset.min, 0);
+			const max = num(el.getAttribute('aria-valuemax') || el.dataset.max, 100);
+			if (max <= min) return {found: true, native: false, error: 'неизвестны границы слайдера'};
+			// Границы дорожки берутся у крайних ручек: у второй ручки aria-valuemin часто
+			// равен значению первой, а дорожка размечена от общего минимума до максимума
+			const handles = Array.from(track.querySelectorAll('[role="slider"]'));
+			const trackMin = Math.min(...handles.map(h => num(h.getAttribute('aria-valuemin'), min)), min);
+			const trackMax = Math.max(...handles.map(h => num(h.getAttribute('aria-valuemax'), max)), max);
+			const v = Math.min(max, Math.max(min, target));
+			const ratio = (v - trackMin) / (trackMax - trackMin);
+			el.setAttribute(mark, '');
+			return {
+				found: true,
+				native: false,
+				value: v,
+				from_x: handle.left + handle.width / 2,
+				from_y: handle.top + handle.height / 2,
+				to_x: rect.left + ratio * rect.width,
+				to_y: handle.top + handle.height / 2
+			};
+		})()
+	`
+
+	var result struct {
+		Found  bool    `json:"found"`
+		Native bool    `json:"native"`
+		Value  float64 `json:"value"`
+		Error  string  `json:"error"`
+		FromX  float64 `json:"from_x"`
+		FromY  float64 `json:"from_y"`
+		ToX    float64 `json:"to_x"`
+		ToY    float64 `json:"to_y"`
+	}
+	if err := chromedp.Run(ctx, evaluate(script, &result)); err != nil {
+		return 0, fmt.Errorf("failed to set range: %w", err)
+	}
+	if !result.Found {
+		return 0, fmt.Errorf("slider not found: %s", selectorOrLabel)
+	}
+	if result.Error != "" {
+		return 0, fmt.Errorf("не удалось установить слайдер '%s': %s", selectorOrLabel, result.Error)
+	}
+	if result.Native {
+		return result.Value, nil
+	}
+
+	if err := chromedp.Run(ctx, dragMouse(result.FromX, result.FromY, result.ToX, result.ToY), chromedp.Sleep(500*time.Millisecond)); err != nil {
+		return 0, err
+	}
+	// Слайдер сам округляет значение до своего шага - читаем то, что он показал
+	var current struct {
+		OK    bool    `json:"ok"`
+		Value float64 `json:"value"`
+	}
+	readBack := `(function() {
+		const el = document.querySelector('[` + rangeSetAttr + `]');
+		if (!el) return {ok: false};
+		el.removeAttribute('` + rangeSetAttr + `');
+		const n = parseFloat(el.getAttribute('aria-valuenow'));
+		return isFinite(n) ? {ok: true, value: n} : {ok: false};
+	})()`
+	if err := chromedp.Run(ctx, chromedp.Evaluate(readBack, &current)); err == nil && current.OK {
+		return current.Value, nil
+	}
+	return result.Value, nil
+}
+
+// dragMouse перетаскивает мышью из точки в точку с промежуточными движениями,
+// чтобы обработчики mousemove слайдера увидели непрерывное перетаскивание
+func dragMouse(fromX, fromY, toX, toY float64) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := input.DispatchMouseEvent(input.MouseMoved, fromX, fromY).Do(ctx); err != nil {
+			return err
+		}
+		if err := input.DispatchMouseEvent(input.MousePressed, fromX, fromY).
+			WithButton(input.Left).WithButtons(1).WithClickCount(1).Do(ctx); err != nil {
+			return err
+		}
+		const steps = 10
+		for i := 1; i <= steps; i++ {
+			x := fromX + (toX-fromX)*float64(i)/steps
+			y := fromY + (toY-fromY)*float64(i)/steps
+			if err := input.DispatchMouseEvent(input.MouseMoved, x, y).
+				WithButton(input.Left).WithButtons(1).Do(ctx); err != nil {
+				return err
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		return input.DispatchMouseEvent(input.MouseReleased, toX, toY).
+			WithButton(input.Left).WithButtons(0).WithClickCount(1).Do(ctx)
+	})
+}
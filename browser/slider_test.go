@@ -0,0 +1,72 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestSetRangeNativeDualHandles(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<div>Цена
+		<input type="range" id="lo" min="0" max="10000" step="100" value="0">
+		<input type="range" id="hi" min="0" max="10000" step="100" value="10000">
+	</div>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := b.SetRange("Цена до", 3000); err != nil || got != 3000 {
+		t.Fatalf(`SetRange("Цена до") = %v, %v, want 3000`, got, err)
+	}
+	if got, err := b.SetRange("Цена от", -50); err != nil || got != 0 {
+		t.Errorf(`SetRange("Цена от", -50) = %v, %v, want clamped to 0`, got, err)
+	}
+	if _, err := b.SetRange("Цена", 500); err == nil || !strings.Contains(err.Error(), "несколько ручек") {
+		t.Errorf(`SetRange("Цена") error = %v, want an ambiguous handle error`, err)
+	}
+	if got, err := b.SetRange("#hi", 20000); err != nil || got != 10000 {
+		t.Errorf(`SetRange("#hi", 20000) = %v, %v, want clamped to 10000`, got, err)
+	}
+}
+
+func TestSetRangeCustomDualHandles(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<div style="padding: 40px">Цена
+		<div id="track" style="position: relative; width: 400px; height: 10px; background: #ccc">
+			<div role="slider" id="lo" aria-valuemin="0" aria-valuemax="10000" aria-valuenow="0"
+				style="position: absolute; left: 0; width: 20px; height: 20px; margin-left: -10px; background: red"></div>
+			<div role="slider" id="hi" aria-valuemin="0" aria-valuemax="10000" aria-valuenow="10000"
+				style="position: absolute; left: 400px; width: 20px; height: 20px; margin-left: -10px; background: blue"></div>
+		</div>
+	</div>
+	<script>
+		const track = document.getElementById('track');
+		let dragging = null;
+		document.querySelectorAll('[role=slider]').forEach(h => h.addEventListener('mousedown', () => dragging = h));
+		document.addEventListener('mousemove', e => {
+			if (!dragging) return;
+			const rect = track.getBoundingClientRect();
+			const ratio = Math.min(1, Math.max(0, (e.clientX - rect.left) / rect.width));
+			dragging.setAttribute('aria-valuenow', String(Math.round(ratio * 100) * 100));
+			dragging.style.left = (ratio * rect.width) + 'px';
+		});
+		document.addEventListener('mouseup', () => dragging = null);
+	</script>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.SetRange("Цена до", 3000)
+	if err != nil || got != 3000 {
+		t.Fatalf(`SetRange("Цена до") = %v, %v, want 3000`, got, err)
+	}
+	var lo string
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(`document.getElementById('lo').getAttribute('aria-valuenow')`, &lo)); err != nil || lo != "0" {
+		t.Errorf("lower handle moved: %q, %v", lo, err)
+	}
+	if got, err := b.SetRange("Цена от", 12000); err != nil || got != 10000 {
+		t.Errorf(`SetRange("Цена от", 12000) = %v, %v, want clamped to 10000`, got, err)
+	}
+}
@@ -0,0 +1,118 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Состояние цели действия относительно последнего извлечения страницы
+const (
+	TargetStable  = "stable"  // элемент на месте
+	TargetMoved   = "moved"   // элемент с тем же текстом сместился (реклама, баннер, поздняя загрузка)
+	TargetMissing = "missing" // элемент был при анализе страницы, а теперь его нет
+	TargetUnknown = "unknown" // сравнить не с чем: страница не анализировалась или цель не из списка
+)
+
+// targetShiftTolerance - смещение в пикселях, которое еще считается тем же положением
+const targetShiftTolerance = 20
+
+// TargetCheck - результат проверки цели перед кликом или заполнением
+type TargetCheck struct {
+	Status string `json:"status"`
+	Shift  int    `json:"shift"` // смещение в пикселях для TargetMoved
+}
+
+// targetKeyJS - ключ элемента для сопоставления с последним извлечением: текст кнопки
+// или ссылки, для полей - placeholder, name, aria-label или подпись
+const targetKeyJS = `function targetKey(el) {
+					let key = el.matches('input, textarea, select') ?
+						(el.placeholder || el.name || el.getAttribute('aria-label') || (el.labels && el.labels[0] ? el.labels[0].innerText : '') || '') :
+						getButtonText(el);
+					return (key || '').replace(/\s+/g, ' ').trim().toLowerCase().substring(0, 80);
+				}`
+
+// targetSnapshotJS - JS-инструкция, сохраняющая в странице положение видимых кнопок,
+// ссылок и полей на момент извлечения. Встраивается в скрипты GetPageContent и
+// GetQuickPageInfo; VerifyTarget сравнивает с ним положение цели перед действием.
+const targetSnapshotJS = `(function() {
+				` + targetKeyJS + `
+				const items = [];
+				document.querySelectorAll('a, button, input, textarea, select, [role="button"], [role="link"], [onclick]').forEach(el => {
+					if (items.length >= 400 || !isVisible(el)) return;
+					const key = targetKey(el);
+					if (!key) return;
+					const rect = el.getBoundingClientRect();
+					items.push({key: key, x: rect.left + window.scrollX, y: rect.top + window.scrollY});
+				});
+				window.__agentTargets = {url: location.href, items: items};
+			})()`
+
+// VerifyTarget проверяет перед кликом или заполнением, что цель, выбранная моделью
+// по последнему извлечению страницы, все еще на месте: поздно загруженный баннер или
+// реклама сдвигают кнопки, и клик попадает в другой элемент. Цель ищется по селектору
+// или тексту (для полей - placeholder, name). Если страница еще перестраивается,
+// метод ждет, пока положение цели перестанет меняться (до секунды).
+func (b *Browser) VerifyTarget(selector, text string) (*TargetCheck, error) {
+	select {
+	case <-b.ctx.Done():
+		return nil, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(5*time.Second))
+	defer cancel()
+
+	script := fmt.Sprintf(`(function() {
+			const selector = '%s';
+			const search = '%s'.replace(/\s+/g, ' ').trim().toLowerCase();
+			`+useHelpersJS+`
+			`+targetKeyJS+`
+			const snapshot = window.__agentTargets;
+			if (!snapshot || snapshot.url !== location.href) return {status: 'unknown'};
+
+			let el = null;
+			if (selector) {
+				try { el = document.querySelector(selector); } catch (e) { return {status: 'unknown'}; }
+				if (!el) return {status: 'unknown'};
+			} else {
+				const candidates = Array.from(document.querySelectorAll('a, button, input, textarea, select, [role="button"], [role="link"], [onclick]'))
+					.filter(c => isVisible(c));
+				el = candidates.find(c => targetKey(c) === search) || candidates.find(c => targetKey(c).includes(search)) || null;
+			}
+
+			const key = el ? targetKey(el) : search;
+			const before = snapshot.items.filter(item => item.key === key || (!selector && item.key.includes(search)));
+			if (!el) {
+				// Текст мог быть не в кнопке, а в обычном элементе - его ищет клик по тексту
+				const text = (document.body.innerText || '').toLowerCase();
+				return {status: before.length > 0 && !text.includes(search) ? 'missing' : 'unknown'};
+			}
+			if (before.length === 0) return {status: 'unknown'};
+
+			const rect = el.getBoundingClientRect();
+			const x = rect.left + window.scrollX, y = rect.top + window.scrollY;
+			const shift = Math.round(Math.min(...before.map(item => Math.hypot(item.x - x, item.y - y))));
+			return {status: shift > %d ? 'moved' : 'stable', shift: shift, x: Math.round(x), y: Math.round(y)};
+		})()`, escapeJSString(selector), escapeJSString(text), targetShiftTolerance)
+
+	var check struct {
+		TargetCheck
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	// Пока сместившийся элемент двигается (страница перестраивается), действовать рано
+	for attempt := 0; ; attempt++ {
+		x, y := check.X, check.Y
+		if err := chromedp.Run(ctx, evaluate(script, &check)); err != nil {
+			return nil, fmt.Errorf("не удалось проверить цель действия: %w", err)
+		}
+		if check.Status != TargetMoved || attempt > 0 && check.X == x && check.Y == y || attempt == 4 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return &check.TargetCheck, nil
+}
@@ -0,0 +1,61 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// notePageStatus запоминает HTTP-статус основного документа вкладки по событиям сети:
+// запрос нового документа сбрасывает статус, ответ на него - записывает. Переход без
+// ответа сети статус тоже сбрасывает: страница из кэша назад-вперед (bfcache) и смена
+// адреса внутри документа (pushState, якорь) показывают не тот ответ, что записан.
+// Основной фрейм вкладки имеет тот же ID, что и сама вкладка. Вызывается под eventsMu.
+func (b *Browser) notePageStatus(tabID target.ID, ev interface{}) {
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		if e.Type == network.ResourceTypeDocument && string(e.FrameID) == string(tabID) {
+			delete(b.pageStatuses, tabID)
+		}
+	case *network.EventResponseReceived:
+		if e.Type == network.ResourceTypeDocument && string(e.FrameID) == string(tabID) && e.Response != nil {
+			b.pageStatuses[tabID] = int(e.Response.Status)
+		}
+	case *page.EventFrameNavigated:
+		if e.Frame != nil && string(e.Frame.ID) == string(tabID) && e.Type == page.NavigationTypeBackForwardCacheRestore {
+			delete(b.pageStatuses, tabID)
+		}
+	case *page.EventNavigatedWithinDocument:
+		if string(e.FrameID) == string(tabID) {
+			delete(b.pageStatuses, tabID)
+		}
+	}
+}
+
+// currentStatus - HTTP-статус документа текущей вкладки; 0 - неизвестен
+func (b *Browser) currentStatus() int {
+	tabID := chromedp.FromContext(b.ctx).Target.TargetID
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return b.pageStatuses[tabID]
+}
+
+// GetCurrentStatus возвращает HTTP-статус ответа, которым загрузилась текущая страница:
+// по тексту стилизованной страницы 404 или 500 не отличить от обычной. Статус
+// неизвестен для страниц без HTTP-ответа (about:blank) и вкладок, открытых до
+// подключения агента, - тогда возвращается ошибка.
+func (b *Browser) GetCurrentStatus() (int, error) {
+	select {
+	case <-b.ctx.Done():
+		return 0, fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	status := b.currentStatus()
+	if status == 0 {
+		return 0, fmt.Errorf("HTTP-статус текущей страницы неизвестен")
+	}
+	return status, nil
+}
@@ -0,0 +1,47 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+)
+
+func TestNotePageStatus(t *testing.T) {
+	const tab = target.ID("TAB")
+	mainFrame := cdp.FrameID(tab)
+	request := &network.EventRequestWillBeSent{Type: network.ResourceTypeDocument, FrameID: mainFrame}
+	response := func(status int64, frame cdp.FrameID) *network.EventResponseReceived {
+		return &network.EventResponseReceived{Type: network.ResourceTypeDocument, FrameID: frame, Response: &network.Response{Status: status}}
+	}
+
+	tests := []struct {
+		name   string
+		events []interface{}
+		want   int
+	}{
+		{"document response", []interface{}{request, response(404, mainFrame)}, 404},
+		{"new document request", []interface{}{response(500, mainFrame), request}, 0},
+		{"iframe response ignored", []interface{}{response(200, mainFrame), response(404, "CHILD")}, 200},
+		{"regular navigation keeps the response", []interface{}{request, response(200, mainFrame),
+			&page.EventFrameNavigated{Frame: &cdp.Frame{ID: mainFrame}, Type: page.NavigationTypeNavigation}}, 200},
+		{"bfcache restore", []interface{}{response(404, mainFrame),
+			&page.EventFrameNavigated{Frame: &cdp.Frame{ID: mainFrame}, Type: page.NavigationTypeBackForwardCacheRestore}}, 0},
+		{"bfcache restore of an iframe", []interface{}{response(404, mainFrame),
+			&page.EventFrameNavigated{Frame: &cdp.Frame{ID: "CHILD", ParentID: mainFrame}, Type: page.NavigationTypeBackForwardCacheRestore}}, 404},
+		{"pushState", []interface{}{response(200, mainFrame), &page.EventNavigatedWithinDocument{FrameID: mainFrame, URL: "/missing"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Browser{pageStatuses: make(map[target.ID]int)}
+			for _, ev := range tt.events {
+				b.notePageStatus(tab, ev)
+			}
+			if got := b.pageStatuses[tab]; got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
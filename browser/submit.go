@@ -0,0 +1,185 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// lastFocusedJS - функция lastFocused(): последнее поле, получившее фокус в документе.
+// После заполнения фокус может уйти с поля (клик по подсказке, перерисовка), а
+// отправить нужно форму именно этого поля.
+const lastFocusedJS = `let focusedField = null;
+	document.addEventListener('focusin', e => {
+		if (e.target && e.target.matches && e.target.matches('input, textarea, select, [contenteditable]')) focusedField = e.target;
+	}, true);
+	function lastFocused() {
+		return focusedField && focusedField.isConnected ? focusedField : null;
+	}`
+
+// submitResult - итог поиска и нажатия кнопки отправки формы
+type submitResult struct {
+	Found    bool   `json:"found"`    // поле или форма найдены
+	NoForm   bool   `json:"noForm"`   // поле не внутри <form>
+	Field    string `json:"field"`    // подпись поля, от которого искалась форма
+	Control  string `json:"control"`  // текст нажатой кнопки; пусто - форма отправлена без кнопки
+	Disabled bool   `json:"disabled"` // кнопка отправки неактивна
+	Fallback bool   `json:"fallback"` // кнопки отправки нет, control - первая видимая кнопка формы
+	Pending  bool   `json:"pending"`  // кнопка fallback не нажата: ее текст не совпал с confirm
+}
+
+// submitFormScript находит форму поля selector (пустой - поле в фокусе или последнее
+// заполненное, иначе единственная видимая форма страницы) и нажимает ее кнопку
+// отправки: button[type=submit] или input[type=submit|image]. Если такой нет, первая
+// видимая кнопка формы нажимается, только когда ее текст равен confirm, - иначе
+// скрипт лишь сообщает ее текст (pending). Форма без кнопок отправляется requestSubmit -
+// с проверкой полей и событием submit.
+func submitFormScript(selector, confirm string) string {
+	return `(function() {
+			` + useHelpersJS + `
+			const selector = '` + escapeJSString(selector) + `';
+			const confirm = '` + escapeJSString(confirm) + `';
+			const clean = s => (s || '').replace(/\s+/g, ' ').trim();
+			const fieldLabel = el => clean(el.getAttribute('aria-label') || el.placeholder || el.name || el.id || el.tagName.toLowerCase()).substring(0, 60);
+
+			let start = null;
+			if (selector) {
+				try {
+					start = document.querySelector(selector);
+				} catch (e) {}
+				if (!start) return {found: false};
+			} else {
+				const active = document.activeElement;
+				start = active && active !== document.body && active !== document.documentElement ? active : lastFocused();
+			}
+			let form = null;
+			if (start) {
+				form = start.tagName === 'FORM' ? start : (start.form || start.closest('form'));
+			} else {
+				const forms = Array.from(document.forms).filter(isVisible);
+				if (forms.length !== 1) return {found: false};
+				form = forms[0];
+			}
+			const field = start ? fieldLabel(start) : '';
+			if (!form) return {found: true, noForm: true, field};
+
+			// form.elements включает и кнопки с атрибутом form="id" вне самой формы
+			const controls = Array.from(form.elements).concat(Array.from(form.querySelectorAll('[role="button"]')))
+				.filter(isVisible);
+			const isSubmit = el => (el.tagName === 'BUTTON' && el.type === 'submit') ||
+				(el.tagName === 'INPUT' && (el.type === 'submit' || el.type === 'image'));
+			const submitControl = controls.find(isSubmit);
+			const control = submitControl ||
+				controls.find(el => el.tagName === 'BUTTON' || el.getAttribute('role') === 'button' || (el.tagName === 'INPUT' && el.type === 'button'));
+			const controlText = el => clean(getButtonText(el) || el.value || el.alt) || el.tagName.toLowerCase();
+			const fallback = !submitControl && !!control;
+
+			if (!control) {
+				// Форма с target="_blank" открыла бы результат в новой вкладке
+				form.removeAttribute('target');
+				form.requestSubmit();
+				return {found: true, field};
+			}
+			const text = controlText(control);
+			if (control.disabled || control.getAttribute('aria-disabled') === 'true') {
+				return {found: true, field, control: text, disabled: true, fallback};
+			}
+			if (fallback && text !== confirm) {
+				return {found: true, field, control: text, fallback, pending: true};
+			}
+			form.removeAttribute('target');
+			control.scrollIntoView({block: 'center'});
+			control.click();
+			return {found: true, field, control: text, fallback};
+		})()`
+}
+
+// SubmitEnclosingForm отправляет форму поля selector - CSS-селектор поля или самой
+// формы; пустой selector - поле в фокусе или последнее заполненное. Нажимается
+// кнопка отправки формы, поэтому модели не нужно знать ее текст. Если кнопки
+// отправки нет, первая видимая кнопка формы нажимается только после approve с ее
+// текстом: это может быть и "Удалить". Возвращает текст нажатой кнопки (пусто - у
+// формы нет кнопок и она отправлена requestSubmit).
+func (b *Browser) SubmitEnclosingForm(selector string, approve func(control string) error) (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if err := b.crashedError(); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(10*time.Second))
+	defer cancel()
+
+	mark := b.routeMark()
+	run := func(script string, result *submitResult) error {
+		return chromedp.Run(ctx, evaluate(script, result))
+	}
+	control, err := submitForm(run, selector, approve)
+	if err != nil {
+		return "", err
+	}
+	if err := chromedp.Run(ctx, b.waitAfterClick(mark, 1*time.Second)); err != nil {
+		return "", fmt.Errorf("failed to submit form: %w", err)
+	}
+	return control, nil
+}
+
+// SubmitEnclosingFormInFrame - SubmitEnclosingForm в документе фрейма frame-N
+func (b *Browser) SubmitEnclosingFormInFrame(frame, selector string, approve func(control string) error) (string, error) {
+	run := func(script string, result *submitResult) error {
+		return b.runInFrame(frame, script, result)
+	}
+	return submitForm(run, selector, approve)
+}
+
+// submitForm выполняет submitFormScript через run: кнопка отправки нажимается сразу,
+// а первая видимая кнопка формы - вторым запуском, если approve разрешил ее текст и
+// кнопка за это время не сменилась
+func submitForm(run func(script string, result *submitResult) error, selector string, approve func(control string) error) (string, error) {
+	var result submitResult
+	if err := run(submitFormScript(selector, ""), &result); err != nil {
+		return "", fmt.Errorf("failed to submit form: %w", err)
+	}
+	if err := submitResultError(selector, &result); err != nil {
+		return "", err
+	}
+	if !result.Pending {
+		return result.Control, nil
+	}
+
+	control := result.Control
+	if err := approve(control); err != nil {
+		return "", err
+	}
+	result = submitResult{}
+	if err := run(submitFormScript(selector, control), &result); err != nil {
+		return "", fmt.Errorf("failed to submit form: %w", err)
+	}
+	if err := submitResultError(selector, &result); err != nil {
+		return "", err
+	}
+	if result.Pending {
+		return "", fmt.Errorf("кнопка формы сменилась ('%s' вместо '%s') - форма не отправлена, проверь страницу", result.Control, control)
+	}
+	return result.Control, nil
+}
+
+// submitResultError объясняет, почему форма не отправлена, и подсказывает модели обход
+func submitResultError(selector string, result *submitResult) error {
+	switch {
+	case !result.Found && selector != "":
+		return fmt.Errorf("element %s not found - нет поля или формы для отправки", selector)
+	case !result.Found:
+		return fmt.Errorf("не найдена форма для отправки: ни одно поле не в фокусе - заполни поле формы (fill) или укажи 'selector' поля")
+	case result.NoForm:
+		return fmt.Errorf("поле '%s' не внутри <form> - нажми enter (press_key) или кликни кнопку отправки по тексту", result.Field)
+	case result.Disabled:
+		return fmt.Errorf("кнопка отправки '%s' неактивна - проверь, что заполнены все обязательные поля формы", result.Control)
+	}
+	return nil
+}
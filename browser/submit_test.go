@@ -0,0 +1,117 @@
+package browser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+// scriptedRun - run для submitForm: отдает результаты по порядку и запоминает confirm запусков
+type scriptedRun struct {
+	results  []submitResult
+	confirms []string
+}
+
+func (r *scriptedRun) run(script string, result *submitResult) error {
+	_, rest, _ := strings.Cut(script, "const confirm = '")
+	confirm, _, _ := strings.Cut(rest, "';")
+	r.confirms = append(r.confirms, confirm)
+	*result = r.results[0]
+	r.results = r.results[1:]
+	return nil
+}
+
+func TestSubmitForm(t *testing.T) {
+	pending := submitResult{Found: true, Control: "Удалить", Fallback: true, Pending: true}
+	clicked := submitResult{Found: true, Control: "Удалить", Fallback: true}
+	allow := func(string) error { return nil }
+	deny := func(control string) error { return errors.New("blocked " + control) }
+
+	tests := []struct {
+		name     string
+		results  []submitResult
+		approve  func(string) error
+		want     string
+		wantErr  string
+		confirms []string
+	}{
+		{
+			name:     "submit control clicked at once",
+			results:  []submitResult{{Found: true, Control: "Войти"}},
+			approve:  deny,
+			want:     "Войти",
+			confirms: []string{""},
+		},
+		{
+			name:     "fallback approved",
+			results:  []submitResult{pending, clicked},
+			approve:  allow,
+			want:     "Удалить",
+			confirms: []string{"", "Удалить"},
+		},
+		{
+			name:     "fallback refused",
+			results:  []submitResult{pending},
+			approve:  deny,
+			wantErr:  "blocked Удалить",
+			confirms: []string{""},
+		},
+		{
+			name:     "fallback changed before click",
+			results:  []submitResult{pending, {Found: true, Control: "Стереть все", Fallback: true, Pending: true}},
+			approve:  allow,
+			wantErr:  "сменилась",
+			confirms: []string{"", "Удалить"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &scriptedRun{results: tt.results}
+			got, err := submitForm(r.run, "", tt.approve)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("submitForm() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("submitForm() = %q, %v, want %q", got, err, tt.want)
+			}
+			if strings.Join(r.confirms, "|") != strings.Join(tt.confirms, "|") {
+				t.Errorf("confirms = %q, want %q", r.confirms, tt.confirms)
+			}
+		})
+	}
+}
+
+func TestSubmitEnclosingFormFallbackButton(t *testing.T) {
+	b := newTestBrowser(t)
+	url := servePage(t, `<form onsubmit="return false">
+		<input id="q" name="q">
+		<button type="button" onclick="window.pressed = (window.pressed || 0) + 1">Удалить</button>
+	</form>`)
+	if err := b.Navigate(url); err != nil {
+		t.Fatal(err)
+	}
+	pressed := func() int {
+		var n int
+		if err := chromedp.Run(b.ctx, chromedp.Evaluate(`window.pressed || 0`, &n)); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	var offered string
+	_, err := b.SubmitEnclosingForm("#q", func(control string) error {
+		offered = control
+		return errors.New("refused")
+	})
+	if err == nil || offered != "Удалить" || pressed() != 0 {
+		t.Fatalf("refused fallback: err %v, offered %q, pressed %d", err, offered, pressed())
+	}
+
+	control, err := b.SubmitEnclosingForm("#q", func(string) error { return nil })
+	if err != nil || control != "Удалить" || pressed() != 1 {
+		t.Errorf("approved fallback: %q, %v, pressed %d", control, err, pressed())
+	}
+}
@@ -0,0 +1,51 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// attachTab подключает браузер к вкладке tabID: все следующие действия выполняются в ней.
+// Контекст вкладки создается один раз и живет до закрытия вкладки или браузера -
+// отмена контекста chromedp закрывает вкладку.
+func (b *Browser) attachTab(tabID target.ID) error {
+	if chromedp.FromContext(b.rootCtx).Target.TargetID == tabID {
+		b.ctx = b.rootCtx
+		return nil
+	}
+	if tab, ok := b.tabs[tabID]; ok {
+		b.ctx = tab
+		return nil
+	}
+
+	// Первый Run подключается к вкладке, и подключение живет столько же, сколько
+	// переданный контекст, - поэтому без таймаута
+	tabCtx, tabCancel := chromedp.NewContext(b.rootCtx, chromedp.WithTargetID(tabID))
+	if err := chromedp.Run(tabCtx); err != nil {
+		tabCancel()
+		return fmt.Errorf("failed to attach to tab: %w", err)
+	}
+
+	b.tabs[tabID] = tabCtx
+	b.tabCancels[tabID] = tabCancel
+	b.ctx = tabCtx
+	b.setupTab()
+	return nil
+}
+
+// detachTab закрывает подключенную вкладку через ее контекст; false - вкладка не подключена
+func (b *Browser) detachTab(tabID target.ID) bool {
+	cancel, ok := b.tabCancels[tabID]
+	if !ok {
+		return false
+	}
+	if b.ctx == b.tabs[tabID] {
+		b.ctx = b.rootCtx
+	}
+	delete(b.tabs, tabID)
+	delete(b.tabCancels, tabID)
+	cancel()
+	return true
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// textWaitPoll - как часто GetText проверяет, появился ли элемент
+const textWaitPoll = 200 * time.Millisecond
+
+// MultipleElementsError возвращается, когда селектор находит несколько элементов, а
+// нужен один: вызывающий уточняет селектор, а не получает текст случайного элемента
+type MultipleElementsError struct {
+	Selector string
+	Count    int
+}
+
+func (e *MultipleElementsError) Error() string {
+	return fmt.Sprintf("селектор '%s' находит %d элементов - уточни его, чтобы он указывал на один элемент (id, :nth-of-type, родитель)", e.Selector, e.Count)
+}
+
+// GetText ждет появления элемента по CSS селектору и возвращает его текст (innerText
+// без пробелов по краям): цену, номер заказа, итоговую сумму - без извлечения всей
+// страницы. Если селектор находит несколько элементов, возвращает *MultipleElementsError.
+func (b *Browser) GetText(selector string) (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+	if err := b.crashedError(); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(selector) == "" {
+		return "", fmt.Errorf("не указан селектор элемента")
+	}
+
+	timeout := b.actionTimeout(10 * time.Second)
+	ctx, cancel := context.WithTimeout(b.ctx, timeout)
+	defer cancel()
+
+	script := `(function() {
+			let found;
+			try { found = document.querySelectorAll('` + escapeJSString(selector) + `'); } catch (e) { return {invalid: true}; }
+			if (found.length !== 1) return {count: found.length};
+			const el = found[0];
+			return {count: 1, text: el.innerText || el.textContent || ''};
+		})()`
+
+	var result struct {
+		Count   int    `json:"count"`
+		Text    string `json:"text"`
+		Invalid bool   `json:"invalid"`
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		// Во время перехода скрипт может не выполниться - ждем дальше до таймаута
+		err := chromedp.Run(ctx, chromedp.Evaluate(script, &result))
+		switch {
+		case err == nil && result.Invalid:
+			return "", fmt.Errorf("некорректный CSS селектор: %s", selector)
+		case err == nil && result.Count > 1:
+			return "", &MultipleElementsError{Selector: selector, Count: result.Count}
+		case err == nil && result.Count == 1:
+			return strings.TrimSpace(result.Text), nil
+		}
+		if time.Now().Add(b.pollInterval(textWaitPoll)).After(deadline) || ctx.Err() != nil {
+			if err != nil && ctx.Err() == nil {
+				return "", fmt.Errorf("failed to read element text: %w", err)
+			}
+			return "", fmt.Errorf("element %s not found - на странице нет элемента по этому селектору", selector)
+		}
+		time.Sleep(b.pollInterval(textWaitPoll))
+	}
+}
@@ -0,0 +1,117 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// FileChooserError возвращается, когда клик открыл системный диалог выбора файла.
+// Диалог перехвачен и не показывается; файл нужно передать действием upload.
+type FileChooserError struct {
+	Selector string // селектор связанного <input type="file">, если его удалось определить
+	Multiple bool   // поле принимает несколько файлов
+}
+
+func (e *FileChooserError) Error() string {
+	selector := e.Selector
+	if selector == "" {
+		selector = `input[type="file"]`
+	}
+	return fmt.Sprintf("клик открыл системный диалог выбора файла (диалог отменен) - используй действие upload с selector '%s' и путем к файлу в value", selector)
+}
+
+// fileChooserMark запоминает счетчик событий выбора файла перед действием
+func (b *Browser) fileChooserMark() int {
+	b.eventsMu.Lock()
+	defer b.eventsMu.Unlock()
+	return b.fileChooserSeq
+}
+
+// checkFileChooser проверяет, открылся ли диалог выбора файла после отметки mark
+func (b *Browser) checkFileChooser(ctx context.Context, mark int) error {
+	b.eventsMu.Lock()
+	ev := b.fileChooser
+	opened := b.fileChooserSeq != mark
+	b.eventsMu.Unlock()
+
+	if !opened || ev == nil {
+		return nil
+	}
+
+	chooserErr := &FileChooserError{Multiple: ev.Mode == page.FileChooserOpenedModeSelectMultiple}
+	if ev.BackendNodeID != 0 {
+		_ = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			node, err := dom.DescribeNode().WithBackendNodeID(ev.BackendNodeID).Do(ctx)
+			if err != nil {
+				return err
+			}
+			chooserErr.Selector = selectorForNode(node)
+			return nil
+		}))
+	}
+	return chooserErr
+}
+
+// selectorForNode строит CSS селектор для поля по его атрибутам
+func selectorForNode(node *cdp.Node) string {
+	attrs := make(map[string]string)
+	for i := 0; i+1 < len(node.Attributes); i += 2 {
+		attrs[node.Attributes[i]] = node.Attributes[i+1]
+	}
+	tag := strings.ToLower(node.NodeName)
+	if id := attrs["id"]; id != "" {
+		return fmt.Sprintf(`%s[id="%s"]`, tag, id)
+	}
+	if name := attrs["name"]; name != "" {
+		return fmt.Sprintf(`%s[name="%s"]`, tag, name)
+	}
+	return tag + `[type="file"]`
+}
+
+// UploadFile передает файлы в поле <input type="file"> без открытия системного диалога.
+// Если selector пустой, используется поле из последнего перехваченного диалога.
+func (b *Browser) UploadFile(selector string, paths []string) error {
+	// Проверяем, не отменен ли контекст браузера
+	select {
+	case <-b.ctx.Done():
+		return fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("не указаны файлы для загрузки")
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.actionTimeout(20*time.Second))
+	defer cancel()
+
+	if selector == "" {
+		b.eventsMu.Lock()
+		ev := b.fileChooser
+		b.eventsMu.Unlock()
+		if !b.caps.FileChooser {
+			return fmt.Errorf("не указан selector поля загрузки, а перехват диалога выбора файла в этой версии браузера отключен")
+		}
+		if ev == nil || ev.BackendNodeID == 0 {
+			return fmt.Errorf("не указан selector поля загрузки и нет перехваченного диалога выбора файла")
+		}
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return dom.SetFileInputFiles(paths).WithBackendNodeID(ev.BackendNodeID).Do(ctx)
+		}))
+	}
+
+	if err := chromedp.Run(ctx,
+		chromedp.SetUploadFiles(selector, paths, chromedp.ByQuery),
+		chromedp.Sleep(500*time.Millisecond),
+	); err != nil {
+		return fmt.Errorf("failed to upload files to %s: %w", selector, err)
+	}
+	return nil
+}
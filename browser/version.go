@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+)
+
+// Version возвращает версию запущенного браузера (например, "Chrome/120.0.6099.109")
+func (b *Browser) Version() (string, error) {
+	select {
+	case <-b.ctx.Done():
+		return "", fmt.Errorf("browser context was canceled - браузер недоступен")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var product string
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		_, product, _, _, _, err = cdpbrowser.GetVersion().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to get browser version: %w", err)
+	}
+	return product, nil
+}
+
+// Headless сообщает, запущен ли браузер без окна
+func (b *Browser) Headless() bool {
+	return b.headless
+}
@@ -0,0 +1,47 @@
+package browser
+
+// isVisibleJS - функция isVisible(el) из общих функций pageHelpersJS.
+// Элемент видим, только если пользователь действительно может его увидеть:
+// не скрыт через display/visibility, не меньше 4×4 px, итоговая прозрачность
+// с учетом родителей не ниже 0.1, не лежит внутри aria-hidden/inert и не вынесен
+// абсолютным позиционированием за границы страницы. Такие элементы сайты
+// используют как ловушки для ботов (honeypot), и взаимодействие с ними выдает агента.
+const isVisibleJS = `function isVisible(el) {
+				if (!el || !el.isConnected) return false;
+				const style = window.getComputedStyle(el);
+				if (style.display === 'none' || style.visibility === 'hidden' || style.visibility === 'collapse') return false;
+				const rect = el.getBoundingClientRect();
+				if (el.offsetWidth < 4 || el.offsetHeight < 4 || rect.width < 4 || rect.height < 4) return false;
+				if (el.closest('[aria-hidden="true"], [inert]')) return false;
+				let opacity = 1;
+				let positioned = false;
+				for (let node = el; node && node.nodeType === 1; node = node.parentElement) {
+					const s = node === el ? style : window.getComputedStyle(node);
+					opacity *= parseFloat(s.opacity || '1');
+					if (s.position === 'absolute' || s.position === 'fixed') positioned = true;
+				}
+				if (opacity < 0.1) return false;
+				// Абсолютно позиционированный элемент за границами страницы (left: -9999px) до пользователя не доходит
+				if (positioned) {
+					const left = rect.left + window.scrollX;
+					const top = rect.top + window.scrollY;
+					const pageWidth = Math.max(document.documentElement.scrollWidth, window.innerWidth);
+					const pageHeight = Math.max(document.documentElement.scrollHeight, window.innerHeight);
+					if (left + rect.width <= 0 || top + rect.height <= 0 || left >= pageWidth || top >= pageHeight) return false;
+				}
+				return true;
+			}`
+
+// isRevealableJS - функция isRevealable(el) из общих функций pageHelpersJS: элемент
+// есть в разметке и занимает место в раскладке, но isVisible его пока не пропускает -
+// нулевая прозрачность, visibility: hidden или нулевой размер до прокрутки к нему
+// (анимация появления, ленивая отрисовка). Элементы внутри aria-hidden/inert и
+// вынесенные за границы страницы (ловушки для ботов, закрытое боковое меню) прокрутка
+// не покажет, поэтому они не подходят.
+const isRevealableJS = `function isRevealable(el) {
+				if (!el || !el.isConnected || el.getClientRects().length === 0) return false;
+				if (el.closest('[aria-hidden="true"], [inert]')) return false;
+				const rect = el.getBoundingClientRect();
+				const pageWidth = Math.max(document.documentElement.scrollWidth, window.innerWidth);
+				return rect.right + window.scrollX > 0 && rect.bottom + window.scrollY > 0 && rect.left + window.scrollX < pageWidth;
+			}`
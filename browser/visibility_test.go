@@ -0,0 +1,62 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+// honeypotPage - ловушки для ботов с тем же placeholder и текстом кнопки стоят раньше настоящих
+const honeypotPage = `<form onsubmit="return false">
+	<input name="trap-tiny" placeholder="Email" style="width:1px;height:1px;padding:0;border:0">
+	<input name="trap-transparent" placeholder="Email" style="opacity:0.01">
+	<input name="trap-offscreen" placeholder="Email" style="position:absolute;left:-9999px">
+	<div aria-hidden="true"><input name="trap-aria" placeholder="Email"></div>
+	<div style="opacity:0.05"><button onclick="document.title = 'trap'">Подписаться</button></div>
+	<input name="email" placeholder="Email">
+	<button onclick="document.title = 'ok'">Подписаться</button>
+</form>`
+
+func TestHoneypotsSkipped(t *testing.T) {
+	b := newTestBrowser(t)
+	if err := b.Navigate(servePage(t, honeypotPage)); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := b.GetPageContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range content.Inputs {
+		if input.Name != "email" {
+			t.Errorf("page analysis lists honeypot field %q", input.Name)
+		}
+	}
+	if len(content.Inputs) != 1 {
+		t.Errorf("inputs = %+v, want only the real email field", content.Inputs)
+	}
+
+	if err := b.FillInputByPlaceholder("Email", "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	var filled map[string]string
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(`Object.fromEntries(Array.from(document.querySelectorAll('input')).map(i => [i.name, i.value]))`, &filled)); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range filled {
+		if want := map[bool]string{true: "user@example.com"}[name == "email"]; value != want {
+			t.Errorf("field %s = %q, want %q", name, value, want)
+		}
+	}
+
+	if err := b.ClickByText("Подписаться"); err != nil {
+		t.Fatal(err)
+	}
+	var title string
+	if err := chromedp.Run(b.ctx, chromedp.Title(&title)); err != nil {
+		t.Fatal(err)
+	}
+	if title != "ok" {
+		t.Errorf("clicked button set title %q, want the visible button (ok)", title)
+	}
+}
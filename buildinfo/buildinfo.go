@@ -0,0 +1,95 @@
+// Package buildinfo сообщает версию сборки агента.
+//
+// Версию и коммит можно задать при сборке:
+//
+//	go build -ldflags "-X github.com/Angabebr/Golang-AI-agent/buildinfo.Version=1.2.0 -X github.com/Angabebr/Golang-AI-agent/buildinfo.Commit=$(git rev-parse --short HEAD)"
+//
+// Если они не заданы, коммит и дата берутся из информации VCS, которую Go
+// встраивает в бинарник (runtime/debug.ReadBuildInfo).
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Значения, задаваемые через -ldflags "-X"
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info описывает сборку агента
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // собрано из рабочей копии с незакоммиченными изменениями
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает информацию о текущей сборке
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && isReleaseVersion(bi.Main.Version) {
+		info.Version = strings.TrimPrefix(bi.Main.Version, "v")
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	return info
+}
+
+// isReleaseVersion отсекает "(devel)" и псевдоверсии вида v0.0.0-20240101120000-abcdef123456,
+// которые Go подставляет для сборок из рабочей копии - коммит и так показывается отдельно
+func isReleaseVersion(v string) bool {
+	if v == "" || v == "(devel)" || strings.Contains(v, "+") {
+		return false
+	}
+	return strings.Count(v, "-") < 2
+}
+
+// String возвращает однострочное описание версии
+func (i Info) String() string {
+	s := "Golang-AI-agent " + i.Version
+	var details []string
+	if i.Commit != "" {
+		commit := i.Commit
+		if i.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, commit)
+	}
+	if i.Date != "" {
+		details = append(details, i.Date)
+	}
+	details = append(details, i.GoVersion)
+	return fmt.Sprintf("%s (%s)", s, strings.Join(details, ", "))
+}
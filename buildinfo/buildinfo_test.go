@@ -0,0 +1,53 @@
+package buildinfo
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsReleaseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.2.0", true},
+		{"v1.2.0-rc.1", true},
+		{"", false},
+		{"(devel)", false},
+		{"v0.0.0-20240101120000-abcdef123456", false},
+		{"v1.2.1-0.20240101120000-abcdef123456", false},
+		{"v1.2.0+dirty", false},
+	}
+	for _, tt := range tests {
+		if got := isReleaseVersion(tt.version); got != tt.want {
+			t.Errorf("isReleaseVersion(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestInfoString(t *testing.T) {
+	info := Info{Version: "1.2.0", Commit: "abc123def456", Date: "2024-01-31T12:00:00Z", Modified: true, GoVersion: "go1.21.5"}
+	if got, want := info.String(), "Golang-AI-agent 1.2.0 (abc123def456-dirty, 2024-01-31T12:00:00Z, go1.21.5)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := (Info{Version: "dev", GoVersion: "go1.21.5"}).String(), "Golang-AI-agent dev (go1.21.5)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestGetPrefersLdflags(t *testing.T) {
+	defer func(version, commit string) { Version, Commit = version, commit }(Version, Commit)
+	Version, Commit = "1.2.0", "0123456789abcdef0123"
+
+	info := Get()
+	if info.Version != "1.2.0" || info.GoVersion != runtime.Version() {
+		t.Errorf("Get() = %+v, want the ldflags version and the running Go version", info)
+	}
+	if info.Commit != "0123456789ab" {
+		t.Errorf("Commit = %q, want the ldflags commit shortened to 12 characters", info.Commit)
+	}
+	if !strings.HasPrefix(info.String(), "Golang-AI-agent 1.2.0 (0123456789ab") {
+		t.Errorf("String() = %q", info.String())
+	}
+}
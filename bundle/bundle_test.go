@@ -0,0 +1,104 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// Пароль из ответа модели, истории и снимка страницы не попадает ни в один файл пакета,
+// а записи остаются корректным JSON
+func TestPasswordNeverReachesBundle(t *testing.T) {
+	const password = `hunter2"pass`
+	const otp = "4821"
+	const shortPIN = "739"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision, _ := json.Marshal(map[string]string{"action": "fill", "text": "Пароль", "value": password})
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": string(decision)}}},
+		})
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "20240131-120000")
+	rec, err := Start(dir, Manifest{Task: "войди в банк"}, map[string]string{"OPENAI_API_KEY": "***"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec.Transport()}
+	body := `{"messages":[{"role":"user","content":"история: код ` + otp + ` введен, PIN ` + shortPIN + `"}]}`
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	rec.RecordIteration(Iteration{
+		Iteration: 1,
+		Page:      json.RawMessage(`{"url":"https://bank.example"}`),
+		History:   []string{"ОТВЕТ ПОЛЬЗОВАТЕЛЯ: " + otp},
+		Decision:  &ai.Decision{Action: "fill", Text: "PIN", Value: shortPIN},
+	}, `<input type="password" value="`+password+`">`)
+	// Секреты становятся известны после записи: ответ с решением fill записан до выполнения
+	rec.Redact(password)
+	rec.Redact(otp)
+	rec.Redact(shortPIN)
+
+	path, err := rec.Finish("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		for _, secret := range []string{password, jsonEscape(password), jsonEscape(jsonEscape(password)), otp} {
+			if bytes.Contains(data, []byte(secret)) {
+				t.Errorf("%s contains secret %q:\n%s", f.Name, secret, data)
+			}
+		}
+		if strings.HasSuffix(f.Name, ".jsonl") {
+			for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+				if len(line) > 0 && !json.Valid(line) {
+					t.Errorf("%s: invalid JSON after redaction: %s", f.Name, line)
+				}
+			}
+		}
+		if f.Name == iterationsFile && !bytes.Contains(data, []byte(`"value":"***"`)) {
+			t.Errorf("short PIN in the decision was not redacted: %s", data)
+		}
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	secrets := []string{"s3cret", "42"}
+	tests := map[string]string{
+		`{"action":"fill","value":"s3cret"}`:   `{"action":"fill","value":"***"}`,
+		`{"action":"fill", "value": "42"}`:     `{"action":"fill", "value": "***"}`,
+		`ввел s3cret в поле`:                   `ввел *** в поле`,
+		`страница 42 из 420`:                   `страница 42 из 420`,
+		`{"action":"fill","value":"42 штуки"}`: `{"action":"fill","value":"42 штуки"}`,
+	}
+	for in, want := range tests {
+		if got := redactText(in, secrets); got != want {
+			t.Errorf("redactText(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// minRedactRunes - секрет короче заменяется только как значение поля "value" решения:
+// замена подстроки из 1-3 символов испортила бы весь остальной текст пакета
+const minRedactRunes = 4
+
+// redactText заменяет секреты в тексте на ***: значение поля "value" (решение модели
+// внутри ответа) - при любой длине, остальные вхождения - для секретов от minRedactRunes
+func redactText(s string, secrets []string) string {
+	for _, secret := range secrets {
+		escaped := jsonEscape(secret)
+		valueField := regexp.MustCompile(`("value"\s*:\s*")` + regexp.QuoteMeta(escaped) + `"`)
+		s = valueField.ReplaceAllString(s, `${1}***"`)
+		if utf8.RuneCountInString(secret) < minRedactRunes {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, "***")
+		if escaped != secret {
+			s = strings.ReplaceAll(s, escaped, "***")
+		}
+	}
+	return s
+}
+
+// jsonEscape - секрет в том виде, в каком он стоит внутри строки JSON
+func jsonEscape(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+// redactJSONLine убирает секреты из строк одной записи JSONL, не ломая JSON: текст
+// ответа модели - тоже строка, и решение fill внутри него очищается вместе с ней
+func redactJSONLine(line []byte, secrets []string) []byte {
+	if len(secrets) == 0 {
+		return line
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return []byte(redactText(string(line), secrets))
+	}
+	changed := false
+	v = redactValue(v, secrets, &changed)
+	if !changed {
+		return line
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return []byte(redactText(string(line), secrets))
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+func redactValue(v interface{}, secrets []string, changed *bool) interface{} {
+	switch val := v.(type) {
+	case string:
+		redacted := redactText(val, secrets)
+		if redacted != val {
+			*changed = true
+		}
+		return redacted
+	case map[string]interface{}:
+		for key, item := range val {
+			if s, ok := item.(string); ok && key == "value" && isSecret(s, secrets) {
+				val[key] = "***"
+				*changed = true
+				continue
+			}
+			val[key] = redactValue(item, secrets, changed)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactValue(item, secrets, changed)
+		}
+	}
+	return v
+}
+
+func isSecret(s string, secrets []string) bool {
+	s = strings.TrimSpace(s)
+	for _, secret := range secrets {
+		if s == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// redactDir убирает секреты из всех файлов каталога пакета: JSONL - по записям,
+// остальное (снимки страниц, настройки) - как текст
+func redactDir(dir string, secrets []string) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var redacted []byte
+		if strings.HasSuffix(path, ".jsonl") {
+			lines := bytes.Split(data, []byte("\n"))
+			for i, line := range lines {
+				if len(bytes.TrimSpace(line)) > 0 {
+					lines[i] = redactJSONLine(line, secrets)
+				}
+			}
+			redacted = bytes.Join(lines, []byte("\n"))
+		} else {
+			redacted = []byte(redactText(string(data), secrets))
+		}
+		if bytes.Equal(redacted, data) {
+			return nil
+		}
+		return os.WriteFile(path, redacted, info.Mode().Perm())
+	})
+}
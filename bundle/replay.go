@@ -0,0 +1,191 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Angabebr/Golang-AI-agent/ai"
+)
+
+// IterationResult - итог воспроизведения одного шага
+type IterationResult struct {
+	Iteration int
+	Recorded  string   // решение из пакета
+	Replayed  string   // решение текущего кода
+	Match     bool     // решения совпали полностью
+	Drift     []string // отличия промпта от записанного
+}
+
+// Report - итог воспроизведения пакета
+type Report struct {
+	Task       string
+	Iterations []IterationResult
+}
+
+// Mismatches возвращает число шагов, на которых решение изменилось
+func (r *Report) Mismatches() int {
+	n := 0
+	for _, it := range r.Iterations {
+		if !it.Match {
+			n++
+		}
+	}
+	return n
+}
+
+// Replay воспроизводит логику решений по пакету (zip или каталог): на каждом шаге
+// модель получает записанную страницу и историю, а вместо запроса к API - записанный
+// ответ. Браузер и сеть не нужны, поэтому пакет из отчета об ошибке можно запускать
+// в тестах: изменившееся решение или промпт видны в Report.
+func Replay(ctx context.Context, path string) (*Report, error) {
+	read, closeBundle, err := openBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBundle()
+
+	snapshots, err := loadSnapshots(read)
+	if err != nil {
+		return nil, err
+	}
+	manifest := snapshots.manifest
+	var exchanges []ai.Exchange
+	if err := readJSONL(read, exchangesFile, func(line []byte) error {
+		var exchange ai.Exchange
+		err := json.Unmarshal(line, &exchange)
+		exchanges = append(exchanges, exchange)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	client := ai.NewClient("replay", manifest.Model)
+	if manifest.ContextTokens > 0 {
+		client.SetContextTokens(manifest.ContextTokens)
+	}
+	client.SetSafeMode(manifest.SafeMode)
+	client.SetHandoffEnabled(manifest.Handoff)
+	client.SetResultSchema(manifest.ResultSchema)
+	client.SetPreferredTargets(manifest.Preferred)
+	replayer := ai.NewReplayer()
+	client.SetTransport(replayer)
+
+	report := &Report{Task: manifest.Task}
+	for snapshots.Remaining() > 0 {
+		page, err := snapshots.page()
+		if err != nil {
+			return nil, err
+		}
+		it := snapshots.Current()
+		from, to := it.Exchanges[0], it.Exchanges[1]
+		if from < 0 || to > len(exchanges) || from > to {
+			return nil, fmt.Errorf("шаг %d: обмены %d..%d вне записи (%d)", it.Iteration, from, to, len(exchanges))
+		}
+		replayer.Serve(exchanges[from:to])
+		client.SetSystemPrompt(it.SystemPrompt)
+		if it.PageTextRequested {
+			client.RequestPageText()
+		}
+
+		decision, err := client.MakeDecision(ctx, manifest.Task, page, it.History, 500)
+		result := IterationResult{
+			Iteration: it.Iteration,
+			Recorded:  describeOutcome(it.Decision, it.Error),
+			Drift:     replayer.Drift(),
+		}
+		if err != nil {
+			result.Replayed = describeOutcome(nil, err.Error())
+			result.Match = it.Decision == nil && it.Error == err.Error()
+		} else {
+			result.Replayed = describeOutcome(decision, "")
+			result.Match = sameDecision(it.Decision, decision)
+		}
+		report.Iterations = append(report.Iterations, result)
+	}
+	return report, nil
+}
+
+func sameDecision(recorded, replayed *ai.Decision) bool {
+	if recorded == nil || replayed == nil {
+		return recorded == replayed
+	}
+	a, _ := json.Marshal(recorded)
+	b, _ := json.Marshal(replayed)
+	return bytes.Equal(a, b)
+}
+
+func describeOutcome(decision *ai.Decision, errText string) string {
+	if decision == nil {
+		return "ошибка: " + errText
+	}
+	parts := []string{decision.Action}
+	for _, field := range []string{decision.Text, decision.Selector, decision.Value, decision.URL} {
+		if field != "" {
+			parts = append(parts, fmt.Sprintf("%q", field))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// openBundle возвращает функцию чтения файлов пакета из zip-архива или каталога
+func openBundle(path string) (func(name string) ([]byte, error), func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("пакет недоступен: %w", err)
+	}
+	if info.IsDir() {
+		return func(name string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		}, func() {}, nil
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("не удалось открыть пакет: %w", err)
+	}
+	return func(name string) ([]byte, error) {
+		f, err := archive.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}, func() { archive.Close() }, nil
+}
+
+func readJSON(read func(string) ([]byte, error), name string, v interface{}) error {
+	data, err := read(name)
+	if err != nil {
+		return fmt.Errorf("в пакете нет %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("некорректный %s: %w", name, err)
+	}
+	return nil
+}
+
+func readJSONL(read func(string) ([]byte, error), name string, each func([]byte) error) error {
+	data, err := read(name)
+	if err != nil {
+		return fmt.Errorf("в пакете нет %s: %w", name, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := each(scanner.Bytes()); err != nil {
+			return fmt.Errorf("некорректная строка %d в %s: %w", n, name, err)
+		}
+	}
+	return scanner.Err()
+}
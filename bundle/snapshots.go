@@ -0,0 +1,132 @@
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Angabebr/Golang-AI-agent/browser"
+)
+
+// ErrSnapshotsExhausted - записанные шаги закончились: запуск ушел дальше записи
+var ErrSnapshotsExhausted = errors.New("в пакете больше нет записанных страниц")
+
+// Snapshots - записанные страницы пакета по шагам вместо живого браузера. Методы
+// повторяют методы browser.Browser, которыми агент читает страницу: как и агент,
+// вызывающий сначала запрашивает быструю информацию и при ошибке - полный анализ,
+// и получает ту страницу, которую на этом шаге видела модель. Так последовательность
+// решений упавшей задачи воспроизводится без сайта.
+type Snapshots struct {
+	manifest   Manifest
+	iterations []Iteration
+	next       int // шаг, страница которого будет выдана следующей
+}
+
+// OpenSnapshots читает записанные страницы пакета (zip или каталог)
+func OpenSnapshots(path string) (*Snapshots, error) {
+	read, closeBundle, err := openBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBundle()
+	return loadSnapshots(read)
+}
+
+func loadSnapshots(read func(string) ([]byte, error)) (*Snapshots, error) {
+	s := &Snapshots{}
+	if err := readJSON(read, manifestFile, &s.manifest); err != nil {
+		return nil, err
+	}
+	if err := readJSONL(read, iterationsFile, func(line []byte) error {
+		var it Iteration
+		err := json.Unmarshal(line, &it)
+		s.iterations = append(s.iterations, it)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Task возвращает задачу записанного запуска
+func (s *Snapshots) Task() string {
+	return s.manifest.Task
+}
+
+// Remaining возвращает число еще не выданных шагов
+func (s *Snapshots) Remaining() int {
+	return len(s.iterations) - s.next
+}
+
+// Current возвращает записанный шаг, страница которого выдана последней: историю,
+// которую видела модель, и ее решение для сравнения. nil - страниц еще не выдавалось.
+func (s *Snapshots) Current() *Iteration {
+	if s.next == 0 {
+		return nil
+	}
+	return &s.iterations[s.next-1]
+}
+
+// GetQuickPageInfo выдает страницу следующего шага, если на нем модель получила быструю
+// информацию. Если на шаге был полный анализ, возвращает ошибку, не переходя к следующему
+// шагу, - как у агента, следующий вызов GetPageContent выдаст этот шаг.
+func (s *Snapshots) GetQuickPageInfo() (*browser.QuickPageInfo, error) {
+	it, err := s.peek()
+	if err != nil {
+		return nil, err
+	}
+	if it.Kind == "full" {
+		return nil, fmt.Errorf("шаг %d записан с полным анализом страницы", it.Iteration)
+	}
+	var page browser.QuickPageInfo
+	if err := json.Unmarshal(it.Page, &page); err != nil {
+		return nil, fmt.Errorf("шаг %d: %w", it.Iteration, err)
+	}
+	s.next++
+	return &page, nil
+}
+
+// GetPageContent выдает страницу следующего шага с полным анализом
+func (s *Snapshots) GetPageContent() (*browser.PageContent, error) {
+	it, err := s.peek()
+	if err != nil {
+		return nil, err
+	}
+	if it.Kind != "full" {
+		return nil, fmt.Errorf("шаг %d записан с быстрой информацией о странице", it.Iteration)
+	}
+	var page browser.PageContent
+	if err := json.Unmarshal(it.Page, &page); err != nil {
+		return nil, fmt.Errorf("шаг %d: %w", it.Iteration, err)
+	}
+	s.next++
+	return &page, nil
+}
+
+// GetCurrentURL возвращает адрес страницы последнего выданного шага
+func (s *Snapshots) GetCurrentURL() (string, error) {
+	it := s.Current()
+	if it == nil {
+		return "", fmt.Errorf("страницы пакета еще не запрашивались")
+	}
+	return it.URL, nil
+}
+
+// page выдает страницу следующего шага в том виде, в каком ее получила модель
+func (s *Snapshots) page() (interface{}, error) {
+	it, err := s.peek()
+	if err != nil {
+		return nil, err
+	}
+	if it.Kind == "full" {
+		return s.GetPageContent()
+	}
+	return s.GetQuickPageInfo()
+}
+
+func (s *Snapshots) peek() (*Iteration, error) {
+	if s.next >= len(s.iterations) {
+		return nil, ErrSnapshotsExhausted
+	}
+	return &s.iterations[s.next], nil
+}
@@ -0,0 +1,249 @@
+// Package console настраивает вывод агента под возможности терминала:
+// принудительно включает UTF-8 и заменяет эмодзи на ASCII-маркеры там,
+// где они не отображаются (старые консоли Windows, перенаправленный вывод).
+package console
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Style определяет, как выводятся маркеры сообщений
+type Style int
+
+const (
+	StyleEmoji Style = iota // эмодзи как есть
+	StylePlain              // ASCII-маркеры ([OK], [WARN] ...)
+)
+
+func (s Style) String() string {
+	if s == StylePlain {
+		return "plain"
+	}
+	return "emoji"
+}
+
+// markers - таблица замены эмодзи на ASCII-маркеры
+var markers = map[rune]string{
+	'✅': "[OK]",
+	'❌': "[ERR]",
+	'⚠': "[WARN]",
+	'❓': "[?]",
+	'🚫': "[BLOCKED]",
+	'🛑': "[STOP]",
+	'🤖': "[AGENT]",
+	'🚀': "[START]",
+	'🌐': "[WEB]",
+	'📁': "[DIR]",
+	'ℹ': "[INFO]",
+	'🎯': "[>]",
+	'💭': "[THINK]",
+	'🖱': "[CLICK]",
+	'✍': "[FILL]",
+	'⌨': "[KEY]",
+	'🔄': "[TAB]",
+	'⏳': "[WAIT]",
+	'⏱': "[TIME]",
+	'📄': "[PAGE]",
+	'📋': "[SUMMARY]",
+	'📍': "[URL]",
+	'🔍': "[DEBUG]",
+	'🛡': "[SAFE]",
+	'🐢': "[SLOW]",
+	'📎': "[FILE]",
+	'♻': "[RESUME]",
+	'🔐': "[OTP]",
+	'👤': "[PROFILE]",
+	'🔁': "[RETRY]",
+	'▶': "[PLAY]",
+	'⏸': "[PAUSE]",
+	'📝': "[HELP]",
+	'📖': "[HELP]",
+	'📥': "[DOWNLOAD]",
+	'🖨': "[PDF]",
+	'🖍': "[SELECT]",
+	'🎚': "[RANGE]",
+	'☑': "[CHECK]",
+	'📜': "[SCROLL]",
+	'📚': "[PAGES]",
+	'🧩': "[TEMPLATE]",
+	'📑': "[REPORT]",
+	'🔓': "[LOGGED-IN]",
+	'🔒': "[LOGGED-OUT]",
+	'🧭': "[ROUTE]",
+	'⏪': "[BACK]",
+	'🧹': "[CLEANUP]",
+	'🙅': "[DECLINED]",
+	'🙋': "[HANDOFF]",
+	'🐌': "[SLOW-LLM]",
+	'👀': "[WATCH]",
+	'🔎': "[CHECK]",
+	'🔔': "[ALERT]",
+	'🔕': "[COOLDOWN]",
+	'💡': "[TIP]",
+	'⚙': "[CMD]",
+	'👋': "[BYE]",
+	'•': "*",
+	'→': "->",
+}
+
+// Marker возвращает ASCII-замену для эмодзи или пустую строку, если замены нет
+func Marker(r rune) string {
+	return markers[r]
+}
+
+// Plain заменяет эмодзи в строке на ASCII-маркеры
+func Plain(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		writePlainRune(&sb, r)
+	}
+	return sb.String()
+}
+
+func writePlainRune(sb *strings.Builder, r rune) {
+	if r == '\uFE0F' || r == '\u200D' {
+		// Селекторы вариантов и ZWJ без самого эмодзи не нужны
+		return
+	}
+	if m, ok := markers[r]; ok {
+		sb.WriteString(m)
+		return
+	}
+	if isEmoji(r) {
+		sb.WriteString("[*]")
+		return
+	}
+	sb.WriteRune(r)
+}
+
+func isEmoji(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x2300 && r <= 0x23FF)
+}
+
+// plainWriter заменяет эмодзи на лету; неполные UTF-8 последовательности
+// на границе записи откладываются до следующего вызова Write
+type plainWriter struct {
+	out     io.Writer
+	pending []byte
+}
+
+func (w *plainWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+
+	// Отрезаем незавершенный символ в конце буфера
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[cut:]...)
+
+	if _, err := io.WriteString(w.out, Plain(string(data[:cut]))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+var (
+	mu       sync.Mutex
+	active   = StyleEmoji
+	restores []func()
+)
+
+// Setup определяет возможности терминала и настраивает вывод.
+// Переменная CONSOLE_STYLE=plain|emoji позволяет выбрать стиль явно.
+func Setup() Style {
+	mu.Lock()
+	defer mu.Unlock()
+
+	enableUTF8()
+
+	style := detectStyle()
+	switch strings.ToLower(os.Getenv("CONSOLE_STYLE")) {
+	case "plain", "ascii":
+		style = StylePlain
+	case "emoji":
+		style = StyleEmoji
+	}
+
+	if style == StylePlain {
+		os.Stdout = redirect(os.Stdout)
+		os.Stderr = redirect(os.Stderr)
+	}
+	active = style
+	return style
+}
+
+// Current возвращает активный стиль вывода
+func Current() Style {
+	mu.Lock()
+	defer mu.Unlock()
+	return active
+}
+
+// Restore дописывает буферизованный вывод и возвращает исходные потоки.
+// Вызывайте перед os.Exit, чтобы не потерять последние сообщения.
+func Restore() {
+	mu.Lock()
+	defer mu.Unlock()
+	for i := len(restores) - 1; i >= 0; i-- {
+		restores[i]()
+	}
+	restores = nil
+	active = StyleEmoji
+}
+
+// IsTerminal сообщает, подключен ли файл к терминалу (а не к pipe или файлу)
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func detectStyle() Style {
+	// Перенаправленный вывод всегда в простом стиле, чтобы логи были чистыми
+	if !IsTerminal(os.Stdout) {
+		return StylePlain
+	}
+	if !emojiSupported() {
+		return StylePlain
+	}
+	return StyleEmoji
+}
+
+// redirect подменяет файл на pipe, вывод из которого проходит через plainWriter
+func redirect(original *os.File) *os.File {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return original
+	}
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&plainWriter{out: original}, r)
+		close(done)
+	}()
+
+	restores = append(restores, func() {
+		w.Close()
+		<-done
+		r.Close()
+		if os.Stdout == w {
+			os.Stdout = original
+		}
+		if os.Stderr == w {
+			os.Stderr = original
+		}
+	})
+	return w
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package console
+
+import (
+	"os"
+	"strings"
+)
+
+// enableUTF8 - в Unix-терминалах UTF-8 используется по умолчанию
+func enableUTF8() {}
+
+// emojiSupported проверяет TERM и локаль: текстовая консоль Linux и не-UTF-8 локали эмодзи не покажут
+func emojiSupported() bool {
+	term := os.Getenv("TERM")
+	if term == "" || term == "dumb" || term == "linux" {
+		return false
+	}
+
+	locale := os.Getenv("LC_ALL")
+	if locale == "" {
+		locale = os.Getenv("LC_CTYPE")
+	}
+	if locale == "" {
+		locale = os.Getenv("LANG")
+	}
+	if locale != "" {
+		lower := strings.ToLower(locale)
+		return strings.Contains(lower, "utf-8") || strings.Contains(lower, "utf8")
+	}
+	return true
+}
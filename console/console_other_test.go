@@ -0,0 +1,32 @@
+//go:build !windows
+
+package console
+
+import "testing"
+
+func TestEmojiSupported(t *testing.T) {
+	tests := []struct {
+		name              string
+		term, lcAll, lang string
+		want              bool
+	}{
+		{"utf-8 terminal", "xterm-256color", "", "ru_RU.UTF-8", true},
+		{"no locale", "xterm-256color", "", "", true},
+		{"dumb terminal", "dumb", "", "en_US.UTF-8", false},
+		{"linux console", "linux", "", "en_US.UTF-8", false},
+		{"no TERM", "", "", "en_US.UTF-8", false},
+		{"legacy locale", "xterm", "", "ru_RU.KOI8-R", false},
+		{"LC_ALL wins", "xterm", "C", "ru_RU.UTF-8", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_CTYPE", "")
+			t.Setenv("LANG", tt.lang)
+			if got := emojiSupported(); got != tt.want {
+				t.Errorf("emojiSupported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package console
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"✅ Задача выполнена", "[OK] Задача выполнена"},
+		{"⚠️  Не удалось сохранить отчет", "[WARN]  Не удалось сохранить отчет"},
+		{"🖱️ Клик → «Найти»", "[CLICK] Клик -> «Найти»"},
+		{"🦄 неизвестный эмодзи", "[*] неизвестный эмодзи"},
+		{"👨‍💻 ZWJ", "[*][*] ZWJ"},
+		{"Кириллица и 中文 без изменений", "Кириллица и 中文 без изменений"},
+	}
+	for _, tt := range tests {
+		if got := Plain(tt.in); got != tt.want {
+			t.Errorf("Plain(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlainWriterSplitRunes(t *testing.T) {
+	var out strings.Builder
+	w := &plainWriter{out: &out}
+	msg := []byte("✅ Готово 🙅\n")
+	// Запись по байту режет эмодзи и кириллицу посередине символа
+	for i := range msg {
+		if n, err := w.Write(msg[i : i+1]); n != 1 || err != nil {
+			t.Fatalf("Write() = %d, %v", n, err)
+		}
+	}
+	if want := "[OK] Готово [DECLINED]\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if len(w.pending) != 0 {
+		t.Errorf("pending = %q after complete runes", w.pending)
+	}
+}
+
+func TestMarkersAreASCII(t *testing.T) {
+	for r, marker := range markers {
+		for _, c := range marker {
+			if c > 127 {
+				t.Errorf("marker for %q = %q contains non-ASCII %q", r, marker, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package console
+
+import (
+	"os"
+	"syscall"
+)
+
+const utf8CodePage = 65001
+
+var (
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleOutputCP = kernel32.NewProc("SetConsoleOutputCP")
+	procSetConsoleCP       = kernel32.NewProc("SetConsoleCP")
+)
+
+// enableUTF8 переключает кодовую страницу консоли на UTF-8, чтобы кириллица не превращалась в кракозябры
+func enableUTF8() {
+	procSetConsoleOutputCP.Call(uintptr(utf8CodePage))
+	procSetConsoleCP.Call(uintptr(utf8CodePage))
+}
+
+// emojiSupported - эмодзи отображаются только в Windows Terminal и терминалах с поддержкой VT (например, VS Code)
+func emojiSupported() bool {
+	if os.Getenv("WT_SESSION") != "" {
+		return true
+	}
+	if os.Getenv("TERM_PROGRAM") == "vscode" {
+		return true
+	}
+	// Классический conhost с устаревшей кодовой страницей
+	return false
+}
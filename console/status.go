@@ -0,0 +1,109 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	statusDelay = 300 * time.Millisecond // быстрые операции строку статуса не показывают
+	statusTick  = 120 * time.Millisecond
+)
+
+var statusFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+var (
+	statusMu       sync.Mutex
+	statusDisabled bool
+)
+
+var (
+	lineMu    sync.Mutex
+	lineWidth int // ширина строки статуса на экране; 0 - строки нет
+)
+
+// SetStatusEnabled включает или выключает анимированную строку статуса (AGENT_SPINNER)
+func SetStatusEnabled(enabled bool) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statusDisabled = !enabled
+}
+
+// StartStatus показывает анимированную строку статуса ("думаю... 4s") до вызова
+// возвращенной функции, которая стирает строку. В простом стиле вывода и при
+// выводе не в терминал (pipe, файл) строка не показывается.
+func StartStatus(text string) (stop func()) {
+	statusMu.Lock()
+	disabled := statusDisabled
+	statusMu.Unlock()
+	if disabled || Current() == StylePlain || !IsTerminal(os.Stdout) {
+		return func() {}
+	}
+
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		start := time.Now()
+		select {
+		case <-quit:
+			return
+		case <-time.After(statusDelay):
+		}
+
+		ticker := time.NewTicker(statusTick)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			drawStatus(fmt.Sprintf("%s %s %ds", statusFrames[frame%len(statusFrames)], text, int(time.Since(start).Seconds())))
+			select {
+			case <-quit:
+				lineMu.Lock()
+				clearStatusLocked()
+				lineMu.Unlock()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+			<-done
+		})
+	}
+}
+
+// Printf печатает сообщение, не смешивая его со строкой статуса: строка стирается,
+// сообщение выводится с начала строки, а статус рисуется заново следующим кадром.
+// Сообщения, которые могут появиться во время StartStatus (повтор запроса к модели
+// и т.п.), печатайте через Printf, а не fmt.Printf.
+func Printf(format string, args ...interface{}) {
+	lineMu.Lock()
+	defer lineMu.Unlock()
+	clearStatusLocked()
+	fmt.Fprintf(os.Stdout, format, args...)
+}
+
+// drawStatus рисует кадр строки статуса поверх предыдущего
+func drawStatus(line string) {
+	lineMu.Lock()
+	defer lineMu.Unlock()
+	// Стираем строку пробелами, а не ANSI-последовательностями: так работает и в conhost
+	fmt.Fprintf(os.Stdout, "\r%s%s", line, strings.Repeat(" ", max(lineWidth-utf8.RuneCountInString(line), 0)))
+	lineWidth = utf8.RuneCountInString(line)
+}
+
+// clearStatusLocked стирает строку статуса, если она на экране (вызывается под lineMu)
+func clearStatusLocked() {
+	if lineWidth == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stdout, "\r%s\r", strings.Repeat(" ", lineWidth))
+	lineWidth = 0
+}
@@ -0,0 +1,36 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrintfClearsStatusLine(t *testing.T) {
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+
+	drawStatus("⠋ думаю... 3s")
+	Printf("⏳ повтор %d из %d\n", 1, 3)
+	Printf("🙅 отказ\n")
+	drawStatus("⠙ думаю... 4s")
+	out.Close()
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\r⠋ думаю... 3s" + "\r             \r" + "⏳ повтор 1 из 3\n" + "🙅 отказ\n" + "\r⠙ думаю... 4s"
+	if string(data) != want {
+		t.Errorf("output = %q, want %q", data, want)
+	}
+	if lineWidth != 13 {
+		t.Errorf("lineWidth = %d, want the redrawn status width", lineWidth)
+	}
+	lineWidth = 0
+}
@@ -0,0 +1,189 @@
+// Package document извлекает текст из документов, которые агент скачивает
+// или находит на диске: PDF, TXT, CSV и HTML.
+package document
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format - формат документа
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatText Format = "txt"
+	FormatCSV  Format = "csv"
+	FormatHTML Format = "html"
+)
+
+// Document - извлеченный текст документа
+type Document struct {
+	Name   string // имя файла-источника
+	Format Format
+	Text   string
+}
+
+// DetectFormat определяет формат по расширению имени, Content-Type и сигнатуре данных
+func DetectFormat(name, contentType string, data []byte) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".pdf":
+		return FormatPDF, nil
+	case ".txt", ".text", ".log", ".md":
+		return FormatText, nil
+	case ".csv", ".tsv":
+		return FormatCSV, nil
+	case ".html", ".htm", ".xhtml":
+		return FormatHTML, nil
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "application/pdf"):
+		return FormatPDF, nil
+	case strings.Contains(contentType, "text/csv"):
+		return FormatCSV, nil
+	case strings.Contains(contentType, "text/html"), strings.Contains(contentType, "application/xhtml"):
+		return FormatHTML, nil
+	case strings.Contains(contentType, "text/plain"):
+		return FormatText, nil
+	}
+
+	head := bytes.TrimSpace(data[:min(len(data), 512)])
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return FormatPDF, nil
+	case bytes.HasPrefix(bytes.ToLower(head), []byte("<!doctype html")), bytes.HasPrefix(bytes.ToLower(head), []byte("<html")):
+		return FormatHTML, nil
+	case utf8.Valid(head) && !bytes.ContainsRune(head, 0):
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("неподдерживаемый формат документа %s (поддерживаются .pdf, .txt, .csv, .html)", name)
+}
+
+// Extract извлекает текст документа
+func Extract(name, contentType string, data []byte) (*Document, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("документ %s пуст", name)
+	}
+	format, err := DetectFormat(name, contentType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	switch format {
+	case FormatPDF:
+		text, err = extractPDF(data)
+	case FormatCSV:
+		text, err = extractCSV(decodeText(data), strings.EqualFold(filepath.Ext(name), ".tsv"))
+	case FormatHTML:
+		text = extractHTML(decodeText(data))
+	default:
+		text = decodeText(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось извлечь текст из %s: %w", name, err)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("в документе %s нет текста (возможно, это скан без текстового слоя)", name)
+	}
+	return &Document{Name: name, Format: format, Text: text}, nil
+}
+
+// decodeText возвращает текст в UTF-8; файлы не в UTF-8 считаются windows-1251
+func decodeText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	var sb strings.Builder
+	sb.Grow(len(data) * 2)
+	for _, c := range data {
+		sb.WriteRune(cp1251Rune(c))
+	}
+	return sb.String()
+}
+
+// cp1251Rune переводит байт windows-1251 в символ Unicode (кириллица и основные знаки)
+func cp1251Rune(c byte) rune {
+	switch {
+	case c < 0x80:
+		return rune(c)
+	case c >= 0xC0:
+		return rune(0x0410 + int(c) - 0xC0)
+	case c == 0xA8:
+		return 'Ё'
+	case c == 0xB8:
+		return 'ё'
+	case c == 0xB9:
+		return '№'
+	case c == 0xAB:
+		return '«'
+	case c == 0xBB:
+		return '»'
+	case c == 0x96:
+		return '–'
+	case c == 0x97:
+		return '—'
+	case c == 0xA0:
+		return ' '
+	}
+	return '?'
+}
+
+// extractCSV превращает таблицу в строки с разделителем " | ", понятные модели
+func extractCSV(text string, tab bool) (string, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if tab {
+		reader.Comma = '\t'
+	} else if first, _, _ := strings.Cut(text, "\n"); strings.Count(first, ";") > strings.Count(first, ",") {
+		// Excel с русской локалью сохраняет CSV через точку с запятой
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, record := range records {
+		sb.WriteString(strings.Join(record, " | "))
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+var (
+	htmlSkipRe   = regexp.MustCompile(`(?is)<(script|style|noscript|template)[^>]*>.*?</(script|style|noscript|template)>|<!--.*?-->`)
+	htmlBlockRe  = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6]|/table|/section|/article)[^>]*>`)
+	htmlCellRe   = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTagRe    = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacesRe     = regexp.MustCompile(`[ \t\x{00A0}]+`)
+	emptyLinesRe = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// extractHTML возвращает видимый текст HTML-страницы с переносами строк на месте блоков
+func extractHTML(text string) string {
+	text = htmlSkipRe.ReplaceAllString(text, " ")
+	text = htmlBlockRe.ReplaceAllString(text, "\n")
+	text = htmlCellRe.ReplaceAllString(text, " | ")
+	text = htmlTagRe.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = spacesRe.ReplaceAllString(text, " ")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return emptyLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
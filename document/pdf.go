@@ -0,0 +1,856 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Минимальный разбор PDF без внешних зависимостей: объекты (в том числе из
+// объектных потоков), потоки FlateDecode, дерево страниц и текстовые операторы.
+// Шрифты с ToUnicode декодируются через CMap, остальные - как Latin-1.
+// Сканы без текстового слоя и зашифрованные файлы не поддерживаются.
+
+type (
+	pdfName    string
+	pdfRef     int
+	pdfKeyword string
+	pdfDict    map[string]interface{}
+	pdfArray   []interface{}
+)
+
+// pdfObject - объект файла: значение и (для потоков) декодированные данные
+type pdfObject struct {
+	value  interface{}
+	stream []byte
+}
+
+// maxPDFObjects ограничивает разбор очень больших файлов
+const maxPDFObjects = 200000
+
+// maxPDFStream и maxPDFInflated ограничивают распаковку одного потока и всех потоков
+// файла: несколько килобайт сжатых нулей распаковываются в гигабайты
+const (
+	maxPDFStream   = 64 << 20
+	maxPDFInflated = 256 << 20
+)
+
+var pdfObjRe = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+
+type pdfFile struct {
+	objects  map[int]*pdfObject
+	inflated int64 // сколько байт уже распаковано
+	budget   int64 // предел распаковки всех потоков файла
+}
+
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data[:min(len(data), 1024)]), []byte("%PDF-")) {
+		return "", fmt.Errorf("файл не является PDF")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", fmt.Errorf("зашифрованные PDF не поддерживаются")
+	}
+
+	f := &pdfFile{objects: make(map[int]*pdfObject), budget: maxPDFInflated}
+	f.parseObjects(data)
+	if len(f.objects) == 0 {
+		return "", fmt.Errorf("не найдено ни одного объекта PDF")
+	}
+
+	var sb strings.Builder
+	pages := f.pages()
+	if len(pages) == 0 {
+		// Дерево страниц не разобрано - берем все потоки с текстовыми операторами
+		fonts := f.allFonts()
+		for _, num := range f.sortedNums() {
+			obj := f.objects[num]
+			if obj.stream != nil && bytes.Contains(obj.stream, []byte("BT")) {
+				sb.WriteString(extractContentText(obj.stream, fonts))
+				sb.WriteString("\n")
+			}
+		}
+		return sb.String(), nil
+	}
+
+	for i, page := range pages {
+		fonts := f.pageFonts(page.resources)
+		for _, content := range f.contents(page.dict) {
+			sb.WriteString(extractContentText(content, fonts))
+			sb.WriteString("\n")
+		}
+		if i < len(pages)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// parseObjects находит все "N G obj" и разбирает их, затем раскрывает объектные потоки
+func (f *pdfFile) parseObjects(data []byte) {
+	streamEnd := 0
+	for _, loc := range pdfObjRe.FindAllSubmatchIndex(data, maxPDFObjects) {
+		if loc[0] < streamEnd {
+			// Совпадение внутри данных предыдущего потока
+			continue
+		}
+		num, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		if err != nil {
+			continue
+		}
+		lex := &pdfLexer{data: data, pos: loc[1]}
+		value, ok := lex.next()
+		if !ok {
+			continue
+		}
+		obj := &pdfObject{value: value}
+		if dict, isDict := value.(pdfDict); isDict {
+			if kw, _ := lex.next(); kw == pdfKeyword("stream") {
+				var raw []byte
+				raw, streamEnd = rawStream(data, lex.pos, dict)
+				obj.stream = f.decodeStream(dict, raw)
+			}
+		}
+		// Более поздние ревизии объекта (инкрементальные обновления) заменяют ранние
+		f.objects[num] = obj
+	}
+
+	for _, obj := range f.objects {
+		dict, ok := obj.value.(pdfDict)
+		if !ok || dict["Type"] != pdfName("ObjStm") || obj.stream == nil {
+			continue
+		}
+		f.parseObjectStream(dict, obj.stream)
+	}
+}
+
+// parseObjectStream извлекает объекты из потока /Type /ObjStm
+func (f *pdfFile) parseObjectStream(dict pdfDict, data []byte) {
+	n, _ := dict["N"].(float64)
+	first, _ := dict["First"].(float64)
+	lex := &pdfLexer{data: data}
+	type entry struct{ num, offset int }
+	var entries []entry
+	for i := 0; i < int(n); i++ {
+		num, ok1 := lex.next()
+		offset, ok2 := lex.next()
+		if !ok1 || !ok2 {
+			break
+		}
+		numF, _ := num.(float64)
+		offF, _ := offset.(float64)
+		entries = append(entries, entry{int(numF), int(offF)})
+	}
+	for _, e := range entries {
+		pos := int(first) + e.offset
+		if pos < 0 || pos >= len(data) {
+			continue
+		}
+		if _, exists := f.objects[e.num]; exists {
+			continue
+		}
+		objLex := &pdfLexer{data: data, pos: pos}
+		if value, ok := objLex.next(); ok {
+			f.objects[e.num] = &pdfObject{value: value}
+		}
+	}
+}
+
+// rawStream возвращает данные потока, начинающиеся после ключевого слова stream, и позицию их конца
+func rawStream(data []byte, pos int, dict pdfDict) ([]byte, int) {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+	if length, ok := dict["Length"].(float64); ok && length > 0 {
+		end := pos + int(length)
+		if end <= len(data) && bytes.HasPrefix(bytes.TrimLeft(data[end:min(len(data), end+20)], "\r\n "), []byte("endstream")) {
+			return data[pos:end], end
+		}
+	}
+	if end := bytes.Index(data[pos:], []byte("endstream")); end >= 0 {
+		return bytes.TrimRight(data[pos:pos+end], "\r\n"), pos + end
+	}
+	return nil, len(data)
+}
+
+// decodeStream распаковывает FlateDecode; потоки с другими фильтрами (изображения) пропускаются.
+// Когда распакованный объем файла достигает budget, следующие потоки не распаковываются.
+func (f *pdfFile) decodeStream(dict pdfDict, raw []byte) []byte {
+	var filters []pdfName
+	switch filter := dict["Filter"].(type) {
+	case pdfName:
+		filters = append(filters, filter)
+	case pdfArray:
+		for _, item := range filter {
+			if name, ok := item.(pdfName); ok {
+				filters = append(filters, name)
+			}
+		}
+	}
+	data := raw
+	for _, filter := range filters {
+		if filter != "FlateDecode" {
+			return nil
+		}
+		remaining := min(f.budget-f.inflated, maxPDFStream)
+		if remaining <= 0 {
+			return nil
+		}
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		// Поврежденный хвост потока не мешает прочитать начало
+		decoded, _ := io.ReadAll(io.LimitReader(reader, remaining))
+		reader.Close()
+		f.inflated += int64(len(decoded))
+		data = decoded
+	}
+	return data
+}
+
+func (f *pdfFile) resolve(value interface{}) interface{} {
+	for i := 0; i < 10; i++ {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		obj, exists := f.objects[int(ref)]
+		if !exists {
+			return nil
+		}
+		value = obj.value
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(value interface{}) pdfDict {
+	d, _ := f.resolve(value).(pdfDict)
+	return d
+}
+
+func (f *pdfFile) sortedNums() []int {
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages обходит дерево страниц от каталога документа, наследуя /Resources
+func (f *pdfFile) pages() []pdfPage {
+	var root pdfDict
+	for _, num := range f.sortedNums() {
+		if d, ok := f.objects[num].value.(pdfDict); ok && d["Type"] == pdfName("Catalog") {
+			root = d
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	var pages []pdfPage
+	visited := make(map[pdfRef]bool)
+	var walk func(value interface{}, resources pdfDict, depth int)
+	walk = func(value interface{}, resources pdfDict, depth int) {
+		if ref, ok := value.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		node := f.dict(value)
+		if node == nil || depth > 50 {
+			return
+		}
+		if res := f.dict(node["Resources"]); res != nil {
+			resources = res
+		}
+		if node["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: node, resources: resources})
+			return
+		}
+		kids, _ := f.resolve(node["Kids"]).(pdfArray)
+		for _, kid := range kids {
+			walk(kid, resources, depth+1)
+		}
+	}
+	walk(root["Pages"], nil, 0)
+	return pages
+}
+
+// contents возвращает декодированные потоки содержимого страницы
+func (f *pdfFile) contents(page pdfDict) [][]byte {
+	var refs pdfArray
+	switch c := page["Contents"].(type) {
+	case pdfRef:
+		if arr, ok := f.resolve(c).(pdfArray); ok {
+			refs = arr
+		} else {
+			refs = pdfArray{c}
+		}
+	case pdfArray:
+		refs = c
+	}
+	var result [][]byte
+	for _, ref := range refs {
+		if r, ok := ref.(pdfRef); ok {
+			if obj, exists := f.objects[int(r)]; exists && obj.stream != nil {
+				result = append(result, obj.stream)
+			}
+		}
+	}
+	return result
+}
+
+// pdfFont - способ декодирования строк шрифта
+type pdfFont struct {
+	cmap     map[uint32]string
+	codeSize int // байт на код: 1 - простые шрифты, 2 - составные (Type0)
+}
+
+func (f *pdfFile) font(value interface{}) *pdfFont {
+	dict := f.dict(value)
+	if dict == nil {
+		return nil
+	}
+	font := &pdfFont{codeSize: 1}
+	if dict["Subtype"] == pdfName("Type0") {
+		font.codeSize = 2
+	}
+	if ref, ok := dict["ToUnicode"].(pdfRef); ok {
+		if obj, exists := f.objects[int(ref)]; exists && obj.stream != nil {
+			font.cmap, font.codeSize = parseCMap(obj.stream, font.codeSize)
+		}
+	}
+	return font
+}
+
+func (f *pdfFile) pageFonts(resources pdfDict) map[string]*pdfFont {
+	fonts := make(map[string]*pdfFont)
+	for name, value := range f.dict(resources["Font"]) {
+		if font := f.font(value); font != nil {
+			fonts[name] = font
+		}
+	}
+	if len(fonts) == 0 {
+		return f.allFonts()
+	}
+	return fonts
+}
+
+// allFonts собирает шрифты из всех словарей /Font документа (когда страницы не разобраны)
+func (f *pdfFile) allFonts() map[string]*pdfFont {
+	fonts := make(map[string]*pdfFont)
+	for _, num := range f.sortedNums() {
+		d, ok := f.objects[num].value.(pdfDict)
+		if !ok {
+			continue
+		}
+		res := f.dict(d["Resources"])
+		if res == nil {
+			continue
+		}
+		for name, value := range f.dict(res["Font"]) {
+			if font := f.font(value); font != nil {
+				fonts[name] = font
+			}
+		}
+	}
+	return fonts
+}
+
+// parseCMap читает таблицу ToUnicode: bfchar и bfrange
+func parseCMap(data []byte, defaultSize int) (map[uint32]string, int) {
+	cmap := make(map[uint32]string)
+	codeSize := defaultSize
+	lex := &pdfLexer{data: data}
+	var operands []interface{}
+	mode := ""
+	for {
+		token, ok := lex.next()
+		if !ok {
+			break
+		}
+		kw, isKeyword := token.(pdfKeyword)
+		if !isKeyword {
+			operands = append(operands, token)
+			continue
+		}
+		switch kw {
+		case "begincodespacerange", "beginbfchar", "beginbfrange":
+			mode = string(kw)
+		case "endcodespacerange":
+			if len(operands) >= 1 {
+				if lo, ok := operands[0].([]byte); ok && len(lo) > 0 {
+					codeSize = len(lo)
+				}
+			}
+			mode = ""
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					cmap[bytesToCode(src)] = utf16BytesToString(dst)
+				}
+			}
+			mode = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := bytesToCode(lo), bytesToCode(hi)
+				if end < start || end-start > 65535 {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					base := []rune(utf16BytesToString(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						r := append([]rune{}, base...)
+						r[len(r)-1] += rune(code - start)
+						cmap[code] = string(r)
+					}
+				case pdfArray:
+					for j, item := range dst {
+						if b, ok := item.([]byte); ok && start+uint32(j) <= end {
+							cmap[start+uint32(j)] = utf16BytesToString(b)
+						}
+					}
+				}
+			}
+			mode = ""
+		}
+		if mode == "" || strings.HasPrefix(string(kw), "begin") {
+			operands = operands[:0]
+		}
+	}
+	return cmap, codeSize
+}
+
+func bytesToCode(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+func utf16BytesToString(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeString переводит байты строки PDF в текст с учетом шрифта
+func (font *pdfFont) decodeString(s []byte) string {
+	if bytes.HasPrefix(s, []byte{0xFE, 0xFF}) {
+		return utf16BytesToString(s[2:])
+	}
+	if font == nil || font.cmap == nil {
+		if font != nil && font.codeSize == 2 {
+			// Составной шрифт без ToUnicode - коды глифов не переводятся в текст
+			return ""
+		}
+		runes := make([]rune, len(s))
+		for i, c := range s {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	}
+	var sb strings.Builder
+	for i := 0; i+font.codeSize <= len(s); i += font.codeSize {
+		if text, ok := font.cmap[bytesToCode(s[i:i+font.codeSize])]; ok {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// extractContentText выполняет текстовые операторы потока содержимого страницы
+func extractContentText(content []byte, fonts map[string]*pdfFont) string {
+	var sb strings.Builder
+	var font *pdfFont
+	var operands []interface{}
+	lastY, hasY := 0.0, false
+
+	newline := func() {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	space := func() {
+		if s := sb.String(); len(s) > 0 && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			sb.WriteString(" ")
+		}
+	}
+	number := func(i int) float64 {
+		if i < 0 || i >= len(operands) {
+			return 0
+		}
+		v, _ := operands[i].(float64)
+		return v
+	}
+
+	lex := &pdfLexer{data: content, content: true}
+	for {
+		token, ok := lex.next()
+		if !ok {
+			break
+		}
+		kw, isKeyword := token.(pdfKeyword)
+		if !isKeyword {
+			operands = append(operands, token)
+			continue
+		}
+		switch kw {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = fonts[string(name)]
+				}
+			}
+		case "Tj":
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					sb.WriteString(font.decodeString(s))
+				}
+			}
+		case "'", "\"":
+			newline()
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					sb.WriteString(font.decodeString(s))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if arr, ok := operands[len(operands)-1].(pdfArray); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case []byte:
+							sb.WriteString(font.decodeString(v))
+						case float64:
+							// Большой сдвиг влево в тысячных долях шрифта - пробел между словами
+							if v < -200 {
+								space()
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if ty := number(len(operands) - 1); ty != 0 {
+				newline()
+			} else if number(len(operands)-2) > 0 {
+				space()
+			}
+		case "T*":
+			newline()
+		case "Tm":
+			y := number(len(operands) - 1)
+			if hasY && y != lastY {
+				newline()
+			} else if hasY {
+				space()
+			}
+			lastY, hasY = y, true
+		case "ET":
+			space()
+		}
+		operands = operands[:0]
+	}
+	return sb.String()
+}
+
+// pdfLexer разбирает объекты PDF и операторы потоков содержимого
+type pdfLexer struct {
+	data    []byte
+	pos     int
+	content bool // поток содержимого: inline-изображения BI ... ID ... EI пропускаются
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) {
+			l.pos++
+		} else if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// next возвращает следующий объект: число (float64), строку ([]byte), имя,
+// ссылку, массив, словарь или ключевое слово (оператор)
+func (l *pdfLexer) next() (interface{}, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodeNameEscapes(string(l.data[start:l.pos]))), true
+	case c == '(':
+		return l.literalString(), true
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return l.dictionary(), true
+		}
+		return l.hexString(), true
+	case c == '[':
+		l.pos++
+		var arr pdfArray
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return arr, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, true
+			}
+			item, ok := l.next()
+			if !ok {
+				return arr, true
+			}
+			arr = append(arr, item)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(string(c)), true
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.number(), true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	switch word {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	case "ID":
+		if l.content {
+			l.skipInlineImage()
+		}
+	}
+	return pdfKeyword(word), true
+}
+
+func (l *pdfLexer) number() interface{} {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.data) && (l.data[l.pos] == '.' || (l.data[l.pos] >= '0' && l.data[l.pos] <= '9')) {
+		l.pos++
+	}
+	value, _ := strconv.ParseFloat(string(l.data[start:l.pos]), 64)
+
+	// Ссылка на объект: "12 0 R" (в потоках содержимого ссылок не бывает)
+	if !l.content && value >= 0 && value == float64(int(value)) {
+		save := l.pos
+		l.skipSpace()
+		genStart := l.pos
+		for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+			l.pos++
+		}
+		if l.pos > genStart {
+			l.skipSpace()
+			if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelimiter(l.data[l.pos+1])) {
+				l.pos++
+				return pdfRef(int(value))
+			}
+		}
+		l.pos = save
+	}
+	return value
+}
+
+func (l *pdfLexer) dictionary() pdfDict {
+	dict := make(pdfDict)
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return dict
+		}
+		if l.data[l.pos] == '>' {
+			l.pos += 2
+			return dict
+		}
+		key, ok := l.next()
+		if !ok {
+			return dict
+		}
+		name, isName := key.(pdfName)
+		if !isName {
+			continue
+		}
+		value, ok := l.next()
+		if !ok {
+			return dict
+		}
+		dict[string(name)] = value
+	}
+}
+
+func (l *pdfLexer) literalString() []byte {
+	l.pos++ // (
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return buf
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					buf = append(buf, byte(v))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+func (l *pdfLexer) hexString() []byte {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	buf := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		buf = append(buf, byte(v))
+	}
+	return buf
+}
+
+// skipInlineImage пропускает двоичные данные inline-изображения до оператора EI
+func (l *pdfLexer) skipInlineImage() {
+	for l.pos+2 < len(l.data) {
+		if isPDFSpace(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.data) || isPDFSpace(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+// decodeNameEscapes раскрывает #xx в именах PDF
+func decodeNameEscapes(name string) string {
+	if !strings.Contains(name, "#") {
+		return name
+	}
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if v, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String()
+}
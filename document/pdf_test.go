@@ -0,0 +1,190 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// pdfStream - тело потока и дополнительные ключи его словаря
+type pdfStream struct {
+	dict string
+	data []byte
+}
+
+// buildPDF собирает файл из объектов 1..N: строка - обычный объект, pdfStream - поток,
+// nil - номер пропущен (объект лежит в объектном потоке)
+func buildPDF(objects ...interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		if obj == nil {
+			continue
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		switch v := obj.(type) {
+		case string:
+			buf.WriteString(v)
+		case pdfStream:
+			fmt.Fprintf(&buf, "<< /Length %d %s >>\nstream\n", len(v.data), v.dict)
+			buf.Write(v.data)
+			buf.WriteString("\nendstream")
+		}
+		buf.WriteString("\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// onePage - каталог, дерево из одной страницы со шрифтом F1 (объект 4) и содержимым (объект 5)
+func onePage(font interface{}, content interface{}, extra ...interface{}) []byte {
+	objects := []interface{}{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		font,
+		content,
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+func TestExtractPDF(t *testing.T) {
+	simpleFont := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+	cmap := []byte(`/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+3 beginbfchar
+<0001> <0421>
+<0002> <0443>
+<0006> <0430>
+endbfchar
+1 beginbfrange
+<0003> <0005> <043C>
+endbfrange
+endcmap`)
+
+	tests := []struct {
+		name string
+		pdf  []byte
+		want string
+	}{
+		{
+			name: "plain content",
+			pdf:  onePage(simpleFont, pdfStream{data: []byte("BT /F1 12 Tf 72 720 Td (Hello) Tj 0 -14 Td (World) Tj ET")}),
+			want: "Hello\nWorld",
+		},
+		{
+			name: "TJ word spacing",
+			pdf:  onePage(simpleFont, pdfStream{data: []byte("BT /F1 12 Tf [(Total)-300(due:)-250(42)] TJ ET")}),
+			want: "Total due: 42",
+		},
+		{
+			name: "FlateDecode content",
+			pdf:  onePage(simpleFont, pdfStream{dict: "/Filter /FlateDecode", data: deflate([]byte("BT /F1 12 Tf (Compressed text) Tj ET"))}),
+			want: "Compressed text",
+		},
+		{
+			name: "escapes in literal strings",
+			pdf:  onePage(simpleFont, pdfStream{data: []byte(`BT /F1 12 Tf (a \(b\) c\\d) Tj ET`)}),
+			want: `a (b) c\d`,
+		},
+		{
+			name: "Type0 font with ToUnicode",
+			pdf: onePage("<< /Type /Font /Subtype /Type0 /ToUnicode 6 0 R >>",
+				pdfStream{data: []byte("BT /F1 12 Tf [<00010002000300030006>-400<0004000500030006>] TJ ET")},
+				pdfStream{data: cmap}),
+			want: "Сумма нома",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Extract("file.pdf", "", tt.pdf)
+			if err != nil {
+				t.Fatalf("Extract() error: %v", err)
+			}
+			if doc.Format != FormatPDF || doc.Text != tt.want {
+				t.Errorf("Extract() = %s %q, want %q", doc.Format, doc.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractPDFObjectStream(t *testing.T) {
+	// Страница и шрифт лежат в объектном потоке (объект 6)
+	page := "<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>"
+	font := "<< /Type /Font /Subtype /Type1 >>"
+	header := fmt.Sprintf("3 0 4 %d ", len(page)+1)
+	objStm := pdfStream{
+		dict: fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)),
+		data: deflate([]byte(header + page + " " + font)),
+	}
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		nil,
+		nil,
+		pdfStream{data: []byte("BT /F1 12 Tf (From object stream) Tj ET")},
+		objStm,
+	)
+
+	text, err := extractPDF(pdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(text) != "From object stream" {
+		t.Errorf("extractPDF() = %q", text)
+	}
+}
+
+func TestExtractPDFErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not a pdf", []byte("%PNG....."), "не является PDF"},
+		{"encrypted", []byte("%PDF-1.7\n1 0 obj << /Encrypt 2 0 R >> endobj"), "зашифрованные"},
+		{"no objects", []byte("%PDF-1.7\n%%EOF"), "ни одного объекта"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := extractPDF(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("extractPDF() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Распаковка ограничена и на поток, и на весь файл: сжатые нули не раздувают память
+func TestDecodeStreamBudget(t *testing.T) {
+	bomb := deflate(make([]byte, 1<<20))
+	f := &pdfFile{objects: make(map[int]*pdfObject), budget: 1 << 20}
+	flate := pdfDict{"Filter": pdfName("FlateDecode")}
+
+	first := f.decodeStream(flate, bomb)
+	if len(first) != 1<<20 {
+		t.Fatalf("first stream = %d bytes, want %d", len(first), 1<<20)
+	}
+	if second := f.decodeStream(flate, bomb); second != nil {
+		t.Errorf("stream past the file budget decoded %d bytes", len(second))
+	}
+
+	f = &pdfFile{objects: make(map[int]*pdfObject), budget: 1 << 19}
+	if partial := f.decodeStream(flate, bomb); len(partial) != 1<<19 {
+		t.Errorf("stream over the remaining budget = %d bytes, want %d", len(partial), 1<<19)
+	}
+	if other := f.decodeStream(pdfDict{"Filter": pdfName("DCTDecode")}, []byte("jpeg")); other != nil {
+		t.Errorf("non-Flate stream decoded: %q", other)
+	}
+}
@@ -3,13 +3,13 @@ module github.com/Angabebr/Golang-AI-agent
 go 1.21
 
 require (
+	github.com/chromedp/cdproto v0.0.0-20231011050154-1d073bb38998
 	github.com/chromedp/chromedp v0.9.3
 	github.com/joho/godotenv v1.5.1
 	github.com/sashabaranov/go-openai v1.20.0
 )
 
 require (
-	github.com/chromedp/cdproto v0.0.0-20231011050154-1d073bb38998 // indirect
 	github.com/chromedp/sysutil v1.0.0 // indirect
 	github.com/gobwas/httphead v0.1.0 // indirect
 	github.com/gobwas/pool v0.2.1 // indirect
@@ -3,19 +3,28 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/Angabebr/Golang-AI-agent/agent"
 	"github.com/Angabebr/Golang-AI-agent/ai"
 	"github.com/Angabebr/Golang-AI-agent/browser"
+	"github.com/Angabebr/Golang-AI-agent/buildinfo"
+	"github.com/Angabebr/Golang-AI-agent/bundle"
+	"github.com/Angabebr/Golang-AI-agent/console"
+	"github.com/Angabebr/Golang-AI-agent/retention"
+	"github.com/Angabebr/Golang-AI-agent/selftest"
 	"github.com/joho/godotenv"
 )
 
@@ -33,11 +42,300 @@ func (w *ErrorFilterWriter) Write(p []byte) (n int, err error) {
 	return w.original.Write(p)
 }
 
+// sessionRestartThreshold - сколько задач подряд с ошибками контекста допускается до перезапуска браузера
+const sessionRestartThreshold = 2
+
+// isContextFailure определяет ошибки, после которых контекст chromedp перестает работать
+func isContextFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "context canceled") ||
+		strings.Contains(msg, "context was canceled") ||
+		strings.Contains(msg, "invalid context") ||
+		strings.Contains(msg, "target closed") ||
+		strings.Contains(msg, "websocket: close")
+}
+
+// cleanupArtifacts удаляет журналы и отчеты по правилам хранения; в режиме dryRun
+// только показывает, что было бы удалено
+func cleanupArtifacts(dirs []string, policy retention.Policy, dryRun bool) {
+	files, err := retention.Plan(dirs, policy, time.Now())
+	if err != nil {
+		fmt.Printf("⚠️  Очистка артефактов: %v\n", err)
+		return
+	}
+	if len(files) == 0 {
+		if dryRun {
+			fmt.Printf("🧹 Удалять нечего (правила: %s)\n", policy)
+		}
+		return
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	if dryRun {
+		fmt.Printf("🧹 Будет удалено %d файл(ов), %s (правила: %s):\n", len(files), retention.FormatSize(total), policy)
+		for _, f := range files {
+			reason := "старый"
+			if f.Reason == "size" {
+				reason = "превышен объем"
+			}
+			fmt.Printf("   %s  %s  %s (%s)\n", f.ModTime.Format("2006-01-02 15:04"), retention.FormatSize(f.Size), f.Path, reason)
+		}
+		return
+	}
+
+	removed, freed, err := retention.Apply(files)
+	fmt.Printf("🧹 Удалено артефактов: %d, освобождено %s\n", removed, retention.FormatSize(freed))
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+// printLogins проверяет вход на сайтах в фоновых вкладках и печатает результат
+func printLogins(a *agent.Agent, domains []string, refresh bool) {
+	fmt.Println("🔑 Проверка входа на сайтах (в фоновых вкладках)...")
+	for _, login := range a.CheckLogins(domains, refresh) {
+		icon, state := "❔", "неизвестно"
+		switch login.State.State {
+		case browser.LoginStateLoggedIn:
+			icon, state = "🔓", "вход выполнен"
+		case browser.LoginStateLoggedOut:
+			icon, state = "🔒", "вход не выполнен"
+		}
+		line := fmt.Sprintf("   %s %s: %s", icon, login.Domain, state)
+		if login.Err != nil {
+			line += fmt.Sprintf(" (ошибка: %v)", login.Err)
+		} else if login.State.Evidence != "" {
+			line += fmt.Sprintf(" (%s)", login.State.Evidence)
+		}
+		if login.Cached {
+			line += fmt.Sprintf(" - проверено в %s", login.CheckedAt.Format("15:04"))
+		}
+		fmt.Println(line)
+	}
+}
+
+// printChromeProfiles печатает профили Chrome в каталоге данных браузера (команда profiles)
+func printChromeProfiles(b *browser.Browser) {
+	profiles, err := b.ChromeProfiles()
+	if err != nil {
+		fmt.Printf("⚠️  Не удалось получить список профилей: %v\n", err)
+		return
+	}
+	fmt.Printf("👤 Профили Chrome в %s:\n", b.ProfileDir())
+	for _, profile := range profiles {
+		marker := "  "
+		if profile.Active {
+			marker = "▶ "
+		}
+		line := "   " + marker + profile.Directory
+		if profile.Name != "" {
+			line += fmt.Sprintf(" (%s)", profile.Name)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("   Переключение: profile \"Имя\" или profile \"Profile 2\"")
+}
+
+// taskInterrupts связывает Ctrl+C с командой, которая сейчас выполняется (задача,
+// resume, watch, batch): первый Ctrl+C отменяет ее контекст - пауза перед переходом,
+// ожидание модели и наблюдение прерываются, а программа возвращается к вводу команд.
+// Ctrl+C без команды или повторный Ctrl+C до ее завершения закрывает программу.
+type taskInterrupts struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc // отмена текущей команды; nil - команда не выполняется
+}
+
+// begin возвращает контекст команды, который отменяет Ctrl+C, и функцию завершения команды
+func (t *taskInterrupts) begin() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.mu.Lock()
+	t.cancel = cancel
+	t.mu.Unlock()
+	return ctx, func() {
+		t.mu.Lock()
+		t.cancel = nil
+		t.mu.Unlock()
+		cancel()
+	}
+}
+
+// interrupt отменяет текущую команду; false - отменять нечего, и программу нужно закрыть
+func (t *taskInterrupts) interrupt() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel == nil {
+		return false
+	}
+	t.cancel()
+	t.cancel = nil
+	return true
+}
+
+// configureRetries применяет OPENAI_MAX_RETRIES и OPENAI_RETRY_DELAY - повторы запроса
+// к модели после ответа 429 и временных ошибок сервера
+func configureRetries(aiClient *ai.Client) {
+	retriesEnv, delayEnv := os.Getenv("OPENAI_MAX_RETRIES"), os.Getenv("OPENAI_RETRY_DELAY")
+	if retriesEnv == "" && delayEnv == "" {
+		return
+	}
+	retries, delay := 3, time.Second
+	if retriesEnv != "" {
+		n, err := strconv.Atoi(retriesEnv)
+		if err != nil || n < 0 {
+			log.Printf("⚠️  Некорректное значение OPENAI_MAX_RETRIES (%q): ожидается целое число от 0", retriesEnv)
+		} else {
+			retries = n
+		}
+	}
+	if delayEnv != "" {
+		d, err := time.ParseDuration(delayEnv)
+		if err != nil || d <= 0 {
+			log.Printf("⚠️  Некорректное значение OPENAI_RETRY_DELAY (%q): ожидается длительность, например 2s", delayEnv)
+		} else {
+			delay = d
+		}
+	}
+	aiClient.SetRetryPolicy(retries, delay)
+}
+
+// configEnvKeys - переменные окружения, которые читает main: их значения (без секретов)
+// записываются в пакет для воспроизведения
+var configEnvKeys = []string{
+	"OPENAI_API_KEY", "OPENAI_MODEL", "OPENAI_FALLBACK_MODEL", "OPENAI_ESCALATION_MODEL", "AI_CONTEXT_TOKENS",
+	"BROWSER_USER_DATA_DIR", "BROWSER_PROFILE_NAME", "BROWSER_PROFILE_DIR", "BROWSER_IGNORE_CERT_ERRORS",
+	"BROWSER_ALLOW_OLD_CHROME", "KEEP_BROWSER_OPEN", "START_URL", "AGENT_SAFE_MODE", "AGENT_LOCALE",
+	"AGENT_VERBOSE", "AGENT_SPINNER", "ALLOW_HANDOFF", "CONTENT_LIMITS", "EXTRA_LINK_SELECTORS",
+	"EXTRA_BUTTON_SELECTORS", "EXTRA_INPUT_SELECTORS", "EXTRA_HEADERS", "NAVIGATE_HOST_DELAY", "CONFIRM_BATCH", "IDLE_WARNING",
+	"CAPTURE_FINAL_PAGE", "DISABLE_DESTRUCTIVE_CHECK", "LOGIN_INDICATOR", "LOGIN_CHECK_DOMAINS",
+	"PAGE_SETTLE_MAX", "SLOW_LLM_THRESHOLD", "AUTO_SCROLL_MAX", "PDF_PAPER_SIZE", "PDF_PRINT_BACKGROUND",
+	"ACTION_ALIASES", "OPENAI_MAX_RETRIES", "OPENAI_RETRY_DELAY", "PAGE_AUTO_SCROLL", "LOW_POWER",
+	"DOWNLOADS_DIR", "UPLOAD_DIRS", "ARTIFACT_CLEANUP_ON_START",
+}
+
+// runReplayBundle воспроизводит решения по пакету и печатает расхождения.
+// Код выхода 1 - решение на каком-то шаге изменилось или пакет не читается.
+func runReplayBundle(path string) int {
+	report, err := bundle.Replay(context.Background(), path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("📦 Задача: %s\n", report.Task)
+	for _, it := range report.Iterations {
+		if it.Match {
+			fmt.Printf("✅ Шаг %d: %s\n", it.Iteration, it.Replayed)
+		} else {
+			fmt.Printf("❌ Шаг %d: было %s, стало %s\n", it.Iteration, it.Recorded, it.Replayed)
+		}
+		for _, drift := range it.Drift {
+			fmt.Printf("   ⚠️  Промпт изменился, %s\n", drift)
+		}
+	}
+	if n := report.Mismatches(); n > 0 {
+		fmt.Printf("\n❌ Решение изменилось на шагах: %d из %d\n", n, len(report.Iterations))
+		return 1
+	}
+	fmt.Printf("\n✅ Шагов воспроизведено без изменений: %d\n", len(report.Iterations))
+	return 0
+}
+
+// runSelftest выполняет задачи самопроверки в отдельном браузере без окна с временным
+// каталогом данных (профиль пользователя не затрагивается). Возвращает код выхода:
+// 1, если хотя бы одна задача не прошла, - для запуска из cron.
+func runSelftest(offline bool) int {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if offline {
+		apiKey = "selftest-offline"
+	} else if apiKey == "" {
+		fmt.Println("❌ OPENAI_API_KEY не установлен - для проверки без модели используйте --selftest-offline")
+		return 1
+	}
+	model := os.Getenv("OPENAI_MODEL")
+	if model == "" {
+		model = "gpt-4-turbo-preview"
+	}
+
+	userDataDir, err := os.MkdirTemp("", "agent-selftest-")
+	if err != nil {
+		fmt.Printf("❌ Не удалось создать временный каталог браузера: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(userDataDir)
+	browserInstance, err := browser.NewBrowser(userDataDir, true)
+	if err != nil {
+		fmt.Printf("❌ Не удалось запустить браузер: %v\n", err)
+		return 1
+	}
+	defer browserInstance.Close()
+
+	var offlineModel *selftest.OfflineModel
+	aiClient := ai.NewClient(apiKey, model)
+	if offline {
+		offlineModel = selftest.NewOfflineModel()
+		aiClient = ai.NewClientWithProvider(offlineModel, model)
+	}
+	selftestAgent := agent.NewAgent(browserInstance, aiClient)
+	selftestAgent.SetInteractive(false)
+	selftestAgent.SetAllowHandoff(false)
+	selftestAgent.SetNavigateHostDelay(0)
+
+	mode := "модель " + model
+	if offline {
+		mode = "сценарии без модели"
+	}
+	fmt.Printf("🧪 Самопроверка: %s, %s\n", buildinfo.Get(), mode)
+	results := selftest.Run(context.Background(), selftestAgent, offlineModel)
+	fmt.Println()
+	if !selftest.PrintResults(os.Stdout, results) {
+		return 1
+	}
+	return 0
+}
+
 func main() {
+	console.Setup()
+	defer console.Restore()
+	log.SetOutput(os.Stderr)
+
+	showVersion := flag.Bool("version", false, "показать версию и выйти")
+	printReport := flag.Bool("report", false, "печатать краткий итог каждой задачи (полный отчет - в REPORT_DIR)")
+	recordBundle := flag.Bool("bundle", false, "записывать каждую задачу в пакет для воспроизведения (zip в BUNDLE_DIR)")
+	replayBundle := flag.String("replay-bundle", "", "воспроизвести решения модели по пакету (без браузера и сети) и выйти")
+	runSelftestFlag := flag.Bool("selftest", false, "выполнить задачи самопроверки на локальных тестовых сайтах и выйти")
+	selftestOffline := flag.Bool("selftest-offline", false, "самопроверка со сценарными ответами вместо модели (без ключа API)")
+	actionsSchema := flag.Bool("actions-schema", false, "вывести JSON-схему решения модели (действия и их поля) и выйти")
+	startURLFlag := flag.String("start-url", "", "стартовая страница вместо START_URL (для одной задачи - директива !start=URL)")
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(buildinfo.Get())
+		return
+	}
+	if *actionsSchema {
+		fmt.Println(string(ai.ActionSchema()))
+		return
+	}
+	if *replayBundle != "" {
+		code := runReplayBundle(*replayBundle)
+		console.Restore() // os.Exit не выполняет defer
+		os.Exit(code)
+	}
+
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found or error loading: %v", err)
 		log.Println("Попытка продолжить с переменными окружения системы...")
 	}
+	if *runSelftestFlag || *selftestOffline {
+		code := runSelftest(*selftestOffline)
+		console.Restore() // os.Exit не выполняет defer
+		os.Exit(code)
+	}
+	console.SetStatusEnabled(os.Getenv("AGENT_SPINNER") != "off")
 
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
@@ -94,12 +392,84 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 	os.Remove(testFile)
 
 	keepBrowserOpen := os.Getenv("KEEP_BROWSER_OPEN") == "true"
+	safeMode := os.Getenv("AGENT_SAFE_MODE") == "true"
 
+	fmt.Printf("ℹ️  %s\n", buildinfo.Get())
 	fmt.Println("🚀 Инициализация AI-агента...")
 	fmt.Printf("📁 Директория браузера: %s\n", userDataDir)
 	fmt.Println("🌐 Запуск браузера...")
 
-	browserInstance, err := browser.NewBrowser(userDataDir, false)
+	profileName := os.Getenv("BROWSER_PROFILE_NAME")
+	profileDirectory := os.Getenv("BROWSER_PROFILE_DIR")
+	if profileDirectory != "" {
+		fmt.Printf("👤 Профиль Chrome: %s\n", profileDirectory)
+	}
+	browserOpts := []browser.Option{
+		browser.WithProfile(profileName),
+		browser.WithProfileDirectory(profileDirectory),
+	}
+	if os.Getenv("BROWSER_IGNORE_CERT_ERRORS") == "true" {
+		fmt.Println("⚠️  Проверка сертификатов HTTPS отключена (BROWSER_IGNORE_CERT_ERRORS)")
+		browserOpts = append(browserOpts, browser.WithIgnoreCertErrors(true))
+	}
+	if os.Getenv("BROWSER_ALLOW_OLD_CHROME") == "true" {
+		browserOpts = append(browserOpts, browser.WithAllowOldChrome(true))
+	}
+	if os.Getenv("PAGE_AUTO_SCROLL") == "true" {
+		browserOpts = append(browserOpts, browser.WithAutoScroll(true))
+	}
+	if os.Getenv("LOW_POWER") == "true" {
+		browserOpts = append(browserOpts, browser.WithLowPower(true))
+	}
+	downloadsDir := os.Getenv("DOWNLOADS_DIR")
+	if downloadsDir == "" {
+		downloadsDir = "./downloads"
+	}
+	if downloadsDir != "off" {
+		browserOpts = append(browserOpts, browser.WithDownloadDir(downloadsDir))
+	}
+	pdfOptions := browser.DefaultPDFOptions()
+	if paper := os.Getenv("PDF_PAPER_SIZE"); paper != "" {
+		if parsed, err := browser.ParsePaperSize(paper); err != nil {
+			log.Printf("⚠️  Некорректное значение PDF_PAPER_SIZE: %v", err)
+		} else {
+			pdfOptions.Paper = parsed
+		}
+	}
+	if os.Getenv("PDF_PRINT_BACKGROUND") == "false" {
+		pdfOptions.PrintBackground = false
+	}
+	browserOpts = append(browserOpts, browser.WithPDFOptions(pdfOptions))
+	if limitsEnv := os.Getenv("CONTENT_LIMITS"); limitsEnv != "" {
+		limits, err := browser.ParseContentLimits(limitsEnv)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение CONTENT_LIMITS (%q): %v", limitsEnv, err)
+		} else {
+			fmt.Printf("ℹ️  Лимиты извлечения: %s\n", limits)
+			browserOpts = append(browserOpts, browser.WithContentLimits(limits))
+		}
+	}
+	// Собственные компоненты сайта, которые не находят селекторы извлечения по умолчанию
+	linkSelectors := browser.ParseSelectorList(os.Getenv("EXTRA_LINK_SELECTORS"))
+	buttonSelectors := browser.ParseSelectorList(os.Getenv("EXTRA_BUTTON_SELECTORS"))
+	inputSelectors := browser.ParseSelectorList(os.Getenv("EXTRA_INPUT_SELECTORS"))
+	if len(linkSelectors)+len(buttonSelectors)+len(inputSelectors) > 0 {
+		browserOpts = append(browserOpts, browser.WithExtractionSelectors(linkSelectors, buttonSelectors, inputSelectors))
+	}
+	if headersEnv := os.Getenv("EXTRA_HEADERS"); headersEnv != "" {
+		headers, err := browser.ParseHeaders(headersEnv)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение EXTRA_HEADERS (%q): %v", headersEnv, err)
+		} else {
+			fmt.Printf("ℹ️  Дополнительные заголовки: %s\n", headersEnv)
+			browserOpts = append(browserOpts, browser.WithExtraHeaders(headers))
+		}
+	}
+	browserInstance, err := browser.NewBrowser(userDataDir, false, browserOpts...)
+	var unsupported *browser.UnsupportedChromeError
+	if errors.As(err, &unsupported) {
+		log.Fatalf("\n❌ %v\n", err)
+	}
 	if err != nil {
 		log.Fatalf("\n❌ Не удалось запустить браузер: %v\n\nУбедитесь, что Chrome/Chromium установлен и доступен.", err)
 	}
@@ -111,11 +481,178 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 	}
 
 	fmt.Println("✅ Браузер запущен")
+	if version, err := browserInstance.Version(); err == nil {
+		fmt.Printf("ℹ️  Версия браузера: %s\n", version)
+	}
 
 	aiClient := ai.NewClient(apiKey, model)
+	aiClient.SetRefusalFallbackModel(os.Getenv("OPENAI_FALLBACK_MODEL"))
+	aiClient.SetEscalationModel(os.Getenv("OPENAI_ESCALATION_MODEL"))
+	if aliasesEnv := os.Getenv("ACTION_ALIASES"); aliasesEnv != "" {
+		aliases, err := ai.ParseActionAliases(aliasesEnv)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение ACTION_ALIASES (%q): %v", aliasesEnv, err)
+		} else {
+			aiClient.SetActionAliases(aliases)
+		}
+	}
+	if raw := os.Getenv("AI_CONTEXT_TOKENS"); raw != "" {
+		if tokens, err := strconv.Atoi(raw); err == nil && tokens > 0 {
+			aiClient.SetContextTokens(tokens)
+		} else {
+			fmt.Printf("⚠️  Некорректный AI_CONTEXT_TOKENS=%q, используется контекст по модели\n", raw)
+		}
+	}
+	configureRetries(aiClient)
 	fmt.Println("✅ AI клиент инициализирован")
+	fmt.Printf("ℹ️  Профиль промпта: %s\n", aiClient.PromptProfileInfo())
 
 	mainAgent := agent.NewAgent(browserInstance, aiClient)
+	mainAgent.SetSafeMode(safeMode)
+	if hostDelay := os.Getenv("NAVIGATE_HOST_DELAY"); hostDelay != "" {
+		delay, err := time.ParseDuration(hostDelay)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение NAVIGATE_HOST_DELAY (%q): %v", hostDelay, err)
+		} else {
+			mainAgent.SetNavigateHostDelay(delay)
+		}
+	}
+	switch batch := os.Getenv("CONFIRM_BATCH"); batch {
+	case "", "on":
+	case "off", "all":
+		policy := agent.DefaultConfirmationPolicy()
+		policy.Batch = batch == "all"
+		policy.BatchHigh = batch == "all"
+		mainAgent.SetConfirmationPolicy(policy)
+	default:
+		log.Printf("⚠️  Некорректное значение CONFIRM_BATCH (%q): ожидается on, off или all", batch)
+	}
+	if idleEnv := os.Getenv("IDLE_WARNING"); idleEnv != "" {
+		mode, err := agent.ParseIdleWarningMode(idleEnv)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение IDLE_WARNING (%q): %v", idleEnv, err)
+		} else {
+			mainAgent.SetIdleWarningMode(mode)
+		}
+	}
+	if os.Getenv("CAPTURE_FINAL_PAGE") == "true" {
+		mainAgent.SetCaptureFinalPage(true)
+	}
+	if os.Getenv("DISABLE_DESTRUCTIVE_CHECK") == "true" {
+		mainAgent.SetDisableDestructiveCheck(true)
+		fmt.Println("⚠️  Проверка деструктивных действий отключена: подтверждения не запрашиваются")
+	}
+	mainAgent.SetLoginIndicator(os.Getenv("LOGIN_INDICATOR"))
+	mainAgent.SetVerbose(os.Getenv("AGENT_VERBOSE") == "true")
+	if settleEnv := os.Getenv("PAGE_SETTLE_MAX"); settleEnv != "" {
+		if d, err := time.ParseDuration(settleEnv); err != nil || d < 0 {
+			log.Printf("⚠️  Некорректное значение PAGE_SETTLE_MAX (%q): ожидается длительность, например 1s или 500ms", settleEnv)
+		} else {
+			mainAgent.SetSettleMax(d)
+		}
+	}
+	if slow := os.Getenv("SLOW_LLM_THRESHOLD"); slow != "" {
+		threshold, err := time.ParseDuration(slow)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение SLOW_LLM_THRESHOLD (%q): %v", slow, err)
+		} else {
+			mainAgent.SetSlowDecisionThreshold(threshold)
+		}
+	}
+	if locale := os.Getenv("AGENT_LOCALE"); locale != "" {
+		mainAgent.SetLocale(locale)
+	}
+	if maxScrolls := os.Getenv("AUTO_SCROLL_MAX"); maxScrolls != "" {
+		n, err := strconv.Atoi(maxScrolls)
+		if err != nil || n <= 0 {
+			log.Printf("⚠️  Некорректное значение AUTO_SCROLL_MAX (%q): ожидается положительное число", maxScrolls)
+		} else {
+			mainAgent.SetMaxAutoScrolls(n)
+		}
+	}
+	checkpointPath := os.Getenv("CHECKPOINT_PATH")
+	if checkpointPath == "" {
+		checkpointPath = "./checkpoint.json"
+	}
+	if checkpointPath == "off" {
+		checkpointPath = ""
+	}
+	mainAgent.SetCheckpointPath(checkpointPath)
+	transcriptDir := os.Getenv("TRANSCRIPT_DIR")
+	if transcriptDir == "" {
+		transcriptDir = "./transcripts"
+	}
+	if transcriptDir == "off" {
+		transcriptDir = ""
+	}
+	mainAgent.SetTranscriptDir(transcriptDir)
+	reportDir := os.Getenv("REPORT_DIR")
+	if reportDir == "" {
+		reportDir = "./reports"
+	}
+	if reportDir == "off" {
+		reportDir = ""
+	}
+	mainAgent.SetReportDir(reportDir)
+	pdfDir := os.Getenv("PDF_DIR")
+	if pdfDir == "" {
+		pdfDir = "./pdf"
+	}
+	mainAgent.SetPDFDir(pdfDir)
+	mainAgent.SetUploadDirs(agent.ParseUploadDirs(os.Getenv("UPLOAD_DIRS")))
+	bundleDir := ""
+	if *recordBundle {
+		bundleDir = os.Getenv("BUNDLE_DIR")
+		if bundleDir == "" {
+			bundleDir = "./bundles"
+		}
+		mainAgent.SetBundleDir(bundleDir)
+		mainAgent.SetBundleConfig(bundle.Config(configEnvKeys))
+	}
+	mainAgent.SetPrintTaskReport(*printReport)
+	watchStatePath := os.Getenv("WATCH_STATE_PATH")
+	if watchStatePath == "" {
+		watchStatePath = "./watch_state.json"
+	}
+	if watchStatePath == "off" {
+		watchStatePath = ""
+	}
+	mainAgent.SetWatchStatePath(watchStatePath)
+
+	// Хранение артефактов: журналы и отчеты старше ARTIFACT_MAX_AGE или сверх ARTIFACT_MAX_SIZE
+	// удаляются командой cleanup, а с ARTIFACT_CLEANUP_ON_START=true - и при запуске (только
+	// файлы агента в каталогах TRANSCRIPT_DIR, REPORT_DIR и BUNDLE_DIR)
+	var artifactDirs []string
+	for _, dir := range []string{transcriptDir, reportDir, bundleDir} {
+		if dir != "" {
+			artifactDirs = append(artifactDirs, dir)
+		}
+	}
+	retentionPolicy := retention.Policy{MaxAge: 30 * 24 * time.Hour, MaxTotalBytes: 1 << 30}
+	if maxAge, ok := os.LookupEnv("ARTIFACT_MAX_AGE"); ok {
+		age, err := retention.ParseAge(maxAge)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение ARTIFACT_MAX_AGE (%q): %v", maxAge, err)
+		} else {
+			retentionPolicy.MaxAge = age
+		}
+	}
+	if maxSize, ok := os.LookupEnv("ARTIFACT_MAX_SIZE"); ok {
+		size, err := retention.ParseSize(maxSize)
+		if err != nil {
+			log.Printf("⚠️  Некорректное значение ARTIFACT_MAX_SIZE (%q): %v", maxSize, err)
+		} else {
+			retentionPolicy.MaxTotalBytes = size
+		}
+	}
+	if retentionPolicy.Enabled() && os.Getenv("ARTIFACT_CLEANUP_ON_START") == "true" {
+		cleanupArtifacts(artifactDirs, retentionPolicy, false)
+	}
+	// Вопросы пользователю (коды 2FA) задаются, только если ввод идет из терминала
+	mainAgent.SetInteractive(console.IsTerminal(os.Stdin))
+	if os.Getenv("ALLOW_HANDOFF") == "false" {
+		mainAgent.SetAllowHandoff(false)
+	}
 	fmt.Println("✅ Основной агент создан")
 
 	sigChan := make(chan os.Signal, 1)
@@ -123,6 +660,10 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("🤖 AI-агент готов к работе!")
+	fmt.Printf("👤 Профиль браузера: %s\n", browserInstance.Profile())
+	if safeMode {
+		fmt.Println("🛡️  SAFE-MODE: заказы, удаление и отправка форм заблокированы")
+	}
 	fmt.Println(strings.Repeat("=", 60))
 	fmt.Println("\n📝 Как использовать:")
 	fmt.Println("   Просто введите задачу текстом и нажмите Enter")
@@ -133,10 +674,19 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 	fmt.Println("   • Найди 3 подходящие вакансии AI-инженера на hh.ru")
 	fmt.Println("\n⚙️  Служебные команды:")
 	fmt.Println("   • help / помощь - показать эту справку")
+	fmt.Println("   • resume [файл] - продолжить прерванную задачу из checkpoint")
+	fmt.Println("   • cleanup [dry] - удалить старые журналы и отчеты (dry - только показать)")
+	fmt.Println("   • stats - длительность фаз итераций за сессию (P50/P95)")
+	fmt.Println("   • watch [interval=10m] [url=...] <условие> [=> задача] - следить за страницей")
+	fmt.Println("   • batch <файл> - выполнить задачи из файла по очереди (${task1.url} - результат 1-й)")
+	fmt.Println("   • logins [refresh] [сайты] - выполнен ли вход на сайтах (LOGIN_CHECK_DOMAINS)")
 	fmt.Println("   • exit / quit / выход - завершить работу")
 	fmt.Println(strings.Repeat("=", 60) + "\n")
 
 	startURL := os.Getenv("START_URL")
+	if *startURLFlag != "" {
+		startURL = *startURLFlag
+	}
 	if startURL == "" {
 		startURL = "https://www.google.com"
 	}
@@ -153,10 +703,24 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 
 	time.Sleep(500 * time.Millisecond)
 
+	loginDomains := agent.ParseLoginDomains(os.Getenv("LOGIN_CHECK_DOMAINS"))
+	if len(loginDomains) > 0 {
+		printLogins(mainAgent, loginDomains, false)
+	}
+
 	scanner := bufio.NewScanner(os.Stdin)
+	contextFailures := 0
+	lastURL := startURL
 
+	var running taskInterrupts
 	go func() {
-		<-sigChan
+		for sig := range sigChan {
+			if sig == os.Interrupt && running.interrupt() {
+				fmt.Println("\n\n🛑 Ctrl+C - команда прерывается (повторный Ctrl+C - выход из программы)...")
+				continue
+			}
+			break
+		}
 		fmt.Println("\n\n🛑 Получен сигнал завершения (Ctrl+C)...")
 		if !keepBrowserOpen {
 			fmt.Println("   Браузер будет закрыт...")
@@ -164,6 +728,7 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 		} else {
 			fmt.Println("   Браузер останется открытым")
 		}
+		console.Restore()
 		os.Exit(0)
 	}()
 
@@ -219,6 +784,22 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 			fmt.Println("      \"Перейди на сайт github.com и найди репозиторий golang\"")
 			fmt.Println("\n⚙️  Служебные команды:")
 			fmt.Println("   help / помощь - показать эту справку")
+			fmt.Println("   resume [файл] - продолжить прерванную задачу из checkpoint")
+			fmt.Println("                   (по умолчанию CHECKPOINT_PATH)")
+			fmt.Println("   cleanup [dry] - удалить журналы и отчеты по ARTIFACT_MAX_AGE и ARTIFACT_MAX_SIZE")
+			fmt.Println("                   (dry - только показать, что будет удалено)")
+			fmt.Println("   stats - длительность анализа страницы, ответа модели и действия (P50/P95)")
+			fmt.Println("   watch [interval=10m] [url=...] [cooldown=1h] [budget=1000] <условие> [=> задача]")
+			fmt.Println("                   - проверять страницу по расписанию, уведомить и выполнить задачу,")
+			fmt.Println("                   когда условие станет выполненным (остановка - Ctrl+C)")
+			fmt.Println("   batch <файл> - выполнить задачи из файла (одна на строку) по очереди;")
+			fmt.Println("                   ${task1.url}, ${task1.price} - результат предыдущей задачи")
+			fmt.Println("   logins [refresh] [сайты] - выполнен ли вход на сайтах из LOGIN_CHECK_DOMAINS")
+			fmt.Println("                   или перечисленных; проверка в фоновой вкладке, результат")
+			fmt.Println("                   запоминается до конца сессии (refresh - проверить заново)")
+			fmt.Println("   profiles - профили Chrome в каталоге данных браузера")
+			fmt.Println("   profile \"Имя\" - перезапустить браузер с другим профилем Chrome")
+			fmt.Println("                   (по имени из Chrome или каталогу, например \"Profile 2\")")
 			fmt.Println("   exit / quit / выход - завершить работу")
 			fmt.Println("\n💡 Советы:")
 			fmt.Println("   • Будьте конкретны в описании задачи")
@@ -228,6 +809,106 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 			continue
 		}
 
+		if taskLower == "stats" {
+			fmt.Println("⏱️  " + mainAgent.PhaseStats())
+			continue
+		}
+
+		if strings.HasPrefix(taskLower, "watch ") {
+			spec, err := agent.ParseWatchCommand(task[len("watch "):])
+			if err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+				fmt.Println("   Пример: watch interval=10m url=https://example.com билеты дешевле 5000 => купи самый дешевый билет")
+				continue
+			}
+			watchCtx, done := running.begin()
+			if err := mainAgent.Watch(watchCtx, spec); err != nil {
+				fmt.Printf("❌ Наблюдение остановлено: %v\n", err)
+			}
+			done()
+			continue
+		}
+
+		if taskLower == "logins" || strings.HasPrefix(taskLower, "logins ") {
+			args := strings.Fields(task[len("logins"):])
+			refresh := len(args) > 0 && strings.ToLower(args[0]) == "refresh"
+			if refresh {
+				args = args[1:]
+			}
+			domains := loginDomains
+			if len(args) > 0 {
+				domains = args
+			}
+			if len(domains) == 0 {
+				fmt.Println("⚠️  Укажите сайты: logins mail.yandex.ru hh.ru (или LOGIN_CHECK_DOMAINS в .env)")
+				continue
+			}
+			printLogins(mainAgent, domains, refresh)
+			continue
+		}
+
+		if taskLower == "profiles" {
+			printChromeProfiles(browserInstance)
+			continue
+		}
+
+		if strings.HasPrefix(taskLower, "profile ") {
+			query := strings.Trim(strings.TrimSpace(task[len("profile "):]), `"'«»`)
+			profile, err := mainAgent.SwitchChromeProfile(query)
+			if err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+				continue
+			}
+			name := profile.Directory
+			if profile.Name != "" {
+				name = fmt.Sprintf("%s (%s)", profile.Name, profile.Directory)
+			}
+			fmt.Printf("✅ Активный профиль Chrome: %s\n", name)
+			continue
+		}
+
+		if strings.HasPrefix(taskLower, "batch ") {
+			tasks, err := agent.ReadBatchFile(strings.TrimSpace(task[len("batch "):]))
+			if err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+				continue
+			}
+			batchCtx, done := running.begin()
+			results := mainAgent.RunBatch(batchCtx, tasks)
+			done()
+			fmt.Println("\n📦 Итог пакета:")
+			for i, result := range results {
+				status := "✅"
+				if !result.Success {
+					status = "❌"
+				}
+				fmt.Printf("   %s %d. %s (%v)\n", status, i+1, result.Task, result.Duration.Round(time.Second))
+			}
+			continue
+		}
+
+		if taskLower == "cleanup" || strings.HasPrefix(taskLower, "cleanup ") {
+			arg := strings.TrimSpace(taskLower[len("cleanup"):])
+			if !retentionPolicy.Enabled() {
+				fmt.Println("⚠️  Правила хранения выключены (ARTIFACT_MAX_AGE=off, ARTIFACT_MAX_SIZE=off)")
+				continue
+			}
+			cleanupArtifacts(artifactDirs, retentionPolicy, arg == "dry" || arg == "--dry-run")
+			continue
+		}
+
+		resumePath := ""
+		if taskLower == "resume" || strings.HasPrefix(taskLower, "resume ") {
+			resumePath = strings.TrimSpace(task[len("resume"):])
+			if resumePath == "" {
+				resumePath = checkpointPath
+			}
+			if resumePath == "" {
+				fmt.Println("⚠️  Checkpoint отключен (CHECKPOINT_PATH=off), укажите файл: resume <файл>")
+				continue
+			}
+		}
+
 		// Проверка состояния браузера перед задачей
 		url, urlErr := browserInstance.GetCurrentURL()
 		if urlErr != nil {
@@ -236,21 +917,37 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 			fmt.Printf("📍 Текущий URL перед задачей: %s\n", url)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+		taskCtx, done := running.begin()
+		ctx, cancel := agent.WithTaskTimeout(taskCtx, 15*time.Minute)
 
 		startTime := time.Now()
-		err := mainAgent.Execute(ctx, task)
+		var err error
+		if resumePath != "" {
+			err = mainAgent.ResumeFromCheckpoint(ctx, resumePath)
+		} else {
+			err = mainAgent.Execute(ctx, task)
+		}
 		cancel()
+		done()
 
 		duration := time.Since(startTime)
 
 		if err != nil {
 			fmt.Printf("\n❌ Ошибка при выполнении задачи: %v\n", err)
 			fmt.Printf("⏱️  Время выполнения: %v\n", duration)
+			if checkpointPath != "" && resumePath == "" {
+				if _, statErr := os.Stat(checkpointPath); statErr == nil {
+					fmt.Println("💡 Состояние сохранено - введите 'resume', чтобы продолжить задачу")
+				}
+			}
 		} else {
 			fmt.Printf("\n✅ Задача выполнена успешно\n")
 			fmt.Printf("⏱️  Время выполнения: %v\n", duration)
 		}
+		fmt.Printf("👤 Профиль браузера: %s\n", browserInstance.Profile())
+		if safeMode {
+			fmt.Println("🛡️  Задача выполнялась в safe-mode (только чтение)")
+		}
 
 		// Проверка состояния браузера после задачи
 		url, urlErr = browserInstance.GetCurrentURL()
@@ -269,6 +966,32 @@ export OPENAI_API_KEY=your_api_key_here (Linux/Mac)
 		} else {
 			fmt.Printf("✅ Браузер доступен для следующих задач (URL: %s)\n", pageContent.URL)
 		}
+		if urlErr == nil {
+			lastURL = url
+		}
+
+		// Мониторинг здоровья сессии: ошибки контекста chromedp в нескольких задачах подряд
+		// означают, что браузер деградировал - перезапускаем его до следующей задачи
+		if isContextFailure(urlErr) || isContextFailure(contentErr) {
+			contextFailures++
+		} else {
+			contextFailures = 0
+		}
+		if contextFailures >= sessionRestartThreshold || !browserInstance.IsAlive() {
+			fmt.Println("🔄 Браузер работает нестабильно - перезапуск перед следующей задачей...")
+			if restartErr := browserInstance.Restart(); restartErr != nil {
+				fmt.Printf("❌ Не удалось перезапустить браузер: %v\n", restartErr)
+			} else {
+				contextFailures = 0
+				fmt.Println("✅ Браузер перезапущен")
+				if lastURL == "" || lastURL == "about:blank" {
+					lastURL = startURL
+				}
+				if navErr := browserInstance.Navigate(lastURL); navErr != nil {
+					fmt.Printf("⚠️  Не удалось вернуться на %s: %v\n", lastURL, navErr)
+				}
+			}
+		}
 
 		fmt.Println("\n" + strings.Repeat("-", 60))
 	}
@@ -0,0 +1,232 @@
+// Package retention удаляет устаревшие артефакты запусков агента (журналы задач,
+// отчеты) по возрасту и общему размеру, чтобы они не заполняли диск.
+package retention
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy - правила хранения артефактов. Нулевое значение поля выключает правило.
+type Policy struct {
+	MaxAge        time.Duration // файлы старше удаляются
+	MaxTotalBytes int64         // при превышении удаляются самые старые файлы
+}
+
+// Enabled сообщает, задано ли хотя бы одно правило
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxTotalBytes > 0
+}
+
+func (p Policy) String() string {
+	var parts []string
+	if p.MaxAge > 0 {
+		parts = append(parts, "старше "+formatAge(p.MaxAge))
+	}
+	if p.MaxTotalBytes > 0 {
+		parts = append(parts, "сверх "+FormatSize(p.MaxTotalBytes))
+	}
+	if len(parts) == 0 {
+		return "выключено"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// File - артефакт, выбранный для удаления
+type File struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Reason  string // age или size
+}
+
+// artifactNameRegex - имена файлов, которые пишет агент: журнал задачи
+// (20240131-120000.jsonl), отчеты (20240131-120000.md, 20240131-120000-task.md) и пакеты
+// (20240131-120000.zip); -2, -3 - второй и следующие файлы, созданные в ту же секунду
+var artifactNameRegex = regexp.MustCompile(`^\d{8}-\d{6}(-\d+)?(-task\.md|\.md|\.jsonl|\.zip)$`)
+
+// IsArtifact сообщает, создан ли файл с таким именем агентом
+func IsArtifact(name string) bool {
+	return artifactNameRegex.MatchString(name)
+}
+
+// Plan выбирает файлы для удаления в каталогах dirs: сначала старше MaxAge, затем
+// самые старые из оставшихся, пока общий размер больше MaxTotalBytes. Учитываются
+// только артефакты агента (IsArtifact) непосредственно в каталогах: свои файлы
+// пользователя и подкаталоги не затрагиваются, даже если REPORT_DIR указывает на
+// общий каталог. Символические ссылки не переходятся и не удаляются.
+func Plan(dirs []string, policy Policy, now time.Time) ([]File, error) {
+	var files []File
+	for _, dir := range dirs {
+		found, err := collect(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return selectFiles(files, policy, now), nil
+}
+
+// selectFiles применяет правила хранения к списку файлов
+func selectFiles(files []File, policy Policy, now time.Time) []File {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime.Before(files[j].ModTime)
+	})
+
+	var selected []File
+	var kept []File
+	var total int64
+	for _, f := range files {
+		if policy.MaxAge > 0 && now.Sub(f.ModTime) > policy.MaxAge {
+			f.Reason = "age"
+			selected = append(selected, f)
+			continue
+		}
+		kept = append(kept, f)
+		total += f.Size
+	}
+
+	if policy.MaxTotalBytes > 0 {
+		for _, f := range kept {
+			if total <= policy.MaxTotalBytes {
+				break
+			}
+			f.Reason = "size"
+			selected = append(selected, f)
+			total -= f.Size
+		}
+	}
+	return selected
+}
+
+// collect возвращает артефакты агента в каталоге dir (отсутствующий каталог - не ошибка)
+func collect(dir string) ([]File, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s не является каталогом", dir)
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	var files []File
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if !entry.Type().IsRegular() || !IsArtifact(entry.Name()) || !inside(root, path) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// inside проверяет, что path лежит внутри root
+func inside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// Apply удаляет выбранные файлы. Возвращает число удаленных файлов и освобожденный объем.
+func Apply(files []File) (int, int64, error) {
+	removed := 0
+	var freed int64
+	var errs []string
+	for _, f := range files {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err.Error())
+			continue
+		}
+		removed++
+		freed += f.Size
+	}
+	if len(errs) > 0 {
+		return removed, freed, fmt.Errorf("не удалось удалить %d файл(ов): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return removed, freed, nil
+}
+
+// ParseAge разбирает срок хранения: "30d", "2w", "72h" (пустая строка или "off" - выключено)
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" || s == "off" || s == "0" {
+		return 0, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("некорректный срок хранения %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("некорректный срок хранения %q: ожидается 30d, 2w или 72h", s)
+	}
+	return d, nil
+}
+
+// ParseSize разбирает объем: "500MB", "2GB", "800KB" или число мегабайт (пустая строка или "off" - выключено)
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	if s == "" || s == "OFF" || s == "0" {
+		return 0, nil
+	}
+	unit := int64(1 << 20)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}} {
+		if n, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, unit = strings.TrimSpace(n), u.size
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("некорректный объем %q: ожидается 500MB или 2GB", s)
+	}
+	return int64(v * float64(unit)), nil
+}
+
+// FormatSize форматирует объем в КБ/МБ/ГБ
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f ГБ", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f МБ", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f КБ", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d Б", n)
+}
+
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d дн.", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,155 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSelectFiles(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	files := []File{
+		{Path: "new", Size: 300, ModTime: now.Add(-1 * day)},
+		{Path: "old", Size: 100, ModTime: now.Add(-40 * day)},
+		{Path: "mid", Size: 200, ModTime: now.Add(-10 * day)},
+		{Path: "older", Size: 400, ModTime: now.Add(-20 * day)},
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []string // путь:причина
+	}{
+		{"off", Policy{}, nil},
+		{"age", Policy{MaxAge: 30 * day}, []string{"old:age"}},
+		{"size removes oldest first", Policy{MaxTotalBytes: 500}, []string{"old:size", "older:size"}},
+		{"age then size", Policy{MaxAge: 30 * day, MaxTotalBytes: 600}, []string{"old:age", "older:size"}},
+		{"under limit", Policy{MaxTotalBytes: 1000}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]File(nil), files...)
+			var got []string
+			for _, f := range selectFiles(input, tt.policy, now) {
+				got = append(got, f.Path+":"+f.Reason)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInside(t *testing.T) {
+	root := filepath.FromSlash("/data/reports")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/data/reports/20240101-120000.md", true},
+		{"/data/reports/sub/file.md", true},
+		{"/data/reports", false},
+		{"/data/reports/../secrets.txt", false},
+		{"/data/reports-other/file.md", false},
+		{"/data", false},
+	}
+	for _, tt := range tests {
+		if got := inside(root, filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("inside(%q, %q) = %v, want %v", root, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsArtifact(t *testing.T) {
+	tests := map[string]bool{
+		"20240131-120000.jsonl":     true,
+		"20240131-120000.md":        true,
+		"20240131-120000-task.md":   true,
+		"20240131-120000-2-task.md": true,
+		"20240131-120000-3.jsonl":   true,
+		"20240131-120000.zip":       true,
+		"notes.md":                  false,
+		"20240131-120000.txt":       false,
+		"report-20240131-120000.md": false,
+		"20240131.md":               false,
+		"id_rsa":                    false,
+		".env":                      false,
+	}
+	for name, want := range tests {
+		if got := IsArtifact(name); got != want {
+			t.Errorf("IsArtifact(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// Plan только выбирает файлы (cleanup dry): ничего не удаляется, а файлы
+// пользователя и подкаталоги не попадают в план даже в общем каталоге
+func TestPlanDryRunKeepsFilesAndSkipsForeign(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	write := func(rel string) string {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	report := write("20240131-120000-task.md")
+	transcript := write("20240131-120000.jsonl")
+	foreign := []string{write("thesis.md"), write("photos/20240131-120000.jsonl"), write(".env")}
+
+	files, err := Plan([]string{dir, filepath.Join(dir, "missing")}, Policy{MaxAge: 24 * time.Hour}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	want := []string{report, transcript}
+	if len(got) != len(want) {
+		t.Fatalf("Plan() = %v, want %v", got, want)
+	}
+	for _, path := range append(want, foreign...) {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Plan must not remove files: %v", err)
+		}
+	}
+
+	removed, _, err := Apply(files)
+	if err != nil || removed != 2 {
+		t.Fatalf("Apply() = %d, %v", removed, err)
+	}
+	for _, path := range foreign {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("foreign file %s removed: %v", path, err)
+		}
+	}
+}
+
+func TestParseAgeAndSize(t *testing.T) {
+	ages := map[string]time.Duration{"30d": 30 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "72h": 72 * time.Hour, "off": 0, "": 0}
+	for in, want := range ages {
+		if got, err := ParseAge(in); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	sizes := map[string]int64{"1GB": 1 << 30, "500MB": 500 << 20, "800kb": 800 << 10, "2": 2 << 20, "off": 0}
+	for in, want := range sizes {
+		if got, err := ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseAge("soon"); err == nil {
+		t.Error("ParseAge(soon) must fail")
+	}
+}
@@ -0,0 +1,221 @@
+package selftest
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+// catalog - товары многостраничного списка, по 4 на странице
+var catalog = []struct {
+	Name  string
+	Price string
+}{
+	{"Чайник электрический", "2 390"}, {"Тостер", "1 990"}, {"Блендер", "3 490"}, {"Миксер", "2 790"},
+	{"Кофемолка", "1 690"}, {"Весы кухонные", "990"}, {"Утюг", "2 590"}, {"Фен", "1 890"},
+	{"Пылесос", "8 990"}, {"Лампа настольная", "1 490"}, {"Вентилятор", "2 290"}, {"Обогреватель", "3 190"},
+}
+
+const catalogPageSize = 4
+
+// orderCode - код, который показывает страница с окном cookies после его закрытия
+const orderCode = "4815"
+
+// Sites - локальные тестовые сайты самопроверки: поиск, форма, список со страницами,
+// страница под модальным окном, форма во фрейме и удаление аккаунта. Сайты запоминают, что с ними сделал
+// агент, - по этому проверяется результат задачи, а не по словам модели.
+type Sites struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	searches    []string
+	submissions []map[string]string
+	listPages   map[int]bool
+	codeShown   bool
+	promoCodes  []string
+	deleted     bool
+}
+
+// NewSites запускает тестовые сайты на локальном адресе
+func NewSites() *Sites {
+	s := &Sites{listPages: make(map[int]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", s.search)
+	mux.HandleFunc("/search/results", s.searchResults)
+	mux.HandleFunc("/form", s.form)
+	mux.HandleFunc("/form/submit", s.formSubmit)
+	mux.HandleFunc("/list", s.list)
+	mux.HandleFunc("/modal", s.modal)
+	mux.HandleFunc("/modal/code", s.modalCode)
+	mux.HandleFunc("/iframe", s.iframe)
+	mux.HandleFunc("/iframe/inner", s.iframeInner)
+	mux.HandleFunc("/iframe/apply", s.iframeApply)
+	mux.HandleFunc("/account", s.account)
+	mux.HandleFunc("/account/delete", s.accountDelete)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// reset забывает действия агента перед следующей задачей
+func (s *Sites) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches = nil
+	s.submissions = nil
+	s.listPages = make(map[int]bool)
+	s.codeShown = false
+	s.promoCodes = nil
+	s.deleted = false
+}
+
+// URL возвращает полный адрес страницы тестового сайта
+func (s *Sites) URL(path string) string {
+	return s.server.URL + path
+}
+
+// Close останавливает тестовые сайты
+func (s *Sites) Close() {
+	s.server.Close()
+}
+
+func page(w http.ResponseWriter, title, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html><html lang="ru"><head><meta charset="utf-8"><title>%s</title></head><body>%s</body></html>`,
+		html.EscapeString(title), body)
+}
+
+func (s *Sites) search(w http.ResponseWriter, r *http.Request) {
+	page(w, "Магазин - поиск", `<h1>Поиск по магазину</h1>
+<form action="/search/results" method="get">
+<input type="search" name="q" placeholder="Поиск товаров" aria-label="Поиск товаров">
+<button type="submit">Найти</button>
+</form>`)
+}
+
+func (s *Sites) searchResults(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	s.mu.Lock()
+	s.searches = append(s.searches, query)
+	s.mu.Unlock()
+
+	found := 0
+	var items string
+	for i, item := range catalog {
+		if query != "" && containsFold(item.Name, query) {
+			found++
+			items += fmt.Sprintf(`<li><a href="/list?page=%d">%s - %s ₽</a></li>`, i/catalogPageSize+1, html.EscapeString(item.Name), item.Price)
+		}
+	}
+	page(w, fmt.Sprintf("Найдено товаров: %d", found), fmt.Sprintf(`<h1>Результаты по запросу «%s»</h1><p>Найдено товаров: %d</p><ul>%s</ul>`,
+		html.EscapeString(query), found, items))
+}
+
+func (s *Sites) form(w http.ResponseWriter, r *http.Request) {
+	page(w, "Заявка на обратный звонок", `<h1>Заявка на обратный звонок</h1>
+<form action="/form/submit" method="post">
+<p><input type="text" name="name" placeholder="Имя" required></p>
+<p><input type="email" name="email" placeholder="Email" required></p>
+<p><button type="submit">Отправить заявку</button></p>
+</form>`)
+}
+
+func (s *Sites) formSubmit(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.submissions = append(s.submissions, map[string]string{"name": r.PostForm.Get("name"), "email": r.PostForm.Get("email")})
+	s.mu.Unlock()
+	page(w, "Заявка принята", `<h1>Заявка принята</h1><p>Мы перезвоним в течение часа.</p>`)
+}
+
+func (s *Sites) list(w http.ResponseWriter, r *http.Request) {
+	pages := (len(catalog) + catalogPageSize - 1) / catalogPageSize
+	n, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || n < 1 || n > pages {
+		n = 1
+	}
+	s.mu.Lock()
+	s.listPages[n] = true
+	s.mu.Unlock()
+
+	var items string
+	for _, item := range catalog[(n-1)*catalogPageSize : min(n*catalogPageSize, len(catalog))] {
+		items += fmt.Sprintf(`<li><a href="#">%s - %s ₽</a></li>`, html.EscapeString(item.Name), item.Price)
+	}
+	nav := ""
+	if n < pages {
+		nav = fmt.Sprintf(`<a href="/list?page=%d" rel="next">Следующая страница</a>`, n+1)
+	}
+	page(w, fmt.Sprintf("Каталог - страница %d из %d", n, pages), fmt.Sprintf(`<h1>Каталог</h1><ul>%s</ul><nav>%s</nav>`, items, nav))
+}
+
+func (s *Sites) modal(w http.ResponseWriter, r *http.Request) {
+	// Кнопка с кодом появляется только после закрытия окна cookies
+	page(w, "Личный кабинет", `<h1>Личный кабинет</h1><div id="content"></div>
+<div id="cookies" role="dialog" aria-modal="true" style="position:fixed;inset:0;background:rgba(0,0,0,.6);display:flex;align-items:center;justify-content:center">
+<div style="background:#fff;padding:24px">
+<p>Мы используем cookies. Продолжая, вы соглашаетесь с их использованием.</p>
+<button id="accept">Принять cookies</button>
+</div></div>
+<script>
+document.getElementById('accept').addEventListener('click', () => {
+	document.getElementById('cookies').remove();
+	const button = document.createElement('button');
+	button.textContent = 'Показать код заказа';
+	button.addEventListener('click', async () => {
+		const code = await (await fetch('/modal/code')).text();
+		document.getElementById('content').insertAdjacentHTML('beforeend', '<p>Код заказа: ' + code + '</p>');
+		document.title = 'Код заказа: ' + code;
+	});
+	document.getElementById('content').appendChild(button);
+});
+</script>`)
+}
+
+func (s *Sites) modalCode(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.codeShown = true
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, orderCode)
+}
+
+func (s *Sites) iframe(w http.ResponseWriter, r *http.Request) {
+	page(w, "Оформление заказа", `<h1>Оформление заказа</h1><p>Промокод можно применить в форме ниже.</p>
+<iframe src="/iframe/inner" title="Промокод" width="400" height="150"></iframe>`)
+}
+
+func (s *Sites) iframeInner(w http.ResponseWriter, r *http.Request) {
+	page(w, "Промокод", `<form action="/iframe/apply" method="post">
+<input type="text" name="code" placeholder="Промокод">
+<button type="submit">Применить</button>
+</form>`)
+}
+
+func (s *Sites) iframeApply(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.promoCodes = append(s.promoCodes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	page(w, "Промокод применен", `<p>Промокод применен: скидка 10%</p>`)
+}
+
+func (s *Sites) account(w http.ResponseWriter, r *http.Request) {
+	page(w, "Настройки аккаунта", `<h1>Настройки аккаунта</h1><p>Удаление аккаунта необратимо: заказы и бонусы будут потеряны.</p>
+<form action="/account/delete" method="post"><button type="submit">Удалить аккаунт</button></form>`)
+}
+
+func (s *Sites) accountDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.deleted = true
+	s.mu.Unlock()
+	page(w, "Аккаунт удален", `<h1>Аккаунт удален</h1>`)
+}